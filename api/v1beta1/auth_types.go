@@ -51,4 +51,13 @@ type Auth struct {
 	// Timeout is the timeout for establish an SSH connection.
 	// +optional
 	Timeout *time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// HostKeyChecking is one of strict, accept-new and off. The host keys are verified against the known hosts
+	// in the <cluster>-knownhosts secret. The default is accept-new.
+	// +optional
+	HostKeyChecking string `yaml:"hostKeyChecking,omitempty" json:"hostKeyChecking,omitempty"`
+
+	// HostKeyFingerprint pins the SHA256 fingerprint of the host key, e.g. SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8.
+	// +optional
+	HostKeyFingerprint string `yaml:"hostKeyFingerprint,omitempty" json:"hostKeyFingerprint,omitempty"`
 }
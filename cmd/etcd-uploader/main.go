@@ -0,0 +1,68 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// The etcd-uploader uploads an etcd snapshot to the backup target. KubeKey builds it for each architecture of the
+// etcd nodes and copies it into the backupScript dir, the backup script runs it after saving a snapshot.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/etcd/uploader"
+)
+
+func main() {
+	config := flag.String("config", uploader.DefaultConfigPath, "Path to the backup target config written by KubeKey")
+	file := flag.String("file", "", "Path to the etcd snapshot to upload")
+	node := flag.String("node", "", "Name of the etcd node, the snapshot is uploaded under <prefix>/<node>/")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := run(ctx, *config, *file, *node); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, config, file, node string) error {
+	if file == "" || node == "" {
+		return errors.New("the snapshot file and the node name can not be empty, specify them by --file and --node")
+	}
+	data, err := os.ReadFile(config)
+	if err != nil {
+		return errors.Wrapf(err, "read the backup target config %s failed", config)
+	}
+	cfg := &uploader.Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return errors.Wrapf(err, "parse the backup target config %s failed", config)
+	}
+
+	key, err := uploader.Upload(ctx, cfg, file, node)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("uploaded %s to %s\n", file, key)
+	return nil
+}
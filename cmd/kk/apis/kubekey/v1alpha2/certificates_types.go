@@ -0,0 +1,121 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	RSA   = "RSA"
+	ECDSA = "ECDSA"
+)
+
+// Certificates configures the CAs and the certs issued by kubekey. The kubernetes certs are issued by kubeadm, which
+// generates RSA 2048 keys, signs the certs for 1 year and the CAs for 10 years, so the key algorithm, the key size
+// and the validity only apply to the etcd and the registry certs.
+type Certificates struct {
+	// KeyAlgorithm is the algorithm of the private keys generated by kubekey, RSA or ECDSA. The default is RSA.
+	KeyAlgorithm string `yaml:"keyAlgorithm" json:"keyAlgorithm,omitempty"`
+	// KeySize is the size of the RSA keys in bits, or the curve size of the ECDSA keys: 256, 384 or 521.
+	// The default is 2048 for RSA and 256 for ECDSA.
+	KeySize int `yaml:"keySize" json:"keySize,omitempty"`
+	// Validity is the validity of the certs signed by kubekey, e.g. 8760h. It is limited by the validity of the CA.
+	// The default is 10 years.
+	Validity string `yaml:"validity" json:"validity,omitempty"`
+	// Kubernetes is the CA used by kubeadm to sign the kubernetes certs. Only caFile, caKeyFile and extraSANs are
+	// supported, the key of the CA is required since kubeadm signs the certs on the masters.
+	Kubernetes ComponentCerts `yaml:"kubernetes" json:"kubernetes,omitempty"`
+	// Etcd is the CA and the certs of the etcd deployed by kubekey.
+	Etcd ComponentCerts `yaml:"etcd" json:"etcd,omitempty"`
+	// Registry is the CA and the certs of the registry deployed by kubekey.
+	Registry ComponentCerts `yaml:"registry" json:"registry,omitempty"`
+}
+
+// ComponentCerts brings an existing CA, e.g. a corporate intermediate CA, instead of the self-signed one generated
+// by kubekey.
+type ComponentCerts struct {
+	// CAFile is the cert of the CA. It may be followed by the chain of the CA.
+	CAFile string `yaml:"caFile" json:"caFile,omitempty"`
+	// CAKeyFile is the key of the CA. If it is empty, kubekey can not sign the certs, and all of them must be
+	// pre-issued in CertsDir.
+	CAKeyFile string `yaml:"caKeyFile" json:"caKeyFile,omitempty"`
+	// CertsDir contains the pre-issued certs and keys, which are named as the ones generated by kubekey, e.g.
+	// member-node1.pem and member-node1-key.pem. They are used instead of the ones signed by kubekey.
+	CertsDir string `yaml:"certsDir" json:"certsDir,omitempty"`
+	// ExtraSANs are the extra DNS names and IP addresses of the server certs.
+	ExtraSANs []string `yaml:"extraSANs" json:"extraSANs,omitempty"`
+}
+
+// IsExternal reports whether the certs are signed by an existing CA instead of the one generated by kubekey.
+func (c ComponentCerts) IsExternal() bool {
+	return c.CAFile != ""
+}
+
+// Validate checks the certificates config before any cert is generated.
+func (c Certificates) Validate() error {
+	if _, err := c.GetKeyAlgorithm(); err != nil {
+		return err
+	}
+	if _, err := c.GetValidity(); err != nil {
+		return err
+	}
+	if c.Kubernetes.IsExternal() && c.Kubernetes.CAKeyFile == "" {
+		return fmt.Errorf("certificates.kubernetes.caKeyFile is required with the caFile, kubeadm signs the kubernetes " +
+			"certs with the CA so they can not be pre-issued")
+	}
+	if c.Kubernetes.CertsDir != "" {
+		return fmt.Errorf("certificates.kubernetes.certsDir is not supported, the kubernetes certs are signed by kubeadm")
+	}
+	components := []struct {
+		name string
+		cfg  ComponentCerts
+	}{{"kubernetes", c.Kubernetes}, {"etcd", c.Etcd}, {"registry", c.Registry}}
+	for _, component := range components {
+		if cfg := component.cfg; !cfg.IsExternal() && (cfg.CAKeyFile != "" || cfg.CertsDir != "") {
+			return fmt.Errorf("certificates.%s.caKeyFile and certificates.%s.certsDir require the caFile", component.name, component.name)
+		}
+	}
+	return nil
+}
+
+// GetKeyAlgorithm returns the algorithm of the private keys.
+func (c Certificates) GetKeyAlgorithm() (x509.PublicKeyAlgorithm, error) {
+	switch strings.ToUpper(c.KeyAlgorithm) {
+	case "", RSA:
+		return x509.RSA, nil
+	case ECDSA:
+		return x509.ECDSA, nil
+	default:
+		return x509.UnknownPublicKeyAlgorithm, fmt.Errorf("unsupported key algorithm %q, it should be %s or %s", c.KeyAlgorithm, RSA, ECDSA)
+	}
+}
+
+// GetValidity returns the validity of the certs, it is 0 if it is not set.
+func (c Certificates) GetValidity() (time.Duration, error) {
+	if c.Validity == "" {
+		return 0, nil
+	}
+	validity, err := time.ParseDuration(c.Validity)
+	if err != nil || validity <= 0 {
+		return 0, fmt.Errorf("invalid certificate validity %q, it should be a positive duration such as 8760h", c.Validity)
+	}
+	return validity, nil
+}
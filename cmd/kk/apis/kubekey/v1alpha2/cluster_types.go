@@ -35,6 +35,7 @@ import (
 type ClusterSpec struct {
 	Hosts                []HostCfg            `yaml:"hosts" json:"hosts,omitempty"`
 	RoleGroups           map[string][]string  `yaml:"roleGroups" json:"roleGroups,omitempty"`
+	SSH                  SSH                  `yaml:"ssh" json:"ssh,omitempty"`
 	ControlPlaneEndpoint ControlPlaneEndpoint `yaml:"controlPlaneEndpoint" json:"controlPlaneEndpoint,omitempty"`
 	System               System               `yaml:"system" json:"system,omitempty"`
 	Etcd                 EtcdCluster          `yaml:"etcd" json:"etcd,omitempty"`
@@ -43,6 +44,7 @@ type ClusterSpec struct {
 	Network              NetworkConfig        `yaml:"network" json:"network,omitempty"`
 	Storage              StorageConfig        `yaml:"storage" json:"storage,omitempty"`
 	Registry             RegistryConfig       `yaml:"registry" json:"registry,omitempty"`
+	Certificates         Certificates         `yaml:"certificates" json:"certificates,omitempty"`
 	Addons               []Addon              `yaml:"addons" json:"addons,omitempty"`
 	KubeSphere           KubeSphere           `json:"kubesphere,omitempty"`
 }
@@ -67,6 +69,17 @@ type HostCfg struct {
 	Arch            string `yaml:"arch,omitempty" json:"arch,omitempty"`
 	Timeout         *int64 `yaml:"timeout,omitempty" json:"timeout,omitempty"`
 
+	// HostKeyChecking overrides the global ssh.hostKeyChecking for the host.
+	HostKeyChecking string `yaml:"hostKeyChecking,omitempty" json:"hostKeyChecking,omitempty"`
+	// HostKeyFingerprint pins the SHA256 fingerprint of the host key, e.g. SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8.
+	HostKeyFingerprint string `yaml:"hostKeyFingerprint,omitempty" json:"hostKeyFingerprint,omitempty"`
+	// Bastion overrides the global ssh.bastion for the host, an empty bastion connects to the host directly.
+	Bastion *Bastion `yaml:"bastion,omitempty" json:"bastion,omitempty"`
+	// MaxSessions overrides the global ssh.maxSessions for the host.
+	MaxSessions int `yaml:"maxSessions,omitempty" json:"maxSessions,omitempty"`
+	// KeepAliveInterval overrides the global ssh.keepAliveInterval for the host.
+	KeepAliveInterval int64 `yaml:"keepAliveInterval,omitempty" json:"keepAliveInterval,omitempty"`
+
 	// Labels defines the kubernetes labels for the node.
 	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
 }
@@ -79,12 +92,24 @@ type ControlPlaneEndpoint struct {
 	Address              string  `yaml:"address" json:"address,omitempty"`
 	Port                 int     `yaml:"port" json:"port,omitempty"`
 	KubeVip              KubeVip `yaml:"kubevip" json:"kubevip,omitempty"`
+
+	Keepalived KeepalivedCfg `yaml:"keepalived" json:"keepalived,omitempty"`
 }
 
 type KubeVip struct {
 	Mode string `yaml:"mode" json:"mode,omitempty"`
 }
 
+// KeepalivedCfg describes the keepalived which holds the VIP on the loadbalancer nodes in front of haproxy.
+type KeepalivedCfg struct {
+	// Interface is the network interface the VIP is bound to. It is detected on each node if empty.
+	Interface string `yaml:"interface" json:"interface,omitempty"`
+	// VirtualRouterID identifies the VRRP instance, it must be unique in the same network.
+	VirtualRouterID int `yaml:"virtualRouterID" json:"virtualRouterID,omitempty"`
+	// AuthPass is the password of the VRRP instance, only the first 8 characters are used.
+	AuthPass string `yaml:"authPass" json:"authPass,omitempty"`
+}
+
 // CustomScripts defines the custom shell scripts for each node to exec before and finished kubernetes install.
 type CustomScripts struct {
 	Name      string   `yaml:"name" json:"name,omitempty"`
@@ -115,6 +140,58 @@ type RegistryConfig struct {
 	NamespaceOverride  string               `yaml:"namespaceOverride" json:"namespaceOverride,omitempty"`
 	BridgeIP           string               `yaml:"bridgeIP" json:"bridgeIP,omitempty"`
 	Auths              runtime.RawExtension `yaml:"auths" json:"auths,omitempty"`
+	Harbor             HarborCfg            `yaml:"harbor" json:"harbor,omitempty"`
+}
+
+// HarborCfg describes the Harbor installed by `kk init registry`. When there are more than one registry hosts,
+// Harbor runs on all of them in HA mode: the instances share the storage, the database and the redis, and are
+// reached by the registry domain resolved to Address.
+type HarborCfg struct {
+	// Address is the VIP or the address of the load balancer in front of the registry hosts.
+	Address string `yaml:"address" json:"address,omitempty"`
+	// InternalLoadbalancer is "keepalived" to hold Address as the VIP on the registry hosts, leave it empty if there
+	// is an external load balancer.
+	InternalLoadbalancer string        `yaml:"internalLoadbalancer" json:"internalLoadbalancer,omitempty"`
+	Keepalived           KeepalivedCfg `yaml:"keepalived" json:"keepalived,omitempty"`
+	// AdminPassword is the initial password of the Harbor admin.
+	AdminPassword string `yaml:"adminPassword" json:"adminPassword,omitempty"`
+	// DataVolume is the data directory of Harbor. It must be a shared storage mounted on all the registry hosts in HA
+	// mode, e.g. NFS, unless the images are stored in S3.
+	DataVolume       string             `yaml:"dataVolume" json:"dataVolume,omitempty"`
+	S3               *HarborS3          `yaml:"s3" json:"s3,omitempty"`
+	ExternalDatabase *HarborDatabaseCfg `yaml:"externalDatabase" json:"externalDatabase,omitempty"`
+	ExternalRedis    *HarborRedisCfg    `yaml:"externalRedis" json:"externalRedis,omitempty"`
+	// Projects are created once Harbor is started, besides the namespaceOverride of the registry.
+	Projects []string `yaml:"projects" json:"projects,omitempty"`
+}
+
+// HarborS3 is the S3 compatible storage the images are stored in.
+type HarborS3 struct {
+	Region         string `yaml:"region" json:"region,omitempty"`
+	Bucket         string `yaml:"bucket" json:"bucket,omitempty"`
+	AccessKey      string `yaml:"accessKey" json:"accessKey,omitempty"`
+	SecretKey      string `yaml:"secretKey" json:"secretKey,omitempty"`
+	RegionEndpoint string `yaml:"regionEndpoint" json:"regionEndpoint,omitempty"`
+	RootDirectory  string `yaml:"rootDirectory" json:"rootDirectory,omitempty"`
+	Secure         bool   `yaml:"secure" json:"secure,omitempty"`
+}
+
+// HarborDatabaseCfg is the external PostgreSQL database of Harbor.
+type HarborDatabaseCfg struct {
+	Host     string `yaml:"host" json:"host,omitempty"`
+	Port     int    `yaml:"port" json:"port,omitempty"`
+	DBName   string `yaml:"dbName" json:"dbName,omitempty"`
+	Username string `yaml:"username" json:"username,omitempty"`
+	Password string `yaml:"password" json:"password,omitempty"`
+	SSLMode  string `yaml:"sslMode" json:"sslMode,omitempty"`
+}
+
+// HarborRedisCfg is the external redis of Harbor.
+type HarborRedisCfg struct {
+	// Host is <host>:<port> of the redis, or the comma separated sentinels when SentinelMasterSet is set.
+	Host              string `yaml:"host" json:"host,omitempty"`
+	Password          string `yaml:"password" json:"password,omitempty"`
+	SentinelMasterSet string `yaml:"sentinelMasterSet" json:"sentinelMasterSet,omitempty"`
 }
 
 // KubeSphere defines the configuration information of the KubeSphere.
@@ -161,6 +238,7 @@ func (cfg *ClusterSpec) GenerateCertSANs() []string {
 	if cfg.Kubernetes.ApiserverCertExtraSans != nil {
 		defaultCertSANs = append(defaultCertSANs, cfg.Kubernetes.ApiserverCertExtraSans...)
 	}
+	defaultCertSANs = append(defaultCertSANs, cfg.Certificates.Kubernetes.ExtraSANs...)
 
 	return defaultCertSANs
 }
@@ -209,6 +287,11 @@ func toHosts(cfg HostCfg) *KubeHost {
 	host.PrivateKeyPath = cfg.PrivateKeyPath
 	host.Arch = cfg.Arch
 	host.Timeout = *cfg.Timeout
+	host.HostKeyChecking = cfg.HostKeyChecking
+	host.HostKeyFingerprint = cfg.HostKeyFingerprint
+	host.Bastion = cfg.Bastion.Chain()
+	host.MaxSessions = cfg.MaxSessions
+	host.KeepAliveInterval = cfg.KeepAliveInterval
 
 	kubeHost := &KubeHost{
 		BaseHost: host,
@@ -305,6 +388,10 @@ func (c ControlPlaneEndpoint) IsInternalLBEnabledVip() bool {
 	return c.InternalLoadbalancer == Kubevip
 }
 
+func (c ControlPlaneEndpoint) IsInternalLBEnabledKeepalived() bool {
+	return c.InternalLoadbalancer == Keepalived
+}
+
 // EnableExternalDNS is used to determine whether to use external dns to resolve kube-apiserver domain.
 func (c *ControlPlaneEndpoint) EnableExternalDNS() bool {
 	if c.ExternalDNS == nil {
@@ -313,6 +400,11 @@ func (c *ControlPlaneEndpoint) EnableExternalDNS() bool {
 	return *c.ExternalDNS
 }
 
+// IsHarborKeepalivedEnabled reports whether keepalived holds the Harbor address as the VIP on the registry hosts.
+func (r *RegistryConfig) IsHarborKeepalivedEnabled() bool {
+	return r.Harbor.InternalLoadbalancer == Keepalived
+}
+
 func (r *RegistryConfig) GetHost() string {
 	if r.PrivateRegistry == "" {
 		return ""
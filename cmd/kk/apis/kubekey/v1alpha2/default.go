@@ -21,7 +21,9 @@ import (
 	"os"
 	"strings"
 
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
+	"github.com/kubesphere/kubekey/v3/util/hostkey"
 )
 
 const (
@@ -39,12 +41,14 @@ const (
 	DefaultDNSDomain               = "cluster.local"
 	DefaultArch                    = "amd64"
 	DefaultSSHTimeout              = 30
+	DefaultHostKeyChecking         = hostkey.CheckingAcceptNew
 	DefaultEtcdVersion             = "v3.5.13"
 	DefaultEtcdPort                = "2379"
 	DefaultDockerVersion           = "24.0.9"
 	DefaultCriDockerdVersion       = "0.3.10"
 	DefaultBuildxVersion           = "v0.14.0"
 	DefaultContainerdVersion       = "1.7.13"
+	DefaultCrioVersion             = "1.29.1"
 	DefaultRuncVersion             = "v1.1.12"
 	DefaultCrictlVersion           = "v1.29.0"
 	DefaultKubeVersion             = "v1.23.15"
@@ -78,10 +82,13 @@ const (
 	Worker                         = "worker"
 	K8s                            = "k8s"
 	Registry                       = "registry"
+	Loadbalancer                   = "loadbalancer"
 	DefaultEtcdBackupDir           = "/var/backups/kube_etcd"
 	DefaultEtcdBackupPeriod        = 1440
 	DefaultKeepBackNumber          = 5
 	DefaultEtcdBackupScriptDir     = "/usr/local/bin/kube-scripts"
+	DefaultEtcdPeerPort            = 2380
+	DefaultEtcdClusterToken        = "k8s_etcd"
 	DefaultPodGateway              = "10.233.64.1"
 	DefaultJoinCIDR                = "100.64.0.0/16"
 	DefaultNetworkType             = "geneve"
@@ -102,9 +109,15 @@ const (
 	Crio       = "crio"
 	Isula      = "isula"
 
-	Haproxy            = "haproxy"
-	Kubevip            = "kube-vip"
-	DefaultKubeVipMode = "ARP"
+	Haproxy                   = "haproxy"
+	Kubevip                   = "kube-vip"
+	Keepalived                = "keepalived"
+	DefaultKubeVipMode        = "ARP"
+	DefaultKeepalivedRouterID = 51
+	DefaultKeepalivedAuthPass = "kubekey"
+
+	// DefaultHarborKeepalivedRouterID differs from the one of the control plane, in case they share the network.
+	DefaultHarborKeepalivedRouterID = 52
 )
 
 func (cfg *ClusterSpec) SetDefaultClusterSpec() (*ClusterSpec, map[string][]*KubeHost) {
@@ -120,7 +133,7 @@ func (cfg *ClusterSpec) SetDefaultClusterSpec() (*ClusterSpec, map[string][]*Kub
 	clusterCfg.System = cfg.System
 	clusterCfg.Kubernetes = SetDefaultClusterCfg(cfg)
 	clusterCfg.DNS = cfg.DNS
-	clusterCfg.Registry = cfg.Registry
+	clusterCfg.Registry = SetDefaultRegistryCfg(cfg)
 	clusterCfg.Addons = cfg.Addons
 	clusterCfg.KubeSphere = cfg.KubeSphere
 
@@ -183,11 +196,51 @@ func SetDefaultHostsCfg(cfg *ClusterSpec) []HostCfg {
 			host.Timeout = &timeout
 		}
 
+		if host.HostKeyChecking == "" {
+			host.HostKeyChecking = cfg.SSH.HostKeyChecking
+		}
+		if host.HostKeyChecking == "" {
+			host.HostKeyChecking = DefaultHostKeyChecking
+		}
+
+		if host.Bastion == nil {
+			host.Bastion = cfg.SSH.Bastion
+		}
+		if host.MaxSessions == 0 {
+			host.MaxSessions = cfg.SSH.MaxSessions
+		}
+		if host.KeepAliveInterval == 0 {
+			host.KeepAliveInterval = cfg.SSH.KeepAliveInterval
+		}
+		host.Bastion = SetDefaultBastion(host.Bastion)
+
 		hostCfg = append(hostCfg, host)
 	}
 	return hostCfg
 }
 
+func SetDefaultBastion(bastion *Bastion) *Bastion {
+	if bastion == nil {
+		return nil
+	}
+	res := &Bastion{JumpHost: setDefaultJumpHost(bastion.JumpHost)}
+	for _, hop := range bastion.Hops {
+		res.Hops = append(res.Hops, setDefaultJumpHost(hop))
+	}
+	return res
+}
+
+func setDefaultJumpHost(hop connector.JumpHost) connector.JumpHost {
+	if hop.Address != "" && hop.Port == 0 {
+		hop.Port = DefaultSSHPort
+	}
+	if hop.PrivateKeyPath != "" && strings.HasPrefix(strings.TrimSpace(hop.PrivateKeyPath), "~/") {
+		homeDir, _ := util.Home()
+		hop.PrivateKeyPath = strings.Replace(hop.PrivateKeyPath, "~/", fmt.Sprintf("%s/", homeDir), 1)
+	}
+	return hop
+}
+
 func SetDefaultLBCfg(cfg *ClusterSpec, masterGroup []*KubeHost) ControlPlaneEndpoint {
 	//Check whether LB should be configured
 	if len(masterGroup) >= 2 && !cfg.ControlPlaneEndpoint.IsInternalLBEnabled() && cfg.ControlPlaneEndpoint.Address == "" && !cfg.ControlPlaneEndpoint.EnableExternalDNS() {
@@ -202,6 +255,12 @@ func SetDefaultLBCfg(cfg *ClusterSpec, masterGroup []*KubeHost) ControlPlaneEndp
 		os.Exit(0)
 	}
 
+	// The keepalived load balancer holds the LB address as the VIP, so it can not be guessed
+	if cfg.ControlPlaneEndpoint.IsInternalLBEnabledKeepalived() && cfg.ControlPlaneEndpoint.Address == "" {
+		fmt.Println("You must set the LB address as the VIP to use the keepalived load balancer.")
+		os.Exit(1)
+	}
+
 	if (cfg.ControlPlaneEndpoint.Address == "" && !cfg.ControlPlaneEndpoint.EnableExternalDNS()) || cfg.ControlPlaneEndpoint.Address == "127.0.0.1" {
 		cfg.ControlPlaneEndpoint.Address = masterGroup[0].GetInternalIPv4Address()
 	}
@@ -214,10 +273,26 @@ func SetDefaultLBCfg(cfg *ClusterSpec, masterGroup []*KubeHost) ControlPlaneEndp
 	if cfg.ControlPlaneEndpoint.KubeVip.Mode == "" {
 		cfg.ControlPlaneEndpoint.KubeVip.Mode = DefaultKubeVipMode
 	}
+	if cfg.ControlPlaneEndpoint.Keepalived.VirtualRouterID == 0 {
+		cfg.ControlPlaneEndpoint.Keepalived.VirtualRouterID = DefaultKeepalivedRouterID
+	}
+	if cfg.ControlPlaneEndpoint.Keepalived.AuthPass == "" {
+		cfg.ControlPlaneEndpoint.Keepalived.AuthPass = DefaultKeepalivedAuthPass
+	}
 	defaultLbCfg := cfg.ControlPlaneEndpoint
 	return defaultLbCfg
 }
 
+func SetDefaultRegistryCfg(cfg *ClusterSpec) RegistryConfig {
+	if cfg.Registry.Harbor.Keepalived.VirtualRouterID == 0 {
+		cfg.Registry.Harbor.Keepalived.VirtualRouterID = DefaultHarborKeepalivedRouterID
+	}
+	if cfg.Registry.Harbor.Keepalived.AuthPass == "" {
+		cfg.Registry.Harbor.Keepalived.AuthPass = DefaultKeepalivedAuthPass
+	}
+	return cfg.Registry
+}
+
 func SetDefaultNetworkCfg(cfg *ClusterSpec) NetworkConfig {
 	if cfg.Network.Plugin == "" {
 		cfg.Network.Plugin = DefaultNetworkPlugin
@@ -354,6 +429,20 @@ func SetDefaultEtcdCfg(cfg *ClusterSpec) EtcdCluster {
 	if cfg.Etcd.BackupScriptDir == "" {
 		cfg.Etcd.BackupScriptDir = DefaultEtcdBackupScriptDir
 	}
+	if cfg.Etcd.PeerPort == 0 {
+		cfg.Etcd.PeerPort = DefaultEtcdPeerPort
+	}
+	if cfg.Etcd.InitialClusterToken == "" {
+		cfg.Etcd.InitialClusterToken = DefaultEtcdClusterToken
+	}
+	// keep the snapshots in the backup target as long as the keepBackupNumber ones in the backupDir
+	if cfg.Etcd.BackupTarget != nil && cfg.Etcd.BackupTarget.RetentionDays == 0 {
+		minutes := cfg.Etcd.KeepBackupNumber * cfg.Etcd.BackupPeriod
+		cfg.Etcd.BackupTarget.RetentionDays = (minutes + 24*60 - 1) / (24 * 60)
+		if cfg.Etcd.BackupTarget.RetentionDays < 1 {
+			cfg.Etcd.BackupTarget.RetentionDays = 1
+		}
+	}
 
 	return cfg.Etcd
 }
@@ -32,6 +32,8 @@ type EtcdCluster struct {
 	KeepBackupNumber        int          `yaml:"keepBackupNumber" json:"keepBackupNumber,omitempty"`
 	BackupScriptDir         string       `yaml:"backupScript" json:"backupScript,omitempty"`
 	DataDir                 *string      `yaml:"dataDir" json:"dataDir,omitempty"`
+	PeerPort                int          `yaml:"peerPort" json:"peerPort,omitempty"`
+	InitialClusterToken     string       `yaml:"initialClusterToken" json:"initialClusterToken,omitempty"`
 	HeartbeatInterval       *int         `yaml:"heartbeatInterval" json:"heartbeatInterval,omitempty"`
 	ElectionTimeout         *int         `yaml:"electionTimeout" json:"electionTimeout,omitempty"`
 	SnapshotCount           *int         `yaml:"snapshotCount" json:"snapshotCount,omitempty"`
@@ -42,6 +44,32 @@ type EtcdCluster struct {
 	MaxSnapshots            *int         `yaml:"maxSnapshots" json:"maxSnapshots,omitempty"`
 	MaxWals                 *int         `yaml:"maxWals" json:"maxWals,omitempty"`
 	LogLevel                *string      `yaml:"logLevel" json:"logLevel"`
+
+	// BackupTarget is the object storage that the backups are uploaded to besides the backupDir on the etcd nodes.
+	BackupTarget *BackupTarget `yaml:"backupTarget" json:"backupTarget,omitempty"`
+}
+
+// BackupTarget describes an S3-compatible object storage, such as AWS S3 or MinIO, that the etcd snapshots are uploaded
+// to by the backup service. The snapshots of each etcd node are kept under <prefix>/<node name>/ in the bucket.
+type BackupTarget struct {
+	// Endpoint is the URL of the object storage, e.g. https://s3.us-west-2.amazonaws.com or http://10.0.0.2:9000.
+	Endpoint string `yaml:"endpoint" json:"endpoint,omitempty"`
+	Region   string `yaml:"region" json:"region,omitempty"`
+	Bucket   string `yaml:"bucket" json:"bucket,omitempty"`
+	Prefix   string `yaml:"prefix" json:"prefix,omitempty"`
+	// VirtualHostedStyle addresses the bucket by the host name instead of the path.
+	VirtualHostedStyle bool `yaml:"virtualHostedStyle" json:"virtualHostedStyle,omitempty"`
+	// CAFile is the path of the CA file of the object storage on the machine running KubeKey.
+	CAFile             string `yaml:"caFile" json:"caFile,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify" json:"insecureSkipVerify,omitempty"`
+	// CredentialsFile is the path of the file with the access key on the machine running KubeKey, in the form of
+	// AWS_ACCESS_KEY_ID=<id> and AWS_SECRET_ACCESS_KEY=<secret> lines or an AWS credentials file. The environment
+	// variables AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are used if it is empty.
+	CredentialsFile string `yaml:"credentialsFile" json:"credentialsFile,omitempty"`
+	// RetentionDays is the number of the days the snapshots are kept in the object storage, KubeKey sets a lifecycle
+	// rule of the bucket which expires the snapshots under the prefix after it. A negative number leaves the lifecycle
+	// rules of the bucket alone.
+	RetentionDays int `yaml:"retentionDays" json:"retentionDays,omitempty"`
 }
 
 // ExternalEtcd describes how to connect to an external etcd cluster
@@ -0,0 +1,59 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+)
+
+// SSH defines the ssh settings of all hosts, a host can override them in its own config.
+type SSH struct {
+	// HostKeyChecking is one of strict, accept-new and off. The host keys are verified against the known_hosts
+	// file in the work dir of KubeKey. The default is accept-new.
+	HostKeyChecking string `yaml:"hostKeyChecking" json:"hostKeyChecking,omitempty"`
+	// MaxSessions caps the concurrent sessions on the connection to a host, it is lowered if the server allows fewer.
+	// The default is 10, the default MaxSessions of OpenSSH.
+	MaxSessions int `yaml:"maxSessions" json:"maxSessions,omitempty"`
+	// KeepAliveInterval is the interval in seconds of the keepalive probes, the connection is re-established after
+	// 3 probes fail in a row. The default is 15.
+	KeepAliveInterval int64 `yaml:"keepAliveInterval" json:"keepAliveInterval,omitempty"`
+	// Bastion is the jump host, or the chain of jump hosts, through which all hosts are connected.
+	Bastion *Bastion `yaml:"bastion,omitempty" json:"bastion,omitempty"`
+}
+
+// Bastion defines the jump hosts in front of the hosts. Each hop has its own address, user and credentials:
+// password, privateKey, privateKeyPath or agentSocket. The agentSocket is a unix socket path, or env:NAME to read
+// the path from an environment variable, e.g. env:SSH_AUTH_SOCK. A hop without any credentials uses the ones of the host.
+type Bastion struct {
+	connector.JumpHost `yaml:",inline" json:",inline"`
+	// Hops are the jump hosts connected in order before the bastion above, like the ProxyJump of OpenSSH.
+	// The bastion above can be left empty if the last hop is the bastion.
+	Hops []connector.JumpHost `yaml:"hops,omitempty" json:"hops,omitempty"`
+}
+
+// Chain returns the jump hosts in the order they are connected.
+func (b *Bastion) Chain() []connector.JumpHost {
+	if b == nil {
+		return nil
+	}
+	chain := make([]connector.JumpHost, 0, len(b.Hops)+1)
+	chain = append(chain, b.Hops...)
+	if b.Address != "" {
+		chain = append(chain, b.JumpHost)
+	}
+	return chain
+}
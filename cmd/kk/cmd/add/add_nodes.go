@@ -17,6 +17,8 @@ limitations under the License.
 package add
 
 import (
+	"context"
+
 	"github.com/spf13/cobra"
 
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/options"
@@ -26,18 +28,24 @@ import (
 )
 
 type AddNodesOptions struct {
-	CommonOptions    *options.CommonOptions
-	ClusterCfgFile   string
-	SkipPullImages   bool
-	ContainerManager string
-	DownloadCmd      string
-	Artifact         string
-	InstallPackages  bool
+	CommonOptions         *options.CommonOptions
+	DownloadOptions       *options.DownloadOptions
+	ArtifactVerifyOptions *options.ArtifactVerifyOptions
+	ClusterCfgFile        string
+	SkipPullImages        bool
+	ContainerManager      string
+	DownloadCmd           string
+	Artifact              string
+	InstallPackages       bool
+	ReportPath            string
+	NoRollback            bool
 }
 
 func NewAddNodesOptions() *AddNodesOptions {
 	return &AddNodesOptions{
-		CommonOptions: options.NewCommonOptions(),
+		CommonOptions:         options.NewCommonOptions(),
+		DownloadOptions:       options.NewDownloadOptions(),
+		ArtifactVerifyOptions: options.NewArtifactVerifyOptions(),
 	}
 }
 
@@ -49,11 +57,13 @@ func NewCmdAddNodes() *cobra.Command {
 		Short: "Add nodes to the cluster according to the new nodes information from the specified configuration file",
 		Run: func(cmd *cobra.Command, args []string) {
 			util.CheckErr(o.Complete(cmd, args))
-			util.CheckErr(o.Run())
+			util.CheckErr(o.Run(cmd.Context()))
 		},
 	}
 
 	o.CommonOptions.AddCommonFlag(cmd)
+	o.DownloadOptions.AddDownloadFlags(cmd)
+	o.ArtifactVerifyOptions.AddArtifactVerifyFlags(cmd)
 	o.AddFlags(cmd)
 	return cmd
 }
@@ -65,28 +75,40 @@ func (o *AddNodesOptions) Complete(_ *cobra.Command, _ []string) error {
 	return nil
 }
 
-func (o *AddNodesOptions) Run() error {
+func (o *AddNodesOptions) Run(ctx context.Context) error {
+	download, err := o.DownloadOptions.ToDownloadOptions()
+	if err != nil {
+		return err
+	}
+
 	arg := common.Argument{
-		FilePath:         o.ClusterCfgFile,
-		KsEnable:         false,
-		Debug:            o.CommonOptions.Verbose,
-		IgnoreErr:        o.CommonOptions.IgnoreErr,
-		SkipConfirmCheck: o.CommonOptions.SkipConfirmCheck,
-		SkipPullImages:   o.SkipPullImages,
-		ContainerManager: o.ContainerManager,
-		Artifact:         o.Artifact,
-		InstallPackages:  o.InstallPackages,
-		Namespace:        o.CommonOptions.Namespace,
+		FilePath:           o.ClusterCfgFile,
+		KsEnable:           false,
+		Debug:              o.CommonOptions.Verbose,
+		IgnoreErr:          o.CommonOptions.IgnoreErr,
+		SkipConfirmCheck:   o.CommonOptions.SkipConfirmCheck,
+		SkipPullImages:     o.SkipPullImages,
+		ContainerManager:   o.ContainerManager,
+		Artifact:           o.Artifact,
+		ArtifactVerifyKey:  o.ArtifactVerifyOptions.VerifyKey,
+		SkipArtifactVerify: o.ArtifactVerifyOptions.SkipVerify,
+		InstallPackages:    o.InstallPackages,
+		Namespace:          o.CommonOptions.Namespace,
+		ReportPath:         o.ReportPath,
+		NoRollback:         o.NoRollback,
+		Download:           download,
 	}
-	return pipelines.AddNodes(arg, o.DownloadCmd)
+	return pipelines.AddNodes(ctx, arg, o.DownloadCmd)
 }
 
 func (o *AddNodesOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
 	cmd.Flags().BoolVarP(&o.SkipPullImages, "skip-pull-images", "", false, "Skip pre pull images")
 	cmd.Flags().StringVarP(&o.ContainerManager, "container-manager", "", "docker", "Container manager: docker, crio, containerd and isula.")
-	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "curl -L -o %s %s",
-		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL`)
+	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "",
+		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL. The built-in downloader is used if it is empty`)
 	cmd.Flags().StringVarP(&o.Artifact, "artifact", "a", "", "Path to a KubeKey artifact")
 	cmd.Flags().BoolVarP(&o.InstallPackages, "with-packages", "", false, "install operation system packages by artifact")
+	cmd.Flags().StringVarP(&o.ReportPath, "report", "", "", "Path to save the run report as JSON, a JUnit XML report is saved next to it with the .junit.xml extension")
+	cmd.Flags().BoolVarP(&o.NoRollback, "no-rollback", "", false, "Do not roll back the completed tasks of a failed module")
 }
@@ -42,5 +42,6 @@ func NewCmdCri() *cobra.Command {
 
 	o.CommonOptions.AddCommonFlag(cmd)
 	cmd.AddCommand(NewCmdMigrateCri())
+	cmd.AddCommand(NewCmdReloadRegistries())
 	return cmd
 }
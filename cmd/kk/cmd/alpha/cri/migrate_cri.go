@@ -29,6 +29,7 @@ import (
 
 type MigrateCriOptions struct {
 	CommonOptions    *options.CommonOptions
+	DownloadOptions  *options.DownloadOptions
 	ClusterCfgFile   string
 	Kubernetes       string
 	EnableKubeSphere bool
@@ -41,7 +42,8 @@ type MigrateCriOptions struct {
 
 func NewMigrateCriOptions() *MigrateCriOptions {
 	return &MigrateCriOptions{
-		CommonOptions: options.NewCommonOptions(),
+		CommonOptions:   options.NewCommonOptions(),
+		DownloadOptions: options.NewDownloadOptions(),
 	}
 }
 
@@ -58,28 +60,35 @@ func NewCmdMigrateCri() *cobra.Command {
 	}
 
 	o.CommonOptions.AddCommonFlag(cmd)
+	o.DownloadOptions.AddDownloadFlags(cmd)
 	o.AddFlags(cmd)
 	return cmd
 }
 
 func (o *MigrateCriOptions) Run() error {
+	download, err := o.DownloadOptions.ToDownloadOptions()
+	if err != nil {
+		return err
+	}
+
 	arg := common.Argument{
 		FilePath:          o.ClusterCfgFile,
 		Debug:             o.CommonOptions.Verbose,
 		KubernetesVersion: o.Kubernetes,
 		Type:              o.Type,
 		Role:              o.Role,
+		Download:          download,
 	}
 	return pipelines.MigrateCri(arg, o.DownloadCmd)
 }
 
 func (o *MigrateCriOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.Role, "role", "", "", "Role groups for migrating. Support: master, worker, all.")
-	cmd.Flags().StringVarP(&o.Type, "type", "", "", "Type of target CRI. Support: docker, containerd.")
+	cmd.Flags().StringVarP(&o.Type, "type", "", "", "Type of target CRI. Support: docker, containerd, crio.")
 	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
 	cmd.Flags().StringVarP(&o.Kubernetes, "with-kubernetes", "", "", "Specify a supported version of kubernetes")
-	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "curl -L -o %s %s",
-		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL`)
+	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "",
+		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL. The built-in downloader is used if it is empty`)
 	cmd.Flags().StringVarP(&o.Artifact, "artifact", "a", "", "Path to a KubeKey artifact")
 }
 
@@ -94,7 +103,7 @@ func (o *MigrateCriOptions) Validate() error {
 	if o.Type == "" {
 		return errors.New("cri Type can not be empty")
 	}
-	if o.Type != common.Docker && o.Type != common.Containerd {
+	if o.Type != common.Docker && o.Type != common.Containerd && o.Type != common.Crio {
 		return errors.Errorf("cri Type is invalid: %s", o.Type)
 	}
 	if o.ClusterCfgFile == "" {
@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cri
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/options"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/util"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/pipelines"
+)
+
+type ReloadRegistriesOptions struct {
+	CommonOptions  *options.CommonOptions
+	ClusterCfgFile string
+	ReportPath     string
+}
+
+func NewReloadRegistriesOptions() *ReloadRegistriesOptions {
+	return &ReloadRegistriesOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdReloadRegistries creates a new reload registries command
+func NewCmdReloadRegistries() *cobra.Command {
+	o := NewReloadRegistriesOptions()
+	cmd := &cobra.Command{
+		Use:   "reload-registries",
+		Short: "Apply the registries in the configuration file to the containerd of all nodes without reinstalling it",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run(cmd.Context()))
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *ReloadRegistriesOptions) Validate() error {
+	if o.ClusterCfgFile == "" {
+		return errors.New("configuration file can not be empty")
+	}
+	return nil
+}
+
+func (o *ReloadRegistriesOptions) Run(ctx context.Context) error {
+	arg := common.Argument{
+		FilePath:   o.ClusterCfgFile,
+		Debug:      o.CommonOptions.Verbose,
+		ReportPath: o.ReportPath,
+	}
+	return pipelines.ReloadRegistries(ctx, arg)
+}
+
+func (o *ReloadRegistriesOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVarP(&o.ReportPath, "report", "", "", "Path to save the run report as JSON, a JUnit XML report is saved next to it with the .junit.xml extension")
+}
@@ -24,11 +24,13 @@ import (
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/options"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/util"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/images"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/pipelines"
 )
 
 type ArtifactExportOptions struct {
-	CommonOptions *options.CommonOptions
+	CommonOptions   *options.CommonOptions
+	DownloadOptions *options.DownloadOptions
 
 	ManifestFile       string
 	Output             string
@@ -36,12 +38,16 @@ type ArtifactExportOptions struct {
 	DownloadCmd        string
 	ImageStartIndex    int
 	ImageTransport     string
+	ImageConcurrency   int
 	SkipRemoveArtifact bool
+	Base               string
+	SignKey            string
 }
 
 func NewArtifactExportOptions() *ArtifactExportOptions {
 	return &ArtifactExportOptions{
-		CommonOptions: options.NewCommonOptions(),
+		CommonOptions:   options.NewCommonOptions(),
+		DownloadOptions: options.NewDownloadOptions(),
 	}
 }
 
@@ -59,6 +65,7 @@ func NewCmdArtifactExport() *cobra.Command {
 	}
 
 	o.CommonOptions.AddCommonFlag(cmd)
+	o.DownloadOptions.AddDownloadFlags(cmd)
 	o.AddFlags(cmd)
 	return cmd
 }
@@ -78,15 +85,24 @@ func (o *ArtifactExportOptions) Validate(_ []string) error {
 }
 
 func (o *ArtifactExportOptions) Run() error {
+	download, err := o.DownloadOptions.ToDownloadOptions()
+	if err != nil {
+		return err
+	}
+
 	arg := common.ArtifactArgument{
 		ManifestFile:       o.ManifestFile,
 		Output:             o.Output,
 		CriSocket:          o.CriSocket,
 		ImageStartIndex:    o.ImageStartIndex,
 		ImageTransport:     o.ImageTransport,
+		ImageConcurrency:   o.ImageConcurrency,
 		Debug:              o.CommonOptions.Verbose,
 		IgnoreErr:          o.CommonOptions.IgnoreErr,
 		SkipRemoveArtifact: o.SkipRemoveArtifact,
+		Download:           download,
+		Base:               o.Base,
+		SignKey:            o.SignKey,
 	}
 
 	return pipelines.ArtifactExport(arg, o.DownloadCmd)
@@ -95,10 +111,16 @@ func (o *ArtifactExportOptions) Run() error {
 func (o *ArtifactExportOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.ManifestFile, "manifest", "m", "", "Path to a manifest file")
 	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "Path to a output path")
-	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "curl -L -o %s %s",
-		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL`)
+	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "",
+		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL. The built-in downloader is used if it is empty`)
 	cmd.Flags().IntVarP(&o.ImageStartIndex, "image-start-index", "", 0, "Save images from specific index, default to 0")
 	cmd.Flags().StringVarP(&o.ImageTransport, "image-transport", "", "", "Image transport to pull from, take values from [docker, docker-daemon]")
+	cmd.Flags().IntVarP(&o.ImageConcurrency, "image-concurrency", "", images.DefaultCopyConcurrency,
+		"Number of the images saved at the same time, the progress is printed if it is 1")
 	cmd.Flags().BoolVarP(&o.SkipRemoveArtifact, "skip-remove-artifact", "", false, "Skip remove artifact")
+	cmd.Flags().StringVarP(&o.Base, "base", "", "",
+		"Path to a base artifact, the exported artifact only contains the binaries, ISO files and image blobs missing from it")
+	cmd.Flags().StringVarP(&o.SignKey, "sign-key", "", "",
+		"Path to a PEM encoded ed25519 or ECDSA private key, or a cosign private key with its password in $COSIGN_PASSWORD, to sign the content manifest of the artifact")
 
 }
@@ -0,0 +1,107 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package images
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/util"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/artifact"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+)
+
+type ArtifactImagesDiffOptions struct {
+	ArtifactImagesListOptions
+
+	Manifest     string
+	ImageDirPath string
+}
+
+func NewArtifactImagesDiffOptions() *ArtifactImagesDiffOptions {
+	return &ArtifactImagesDiffOptions{
+		ArtifactImagesListOptions: *NewArtifactImagesListOptions(),
+	}
+}
+
+// NewCmdArtifactImagesDiff creates a new `kubekey artifact images diff` command
+func NewCmdArtifactImagesDiff() *cobra.Command {
+	o := NewArtifactImagesDiffOptions()
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "compare the images KubeKey deploys for a cluster with the ones of a manifest or an images directory",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Complete(cmd, args))
+			util.CheckErr(o.Validate(args))
+			util.CheckErr(o.Run())
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *ArtifactImagesDiffOptions) Validate(args []string) error {
+	if err := o.ArtifactImagesListOptions.Validate(args); err != nil {
+		return err
+	}
+	if (o.Manifest == "") == (o.ImageDirPath == "") {
+		return errors.New("one of --manifest or --images-dir must be specified")
+	}
+	return nil
+}
+
+func (o *ArtifactImagesDiffOptions) Run() error {
+	list, err := o.clusterImages()
+	if err != nil {
+		return err
+	}
+
+	var refs []string
+	source := o.ImageDirPath
+	if o.Manifest != "" {
+		manifest, err := common.LoadManifest(o.Manifest)
+		if err != nil {
+			return err
+		}
+		refs = artifact.ManifestImageRefs(&manifest.Spec)
+		source = o.Manifest
+	} else if refs, err = artifact.OCIImageRefs(o.ImageDirPath); err != nil {
+		return err
+	}
+
+	diff := artifact.DiffImages(list, refs)
+	for _, ref := range diff.Missing {
+		fmt.Printf("- %s\n", ref)
+	}
+	for _, ref := range diff.Extra {
+		fmt.Printf("+ %s\n", ref)
+	}
+	if len(diff.Missing) != 0 {
+		return errors.Errorf("%d images needed by the cluster are missing from %s", len(diff.Missing), source)
+	}
+	return nil
+}
+
+func (o *ArtifactImagesDiffOptions) AddFlags(cmd *cobra.Command) {
+	o.ArtifactImagesListOptions.AddFlags(cmd)
+	cmd.Flags().StringVarP(&o.Manifest, "manifest", "m", "", "Path to a manifest file")
+	cmd.Flags().StringVarP(&o.ImageDirPath, "images-dir", "", "", "Path to a KubeKey artifact images directory")
+}
@@ -43,6 +43,8 @@ func NewCmdArtifactImages() *cobra.Command {
 
 	o.CommonOptions.AddCommonFlag(cmd)
 	cmd.AddCommand(NewCmdArtifactImagesPush())
+	cmd.AddCommand(NewCmdArtifactImagesList())
+	cmd.AddCommand(NewCmdArtifactImagesDiff())
 
 	return cmd
 }
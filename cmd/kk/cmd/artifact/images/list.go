@@ -0,0 +1,117 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package images
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/options"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/util"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/artifact"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+)
+
+type ArtifactImagesListOptions struct {
+	CommonOptions *options.CommonOptions
+
+	ClusterCfgFile      string
+	LocalStorage        bool
+	localStorageChanged bool
+}
+
+func NewArtifactImagesListOptions() *ArtifactImagesListOptions {
+	return &ArtifactImagesListOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdArtifactImagesList creates a new `kubekey artifact images list` command
+func NewCmdArtifactImagesList() *cobra.Command {
+	o := NewArtifactImagesListOptions()
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "list the images KubeKey deploys for a cluster",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Complete(cmd, args))
+			util.CheckErr(o.Validate(args))
+			util.CheckErr(o.Run())
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *ArtifactImagesListOptions) Complete(cmd *cobra.Command, _ []string) error {
+	if cmd.Flags().Changed("with-local-storage") {
+		o.localStorageChanged = true
+	}
+	return nil
+}
+
+func (o *ArtifactImagesListOptions) Validate(_ []string) error {
+	if o.ClusterCfgFile == "" {
+		return errors.New("kubekey config file is required")
+	}
+	return nil
+}
+
+func (o *ArtifactImagesListOptions) Run() error {
+	list, err := o.clusterImages()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 10, 4, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "IMAGE\tARCH")
+	for _, image := range list {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", image.Name, strings.Join(image.Arches, ","))
+	}
+	return w.Flush()
+}
+
+func (o *ArtifactImagesListOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().BoolVarP(&o.LocalStorage, "with-local-storage", "", false,
+		"Whether the local PV provisioner is deployed, it is deployed with KubeSphere if not specified")
+}
+
+// clusterImages returns the images of the cluster in the configuration file.
+func (o *ArtifactImagesListOptions) clusterImages() ([]artifact.ClusterImage, error) {
+	arg := common.Argument{
+		FilePath:  o.ClusterCfgFile,
+		Debug:     o.CommonOptions.Verbose,
+		IgnoreErr: o.CommonOptions.IgnoreErr,
+	}
+	if o.localStorageChanged {
+		deploy := o.LocalStorage
+		arg.DeployLocalStorage = &deploy
+	}
+
+	runtime, err := common.NewKubeRuntime(common.File, arg)
+	if err != nil {
+		return nil, err
+	}
+	return artifact.ClusterImages(runtime), nil
+}
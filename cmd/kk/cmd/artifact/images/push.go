@@ -34,17 +34,20 @@ import (
 )
 
 type ArtifactImagesPushOptions struct {
-	CommonOptions *options.CommonOptions
+	CommonOptions         *options.CommonOptions
+	ArtifactVerifyOptions *options.ArtifactVerifyOptions
 
 	ImageDirPath   string
 	ImageTransport string
+	Concurrency    int
 	Artifact       string
 	ClusterCfgFile string
 }
 
 func NewArtifactImagesPushOptions() *ArtifactImagesPushOptions {
 	return &ArtifactImagesPushOptions{
-		CommonOptions: options.NewCommonOptions(),
+		CommonOptions:         options.NewCommonOptions(),
+		ArtifactVerifyOptions: options.NewArtifactVerifyOptions(),
 	}
 }
 
@@ -62,6 +65,7 @@ func NewCmdArtifactImagesPush() *cobra.Command {
 	}
 
 	o.CommonOptions.AddCommonFlag(cmd)
+	o.ArtifactVerifyOptions.AddArtifactVerifyFlags(cmd)
 	o.AddFlags(cmd)
 	return cmd
 }
@@ -90,12 +94,15 @@ func (o *ArtifactImagesPushOptions) Validate(_ []string) error {
 
 func (o *ArtifactImagesPushOptions) Run() error {
 	arg := common.Argument{
-		ImagesDir:      o.ImageDirPath,
-		Artifact:       o.Artifact,
-		FilePath:       o.ClusterCfgFile,
-		ImageTransport: o.ImageTransport,
-		Debug:          o.CommonOptions.Verbose,
-		IgnoreErr:      o.CommonOptions.IgnoreErr,
+		ImagesDir:          o.ImageDirPath,
+		Artifact:           o.Artifact,
+		ArtifactVerifyKey:  o.ArtifactVerifyOptions.VerifyKey,
+		SkipArtifactVerify: o.ArtifactVerifyOptions.SkipVerify,
+		FilePath:           o.ClusterCfgFile,
+		ImageTransport:     o.ImageTransport,
+		ImageConcurrency:   o.Concurrency,
+		Debug:              o.CommonOptions.Verbose,
+		IgnoreErr:          o.CommonOptions.IgnoreErr,
 	}
 	return runPush(arg)
 }
@@ -105,6 +112,8 @@ func (o *ArtifactImagesPushOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.Artifact, "artifact", "a", "", "Path to a KubeKey artifact")
 	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
 	cmd.Flags().StringVarP(&o.ImageTransport, "image-transport", "", "", "Image transport to push to, take values from [docker, docker-daemon]")
+	cmd.Flags().IntVarP(&o.Concurrency, "image-concurrency", "", images.DefaultCopyConcurrency,
+		"Number of the images pushed at the same time, the progress is printed if it is 1")
 }
 
 func runPush(arg common.Argument) error {
@@ -126,7 +135,7 @@ func newImagesPushPipeline(runtime *common.KubeRuntime) error {
 	m := []module.Module{
 		&artifact.UnArchiveModule{Skip: noArtifact},
 		&images.CopyImagesToRegistryModule{ImagePath: runtime.Arg.ImagesDir,
-			ImageTransport: runtime.Arg.ImageTransport},
+			ImageTransport: runtime.Arg.ImageTransport, Concurrency: runtime.Arg.ImageConcurrency},
 		&filesystem.ChownWorkDirModule{},
 	}
 
@@ -28,13 +28,15 @@ import (
 )
 
 type ArtifactImportOptions struct {
-	CommonOptions *options.CommonOptions
-	Artifact      string
+	CommonOptions         *options.CommonOptions
+	ArtifactVerifyOptions *options.ArtifactVerifyOptions
+	Artifact              string
 }
 
 func NewArtifactImportOptions() *ArtifactImportOptions {
 	return &ArtifactImportOptions{
-		CommonOptions: options.NewCommonOptions(),
+		CommonOptions:         options.NewCommonOptions(),
+		ArtifactVerifyOptions: options.NewArtifactVerifyOptions(),
 	}
 }
 
@@ -51,14 +53,17 @@ func NewCmdArtifactImport() *cobra.Command {
 	}
 
 	o.CommonOptions.AddCommonFlag(cmd)
+	o.ArtifactVerifyOptions.AddArtifactVerifyFlags(cmd)
 	o.AddFlags(cmd)
 	return cmd
 }
 
 func (o *ArtifactImportOptions) Run() error {
 	arg := common.Argument{
-		Debug:    o.CommonOptions.Verbose,
-		Artifact: o.Artifact,
+		Debug:              o.CommonOptions.Verbose,
+		Artifact:           o.Artifact,
+		ArtifactVerifyKey:  o.ArtifactVerifyOptions.VerifyKey,
+		SkipArtifactVerify: o.ArtifactVerifyOptions.SkipVerify,
 	}
 	return artifact.ArtifactImport(arg)
 }
@@ -21,6 +21,7 @@ import (
 
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/options"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/util"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/certs"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/pipelines"
 )
@@ -28,6 +29,7 @@ import (
 type CertRenewOptions struct {
 	CommonOptions  *options.CommonOptions
 	ClusterCfgFile string
+	Components     []string
 }
 
 func NewCertRenewOptions() *CertRenewOptions {
@@ -54,12 +56,15 @@ func NewCmdCertRenew() *cobra.Command {
 
 func (o *CertRenewOptions) Run() error {
 	arg := common.Argument{
-		FilePath: o.ClusterCfgFile,
-		Debug:    o.CommonOptions.Verbose,
+		FilePath:        o.ClusterCfgFile,
+		Debug:           o.CommonOptions.Verbose,
+		CertsComponents: o.Components,
 	}
 	return pipelines.RenewCerts(arg)
 }
 
 func (o *CertRenewOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringSliceVar(&o.Components, "component", []string{certs.ComponentAll},
+		"The components whose certs are renewed, support: all, apiserver, front-proxy, kubeconfig, etcd, kubelet")
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package create
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -31,7 +32,9 @@ import (
 )
 
 type CreateClusterOptions struct {
-	CommonOptions *options.CommonOptions
+	CommonOptions         *options.CommonOptions
+	DownloadOptions       *options.DownloadOptions
+	ArtifactVerifyOptions *options.ArtifactVerifyOptions
 
 	ClusterCfgFile      string
 	Kubernetes          string
@@ -47,13 +50,19 @@ type CreateClusterOptions struct {
 	Artifact            string
 	InstallPackages     bool
 	WithBuildx          bool
+	Resume              bool
+	DryRun              bool
+	ReportPath          string
+	NoRollback          bool
 
 	localStorageChanged bool
 }
 
 func NewCreateClusterOptions() *CreateClusterOptions {
 	return &CreateClusterOptions{
-		CommonOptions: options.NewCommonOptions(),
+		CommonOptions:         options.NewCommonOptions(),
+		DownloadOptions:       options.NewDownloadOptions(),
+		ArtifactVerifyOptions: options.NewArtifactVerifyOptions(),
 	}
 }
 
@@ -66,11 +75,13 @@ func NewCmdCreateCluster() *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			util.CheckErr(o.Complete(cmd, args))
 			util.CheckErr(o.Validate(cmd, args))
-			util.CheckErr(o.Run())
+			util.CheckErr(o.Run(cmd.Context()))
 		},
 	}
 
 	o.CommonOptions.AddCommonFlag(cmd)
+	o.DownloadOptions.AddDownloadFlags(cmd)
+	o.ArtifactVerifyOptions.AddArtifactVerifyFlags(cmd)
 	o.AddFlags(cmd)
 
 	if err := completionSetting(cmd); err != nil {
@@ -108,7 +119,12 @@ func (o *CreateClusterOptions) Validate(_ *cobra.Command, _ []string) error {
 	return nil
 }
 
-func (o *CreateClusterOptions) Run() error {
+func (o *CreateClusterOptions) Run(ctx context.Context) error {
+	download, err := o.DownloadOptions.ToDownloadOptions()
+	if err != nil {
+		return err
+	}
+
 	arg := common.Argument{
 		FilePath:            o.ClusterCfgFile,
 		KubernetesVersion:   o.Kubernetes,
@@ -123,9 +139,16 @@ func (o *CreateClusterOptions) Run() error {
 		SkipConfirmCheck:    o.CommonOptions.SkipConfirmCheck,
 		ContainerManager:    o.ContainerManager,
 		Artifact:            o.Artifact,
+		ArtifactVerifyKey:   o.ArtifactVerifyOptions.VerifyKey,
+		SkipArtifactVerify:  o.ArtifactVerifyOptions.SkipVerify,
 		InstallPackages:     o.InstallPackages,
 		Namespace:           o.CommonOptions.Namespace,
 		WithBuildx:          o.WithBuildx,
+		Resume:              o.Resume,
+		DryRun:              o.DryRun,
+		ReportPath:          o.ReportPath,
+		NoRollback:          o.NoRollback,
+		Download:            download,
 	}
 
 	if o.localStorageChanged {
@@ -133,7 +156,7 @@ func (o *CreateClusterOptions) Run() error {
 		arg.DeployLocalStorage = &deploy
 	}
 
-	return pipelines.CreateCluster(arg, o.DownloadCmd)
+	return pipelines.CreateCluster(ctx, arg, o.DownloadCmd)
 }
 
 func (o *CreateClusterOptions) AddFlags(cmd *cobra.Command) {
@@ -146,11 +169,15 @@ func (o *CreateClusterOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&o.SkipPushImages, "skip-push-images", "", false, "Skip pre push images")
 	cmd.Flags().BoolVarP(&o.SecurityEnhancement, "with-security-enhancement", "", false, "Security enhancement")
 	cmd.Flags().StringVarP(&o.ContainerManager, "container-manager", "", "docker", "Container runtime: docker, crio, containerd and isula.")
-	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "curl -L -o %s %s",
-		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL`)
+	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "",
+		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL. The built-in downloader is used if it is empty`)
 	cmd.Flags().StringVarP(&o.Artifact, "artifact", "a", "", "Path to a KubeKey artifact")
 	cmd.Flags().BoolVarP(&o.InstallPackages, "with-packages", "", false, "install operation system packages by artifact")
 	cmd.Flags().BoolVarP(&o.WithBuildx, "with-buildx", "", false, "install buildx when Container runtime is docker")
+	cmd.Flags().BoolVarP(&o.DryRun, "dry-run", "", false, "Print the plan of the remote commands for every host without executing them")
+	cmd.Flags().StringVarP(&o.ReportPath, "report", "", "", "Path to save the run report as JSON, a JUnit XML report is saved next to it with the .junit.xml extension")
+	cmd.Flags().BoolVarP(&o.NoRollback, "no-rollback", "", false, "Do not roll back the completed tasks of a failed module")
+	cmd.Flags().BoolVarP(&o.Resume, "resume", "", false, "Resume from the checkpoint of the last failed run, skip the modules that have been finished on all hosts")
 }
 
 func completionSetting(cmd *cobra.Command) (err error) {
@@ -29,15 +29,17 @@ import (
 )
 
 type CreateBinaryOptions struct {
-	CommonOptions  *options.CommonOptions
-	ClusterCfgFile string
-	Kubernetes     string
-	DownloadCmd    string
+	CommonOptions   *options.CommonOptions
+	DownloadOptions *options.DownloadOptions
+	ClusterCfgFile  string
+	Kubernetes      string
+	DownloadCmd     string
 }
 
 func NewCreateBinaryOptions() *CreateBinaryOptions {
 	return &CreateBinaryOptions{
-		CommonOptions: options.NewCommonOptions(),
+		CommonOptions:   options.NewCommonOptions(),
+		DownloadOptions: options.NewDownloadOptions(),
 	}
 }
 
@@ -53,6 +55,7 @@ func NewCmdCreateBinary() *cobra.Command {
 	}
 
 	o.CommonOptions.AddCommonFlag(cmd)
+	o.DownloadOptions.AddDownloadFlags(cmd)
 	o.AddFlags(cmd)
 	if err := k8sCompletionSetting(cmd); err != nil {
 		panic(fmt.Sprintf("Got error with the completion setting"))
@@ -61,10 +64,16 @@ func NewCmdCreateBinary() *cobra.Command {
 }
 
 func (o *CreateBinaryOptions) Run() error {
+	download, err := o.DownloadOptions.ToDownloadOptions()
+	if err != nil {
+		return err
+	}
+
 	arg := common.Argument{
 		FilePath:          o.ClusterCfgFile,
 		KubernetesVersion: o.Kubernetes,
 		Debug:             o.CommonOptions.Verbose,
+		Download:          download,
 	}
 	return binary.CreateBinary(arg, o.DownloadCmd)
 }
@@ -72,8 +81,8 @@ func (o *CreateBinaryOptions) Run() error {
 func (o *CreateBinaryOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
 	cmd.Flags().StringVarP(&o.Kubernetes, "with-kubernetes", "", "", "Specify a supported version of kubernetes")
-	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "curl -L -o %s %s",
-		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL`)
+	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "",
+		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL. The built-in downloader is used if it is empty`)
 
 }
 
@@ -0,0 +1,48 @@
+/*
+Copyright 2020 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/options"
+)
+
+type BackupOptions struct {
+	CommonOptions *options.CommonOptions
+}
+
+func NewBackupOptions() *BackupOptions {
+	return &BackupOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdEtcdBackup creates a new etcd backup command
+func NewCmdEtcdBackup() *cobra.Command {
+	o := NewBackupOptions()
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Manage the etcd backups in the backup target",
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+
+	cmd.AddCommand(NewCmdEtcdBackupList())
+	cmd.AddCommand(NewCmdEtcdBackupDownload())
+	return cmd
+}
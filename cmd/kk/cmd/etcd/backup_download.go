@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/options"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/util"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/pipelines"
+)
+
+type BackupDownloadOptions struct {
+	CommonOptions  *options.CommonOptions
+	ClusterCfgFile string
+	Snapshot       string
+	ReportPath     string
+}
+
+func NewBackupDownloadOptions() *BackupDownloadOptions {
+	return &BackupDownloadOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdEtcdBackupDownload creates a new etcd backup download command
+func NewCmdEtcdBackupDownload() *cobra.Command {
+	o := NewBackupDownloadOptions()
+	cmd := &cobra.Command{
+		Use:   "download",
+		Short: "Download an etcd snapshot from the backup target onto the etcd nodes",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run(cmd.Context()))
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *BackupDownloadOptions) Validate() error {
+	if o.Snapshot == "" {
+		return errors.New("etcd snapshot can not be empty, specify one listed by kk etcd backup list with --snapshot")
+	}
+	return nil
+}
+
+func (o *BackupDownloadOptions) Run(ctx context.Context) error {
+	arg := common.Argument{
+		FilePath:     o.ClusterCfgFile,
+		Debug:        o.CommonOptions.Verbose,
+		EtcdSnapshot: o.Snapshot,
+		ReportPath:   o.ReportPath,
+	}
+	return pipelines.DownloadETCDBackup(ctx, arg)
+}
+
+func (o *BackupDownloadOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVarP(&o.Snapshot, "snapshot", "", "", "The etcd snapshot to download, as listed by kk etcd backup list")
+	cmd.Flags().StringVarP(&o.ReportPath, "report", "", "", "Path to save the run report as JSON, a JUnit XML report is saved next to it with the .junit.xml extension")
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/options"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/util"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/pipelines"
+)
+
+type BackupListOptions struct {
+	CommonOptions  *options.CommonOptions
+	ClusterCfgFile string
+	Node           string
+}
+
+func NewBackupListOptions() *BackupListOptions {
+	return &BackupListOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdEtcdBackupList creates a new etcd backup list command
+func NewCmdEtcdBackupList() *cobra.Command {
+	o := NewBackupListOptions()
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the etcd snapshots in the backup target",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Run(cmd.Context()))
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *BackupListOptions) Run(ctx context.Context) error {
+	arg := common.Argument{
+		FilePath: o.ClusterCfgFile,
+		Debug:    o.CommonOptions.Verbose,
+		NodeName: o.Node,
+	}
+	return pipelines.ListETCDBackups(ctx, arg)
+}
+
+func (o *BackupListOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVarP(&o.Node, "node", "", "", "Only list the snapshots of the etcd node")
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/options"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/util"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/pipelines"
+)
+
+type MaintainOptions struct {
+	CommonOptions  *options.CommonOptions
+	ClusterCfgFile string
+	Output         string
+	StatusOnly     bool
+	Schedule       string
+	ReportPath     string
+}
+
+func NewMaintainOptions() *MaintainOptions {
+	return &MaintainOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdEtcdMaintain creates a new etcd maintain command
+func NewCmdEtcdMaintain() *cobra.Command {
+	o := NewMaintainOptions()
+	cmd := &cobra.Command{
+		Use:   "maintain",
+		Short: "Compact and defragment the etcd cluster, disarm its alarms and report the status of its members",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run(cmd.Context()))
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *MaintainOptions) Validate() error {
+	if o.Output != "table" && o.Output != "json" {
+		return errors.Errorf("unsupported output format %q, it should be table or json", o.Output)
+	}
+	if o.StatusOnly && o.Schedule != "" {
+		return errors.New("--status-only and --schedule can not be used together")
+	}
+	return nil
+}
+
+func (o *MaintainOptions) Run(ctx context.Context) error {
+	arg := common.Argument{
+		FilePath:       o.ClusterCfgFile,
+		Debug:          o.CommonOptions.Verbose,
+		EtcdOutput:     o.Output,
+		EtcdStatusOnly: o.StatusOnly,
+		EtcdSchedule:   o.Schedule,
+		ReportPath:     o.ReportPath,
+	}
+	return pipelines.MaintainETCD(ctx, arg)
+}
+
+func (o *MaintainOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "table", "Output format of the member status, table or json")
+	cmd.Flags().BoolVarP(&o.StatusOnly, "status-only", "", false, "Only report the status of the members without maintaining them")
+	cmd.Flags().StringVarP(&o.Schedule, "schedule", "", "", "Install a systemd timer maintaining etcd regularly instead of maintaining it at once, "+
+		"the value is an OnCalendar expression of systemd, e.g. \"Sun *-*-* 03:00:00\"")
+	cmd.Flags().StringVarP(&o.ReportPath, "report", "", "", "Path to save the run report as JSON, a JUnit XML report is saved next to it with the .junit.xml extension")
+}
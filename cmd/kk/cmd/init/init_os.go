@@ -26,14 +26,16 @@ import (
 )
 
 type InitOsOptions struct {
-	CommonOptions  *options.CommonOptions
-	ClusterCfgFile string
-	Artifact       string
+	CommonOptions         *options.CommonOptions
+	ArtifactVerifyOptions *options.ArtifactVerifyOptions
+	ClusterCfgFile        string
+	Artifact              string
 }
 
 func NewInitOsOptions() *InitOsOptions {
 	return &InitOsOptions{
-		CommonOptions: options.NewCommonOptions(),
+		CommonOptions:         options.NewCommonOptions(),
+		ArtifactVerifyOptions: options.NewArtifactVerifyOptions(),
 	}
 }
 
@@ -49,15 +51,18 @@ func NewCmdInitOs() *cobra.Command {
 	}
 
 	o.CommonOptions.AddCommonFlag(cmd)
+	o.ArtifactVerifyOptions.AddArtifactVerifyFlags(cmd)
 	o.AddFlags(cmd)
 	return cmd
 }
 
 func (o *InitOsOptions) Run() error {
 	arg := common.Argument{
-		FilePath: o.ClusterCfgFile,
-		Debug:    o.CommonOptions.Verbose,
-		Artifact: o.Artifact,
+		FilePath:           o.ClusterCfgFile,
+		Debug:              o.CommonOptions.Verbose,
+		Artifact:           o.Artifact,
+		ArtifactVerifyKey:  o.ArtifactVerifyOptions.VerifyKey,
+		SkipArtifactVerify: o.ArtifactVerifyOptions.SkipVerify,
 	}
 	return pipelines.InitDependencies(arg)
 }
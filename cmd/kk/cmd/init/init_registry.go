@@ -26,15 +26,19 @@ import (
 )
 
 type InitRegistryOptions struct {
-	CommonOptions  *options.CommonOptions
-	ClusterCfgFile string
-	DownloadCmd    string
-	Artifact       string
+	CommonOptions         *options.CommonOptions
+	DownloadOptions       *options.DownloadOptions
+	ArtifactVerifyOptions *options.ArtifactVerifyOptions
+	ClusterCfgFile        string
+	DownloadCmd           string
+	Artifact              string
 }
 
 func NewInitRegistryOptions() *InitRegistryOptions {
 	return &InitRegistryOptions{
-		CommonOptions: options.NewCommonOptions(),
+		CommonOptions:         options.NewCommonOptions(),
+		DownloadOptions:       options.NewDownloadOptions(),
+		ArtifactVerifyOptions: options.NewArtifactVerifyOptions(),
 	}
 }
 
@@ -51,6 +55,8 @@ func NewCmdInitRegistry() *cobra.Command {
 	}
 
 	o.CommonOptions.AddCommonFlag(cmd)
+	o.DownloadOptions.AddDownloadFlags(cmd)
+	o.ArtifactVerifyOptions.AddArtifactVerifyFlags(cmd)
 	o.AddFlags(cmd)
 	return cmd
 }
@@ -60,17 +66,25 @@ func (o *InitRegistryOptions) Complete(_ *cobra.Command, _ []string) error {
 }
 
 func (o *InitRegistryOptions) Run() error {
+	download, err := o.DownloadOptions.ToDownloadOptions()
+	if err != nil {
+		return err
+	}
+
 	arg := common.Argument{
-		FilePath: o.ClusterCfgFile,
-		Debug:    o.CommonOptions.Verbose,
-		Artifact: o.Artifact,
+		FilePath:           o.ClusterCfgFile,
+		Debug:              o.CommonOptions.Verbose,
+		Artifact:           o.Artifact,
+		ArtifactVerifyKey:  o.ArtifactVerifyOptions.VerifyKey,
+		SkipArtifactVerify: o.ArtifactVerifyOptions.SkipVerify,
+		Download:           download,
 	}
 	return pipelines.InitRegistry(arg, o.DownloadCmd)
 }
 
 func (o *InitRegistryOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
-	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "curl -L -o %s %s",
-		`The user defined command to download the necessary files. The first param '%s' is output path, the second param '%s', is the URL`)
+	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "",
+		`The user defined command to download the necessary files. The first param '%s' is output path, the second param '%s', is the URL. The built-in downloader is used if it is empty`)
 	cmd.Flags().StringVarP(&o.Artifact, "artifact", "a", "", "Path to a KubeKey artifact")
 }
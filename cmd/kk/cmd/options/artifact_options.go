@@ -0,0 +1,36 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package options
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ArtifactVerifyOptions are the flags to verify the signature of a KubeKey artifact.
+type ArtifactVerifyOptions struct {
+	VerifyKey  string
+	SkipVerify bool
+}
+
+func NewArtifactVerifyOptions() *ArtifactVerifyOptions {
+	return &ArtifactVerifyOptions{}
+}
+
+func (o *ArtifactVerifyOptions) AddArtifactVerifyFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.VerifyKey, "verify-key", "", "Path to the PEM encoded public key that verifies the signature of the KubeKey artifact, e.g. cosign.pub")
+	cmd.Flags().BoolVar(&o.SkipVerify, "skip-verify-artifact", false, "Use the KubeKey artifact even if it is unsigned or its signature cannot be verified")
+}
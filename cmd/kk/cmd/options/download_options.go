@@ -0,0 +1,57 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package options
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/files"
+)
+
+// DownloadOptions are the flags of the built-in downloader of the binaries.
+type DownloadOptions struct {
+	Proxy    string
+	CACert   string
+	Mirrors  []string
+	Parallel int
+}
+
+func NewDownloadOptions() *DownloadOptions {
+	return &DownloadOptions{}
+}
+
+func (o *DownloadOptions) AddDownloadFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Proxy, "download-proxy", "", "The HTTP(S) proxy of the built-in downloader, the proxy environment variables are used by default")
+	cmd.Flags().StringVar(&o.CACert, "download-ca", "", "Path to a PEM file of the CAs trusted by the built-in downloader besides the system ones")
+	cmd.Flags().StringArrayVar(&o.Mirrors, "download-mirror", nil,
+		"A fallback URL of a component in the form of <component>=<url>, e.g. kubeadm=https://mirror.local/{version}/{arch}/{file}. It can be repeated, the mirrors are tried in order")
+	cmd.Flags().IntVar(&o.Parallel, "download-parallel", files.DefaultDownloadParallel, "The number of the binaries downloaded at the same time")
+}
+
+// ToDownloadOptions converts the flags to the options of the built-in downloader.
+func (o *DownloadOptions) ToDownloadOptions() (files.DownloadOptions, error) {
+	mirrors, err := files.ParseMirrors(o.Mirrors)
+	if err != nil {
+		return files.DownloadOptions{}, err
+	}
+	return files.DownloadOptions{
+		Proxy:    o.Proxy,
+		CACert:   o.CACert,
+		Mirrors:  mirrors,
+		Parallel: o.Parallel,
+	}, nil
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replace
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/options"
+)
+
+type ReplaceOptions struct {
+	CommonOptions *options.CommonOptions
+}
+
+func NewReplaceOptions() *ReplaceOptions {
+	return &ReplaceOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdReplace creates a new replace command
+func NewCmdReplace() *cobra.Command {
+	o := NewReplaceOptions()
+	cmd := &cobra.Command{
+		Use:   "replace",
+		Short: "Replace a failed member of the cluster",
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+
+	cmd.AddCommand(NewCmdReplaceETCDMember())
+	return cmd
+}
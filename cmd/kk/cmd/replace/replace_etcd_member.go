@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replace
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/options"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/util"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/pipelines"
+)
+
+type ReplaceETCDMemberOptions struct {
+	CommonOptions         *options.CommonOptions
+	DownloadOptions       *options.DownloadOptions
+	ArtifactVerifyOptions *options.ArtifactVerifyOptions
+	ClusterCfgFile        string
+	DownloadCmd           string
+	Artifact              string
+	ReportPath            string
+	NoRollback            bool
+	member                string
+}
+
+func NewReplaceETCDMemberOptions() *ReplaceETCDMemberOptions {
+	return &ReplaceETCDMemberOptions{
+		CommonOptions:         options.NewCommonOptions(),
+		DownloadOptions:       options.NewDownloadOptions(),
+		ArtifactVerifyOptions: options.NewArtifactVerifyOptions(),
+	}
+}
+
+// NewCmdReplaceETCDMember creates a new replace etcd-member command
+func NewCmdReplaceETCDMember() *cobra.Command {
+	o := NewReplaceETCDMemberOptions()
+	cmd := &cobra.Command{
+		Use:   "etcd-member",
+		Short: "Replace a failed etcd member with the new etcd host in the configuration file",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Complete(cmd, args))
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run(cmd.Context()))
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.DownloadOptions.AddDownloadFlags(cmd)
+	o.ArtifactVerifyOptions.AddArtifactVerifyFlags(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *ReplaceETCDMemberOptions) Complete(_ *cobra.Command, args []string) error {
+	o.member = strings.Join(args, "")
+	return nil
+}
+
+func (o *ReplaceETCDMemberOptions) Validate() error {
+	if o.member == "" {
+		return errors.New("the host name of the etcd member to replace can not be empty")
+	}
+	return nil
+}
+
+func (o *ReplaceETCDMemberOptions) Run(ctx context.Context) error {
+	download, err := o.DownloadOptions.ToDownloadOptions()
+	if err != nil {
+		return err
+	}
+
+	arg := common.Argument{
+		FilePath:           o.ClusterCfgFile,
+		Debug:              o.CommonOptions.Verbose,
+		SkipConfirmCheck:   o.CommonOptions.SkipConfirmCheck,
+		NodeName:           o.member,
+		Artifact:           o.Artifact,
+		ArtifactVerifyKey:  o.ArtifactVerifyOptions.VerifyKey,
+		SkipArtifactVerify: o.ArtifactVerifyOptions.SkipVerify,
+		ReportPath:         o.ReportPath,
+		NoRollback:         o.NoRollback,
+		Download:           download,
+	}
+	return pipelines.ReplaceETCDMember(ctx, arg, o.DownloadCmd)
+}
+
+func (o *ReplaceETCDMemberOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "",
+		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL. The built-in downloader is used if it is empty`)
+	cmd.Flags().StringVarP(&o.Artifact, "artifact", "a", "", "Path to a KubeKey artifact")
+	cmd.Flags().StringVarP(&o.ReportPath, "report", "", "", "Path to save the run report as JSON, a JUnit XML report is saved next to it with the .junit.xml extension")
+	cmd.Flags().BoolVarP(&o.NoRollback, "no-rollback", "", false, "Do not roll back the completed tasks of a failed module")
+}
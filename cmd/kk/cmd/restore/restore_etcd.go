@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/options"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/util"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/pipelines"
+)
+
+type RestoreETCDOptions struct {
+	CommonOptions  *options.CommonOptions
+	ClusterCfgFile string
+	Snapshot       string
+	ReportPath     string
+	NoRollback     bool
+}
+
+func NewRestoreETCDOptions() *RestoreETCDOptions {
+	return &RestoreETCDOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdRestoreETCD creates a new restore etcd command
+func NewCmdRestoreETCD() *cobra.Command {
+	o := NewRestoreETCDOptions()
+	cmd := &cobra.Command{
+		Use:   "etcd",
+		Short: "Restore the etcd cluster installed by KubeKey from a snapshot",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run(cmd.Context()))
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *RestoreETCDOptions) Validate() error {
+	if o.Snapshot == "" {
+		return errors.New("etcd snapshot can not be empty, specify it by --snapshot")
+	}
+	return nil
+}
+
+func (o *RestoreETCDOptions) Run(ctx context.Context) error {
+	arg := common.Argument{
+		FilePath:         o.ClusterCfgFile,
+		Debug:            o.CommonOptions.Verbose,
+		SkipConfirmCheck: o.CommonOptions.SkipConfirmCheck,
+		EtcdSnapshot:     o.Snapshot,
+		ReportPath:       o.ReportPath,
+		NoRollback:       o.NoRollback,
+	}
+	return pipelines.RestoreETCD(ctx, arg)
+}
+
+func (o *RestoreETCDOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVarP(&o.Snapshot, "snapshot", "", "", "Path to the etcd snapshot to restore, e.g. one saved by the etcd backup service")
+	cmd.Flags().StringVarP(&o.ReportPath, "report", "", "", "Path to save the run report as JSON, a JUnit XML report is saved next to it with the .junit.xml extension")
+	cmd.Flags().BoolVarP(&o.NoRollback, "no-rollback", "", false, "Do not roll back the completed tasks of a failed module")
+}
@@ -34,9 +34,12 @@ import (
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/completion"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/create"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/delete"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/etcd"
 	initOs "github.com/kubesphere/kubekey/v3/cmd/kk/cmd/init"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/options"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/plugin"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/replace"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/restore"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/upgrade"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/version"
 )
@@ -115,6 +118,9 @@ func NewKubeKeyCommand(o KubeKeyOptions) *cobra.Command {
 	cmds.AddCommand(add.NewCmdAdd())
 	cmds.AddCommand(upgrade.NewCmdUpgrade())
 	cmds.AddCommand(cert.NewCmdCerts())
+	cmds.AddCommand(restore.NewCmdRestore())
+	cmds.AddCommand(etcd.NewCmdEtcd())
+	cmds.AddCommand(replace.NewCmdReplace())
 	cmds.AddCommand(artifact.NewCmdArtifact())
 
 	cmds.AddCommand(plugin.NewCmdPlugin(o.IOStreams))
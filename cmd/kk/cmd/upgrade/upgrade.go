@@ -17,6 +17,7 @@ limitations under the License.
 package upgrade
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -31,21 +32,28 @@ import (
 )
 
 type UpgradeOptions struct {
-	CommonOptions       *options.CommonOptions
-	ClusterCfgFile      string
-	Kubernetes          string
-	EnableKubeSphere    bool
-	KubeSphere          string
-	SkipPullImages      bool
-	SkipDependencyCheck bool
-	EtcdUpgrade         bool
-	DownloadCmd         string
-	Artifact            string
+	CommonOptions         *options.CommonOptions
+	DownloadOptions       *options.DownloadOptions
+	ArtifactVerifyOptions *options.ArtifactVerifyOptions
+	ClusterCfgFile        string
+	Kubernetes            string
+	EnableKubeSphere      bool
+	KubeSphere            string
+	SkipPullImages        bool
+	SkipDependencyCheck   bool
+	EtcdUpgrade           bool
+	DownloadCmd           string
+	Artifact              string
+	DryRun                bool
+	ReportPath            string
+	NoRollback            bool
 }
 
 func NewUpgradeOptions() *UpgradeOptions {
 	return &UpgradeOptions{
-		CommonOptions: options.NewCommonOptions(),
+		CommonOptions:         options.NewCommonOptions(),
+		DownloadOptions:       options.NewDownloadOptions(),
+		ArtifactVerifyOptions: options.NewArtifactVerifyOptions(),
 	}
 }
 
@@ -57,10 +65,12 @@ func NewCmdUpgrade() *cobra.Command {
 		Short: "Upgrade your cluster smoothly to a newer version with this command",
 		Run: func(cmd *cobra.Command, args []string) {
 			util.CheckErr(o.Complete(cmd, args))
-			util.CheckErr(o.Run())
+			util.CheckErr(o.Run(cmd.Context()))
 		},
 	}
 	o.CommonOptions.AddCommonFlag(cmd)
+	o.DownloadOptions.AddDownloadFlags(cmd)
+	o.ArtifactVerifyOptions.AddArtifactVerifyFlags(cmd)
 	o.AddFlags(cmd)
 
 	if err := completionSetting(cmd); err != nil {
@@ -80,7 +90,12 @@ func (o *UpgradeOptions) Complete(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func (o *UpgradeOptions) Run() error {
+func (o *UpgradeOptions) Run(ctx context.Context) error {
+	download, err := o.DownloadOptions.ToDownloadOptions()
+	if err != nil {
+		return err
+	}
+
 	arg := common.Argument{
 		FilePath:            o.ClusterCfgFile,
 		KubernetesVersion:   o.Kubernetes,
@@ -90,10 +105,16 @@ func (o *UpgradeOptions) Run() error {
 		Debug:               o.CommonOptions.Verbose,
 		SkipConfirmCheck:    o.CommonOptions.SkipConfirmCheck,
 		Artifact:            o.Artifact,
+		ArtifactVerifyKey:   o.ArtifactVerifyOptions.VerifyKey,
+		SkipArtifactVerify:  o.ArtifactVerifyOptions.SkipVerify,
 		SkipDependencyCheck: o.SkipDependencyCheck,
 		EtcdUpgrade:         o.EtcdUpgrade,
+		DryRun:              o.DryRun,
+		ReportPath:          o.ReportPath,
+		NoRollback:          o.NoRollback,
+		Download:            download,
 	}
-	return pipelines.UpgradeCluster(arg, o.DownloadCmd)
+	return pipelines.UpgradeCluster(ctx, arg, o.DownloadCmd)
 }
 
 func (o *UpgradeOptions) AddFlags(cmd *cobra.Command) {
@@ -101,11 +122,14 @@ func (o *UpgradeOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.Kubernetes, "with-kubernetes", "", "", "Specify a supported version of kubernetes")
 	cmd.Flags().BoolVarP(&o.EnableKubeSphere, "with-kubesphere", "", false, fmt.Sprintf("Deploy a specific version of kubesphere (default %s)", kubesphere.Latest().Version))
 	cmd.Flags().BoolVarP(&o.SkipPullImages, "skip-pull-images", "", false, "Skip pre pull images")
-	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "curl -L -o %s %s",
-		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL`)
+	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "",
+		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL. The built-in downloader is used if it is empty`)
 	cmd.Flags().StringVarP(&o.Artifact, "artifact", "a", "", "Path to a KubeKey artifact")
 	cmd.Flags().BoolVarP(&o.SkipDependencyCheck, "skip-dependency-check", "", false, "Skip kubernetes and kubesphere dependency version check")
 	cmd.Flags().BoolVarP(&o.EtcdUpgrade, "with-etcd", "", false, "Upgrade etcd")
+	cmd.Flags().BoolVarP(&o.DryRun, "dry-run", "", false, "Print the plan of the remote commands for every host without executing them")
+	cmd.Flags().StringVarP(&o.ReportPath, "report", "", "", "Path to save the run report as JSON, a JUnit XML report is saved next to it with the .junit.xml extension")
+	cmd.Flags().BoolVarP(&o.NoRollback, "no-rollback", "", false, "Do not roll back the completed tasks of a failed module")
 }
 
 func completionSetting(cmd *cobra.Command) (err error) {
@@ -0,0 +1,208 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package artifact
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DeltaFileName is the file in the root of a delta artifact, which lists the files the delta takes from its base.
+const DeltaFileName = "artifact-delta.json"
+
+// Delta describes a delta artifact, it only carries the files missing from or changed since its base artifact.
+type Delta struct {
+	// Base is the file name of the base artifact.
+	Base string `json:"base"`
+	// Files are the files of the base artifact the delta relies on.
+	Files []DeltaFile `json:"files"`
+}
+
+// DeltaFile is a file of the base artifact.
+type DeltaFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Digest string `json:"digest,omitempty"`
+}
+
+type baseFile struct {
+	size   int64
+	digest string
+}
+
+// isBlob reports whether the file is an OCI image blob, whose name is the digest of its content.
+func isBlob(name string) bool {
+	return strings.HasPrefix(filepath.ToSlash(name), "images/blobs/")
+}
+
+// readBaseArtifact returns the regular files in the base artifact with their sha256 digests. The digests of the
+// image blobs are their names, so they are not computed.
+func readBaseArtifact(base string) (map[string]baseFile, error) {
+	f, err := os.Open(base)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open base artifact %s failed", base)
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read base artifact %s failed", base)
+	}
+	defer gr.Close()
+
+	files := make(map[string]baseFile)
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "read base artifact %s failed", base)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := filepath.Clean(hdr.Name)
+		if isBlob(name) {
+			files[name] = baseFile{size: hdr.Size}
+			continue
+		}
+		h := sha256.New()
+		if _, err := io.Copy(h, tr); err != nil {
+			return nil, errors.Wrapf(err, "read %s of base artifact %s failed", hdr.Name, base)
+		}
+		files[name] = baseFile{size: hdr.Size, digest: hex.EncodeToString(h.Sum(nil))}
+	}
+}
+
+// ComputeDelta returns the files under src which are the same in the base artifact, so the delta artifact leaves
+// them out.
+func ComputeDelta(src, base string) (*Delta, error) {
+	baseFiles, err := readBaseArtifact(base)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := &Delta{Base: filepath.Base(base)}
+	err = filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		name, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if name == DeltaFileName {
+			return nil
+		}
+
+		b, ok := baseFiles[name]
+		if !ok || b.size != info.Size() {
+			return nil
+		}
+		if !isBlob(name) {
+			digest, err := fileSHA256(path)
+			if err != nil {
+				return err
+			}
+			if digest != b.digest {
+				return nil
+			}
+		}
+		delta.Files = append(delta.Files, DeltaFile{Path: filepath.ToSlash(name), Size: b.size, Digest: b.digest})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "compare %s with base artifact %s failed", src, base)
+	}
+	sort.Slice(delta.Files, func(i, j int) bool { return delta.Files[i].Path < delta.Files[j].Path })
+	return delta, nil
+}
+
+// Contains reports whether the file is taken from the base artifact.
+func (d *Delta) Contains(name string) bool {
+	name = filepath.ToSlash(name)
+	i := sort.Search(len(d.Files), func(i int) bool { return d.Files[i].Path >= name })
+	return i < len(d.Files) && d.Files[i].Path == name
+}
+
+// WriteFile writes the delta into the dir, it is archived into the delta artifact.
+func (d *Delta) WriteFile(dir string) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, DeltaFileName), data, 0644)
+}
+
+// LoadDelta reads the delta in the dir the artifact is unpacked into, it is nil if the artifact is a full one.
+func LoadDelta(dir string) (*Delta, error) {
+	data, err := os.ReadFile(filepath.Join(dir, DeltaFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	delta := new(Delta)
+	if err := json.Unmarshal(data, delta); err != nil {
+		return nil, errors.Wrapf(err, "parse %s failed", DeltaFileName)
+	}
+	return delta, nil
+}
+
+// Missing returns the files of the base artifact which are not unpacked in the dir, or unpacked with a different
+// size.
+func (d *Delta) Missing(dir string) []string {
+	var missing []string
+	for _, f := range d.Files {
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		if err != nil || info.Size() != f.Size {
+			missing = append(missing, f.Path)
+		}
+	}
+	return missing
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
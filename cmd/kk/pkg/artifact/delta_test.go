@@ -0,0 +1,115 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package artifact
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	coreutil "github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDelta(t *testing.T) {
+	tmp := t.TempDir()
+
+	baseDir := filepath.Join(tmp, "base")
+	writeFiles(t, baseDir, map[string]string{
+		"kube/v1.26.5/amd64/kubeadm":          "kubeadm v1.26.5",
+		"cni/v1.2.0/amd64/cni-plugins.tgz":    "cni v1.2.0",
+		"images/index.json":                   `{"manifests": ["a"]}`,
+		"images/blobs/sha256/aaaa":            "layer a",
+		"repository/amd64/ubuntu/20.04/a.iso": "iso",
+	})
+	base := filepath.Join(tmp, "base.tar.gz")
+	if err := coreutil.Tar(baseDir, base, baseDir); err != nil {
+		t.Fatal(err)
+	}
+
+	src := filepath.Join(tmp, "src")
+	writeFiles(t, src, map[string]string{
+		"kube/v1.26.5/amd64/kubeadm":          "kubeadm v1.26.5",
+		"kube/v1.27.2/amd64/kubeadm":          "kubeadm v1.27.2",
+		"cni/v1.2.0/amd64/cni-plugins.tgz":    "cni v1.2.1",
+		"images/index.json":                   `{"manifests": ["a", "b"]}`,
+		"images/blobs/sha256/aaaa":            "layer a",
+		"images/blobs/sha256/bbbb":            "layer b",
+		"repository/amd64/ubuntu/20.04/a.iso": "iso",
+	})
+
+	delta, err := ComputeDelta(src, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range delta.Files {
+		got = append(got, f.Path)
+	}
+	want := []string{"images/blobs/sha256/aaaa", "kube/v1.26.5/amd64/kubeadm", "repository/amd64/ubuntu/20.04/a.iso"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ComputeDelta() = %v, want %v", got, want)
+	}
+	if delta.Base != "base.tar.gz" || !delta.Contains("kube/v1.26.5/amd64/kubeadm") || delta.Contains("images/index.json") {
+		t.Errorf("unexpected delta %+v", delta)
+	}
+
+	if _, err := archive(src, filepath.Join(tmp, "delta.tar.gz"), base, nil); err != nil {
+		t.Fatal(err)
+	}
+	if coreutil.IsExist(filepath.Join(src, DeltaFileName)) {
+		t.Errorf("%s is left in %s", DeltaFileName, src)
+	}
+
+	// the delta is layered on top of the base
+	workDir := filepath.Join(tmp, "work")
+	if err := coreutil.Untar(filepath.Join(tmp, "delta.tar.gz"), workDir); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadDelta(workDir)
+	if err != nil || loaded == nil {
+		t.Fatalf("LoadDelta() = %v, %v", loaded, err)
+	}
+	if missing := loaded.Missing(workDir); !reflect.DeepEqual(missing, want) {
+		t.Errorf("Missing() = %v, want %v", missing, want)
+	}
+	if err := coreutil.Untar(base, workDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := coreutil.Untar(filepath.Join(tmp, "delta.tar.gz"), workDir); err != nil {
+		t.Fatal(err)
+	}
+	if missing := loaded.Missing(workDir); len(missing) != 0 {
+		t.Errorf("Missing() = %v, want none", missing)
+	}
+	content, err := os.ReadFile(filepath.Join(workDir, "images/index.json"))
+	if err != nil || string(content) != `{"manifests": ["a", "b"]}` {
+		t.Errorf("images/index.json = %s, %v", content, err)
+	}
+}
@@ -0,0 +1,173 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package artifact
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	kubekeyv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/images"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/kubesphere"
+)
+
+// ClusterImage is an image deployed by KubeKey with the arches of the hosts running it.
+type ClusterImage struct {
+	Name   string
+	Arches []string
+}
+
+// Refs returns the refs of the image for all its arches in the OCI layout of an artifact.
+func (c ClusterImage) Refs() []string {
+	refs := make([]string, 0, len(c.Arches))
+	for _, arch := range c.Arches {
+		refs = append(refs, images.ArchImageRef(c.Name, arch))
+	}
+	return refs
+}
+
+// ClusterImages returns the images KubeKey deploys for the cluster. They are named as the images of a manifest,
+// i.e. the upstream images instead of the ones in the private registry of the cluster.
+func ClusterImages(runtime *common.KubeRuntime) []ClusterImage {
+	cluster := *runtime.Cluster
+	cluster.Registry = kubekeyv1alpha2.RegistryConfig{PrivateRegistry: "docker.io"}
+	kubeConf := &common.KubeConf{ClusterName: runtime.ClusterName, Cluster: &cluster, Arg: runtime.Arg}
+
+	localStorage := runtime.Cluster.KubeSphere.Enabled
+	if runtime.Arg.DeployLocalStorage != nil {
+		localStorage = *runtime.Arg.DeployLocalStorage
+	}
+
+	var list []ClusterImage
+	// etcd is only an image when it is deployed by kubeadm, which is decided by GetImage
+	for _, name := range append([]string{"etcd"}, imageNames...) {
+		image := images.GetImage(runtime, kubeConf, name)
+		enabled := image.Enable
+		if name == "provisioner-localpv" || name == "linux-utils" {
+			enabled = localStorage
+		}
+		if !enabled {
+			continue
+		}
+		if arches := hostArches(runtime, image.Group); len(arches) != 0 {
+			list = append(list, ClusterImage{Name: image.ImageName(), Arches: arches})
+		}
+	}
+
+	if runtime.Cluster.KubeSphere.Enabled {
+		// the images of KubeSphere are pulled by ks-installer, only ks-installer itself is deployed by KubeKey
+		cluster := *runtime.Cluster
+		cluster.Registry = kubekeyv1alpha2.RegistryConfig{}
+		repo := kubesphere.MirrorRepo(&common.KubeConf{Cluster: &cluster})
+		if !strings.Contains(repo, "/") {
+			repo = "docker.io/" + repo
+		}
+		list = append(list, ClusterImage{
+			Name:   fmt.Sprintf("%s/ks-installer:%s", repo, runtime.Cluster.KubeSphere.Version),
+			Arches: hostArches(runtime, common.K8s),
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+func hostArches(runtime *common.KubeRuntime, role string) []string {
+	set := make(map[string]struct{})
+	for _, host := range runtime.GetHostsByRole(role) {
+		set[host.GetArch()] = struct{}{}
+	}
+	arches := make([]string, 0, len(set))
+	for arch := range set {
+		arches = append(arches, arch)
+	}
+	sort.Strings(arches)
+	return arches
+}
+
+// ManifestImageRefs returns the refs of the images in the artifact exported with the manifest.
+func ManifestImageRefs(spec *kubekeyv1alpha2.ManifestSpec) []string {
+	var refs []string
+	for _, image := range spec.Images {
+		refs = append(refs, ClusterImage{Name: image, Arches: spec.Arches}.Refs()...)
+	}
+	return refs
+}
+
+// OCIImageRefs returns the refs of the images in the OCI layout, e.g. the images directory of an artifact.
+func OCIImageRefs(dir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "read the OCI index of %s failed", dir)
+	}
+	index := images.NewIndex()
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, errors.Wrapf(err, "parse the OCI index of %s failed", dir)
+	}
+
+	var refs []string
+	for _, m := range index.Manifests {
+		if m.Annotations.RefName != "" {
+			refs = append(refs, m.Annotations.RefName)
+		}
+	}
+	return refs, nil
+}
+
+// ImageDiff is the difference between the images of a cluster and the ones in an artifact.
+type ImageDiff struct {
+	// Missing are the refs the cluster needs but not in the artifact.
+	Missing []string
+	// Extra are the refs in the artifact but not needed by the cluster.
+	Extra []string
+}
+
+// DiffImages compares the images of a cluster with the refs of the images in an artifact.
+func DiffImages(list []ClusterImage, refs []string) *ImageDiff {
+	want := make(map[string]bool)
+	for _, image := range list {
+		for _, ref := range image.Refs() {
+			want[ref] = true
+		}
+	}
+	got := make(map[string]bool)
+	for _, ref := range refs {
+		got[ref] = true
+	}
+
+	diff := &ImageDiff{}
+	for ref := range want {
+		if !got[ref] {
+			diff.Missing = append(diff.Missing, ref)
+		}
+	}
+	for ref := range got {
+		if !want[ref] {
+			diff.Extra = append(diff.Extra, ref)
+		}
+	}
+	sort.Strings(diff.Missing)
+	sort.Strings(diff.Extra)
+	return diff
+}
@@ -0,0 +1,132 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package artifact
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	kubekeyv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+)
+
+const imagesTestConfig = `
+apiVersion: kubekey.kubesphere.io/v1alpha2
+kind: Cluster
+metadata:
+  name: sample
+spec:
+  hosts:
+  - {name: node1, address: 172.16.0.2, internalAddress: 172.16.0.2, user: root, password: "123456"}
+  - {name: node2, address: 172.16.0.3, internalAddress: 172.16.0.3, user: root, password: "123456", arch: arm64}
+  roleGroups:
+    etcd: [node1]
+    control-plane: [node1]
+    worker: [node1, node2]
+  kubernetes:
+    version: v1.26.5
+  network:
+    plugin: flannel
+---
+apiVersion: installer.kubesphere.io/v1alpha1
+kind: ClusterConfiguration
+metadata:
+  name: ks-installer
+  namespace: kubesphere-system
+  labels:
+    version: v3.4.1
+`
+
+func TestClusterImages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFiles(t, filepath.Dir(path), map[string]string{"config.yaml": imagesTestConfig})
+	runtime, err := common.NewKubeRuntime(common.File, common.Argument{FilePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := ClusterImages(runtime)
+	got := make(map[string][]string)
+	for _, image := range list {
+		got[image.Name] = image.Arches
+	}
+
+	tests := []struct {
+		image  string
+		arches []string
+	}{
+		{image: "docker.io/kubesphere/pause:3.9", arches: []string{"amd64", "arm64"}},
+		{image: "docker.io/kubesphere/kube-apiserver:v1.26.5", arches: []string{"amd64"}},
+		{image: "docker.io/coredns/coredns:1.9.3", arches: []string{"amd64", "arm64"}},
+		{image: "docker.io/kubesphere/k8s-dns-node-cache:1.22.20", arches: []string{"amd64", "arm64"}},
+		{image: "docker.io/flannel/flannel:" + kubekeyv1alpha2.DefaultFlannelVersion, arches: []string{"amd64", "arm64"}},
+		{image: "docker.io/openebs/provisioner-localpv:3.3.0", arches: []string{"amd64", "arm64"}},
+		{image: "docker.io/kubesphere/ks-installer:v3.4.1", arches: []string{"amd64", "arm64"}},
+		// the etcd is installed from the binary, and the other network plugins are not deployed
+		{image: "docker.io/kubesphere/etcd:" + kubekeyv1alpha2.DefaultEtcdVersion},
+		{image: "docker.io/calico/node:" + kubekeyv1alpha2.DefaultCalicoVersion},
+		{image: "docker.io/library/haproxy:2.9.6-alpine"},
+	}
+	for _, tt := range tests {
+		if arches := got[tt.image]; !reflect.DeepEqual(arches, tt.arches) {
+			t.Errorf("arches of %s = %v, want %v", tt.image, arches, tt.arches)
+		}
+	}
+
+	// the manifest lacks the arm64 images and has an image not needed by the cluster
+	var names, wantMissing []string
+	for _, image := range list {
+		names = append(names, image.Name)
+		if image.Arches[len(image.Arches)-1] == "arm64" {
+			wantMissing = append(wantMissing, image.Name+"-arm64")
+		}
+	}
+	sort.Strings(wantMissing)
+	spec := &kubekeyv1alpha2.ManifestSpec{Arches: []string{"amd64"}, Images: append(names, "docker.io/library/busybox:1.36")}
+	diff := DiffImages(list, ManifestImageRefs(spec))
+	if !reflect.DeepEqual(diff.Missing, wantMissing) || !reflect.DeepEqual(diff.Extra, []string{"docker.io/library/busybox:1.36-amd64"}) {
+		t.Errorf("DiffImages() = %+v, want missing %v", diff, wantMissing)
+	}
+}
+
+func TestOCIImageRefs(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"index.json": `{
+  "schemaVersion": 2,
+  "manifests": [
+    {"digest": "sha256:a", "annotations": {"org.opencontainers.image.ref.name": "docker.io/kubesphere/pause:3.9-amd64"}},
+    {"digest": "sha256:b", "annotations": {"org.opencontainers.image.ref.name": "docker.io/kubesphere/pause:3.9-arm-v7"}},
+    {"digest": "sha256:c"}
+  ]
+}`})
+
+	refs, err := OCIImageRefs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"docker.io/kubesphere/pause:3.9-amd64", "docker.io/kubesphere/pause:3.9-arm-v7"}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("OCIImageRefs() = %v, want %v", refs, want)
+	}
+
+	diff := DiffImages([]ClusterImage{{Name: "docker.io/kubesphere/pause:3.9", Arches: []string{"amd64", "arm/v7", "arm64"}}}, refs)
+	if !reflect.DeepEqual(diff.Missing, []string{"docker.io/kubesphere/pause:3.9-arm64"}) || len(diff.Extra) != 0 {
+		t.Errorf("DiffImages() = %+v", diff)
+	}
+}
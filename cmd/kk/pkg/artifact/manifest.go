@@ -65,6 +65,10 @@ func CreateManifest(arg common.Argument, name string, registry bool) error {
 			Type:    containerStrArr[0],
 			Version: containerStrArr[1],
 		}
+		// the runtime name reported by cri-o is not the name of its binary
+		if containerRuntime.Type == "cri-o" {
+			containerRuntime.Type = kubekeyv1alpha2.Crio
+		}
 		if containerRuntime.Type == "containerd" &&
 			versionutil.MustParseSemantic(containerRuntime.Version).LessThan(versionutil.MustParseSemantic("1.6.2")) {
 			containerRuntime.Version = "1.6.2"
@@ -214,6 +218,41 @@ func checkFileExists(fileName string) {
 	}
 }
 
+// imageNames are the images defined by images.GetImage, except etcd which is installed from the binary by default.
+var imageNames = []string{
+	"pause",
+	"kube-apiserver",
+	"kube-controller-manager",
+	"kube-scheduler",
+	"kube-proxy",
+
+	// network
+	"coredns",
+	"k8s-dns-node-cache",
+	"calico-kube-controllers",
+	"calico-cni",
+	"calico-node",
+	"calico-flexvol",
+	"calico-typha",
+	"flannel",
+	"flannel-cni-plugin",
+	"cilium",
+	"cilium-operator-generic",
+	"hybridnet",
+	"kubeovn",
+	"multus",
+	// storage
+	"provisioner-localpv",
+	"linux-utils",
+	// load balancer
+	"haproxy",
+	"kubevip",
+	// kata-deploy
+	"kata-deploy",
+	// node-feature-discovery
+	"node-feature-discovery",
+}
+
 func CreateManifestSpecifyVersion(arg common.Argument, name, version string, registry bool, arch []string) error {
 	checkFileExists(arg.FilePath)
 
@@ -221,39 +260,6 @@ func CreateManifestSpecifyVersion(arg common.Argument, name, version string, reg
 
 	k8sVersion := strings.Split(version, ",")
 
-	imageNames := []string{
-		"pause",
-		"kube-apiserver",
-		"kube-controller-manager",
-		"kube-scheduler",
-		"kube-proxy",
-
-		// network
-		"coredns",
-		"k8s-dns-node-cache",
-		"calico-kube-controllers",
-		"calico-cni",
-		"calico-node",
-		"calico-flexvol",
-		"calico-typha",
-		"flannel",
-		"flannel-cni-plugin",
-		"cilium",
-		"cilium-operator-generic",
-		"hybridnet",
-		"kubeovn",
-		"multus",
-		// storage
-		"provisioner-localpv",
-		"linux-utils",
-		// load balancer
-		"haproxy",
-		"kubevip",
-		// kata-deploy
-		"kata-deploy",
-		// node-feature-discovery
-		"node-feature-discovery",
-	}
 	var imageArr []string
 	for _, v := range k8sVersion {
 		versionutil.MustParseGeneric(v)
@@ -86,6 +86,14 @@ func (u *UnArchiveModule) Init() {
 		Action: new(Md5Check),
 	}
 
+	// the artifact with the md5 of the last unarchived one has been verified then, it is not read again
+	verify := &task.LocalTask{
+		Name:    "VerifyArtifact",
+		Desc:    "Verify the signature of the KubeKey artifact",
+		Prepare: &Md5AreEqual{Not: true},
+		Action:  new(VerifyArtifact),
+	}
+
 	unArchive := &task.LocalTask{
 		Name:    "UnArchiveArtifact",
 		Desc:    "UnArchive the KubeKey artifact",
@@ -93,6 +101,13 @@ func (u *UnArchiveModule) Init() {
 		Action:  new(UnArchive),
 	}
 
+	checkDeltaBase := &task.LocalTask{
+		Name:    "CheckDeltaArtifactBase",
+		Desc:    "Check the base of the delta KubeKey artifact",
+		Prepare: &Md5AreEqual{Not: true},
+		Action:  new(CheckDeltaBase),
+	}
+
 	createMd5File := &task.LocalTask{
 		Name:    "CreateArtifactMd5File",
 		Desc:    "Create the KubeKey artifact Md5 file",
@@ -102,7 +117,9 @@ func (u *UnArchiveModule) Init() {
 
 	u.Tasks = []task.Interface{
 		md5Check,
+		verify,
 		unArchive,
+		checkDeltaBase,
 		createMd5File,
 	}
 }
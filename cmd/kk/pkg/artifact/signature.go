@@ -0,0 +1,346 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package artifact
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// ContentFileName is the file in the root of an artifact, which lists the sha256 digests of all the files in it.
+	ContentFileName = "artifact-content.json"
+	// SignatureFileName is the base64 encoded signature of the content file, it can be verified by
+	// `cosign verify-blob --key <public key> --signature artifact-content.json.sig artifact-content.json`.
+	SignatureFileName = ContentFileName + ".sig"
+
+	// CosignPasswordEnv is the password of an encrypted cosign private key, it is the one used by cosign.
+	CosignPasswordEnv = "COSIGN_PASSWORD"
+)
+
+// Content is the content manifest of an artifact.
+type Content struct {
+	Files []ContentFile `json:"files"`
+}
+
+// ContentFile is a file in the artifact with its sha256 digest.
+type ContentFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Digest string `json:"digest"`
+}
+
+// NewContent returns the content manifest of the files under src which are archived, the filter is called with the
+// names of the files in the archive.
+func NewContent(src string, filter func(name string) bool) (*Content, error) {
+	content := &Content{}
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		name, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if isContentFile(name) || (filter != nil && !filter(name)) {
+			return nil
+		}
+
+		digest, err := fileSHA256(path)
+		if err != nil {
+			return err
+		}
+		content.Files = append(content.Files, ContentFile{Path: filepath.ToSlash(name), Size: info.Size(), Digest: digest})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "compute the content of %s failed", src)
+	}
+	sort.Slice(content.Files, func(i, j int) bool { return content.Files[i].Path < content.Files[j].Path })
+	return content, nil
+}
+
+// isContentFile reports whether the file is the content manifest or its signature, which are not listed in the
+// content manifest.
+func isContentFile(name string) bool {
+	name = filepath.ToSlash(name)
+	return name == ContentFileName || name == SignatureFileName
+}
+
+// WriteFiles writes the content manifest into the dir, and its signature if the signer is not nil.
+func (c *Content) WriteFiles(dir string, signer crypto.Signer) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, ContentFileName), data, 0644); err != nil {
+		return err
+	}
+	if signer == nil {
+		return os.RemoveAll(filepath.Join(dir, SignatureFileName))
+	}
+
+	sig, err := Sign(signer, data)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, SignatureFileName), []byte(base64.StdEncoding.EncodeToString(sig)), 0644)
+}
+
+// Sign signs the data as cosign does, the ECDSA signature is made over the sha256 digest of the data and the ed25519
+// one over the data itself.
+func Sign(signer crypto.Signer, data []byte) ([]byte, error) {
+	switch signer.Public().(type) {
+	case ed25519.PublicKey:
+		return signer.Sign(rand.Reader, data, crypto.Hash(0))
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+		return signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	default:
+		return nil, errors.Errorf("unsupported key type %T, only ed25519 and ECDSA keys are supported", signer.Public())
+	}
+}
+
+// Verify verifies the signature made by Sign.
+func Verify(pub crypto.PublicKey, data, sig []byte) error {
+	switch key := pub.(type) {
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, data, sig) {
+			return errors.New("invalid ed25519 signature")
+		}
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+		if !ecdsa.VerifyASN1(key, digest[:], sig) {
+			return errors.New("invalid ECDSA signature")
+		}
+	default:
+		return errors.Errorf("unsupported key type %T, only ed25519 and ECDSA keys are supported", pub)
+	}
+	return nil
+}
+
+// encryptedKey is an encrypted private key generated by `cosign generate-key-pair`.
+type encryptedKey struct {
+	KDF struct {
+		Name   string `json:"name"`
+		Params struct {
+			N int `json:"N"`
+			R int `json:"r"`
+			P int `json:"p"`
+		} `json:"params"`
+		Salt []byte `json:"salt"`
+	} `json:"kdf"`
+	Cipher struct {
+		Name  string `json:"name"`
+		Nonce []byte `json:"nonce"`
+	} `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// LoadSigner loads the private key in the PEM file, which is a PKCS #8 or SEC 1 private key, or an encrypted cosign
+// private key with its password in the COSIGN_PASSWORD environment variable.
+func LoadSigner(keyFile string) (crypto.Signer, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read the signing key %s failed", keyFile)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.Errorf("no PEM data is found in the signing key %s", keyFile)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "ENCRYPTED SIGSTORE PRIVATE KEY", "ENCRYPTED COSIGN PRIVATE KEY":
+		var der []byte
+		if der, err = decryptCosignKey(block.Bytes, []byte(os.Getenv(CosignPasswordEnv))); err == nil {
+			key, err = x509.ParsePKCS8PrivateKey(der)
+		}
+	default:
+		return nil, errors.Errorf("unsupported PEM type %s of the signing key %s", block.Type, keyFile)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse the signing key %s failed", keyFile)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.Errorf("unsupported key type %T of the signing key %s", key, keyFile)
+	}
+	switch signer.Public().(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey:
+	default:
+		return nil, errors.Errorf("unsupported key type %T of the signing key %s, only ed25519 and ECDSA keys are supported", key, keyFile)
+	}
+	return signer, nil
+}
+
+func decryptCosignKey(data, password []byte) ([]byte, error) {
+	k := encryptedKey{}
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, err
+	}
+	if k.KDF.Name != "scrypt" || k.Cipher.Name != "nacl/secretbox" || len(k.Cipher.Nonce) != 24 {
+		return nil, errors.Errorf("unsupported encryption %s with %s", k.Cipher.Name, k.KDF.Name)
+	}
+
+	derived, err := scrypt.Key(password, k.KDF.Salt, k.KDF.Params.N, k.KDF.Params.R, k.KDF.Params.P, 32)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	var secret [32]byte
+	copy(nonce[:], k.Cipher.Nonce)
+	copy(secret[:], derived)
+	der, ok := secretbox.Open(nil, k.Ciphertext, &nonce, &secret)
+	if !ok {
+		return nil, errors.Errorf("decrypt failed, please check the password in %s", CosignPasswordEnv)
+	}
+	return der, nil
+}
+
+// LoadPublicKey loads the PEM encoded PKIX public key, e.g. the cosign.pub generated by cosign.
+func LoadPublicKey(keyFile string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read the verification key %s failed", keyFile)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, errors.Errorf("no PEM encoded public key is found in %s", keyFile)
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse the verification key %s failed", keyFile)
+	}
+	return pub, nil
+}
+
+// VerifyArchive verifies the signature of the content manifest in the artifact with the public key, and checks that
+// the files in the artifact are the ones in the content manifest.
+func VerifyArchive(artifact string, pub crypto.PublicKey) error {
+	f, err := os.Open(artifact)
+	if err != nil {
+		return errors.Wrapf(err, "open artifact %s failed", artifact)
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return errors.Wrapf(err, "read artifact %s failed", artifact)
+	}
+	defer gr.Close()
+
+	var contentData, sig []byte
+	files := make(map[string]ContentFile)
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "read artifact %s failed", artifact)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := filepath.ToSlash(filepath.Clean(hdr.Name))
+		switch name {
+		case ContentFileName:
+			if contentData, err = io.ReadAll(tr); err != nil {
+				return errors.Wrapf(err, "read %s of artifact %s failed", name, artifact)
+			}
+			continue
+		case SignatureFileName:
+			if sig, err = io.ReadAll(tr); err != nil {
+				return errors.Wrapf(err, "read %s of artifact %s failed", name, artifact)
+			}
+			continue
+		}
+
+		h := sha256.New()
+		size, err := io.Copy(h, tr)
+		if err != nil {
+			return errors.Wrapf(err, "read %s of artifact %s failed", name, artifact)
+		}
+		files[name] = ContentFile{Path: name, Size: size, Digest: hex.EncodeToString(h.Sum(nil))}
+	}
+
+	if contentData == nil || sig == nil {
+		return errors.Errorf("artifact %s is not signed", artifact)
+	}
+	sigBytes, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return errors.Wrapf(err, "decode the signature of artifact %s failed", artifact)
+	}
+	if err := Verify(pub, contentData, sigBytes); err != nil {
+		return errors.Wrapf(err, "verify the signature of artifact %s failed", artifact)
+	}
+
+	content := &Content{}
+	if err := json.Unmarshal(contentData, content); err != nil {
+		return errors.Wrapf(err, "parse %s of artifact %s failed", ContentFileName, artifact)
+	}
+	for _, want := range content.Files {
+		got, ok := files[want.Path]
+		if !ok {
+			return errors.Errorf("%s is missing from artifact %s", want.Path, artifact)
+		}
+		if got != want {
+			return errors.Errorf("%s of artifact %s has been tampered with, its sha256 digest is %s, want %s",
+				want.Path, artifact, got.Digest, want.Digest)
+		}
+		delete(files, want.Path)
+	}
+	if len(files) != 0 {
+		extra := make([]string, 0, len(files))
+		for name := range files {
+			extra = append(extra, name)
+		}
+		sort.Strings(extra)
+		return errors.Errorf("%s of artifact %s are not in the signed content manifest", strings.Join(extra, ", "), artifact)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package artifact
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+
+	coreutil "github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
+)
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeCosignKey writes the private key encrypted as `cosign generate-key-pair` does.
+func writeCosignKey(t *testing.T, path string, key crypto.Signer, password string) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := encryptedKey{}
+	k.KDF.Name = "scrypt"
+	k.KDF.Params.N, k.KDF.Params.R, k.KDF.Params.P = 1024, 8, 1
+	k.KDF.Salt = []byte("0123456789abcdef0123456789abcdef")
+	k.Cipher.Name = "nacl/secretbox"
+	k.Cipher.Nonce = []byte("0123456789abcdef01234567")
+
+	derived, err := scrypt.Key([]byte(password), k.KDF.Salt, k.KDF.Params.N, k.KDF.Params.R, k.KDF.Params.P, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nonce [24]byte
+	var secret [32]byte
+	copy(nonce[:], k.Cipher.Nonce)
+	copy(secret[:], derived)
+	k.Ciphertext = secretbox.Seal(nil, der, &nonce, &secret)
+
+	data, err := json.Marshal(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, path, "ENCRYPTED SIGSTORE PRIVATE KEY", data)
+}
+
+func TestLoadSigner(t *testing.T) {
+	dir := t.TempDir()
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(edKey)
+	writePEM(t, filepath.Join(dir, "ed25519.key"), "PRIVATE KEY", pkcs8)
+	sec1, _ := x509.MarshalECPrivateKey(ecKey)
+	writePEM(t, filepath.Join(dir, "ecdsa.key"), "EC PRIVATE KEY", sec1)
+	writeCosignKey(t, filepath.Join(dir, "cosign.key"), ecKey, "secret")
+
+	tests := []struct {
+		name     string
+		key      string
+		password string
+		pub      crypto.PublicKey
+		wantErr  bool
+	}{
+		{name: "ed25519", key: "ed25519.key", pub: edKey.Public()},
+		{name: "ecdsa", key: "ecdsa.key", pub: ecKey.Public()},
+		{name: "cosign", key: "cosign.key", password: "secret", pub: ecKey.Public()},
+		{name: "cosign with wrong password", key: "cosign.key", password: "wrong", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(CosignPasswordEnv, tt.password)
+			signer, err := LoadSigner(filepath.Join(dir, tt.key))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadSigner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			pubDER, _ := x509.MarshalPKIXPublicKey(tt.pub)
+			writePEM(t, filepath.Join(dir, tt.name+".pub"), "PUBLIC KEY", pubDER)
+			pub, err := LoadPublicKey(filepath.Join(dir, tt.name+".pub"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data := []byte(`{"files": []}`)
+			sig, err := Sign(signer, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Verify(pub, data, sig); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+			if err := Verify(pub, []byte(`{"files": null}`), sig); err == nil {
+				t.Errorf("Verify() of the tampered data succeeded")
+			}
+		})
+	}
+}
+
+func TestVerifyArchive(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	files := map[string]string{
+		"kube/v1.26.5/amd64/kubeadm":          "kubeadm v1.26.5",
+		"images/index.json":                   `{"manifests": []}`,
+		"images/blobs/sha256/aaaa":            "layer a",
+		"repository/amd64/ubuntu/20.04/a.iso": "iso",
+	}
+
+	tests := []struct {
+		name    string
+		signer  crypto.Signer
+		pub     crypto.PublicKey
+		tamper  func(t *testing.T, src string)
+		wantErr string
+	}{
+		{name: "signed", signer: key, pub: key.Public()},
+		{name: "unsigned", pub: key.Public(), wantErr: "is not signed"},
+		{name: "wrong key", signer: key, pub: otherPub, wantErr: "invalid ed25519 signature"},
+		{
+			name: "modified file", signer: key, pub: key.Public(), wantErr: "has been tampered with",
+			tamper: func(t *testing.T, src string) {
+				writeFiles(t, src, map[string]string{"kube/v1.26.5/amd64/kubeadm": "kubeadm v1.26.6"})
+			},
+		},
+		{
+			name: "added file", signer: key, pub: key.Public(), wantErr: "not in the signed content manifest",
+			tamper: func(t *testing.T, src string) {
+				writeFiles(t, src, map[string]string{"images/blobs/sha256/bbbb": "layer b"})
+			},
+		},
+		{
+			name: "removed file", signer: key, pub: key.Public(), wantErr: "is missing from",
+			tamper: func(t *testing.T, src string) {
+				if err := os.Remove(filepath.Join(src, "repository/amd64/ubuntu/20.04/a.iso")); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			src := filepath.Join(tmp, "src")
+			writeFiles(t, src, files)
+			dst := filepath.Join(tmp, "artifact.tar.gz")
+
+			if tt.tamper == nil {
+				if _, err := archive(src, dst, "", tt.signer); err != nil {
+					t.Fatal(err)
+				}
+				if coreutil.IsExist(filepath.Join(src, ContentFileName)) {
+					t.Errorf("%s is left in %s", ContentFileName, src)
+				}
+			} else {
+				content, err := NewContent(src, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := content.WriteFiles(src, tt.signer); err != nil {
+					t.Fatal(err)
+				}
+				tt.tamper(t, src)
+				if err := coreutil.Tar(src, dst, src); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := VerifyArchive(dst, tt.pub)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("VerifyArchive() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("VerifyArchive() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -17,8 +17,10 @@
 package artifact
 
 import (
+	"crypto"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -56,6 +58,19 @@ func (d *DownloadISOFile) Execute(runtime connector.Runtime) error {
 			continue
 		}
 
+		if d.Manifest.Arg.DownloadCommand == nil {
+			downloader, err := files.NewDownloader(d.Manifest.Arg.Download)
+			if err != nil {
+				return err
+			}
+			if err := downloader.Download(runtime.GetRunner().Context(), fileName, []string{sys.Repository.Iso.Url}, filePath,
+				sys.Repository.Iso.Checksum); err != nil {
+				return fmt.Errorf("Failed to download %s iso file: %s error: %w ", fileName, sys.Repository.Iso.Url, err)
+			}
+			d.Manifest.Spec.OperatingSystems[i].Repository.Iso.LocalPath = filePath
+			continue
+		}
+
 		getCmd := d.Manifest.Arg.DownloadCommand(filePath, sys.Repository.Iso.Url)
 
 		cmd := exec.Command("/bin/sh", "-c", getCmd)
@@ -116,9 +131,24 @@ type ArchiveDependencies struct {
 }
 
 func (a *ArchiveDependencies) Execute(runtime connector.Runtime) error {
+	var signer crypto.Signer
+	if a.Manifest.Arg.SignKey != "" {
+		s, err := LoadSigner(a.Manifest.Arg.SignKey)
+		if err != nil {
+			return err
+		}
+		signer = s
+	} else {
+		logger.Log.Warnf("the artifact is not signed, it is refused by the import unless the verification is skipped")
+	}
+
 	src := filepath.Join(runtime.GetWorkDir(), common.Artifact)
-	if err := coreutil.Tar(src, a.Manifest.Arg.Output, src); err != nil {
-		return errors.Wrapf(errors.WithStack(err), "archive %s failed", src)
+	delta, err := archive(src, a.Manifest.Arg.Output, a.Manifest.Arg.Base, signer)
+	if err != nil {
+		return err
+	}
+	if delta != nil {
+		logger.Log.Infof("%d files are taken from the base artifact %s", len(delta.Files), a.Manifest.Arg.Base)
 	}
 
 	// skip remove artifact if --skip-remove-artifact
@@ -133,17 +163,108 @@ func (a *ArchiveDependencies) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+// archive archives the files under src with their content manifest, which is signed if the signer is not nil. If the
+// base artifact is given, the files which are the same in it are left out and listed in the delta file of the archive.
+func archive(src, dst, base string, signer crypto.Signer) (*Delta, error) {
+	var delta *Delta
+	if base != "" {
+		d, err := ComputeDelta(src, base)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.WriteFile(src); err != nil {
+			return nil, errors.Wrapf(errors.WithStack(err), "write %s failed", DeltaFileName)
+		}
+		defer os.Remove(filepath.Join(src, DeltaFileName))
+		delta = d
+	}
+	archived := func(name string) bool {
+		return delta == nil || !delta.Contains(name)
+	}
+
+	content, err := NewContent(src, archived)
+	if err != nil {
+		return nil, err
+	}
+	if err := content.WriteFiles(src, signer); err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "write %s failed", ContentFileName)
+	}
+	defer os.Remove(filepath.Join(src, ContentFileName))
+	defer os.Remove(filepath.Join(src, SignatureFileName))
+
+	if err := coreutil.TarWithFilter(src, dst, src, func(name string, _ fs.FileInfo) bool {
+		return archived(name)
+	}); err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "archive %s failed", src)
+	}
+	return delta, nil
+}
+
 type UnArchive struct {
 	common.KubeAction
 }
 
 func (u *UnArchive) Execute(runtime connector.Runtime) error {
+	// the delta file is only left by a delta artifact, it is removed in case a full artifact is unarchived over it
+	if err := os.RemoveAll(filepath.Join(runtime.GetWorkDir(), DeltaFileName)); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "remove %s failed", DeltaFileName)
+	}
 	if err := coreutil.Untar(u.KubeConf.Arg.Artifact, runtime.GetWorkDir()); err != nil {
 		return errors.Wrapf(errors.WithStack(err), "unArchive %s failed", u.KubeConf.Arg.Artifact)
 	}
 	return nil
 }
 
+// VerifyArtifact verifies the signature of the artifact and the digests of the files in it before it is unarchived.
+type VerifyArtifact struct {
+	common.KubeAction
+}
+
+func (v *VerifyArtifact) Execute(_ connector.Runtime) error {
+	if v.KubeConf.Arg.SkipArtifactVerify {
+		logger.Log.Warnf("the verification of the artifact %s is skipped", v.KubeConf.Arg.Artifact)
+		return nil
+	}
+	if v.KubeConf.Arg.ArtifactVerifyKey == "" {
+		return errors.Errorf("a public key is required to verify the artifact %s, please specify it by --verify-key "+
+			"or skip the verification by --skip-verify-artifact", v.KubeConf.Arg.Artifact)
+	}
+
+	pub, err := LoadPublicKey(v.KubeConf.Arg.ArtifactVerifyKey)
+	if err != nil {
+		return err
+	}
+	if err := VerifyArchive(v.KubeConf.Arg.Artifact, pub); err != nil {
+		return errors.Wrap(err, "refuse to use the artifact, skip the verification by --skip-verify-artifact if it is trusted")
+	}
+	logger.Log.Infof("the artifact %s is verified by %s", v.KubeConf.Arg.Artifact, v.KubeConf.Arg.ArtifactVerifyKey)
+	return nil
+}
+
+// CheckDeltaBase checks that the base artifact of a delta artifact has been unarchived into the work dir, so the
+// delta is layered on top of it.
+type CheckDeltaBase struct {
+	common.KubeAction
+}
+
+func (c *CheckDeltaBase) Execute(runtime connector.Runtime) error {
+	delta, err := LoadDelta(runtime.GetWorkDir())
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "load %s failed", DeltaFileName)
+	}
+	if delta == nil {
+		return nil
+	}
+
+	if missing := delta.Missing(runtime.GetWorkDir()); len(missing) != 0 {
+		return errors.Errorf("%s is a delta artifact of %s, but %d files of the base artifact are not found in %s, "+
+			"e.g. %s. Please import the base artifact %s first",
+			c.KubeConf.Arg.Artifact, delta.Base, len(missing), runtime.GetWorkDir(), missing[0], delta.Base)
+	}
+	logger.Log.Infof("the delta artifact %s is layered on top of the base artifact %s", c.KubeConf.Arg.Artifact, delta.Base)
+	return nil
+}
+
 type Md5Check struct {
 	common.KubeAction
 }
@@ -17,6 +17,7 @@
 package binaries
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 
@@ -30,7 +31,7 @@ import (
 )
 
 // K8sFilesDownloadHTTP defines the kubernetes' binaries that need to be downloaded in advance and downloads them.
-func K8sFilesDownloadHTTP(kubeConf *common.KubeConf, path, version, arch string, pipelineCache *cache.Cache) error {
+func K8sFilesDownloadHTTP(ctx context.Context, kubeConf *common.KubeConf, path, version, arch string, pipelineCache *cache.Cache) error {
 
 	etcd := files.NewKubeBinary("etcd", arch, kubekeyapiv1alpha2.DefaultEtcdVersion, path, kubeConf.Arg.DownloadCommand)
 	kubeadm := files.NewKubeBinary("kubeadm", arch, version, path, kubeConf.Arg.DownloadCommand)
@@ -43,6 +44,7 @@ func K8sFilesDownloadHTTP(kubeConf *common.KubeConf, path, version, arch string,
 	crictl := files.NewKubeBinary("crictl", arch, kubekeyapiv1alpha2.DefaultCrictlVersion, path, kubeConf.Arg.DownloadCommand)
 	containerd := files.NewKubeBinary("containerd", arch, kubekeyapiv1alpha2.DefaultContainerdVersion, path, kubeConf.Arg.DownloadCommand)
 	runc := files.NewKubeBinary("runc", arch, kubekeyapiv1alpha2.DefaultRuncVersion, path, kubeConf.Arg.DownloadCommand)
+	crio := files.NewKubeBinary("crio", arch, kubekeyapiv1alpha2.DefaultCrioVersion, path, kubeConf.Arg.DownloadCommand)
 	calicoctl := files.NewKubeBinary("calicoctl", arch, kubekeyapiv1alpha2.DefaultCalicoVersion, path, kubeConf.Arg.DownloadCommand)
 
 	buildx := files.NewKubeBinary(common.Buildx, arch, kubekeyapiv1alpha2.DefaultBuildxVersion, path, kubeConf.Arg.DownloadCommand)
@@ -59,6 +61,8 @@ func K8sFilesDownloadHTTP(kubeConf *common.KubeConf, path, version, arch string,
 		}
 	} else if kubeConf.Cluster.Kubernetes.ContainerManager == kubekeyapiv1alpha2.Containerd {
 		binaries = append(binaries, containerd, runc)
+	} else if kubeConf.Cluster.Kubernetes.ContainerManager == kubekeyapiv1alpha2.Crio {
+		binaries = append(binaries, crio)
 	}
 
 	if kubeConf.Cluster.Network.Plugin == "calico" {
@@ -67,34 +71,26 @@ func K8sFilesDownloadHTTP(kubeConf *common.KubeConf, path, version, arch string,
 
 	binariesMap := make(map[string]*files.KubeBinary)
 	for _, binary := range binaries {
-		if err := binary.CreateBaseDir(); err != nil {
-			return errors.Wrapf(errors.WithStack(err), "create file %s base dir failed", binary.FileName)
-		}
-
-		logger.Log.Messagef(common.LocalHost, "downloading %s %s %s ...", arch, binary.ID, binary.Version)
-
 		binariesMap[binary.ID] = binary
-		if util.IsExist(binary.Path()) {
-			// download it again if it's incorrect
-			if err := binary.SHA256Check(); err != nil {
-				p := binary.Path()
-				_ = exec.Command("/bin/sh", "-c", fmt.Sprintf("rm -f %s", p)).Run()
-			} else {
-				logger.Log.Messagef(common.LocalHost, "%s exists", binary.ID)
-				continue
-			}
-		}
-
-		if err := binary.Download(); err != nil {
-			return fmt.Errorf("Failed to download %s binary: %s error: %w ", binary.ID, binary.GetCmd(), err)
-		}
+	}
+	if err := download(ctx, kubeConf.Arg.Download, binaries); err != nil {
+		return err
 	}
 
 	if kubeConf.Cluster.KubeSphere.Version == "v2.1.1" {
 		logger.Log.Infoln(fmt.Sprintf("Downloading %s ...", "helm2"))
-		if util.IsExist(fmt.Sprintf("%s/helm2", helm.BaseDir)) == false {
-			cmd := kubeConf.Arg.DownloadCommand(fmt.Sprintf("%s/helm2", helm.BaseDir),
-				fmt.Sprintf("https://kubernetes-helm.pek3b.qingstor.com/linux-%s/%s/helm", helm.Arch, "v2.16.9"))
+		helm2 := fmt.Sprintf("%s/helm2", helm.BaseDir)
+		url := fmt.Sprintf("https://kubernetes-helm.pek3b.qingstor.com/linux-%s/%s/helm", helm.Arch, "v2.16.9")
+		if util.IsExist(helm2) == false && kubeConf.Arg.DownloadCommand == nil {
+			downloader, err := files.NewDownloader(kubeConf.Arg.Download)
+			if err != nil {
+				return err
+			}
+			if err := downloader.Download(ctx, "helm2", []string{url}, helm2, ""); err != nil {
+				return errors.Wrap(err, "Failed to download helm2 binary")
+			}
+		} else if util.IsExist(helm2) == false {
+			cmd := kubeConf.Arg.DownloadCommand(helm2, url)
 			if output, err := exec.Command("/bin/sh", "-c", cmd).CombinedOutput(); err != nil {
 				fmt.Println(string(output))
 				return errors.Wrap(err, "Failed to download helm2 binary")
@@ -106,7 +102,7 @@ func K8sFilesDownloadHTTP(kubeConf *common.KubeConf, path, version, arch string,
 	return nil
 }
 
-func KubernetesComponentBinariesDownload(manifest *common.ArtifactManifest, path, arch string) error {
+func KubernetesComponentBinariesDownload(ctx context.Context, manifest *common.ArtifactManifest, path, arch string) error {
 	m := manifest.Spec
 	var binaries []*files.KubeBinary
 
@@ -143,62 +139,28 @@ func KubernetesComponentBinariesDownload(manifest *common.ArtifactManifest, path
 		}
 	}
 
-	for _, binary := range binaries {
-		if err := binary.CreateBaseDir(); err != nil {
-			return errors.Wrapf(errors.WithStack(err), "create file %s base dir failed", binary.FileName)
-		}
-
-		logger.Log.Messagef(common.LocalHost, "downloading %s %s %s ...", arch, binary.ID, binary.Version)
-
-		if util.IsExist(binary.Path()) {
-			// download it again if it's incorrect
-			if err := binary.SHA256Check(); err != nil {
-				_ = exec.Command("/bin/sh", "-c", fmt.Sprintf("rm -f %s", binary.Path())).Run()
-			} else {
-				continue
-			}
-		}
-
-		if err := binary.Download(); err != nil {
-			return fmt.Errorf("Failed to download %s binary: %s error: %w ", binary.ID, binary.GetCmd(), err)
-		}
+	if err := download(ctx, manifest.Arg.Download, binaries); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-func KubernetesArtifactBinariesDownload(manifest *common.ArtifactManifest, path, arch, k8sVersion string) error {
+func KubernetesArtifactBinariesDownload(ctx context.Context, manifest *common.ArtifactManifest, path, arch, k8sVersion string) error {
 	kubeadm := files.NewKubeBinary("kubeadm", arch, k8sVersion, path, manifest.Arg.DownloadCommand)
 	kubelet := files.NewKubeBinary("kubelet", arch, k8sVersion, path, manifest.Arg.DownloadCommand)
 	kubectl := files.NewKubeBinary("kubectl", arch, k8sVersion, path, manifest.Arg.DownloadCommand)
 	binaries := []*files.KubeBinary{kubeadm, kubelet, kubectl}
 
-	for _, binary := range binaries {
-		if err := binary.CreateBaseDir(); err != nil {
-			return errors.Wrapf(errors.WithStack(err), "create file %s base dir failed", binary.FileName)
-		}
-
-		logger.Log.Messagef(common.LocalHost, "downloading %s %s %s ...", arch, binary.ID, binary.Version)
-
-		if util.IsExist(binary.Path()) {
-			// download it again if it's incorrect
-			if err := binary.SHA256Check(); err != nil {
-				_ = exec.Command("/bin/sh", "-c", fmt.Sprintf("rm -f %s", binary.Path())).Run()
-			} else {
-				continue
-			}
-		}
-
-		if err := binary.Download(); err != nil {
-			return fmt.Errorf("Failed to download %s binary: %s error: %w ", binary.ID, binary.GetCmd(), err)
-		}
+	if err := download(ctx, manifest.Arg.Download, binaries); err != nil {
+		return err
 	}
 
 	return nil
 }
 
 // CriDownloadHTTP defines the kubernetes' binaries that need to be downloaded in advance and downloads them.
-func CriDownloadHTTP(kubeConf *common.KubeConf, path, arch string, pipelineCache *cache.Cache) error {
+func CriDownloadHTTP(ctx context.Context, kubeConf *common.KubeConf, path, arch string, pipelineCache *cache.Cache) error {
 
 	binaries := []*files.KubeBinary{}
 	switch kubeConf.Arg.Type {
@@ -210,33 +172,29 @@ func CriDownloadHTTP(kubeConf *common.KubeConf, path, arch string, pipelineCache
 		runc := files.NewKubeBinary("runc", arch, kubekeyapiv1alpha2.DefaultRuncVersion, path, kubeConf.Arg.DownloadCommand)
 		crictl := files.NewKubeBinary("crictl", arch, kubekeyapiv1alpha2.DefaultCrictlVersion, path, kubeConf.Arg.DownloadCommand)
 		binaries = append(binaries, containerd, runc, crictl)
+	case common.Crio:
+		crio := files.NewKubeBinary("crio", arch, kubekeyapiv1alpha2.DefaultCrioVersion, path, kubeConf.Arg.DownloadCommand)
+		crictl := files.NewKubeBinary("crictl", arch, kubekeyapiv1alpha2.DefaultCrictlVersion, path, kubeConf.Arg.DownloadCommand)
+		binaries = append(binaries, crio, crictl)
 	default:
 	}
 	binariesMap := make(map[string]*files.KubeBinary)
 	for _, binary := range binaries {
-		if err := binary.CreateBaseDir(); err != nil {
-			return errors.Wrapf(errors.WithStack(err), "create file %s base dir failed", binary.FileName)
-		}
-
-		logger.Log.Messagef(common.LocalHost, "downloading %s %s %s ...", arch, binary.ID, binary.Version)
-
 		binariesMap[binary.ID] = binary
-		if util.IsExist(binary.Path()) {
-			// download it again if it's incorrect
-			if err := binary.SHA256Check(); err != nil {
-				p := binary.Path()
-				_ = exec.Command("/bin/sh", "-c", fmt.Sprintf("rm -f %s", p)).Run()
-			} else {
-				logger.Log.Messagef(common.LocalHost, "%s exists", binary.ID)
-				continue
-			}
-		}
-
-		if err := binary.Download(); err != nil {
-			return fmt.Errorf("Failed to download %s binary: %s error: %w ", binary.ID, binary.GetCmd(), err)
-		}
+	}
+	if err := download(ctx, kubeConf.Arg.Download, binaries); err != nil {
+		return err
 	}
 
 	pipelineCache.Set(common.KubeBinaries+"-"+arch, binariesMap)
 	return nil
 }
+
+// download downloads the binaries in parallel, by the download command if there is one or by the built-in downloader.
+func download(ctx context.Context, opts files.DownloadOptions, binaries []*files.KubeBinary) error {
+	downloader, err := files.NewDownloader(opts)
+	if err != nil {
+		return err
+	}
+	return downloader.DownloadAll(ctx, binaries)
+}
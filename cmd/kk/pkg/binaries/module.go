@@ -173,6 +173,8 @@ func (i *CriBinariesModule) Init() {
 		i.Tasks = CriBinaries(i)
 	case common.Containerd:
 		i.Tasks = CriBinaries(i)
+	case common.Crio:
+		i.Tasks = CriBinaries(i)
 	default:
 	}
 
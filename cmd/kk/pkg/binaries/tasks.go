@@ -55,7 +55,7 @@ func (d *Download) Execute(runtime connector.Runtime) error {
 	}
 
 	for arch := range archMap {
-		if err := K8sFilesDownloadHTTP(d.KubeConf, runtime.GetWorkDir(), kubeVersion, arch, d.PipelineCache); err != nil {
+		if err := K8sFilesDownloadHTTP(runtime.GetRunner().Context(), d.KubeConf, runtime.GetWorkDir(), kubeVersion, arch, d.PipelineCache); err != nil {
 			return err
 		}
 	}
@@ -89,7 +89,7 @@ func (k *K3sDownload) Execute(runtime connector.Runtime) error {
 	}
 
 	for arch := range archMap {
-		if err := K3sFilesDownloadHTTP(k.KubeConf, runtime.GetWorkDir(), kubeVersion, arch, k.PipelineCache); err != nil {
+		if err := K3sFilesDownloadHTTP(runtime.GetRunner().Context(), k.KubeConf, runtime.GetWorkDir(), kubeVersion, arch, k.PipelineCache); err != nil {
 			return err
 		}
 	}
@@ -123,7 +123,7 @@ func (k *K8eDownload) Execute(runtime connector.Runtime) error {
 	}
 
 	for arch := range archMap {
-		if err := K8eFilesDownloadHTTP(k.KubeConf, runtime.GetWorkDir(), kubeVersion, arch, k.PipelineCache); err != nil {
+		if err := K8eFilesDownloadHTTP(runtime.GetRunner().Context(), k.KubeConf, runtime.GetWorkDir(), kubeVersion, arch, k.PipelineCache); err != nil {
 			return err
 		}
 	}
@@ -163,16 +163,16 @@ func (a *ArtifactDownload) Execute(runtime connector.Runtime) error {
 	basePath := filepath.Join(runtime.GetWorkDir(), common.Artifact)
 	for arch := range archMap {
 		for _, version := range kubernetesVersions {
-			if err := KubernetesArtifactBinariesDownload(a.Manifest, basePath, arch, version); err != nil {
+			if err := KubernetesArtifactBinariesDownload(runtime.GetRunner().Context(), a.Manifest, basePath, arch, version); err != nil {
 				return err
 			}
 		}
 
-		if err := KubernetesComponentBinariesDownload(a.Manifest, basePath, arch); err != nil {
+		if err := KubernetesComponentBinariesDownload(runtime.GetRunner().Context(), a.Manifest, basePath, arch); err != nil {
 			return err
 		}
 
-		if err := RegistryBinariesDownload(a.Manifest, basePath, arch); err != nil {
+		if err := RegistryBinariesDownload(runtime.GetRunner().Context(), a.Manifest, basePath, arch); err != nil {
 			return err
 		}
 	}
@@ -212,12 +212,12 @@ func (a *K3sArtifactDownload) Execute(runtime connector.Runtime) error {
 	basePath := filepath.Join(runtime.GetWorkDir(), common.Artifact)
 	for arch := range archMap {
 		for _, version := range kubernetesVersions {
-			if err := K3sArtifactBinariesDownload(a.Manifest, basePath, arch, version); err != nil {
+			if err := K3sArtifactBinariesDownload(runtime.GetRunner().Context(), a.Manifest, basePath, arch, version); err != nil {
 				return err
 			}
 		}
 
-		if err := RegistryBinariesDownload(a.Manifest, basePath, arch); err != nil {
+		if err := RegistryBinariesDownload(runtime.GetRunner().Context(), a.Manifest, basePath, arch); err != nil {
 			return err
 		}
 	}
@@ -257,12 +257,12 @@ func (a *K8eArtifactDownload) Execute(runtime connector.Runtime) error {
 	basePath := filepath.Join(runtime.GetWorkDir(), common.Artifact)
 	for arch := range archMap {
 		for _, version := range kubernetesVersions {
-			if err := K8eArtifactBinariesDownload(a.Manifest, basePath, arch, version); err != nil {
+			if err := K8eArtifactBinariesDownload(runtime.GetRunner().Context(), a.Manifest, basePath, arch, version); err != nil {
 				return err
 			}
 		}
 
-		if err := RegistryBinariesDownload(a.Manifest, basePath, arch); err != nil {
+		if err := RegistryBinariesDownload(runtime.GetRunner().Context(), a.Manifest, basePath, arch); err != nil {
 			return err
 		}
 	}
@@ -275,7 +275,7 @@ type RegistryPackageDownload struct {
 
 func (k *RegistryPackageDownload) Execute(runtime connector.Runtime) error {
 	arch := runtime.GetHostsByRole(common.Registry)[0].GetArch()
-	if err := RegistryPackageDownloadHTTP(k.KubeConf, runtime.GetWorkDir(), arch, k.PipelineCache); err != nil {
+	if err := RegistryPackageDownloadHTTP(runtime.GetRunner().Context(), k.KubeConf, runtime.GetWorkDir(), arch, k.PipelineCache); err != nil {
 		return err
 	}
 
@@ -301,7 +301,7 @@ func (d *CriDownload) Execute(runtime connector.Runtime) error {
 	}
 
 	for arch := range archMap {
-		if err := CriDownloadHTTP(d.KubeConf, runtime.GetWorkDir(), arch, d.PipelineCache); err != nil {
+		if err := CriDownloadHTTP(runtime.GetRunner().Context(), d.KubeConf, runtime.GetWorkDir(), arch, d.PipelineCache); err != nil {
 			return err
 		}
 	}
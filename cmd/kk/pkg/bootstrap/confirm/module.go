@@ -31,6 +31,10 @@ func (i *InstallConfirmModule) IsSkip() bool {
 	return i.Skip
 }
 
+func (i *InstallConfirmModule) AlwaysRun() bool {
+	return true
+}
+
 func (i *InstallConfirmModule) Init() {
 	i.Name = "ConfirmModule"
 	i.Desc = "Display confirmation form"
@@ -157,3 +161,51 @@ func (d *MigrateCriConfirmModule) Init() {
 	}
 
 }
+
+type RestoreETCDConfirmModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (r *RestoreETCDConfirmModule) IsSkip() bool {
+	return r.Skip
+}
+
+func (r *RestoreETCDConfirmModule) Init() {
+	r.Name = "RestoreETCDConfirmModule"
+	r.Desc = "Display restore etcd confirmation form"
+
+	display := &task.LocalTask{
+		Name:   "ConfirmForm",
+		Desc:   "Display confirmation form",
+		Action: new(RestoreETCDConfirm),
+	}
+
+	r.Tasks = []task.Interface{
+		display,
+	}
+}
+
+type ReplaceETCDMemberConfirmModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (r *ReplaceETCDMemberConfirmModule) IsSkip() bool {
+	return r.Skip
+}
+
+func (r *ReplaceETCDMemberConfirmModule) Init() {
+	r.Name = "ReplaceETCDMemberConfirmModule"
+	r.Desc = "Display replace etcd member confirmation form"
+
+	display := &task.LocalTask{
+		Name:   "ConfirmForm",
+		Desc:   "Display confirmation form",
+		Action: &DeleteConfirm{Content: "etcd member"},
+	}
+
+	r.Tasks = []task.Interface{
+		display,
+	}
+}
@@ -411,3 +411,35 @@ func (d *MigrateCri) Execute(runtime connector.Runtime) error {
 
 	return nil
 }
+
+type RestoreETCDConfirm struct {
+	common.KubeAction
+}
+
+func (r *RestoreETCDConfirm) Execute(runtime connector.Runtime) error {
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Printf("The kube-apiserver and etcd will be stopped, and the etcd data on %d hosts will be replaced with the snapshot %s.\n",
+		len(runtime.GetHostsByRole(common.ETCD)), r.KubeConf.Arg.EtcdSnapshot)
+
+	confirmOK := false
+	for !confirmOK {
+		fmt.Printf("Are you sure to restore etcd? [yes/no]: ")
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		input = strings.ToLower(strings.TrimSpace(input))
+
+		switch strings.ToLower(input) {
+		case "yes", "y":
+			confirmOK = true
+		case "no", "n":
+			os.Exit(0)
+		default:
+			continue
+		}
+	}
+
+	return nil
+}
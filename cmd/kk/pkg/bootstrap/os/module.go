@@ -98,6 +98,11 @@ func (c *ConfigureOSModule) Init() {
 
 type ClearNodeOSModule struct {
 	common.KubeModule
+	Skip bool
+}
+
+func (c *ClearNodeOSModule) IsSkip() bool {
+	return c.Skip
 }
 
 func (c *ClearNodeOSModule) Init() {
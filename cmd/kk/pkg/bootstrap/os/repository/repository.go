@@ -36,7 +36,7 @@ func New(os string) (Interface, error) {
 	switch strings.ToLower(os) {
 	case "ubuntu", "debian":
 		return NewDeb(), nil
-	case "centos", "rhel":
+	case "centos", "rhel", "openeuler":
 		return NewRPM(), nil
 	default:
 		return nil, fmt.Errorf("unsupported operation system %s", os)
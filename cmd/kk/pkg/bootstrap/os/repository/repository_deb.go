@@ -90,6 +90,10 @@ func (d *Debian) Install(runtime connector.Runtime, pkg ...string) error {
 }
 
 func (d *Debian) Reset(runtime connector.Runtime) error {
+	if !d.backup {
+		return nil
+	}
+
 	if _, err := runtime.GetRunner().SudoCmd("rm -rf /etc/apt/sources.list.d", false); err != nil {
 		return err
 	}
@@ -101,6 +105,7 @@ func (d *Debian) Reset(runtime connector.Runtime) error {
 	if _, err := runtime.GetRunner().SudoCmd("mv /etc/apt/sources.list.d.kubekey.bak /etc/apt/sources.list.d", false); err != nil {
 		return err
 	}
+	d.backup = false
 
 	return nil
 }
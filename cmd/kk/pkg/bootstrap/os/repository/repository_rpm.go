@@ -98,6 +98,10 @@ func (r *RedhatPackageManager) Install(runtime connector.Runtime, pkg ...string)
 }
 
 func (r *RedhatPackageManager) Reset(runtime connector.Runtime) error {
+	if !r.backup {
+		return nil
+	}
+
 	if _, err := runtime.GetRunner().SudoCmd("rm -rf /etc/yum.repos.d", false); err != nil {
 		return err
 	}
@@ -105,6 +109,7 @@ func (r *RedhatPackageManager) Reset(runtime connector.Runtime) error {
 	if _, err := runtime.GetRunner().SudoCmd("mv /etc/yum.repos.d.kubekey.bak /etc/yum.repos.d", false); err != nil {
 		return err
 	}
+	r.backup = false
 
 	return nil
 }
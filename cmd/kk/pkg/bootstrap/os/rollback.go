@@ -28,17 +28,15 @@ import (
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/ending"
 )
 
+// The rollbacks of the repository tasks run for every task of the module in reverse, so they are idempotent: the ISO
+// is only unmounted while it is mounted, and the original repository is only restored while it is backed up.
+
 type RollbackUmount struct {
 	common.KubeRollback
 }
 
 func (r *RollbackUmount) Execute(runtime connector.Runtime, result *ending.ActionResult) error {
-	mountPath := filepath.Join(common.TmpDir, "iso")
-	umountCmd := fmt.Sprintf("umount %s", mountPath)
-	if _, err := runtime.GetRunner().SudoCmd(umountCmd, false); err != nil {
-		return errors.Wrapf(errors.WithStack(err), "umount %s failed", mountPath)
-	}
-	return nil
+	return umountISO(runtime)
 }
 
 type RecoverBackupSuccessNode struct {
@@ -47,24 +45,11 @@ type RecoverBackupSuccessNode struct {
 
 func (r *RecoverBackupSuccessNode) Execute(runtime connector.Runtime, result *ending.ActionResult) error {
 	if result.Status == ending.SUCCESS {
-		host := runtime.RemoteHost()
-		repo, ok := host.GetCache().Get("repo")
-		if !ok {
-			return errors.New("get repo failed by host cache")
-		}
-
-		re := repo.(repository.Interface)
-		if err := re.Reset(runtime); err != nil {
-			return errors.Wrapf(errors.WithStack(err), "reset repository failed")
+		if err := resetRepository(runtime); err != nil {
+			return err
 		}
 	}
-
-	mountPath := filepath.Join(common.TmpDir, "iso")
-	umountCmd := fmt.Sprintf("umount %s", mountPath)
-	if _, err := runtime.GetRunner().SudoCmd(umountCmd, false); err != nil {
-		return errors.Wrapf(errors.WithStack(err), "umount %s failed", mountPath)
-	}
-	return nil
+	return umountISO(runtime)
 }
 
 type RecoverRepository struct {
@@ -72,17 +57,30 @@ type RecoverRepository struct {
 }
 
 func (r *RecoverRepository) Execute(runtime connector.Runtime, result *ending.ActionResult) error {
-	host := runtime.RemoteHost()
-	repo, ok := host.GetCache().Get("repo")
+	_ = resetRepository(runtime)
+	return umountISO(runtime)
+}
+
+// resetRepository restores the original repository if it has been backed up and not restored yet.
+func resetRepository(runtime connector.Runtime) error {
+	repo, ok := runtime.RemoteHost().GetCache().Get("repo")
 	if !ok {
-		return errors.New("get repo failed by host cache")
+		return nil
 	}
 
 	re := repo.(repository.Interface)
-	_ = re.Reset(runtime)
+	if !re.IsAlreadyBackUp() {
+		return nil
+	}
+	if err := re.Reset(runtime); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "reset repository failed")
+	}
+	return nil
+}
 
+func umountISO(runtime connector.Runtime) error {
 	mountPath := filepath.Join(common.TmpDir, "iso")
-	umountCmd := fmt.Sprintf("umount %s", mountPath)
+	umountCmd := fmt.Sprintf("if mountpoint -q %s; then umount %s; fi", mountPath, mountPath)
 	if _, err := runtime.GetRunner().SudoCmd(umountCmd, false); err != nil {
 		return errors.Wrapf(errors.WithStack(err), "umount %s failed", mountPath)
 	}
@@ -162,6 +162,9 @@ var (
 		"/var/lib/rook",
 		"/tmp/kubekey",
 		"/etc/kubekey",
+		// the state of the pods and the containers kept by cri-o
+		"/var/lib/crio",
+		"/var/run/crio",
 	}
 
 	networkResetCmds = []string{
@@ -434,6 +437,10 @@ func (i *InstallPackage) Execute(runtime connector.Runtime) error {
 		pkg = i.KubeConf.Cluster.System.Debs
 	} else if _, ok := r.(*repository.RedhatPackageManager); ok {
 		pkg = i.KubeConf.Cluster.System.Rpms
+		// iSulad is shipped as a package of openEuler rather than a binary of the artifact
+		if i.KubeConf.Cluster.Kubernetes.ContainerManager == common.Isula {
+			pkg = append(append([]string{}, pkg...), "iSulad")
+		}
 	}
 
 	if installErr := r.Update(runtime); installErr != nil {
@@ -257,7 +257,10 @@ func GenerateHosts(runtime connector.ModuleRuntime, kubeConf *common.KubeConf) [
 	}
 
 	if len(runtime.GetHostsByRole(common.Registry)) > 0 {
-		if kubeConf.Cluster.Registry.PrivateRegistry != "" {
+		// the registry domain is resolved to the VIP or the load balancer in front of the registry hosts if any
+		if address := kubeConf.Cluster.Registry.Harbor.Address; address != "" && kubeConf.Cluster.Registry.PrivateRegistry != "" {
+			hostsList = append(hostsList, fmt.Sprintf("%s  %s", address, kubeConf.Cluster.Registry.GetHost()))
+		} else if kubeConf.Cluster.Registry.PrivateRegistry != "" {
 			hostsList = append(hostsList, fmt.Sprintf("%s  %s", runtime.GetHostsByRole(common.Registry)[0].GetInternalIPv4Address(), kubeConf.Cluster.Registry.GetHost()))
 			if runtime.GetHostsByRole(common.Registry)[0].GetInternalIPv6Address() != "" {
 				hostsList = append(hostsList, fmt.Sprintf("%s  %s", runtime.GetHostsByRole(common.Registry)[0].GetInternalIPv6Address(), kubeConf.Cluster.Registry.GetHost()))
@@ -29,6 +29,10 @@ type GreetingsModule struct {
 	module.BaseTaskModule
 }
 
+func (h *GreetingsModule) AlwaysRun() bool {
+	return true
+}
+
 func (h *GreetingsModule) Init() {
 	h.Name = "GreetingsModule"
 	h.Desc = "Greetings"
@@ -30,6 +30,7 @@ import (
 
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/utils/certs"
 )
 
@@ -112,8 +113,12 @@ func (g *GenerateCerts) Execute(runtime connector.Runtime) error {
 		dnsList = append(dnsList, h.GetName())
 		ipList = append(ipList, netutils.ParseIPSloppy(h.GetInternalIPv4Address()))
 	}
+	if address := g.KubeConf.Cluster.Registry.Harbor.Address; address != "" {
+		ipList = append(ipList, netutils.ParseIPSloppy(address))
+	}
 	altName.DNSNames = dnsList
 	altName.IPs = ipList
+	certs.AppendSANs(&altName, g.KubeConf.Cluster.Certificates.Registry.ExtraSANs)
 
 	files := []string{"ca.pem", "ca-key.pem", fmt.Sprintf("%s.pem", g.KubeConf.Cluster.Registry.GetHost()), fmt.Sprintf("%s-key.pem", g.KubeConf.Cluster.Registry.GetHost())}
 
@@ -123,6 +128,10 @@ func (g *GenerateCerts) Execute(runtime connector.Runtime) error {
 	// Certs
 	certsList = append(certsList, KubekeyCertRegistryServer(g.KubeConf.Cluster.Registry.GetHost(), &altName))
 
+	if err := certs.UseExternalCerts(&g.KubeConf.Cluster.Certificates.Registry, pkiPath, "ca", files); err != nil {
+		return errors.Wrap(err, "failed to use the external registry certs")
+	}
+
 	var lastCACert *certs.KubekeyCert
 	for _, c := range certsList {
 		if c.CAName == "" {
@@ -139,6 +148,11 @@ func (g *GenerateCerts) Execute(runtime connector.Runtime) error {
 		}
 	}
 
+	// the key of an external CA may be kept by the user
+	if !util.IsExist(filepath.Join(pkiPath, "ca-key.pem")) {
+		files = append(files[:1], files[2:]...)
+	}
+
 	g.ModuleCache.Set(LocalCertsDir, pkiPath)
 	g.ModuleCache.Set(CertsFileList, files)
 
@@ -0,0 +1,636 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/registry/templates"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/action"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/registry"
+)
+
+const (
+	harborDataVolume = "/mnt/registry"
+	keepalivedDir    = "/etc/keepalived"
+	checkHarborPath  = "/etc/keepalived/check_harbor.sh"
+
+	harborReadyRetries = 30
+	harborPageSize     = 100
+
+	// harborAPIConfig and harborAPIData on the registry host are the curl config with the credentials of the admin
+	// and the body of the request to the Harbor API.
+	harborAPIConfig = "/etc/kubekey/harbor/api.conf"
+	harborAPIData   = "/etc/kubekey/harbor/api-data.json"
+
+	// harborRobotName is the name of the robot account generated when the auths have no entry of the registry.
+	harborRobotName = "kubekey"
+)
+
+var harborReadyDelay = 10 * time.Second
+
+// isHarborHA reports whether Harbor runs on more than one registry host.
+func isHarborHA(runtime connector.ModuleRuntime) bool {
+	return len(runtime.GetHostsByRole(common.Registry)) > 1
+}
+
+// validateHarbor checks the Harbor config. In HA mode all the instances must share the storage, the database and
+// the redis, and be reached by an address in front of them.
+func validateHarbor(cfg *kubekeyapiv1alpha2.RegistryConfig, hosts int) error {
+	if cfg.Type == "harbor-ha" {
+		return errors.Errorf("registry.type harbor-ha is not supported anymore, set it to %s and put all the Harbor hosts "+
+			"in the registry role group, the HA mode is set up by registry.harbor, see docs/registry.md", common.Harbor)
+	}
+	harbor := cfg.Harbor
+	if harbor.InternalLoadbalancer != "" && !cfg.IsHarborKeepalivedEnabled() {
+		return errors.Errorf("registry.harbor.internalLoadbalancer %s is not supported, only %s is supported",
+			harbor.InternalLoadbalancer, kubekeyapiv1alpha2.Keepalived)
+	}
+	if cfg.IsHarborKeepalivedEnabled() && harbor.Address == "" {
+		return errors.New("registry.harbor.address must be set as the VIP to use keepalived")
+	}
+	if harbor.Address != "" && net.ParseIP(harbor.Address) == nil {
+		return errors.Errorf("registry.harbor.address %s is not an IP address", harbor.Address)
+	}
+	if harbor.S3 != nil && (harbor.S3.Region == "" || harbor.S3.Bucket == "") {
+		return errors.New("registry.harbor.s3.region and registry.harbor.s3.bucket are required to store the images in S3")
+	}
+	if db := harbor.ExternalDatabase; db != nil && (db.Host == "" || db.Username == "") {
+		return errors.New("registry.harbor.externalDatabase.host and registry.harbor.externalDatabase.username are required")
+	}
+	if harbor.ExternalRedis != nil && harbor.ExternalRedis.Host == "" {
+		return errors.New("registry.harbor.externalRedis.host is required")
+	}
+	if name, secret, ok := harborRobot(cfg); ok {
+		if name == "" {
+			return errors.Errorf("the name of the robot account %s%s is empty", templates.RobotPrefix, name)
+		}
+		if !isValidRobotSecret(secret) {
+			return errors.Errorf("the password of the robot account %s%s must be 8 to 128 characters long with at least "+
+				"one uppercase character, one lowercase character and one number", templates.RobotPrefix, name)
+		}
+	}
+
+	if hosts < 2 {
+		return nil
+	}
+	if harbor.Address == "" {
+		return errors.Errorf("Harbor runs on %d registry hosts, registry.harbor.address is required as the VIP or the "+
+			"address of the load balancer in front of them", hosts)
+	}
+	if harbor.S3 == nil && harbor.DataVolume == "" {
+		return errors.Errorf("Harbor runs on %d registry hosts, the images must be stored in a shared "+
+			"registry.harbor.dataVolume or in registry.harbor.s3", hosts)
+	}
+	if harbor.ExternalDatabase == nil || harbor.ExternalRedis == nil {
+		return errors.Errorf("Harbor runs on %d registry hosts, registry.harbor.externalDatabase and "+
+			"registry.harbor.externalRedis are required", hosts)
+	}
+	return nil
+}
+
+// isValidRobotSecret follows the rules of Harbor on the secrets of the robot accounts.
+func isValidRobotSecret(secret string) bool {
+	if len(secret) < 8 || len(secret) > 128 {
+		return false
+	}
+	var upper, lower, number bool
+	for _, c := range secret {
+		switch {
+		case unicode.IsUpper(c):
+			upper = true
+		case unicode.IsLower(c):
+			lower = true
+		case unicode.IsNumber(c):
+			number = true
+		}
+	}
+	return upper && lower && number
+}
+
+// generateRobotSecret returns a random secret following the rules of Harbor.
+func generateRobotSecret() (string, error) {
+	const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	buf := make([]byte, 32)
+	for {
+		if _, err := rand.Read(buf); err != nil {
+			return "", errors.Wrap(err, "generate the secret of the robot account failed")
+		}
+		for i := range buf {
+			buf[i] = letters[int(buf[i])%len(letters)]
+		}
+		if secret := string(buf); isValidRobotSecret(secret) {
+			return secret, nil
+		}
+	}
+}
+
+// harborRobot returns the robot account in the auths of the registry, it is created once Harbor is started so that
+// the cluster pushes and pulls the images with it.
+func harborRobot(cfg *kubekeyapiv1alpha2.RegistryConfig) (name, secret string, ok bool) {
+	domain := cfg.GetHost()
+	if domain == "" {
+		return "", "", false
+	}
+	for repo, entry := range registry.DockerRegistryAuthEntries(cfg.Auths) {
+		if strings.Contains(repo, domain) && strings.HasPrefix(entry.Username, templates.RobotPrefix) {
+			return strings.TrimPrefix(entry.Username, templates.RobotPrefix), entry.Password, true
+		}
+	}
+	return "", "", false
+}
+
+// hasRegistryAuths reports whether the auths have an entry of the registry.
+func hasRegistryAuths(cfg *kubekeyapiv1alpha2.RegistryConfig) bool {
+	domain := cfg.GetHost()
+	for repo := range registry.DockerRegistryAuthEntries(cfg.Auths) {
+		if domain != "" && strings.Contains(repo, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// harborProjects returns the projects the images are pushed into, i.e. the path of the private registry or the
+// namespaceOverride, and the ones in the Harbor config.
+func harborProjects(cfg *kubekeyapiv1alpha2.RegistryConfig) []string {
+	var projects []string
+	if parts := strings.SplitN(cfg.PrivateRegistry, "/", 3); len(parts) > 1 && parts[1] != "" {
+		projects = append(projects, parts[1])
+	} else if cfg.NamespaceOverride != "" {
+		projects = append(projects, cfg.NamespaceOverride)
+	}
+	projects = append(projects, cfg.Harbor.Projects...)
+
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(projects))
+	for _, p := range projects {
+		if !seen[p] {
+			seen[p] = true
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// harborConfigData is the data of harbor.yml, with the defaults of the Harbor config.
+func harborConfigData(kubeConf *common.KubeConf) util.Data {
+	harbor := kubeConf.Cluster.Registry.Harbor
+	if harbor.DataVolume == "" {
+		harbor.DataVolume = harborDataVolume
+	}
+	if harbor.ExternalDatabase != nil {
+		db := *harbor.ExternalDatabase
+		if db.Port == 0 {
+			db.Port = 5432
+		}
+		if db.DBName == "" {
+			db.DBName = "registry"
+		}
+		if db.SSLMode == "" {
+			db.SSLMode = "disable"
+		}
+		harbor.ExternalDatabase = &db
+	}
+
+	host := kubeConf.Cluster.Registry.GetHost()
+	return util.Data{
+		"Domain":      host,
+		"Certificate": fmt.Sprintf("%s.pem", host),
+		"Key":         fmt.Sprintf("%s-key.pem", host),
+		"Password":    templates.Password(kubeConf, host),
+		"DataVolume":  harbor.DataVolume,
+		"Harbor":      harbor,
+	}
+}
+
+type CheckHarborConfig struct {
+	common.KubeAction
+}
+
+func (c *CheckHarborConfig) Execute(runtime connector.Runtime) error {
+	hosts := runtime.GetHostsByRole(common.Registry)
+	if err := validateHarbor(&c.KubeConf.Cluster.Registry, len(hosts)); err != nil {
+		return err
+	}
+	if !c.KubeConf.Cluster.Registry.IsHarborKeepalivedEnabled() {
+		return nil
+	}
+	// keepalived.conf of the loadbalancer nodes would be overwritten
+	for _, host := range hosts {
+		if host.IsRole(common.Loadbalancer) {
+			return errors.Errorf("the registry host %s is a loadbalancer node, keepalived can not hold the VIP of Harbor on it", host.GetName())
+		}
+	}
+	return nil
+}
+
+type InstallKeepalived struct {
+	common.KubeAction
+}
+
+func (i *InstallKeepalived) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd("command -v keepalived", false); err == nil {
+		return nil
+	}
+
+	cmd := "if command -v apt-get > /dev/null; then apt-get update && DEBIAN_FRONTEND=noninteractive apt-get install -y keepalived curl; " +
+		"elif command -v dnf > /dev/null; then dnf install -y keepalived curl; " +
+		"elif command -v yum > /dev/null; then yum install -y keepalived curl; " +
+		"else echo 'neither apt-get, dnf nor yum is found' && exit 1; fi"
+	if _, err := runtime.GetRunner().SudoCmd(cmd, true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "install keepalived failed, please install it manually")
+	}
+	return nil
+}
+
+type GetInterfaceName struct {
+	common.KubeAction
+}
+
+func (g *GetInterfaceName) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	cmd := fmt.Sprintf("ip route "+
+		"| grep ' %s ' "+
+		"| grep 'proto kernel scope link src'"+
+		"| sed -e \"s/^.*dev.//\" -e \"s/.proto.*//\""+
+		"| uniq ", host.GetInternalIPv4Address())
+	interfaceName, err := runtime.GetRunner().SudoCmd(cmd, false)
+	if err != nil {
+		return err
+	}
+	if interfaceName == "" {
+		return errors.New("get interface failed")
+	}
+	// type: string
+	host.GetCache().Set("interface", interfaceName)
+	return nil
+}
+
+type GenerateKeepalivedConfig struct {
+	common.KubeAction
+}
+
+func (g *GenerateKeepalivedConfig) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	keepalived := g.KubeConf.Cluster.Registry.Harbor.Keepalived
+	interfaceName := keepalived.Interface
+	if interfaceName == "" {
+		name, ok := host.GetCache().GetMustString("interface")
+		if !ok {
+			return errors.New("get interface failed")
+		}
+		interfaceName = name
+	}
+
+	// the first registry host has the highest priority and holds the VIP as long as Harbor on it is healthy
+	priority := 100
+	var peers []string
+	for i, h := range runtime.GetHostsByRole(common.Registry) {
+		if h.GetName() == host.GetName() {
+			priority = 100 - i
+			continue
+		}
+		peers = append(peers, h.GetInternalIPv4Address())
+	}
+
+	authPass := keepalived.AuthPass
+	if len(authPass) > 8 {
+		authPass = authPass[:8]
+	}
+
+	templateAction := action.Template{
+		Template: templates.HarborKeepalivedConfig,
+		Dst:      filepath.Join(keepalivedDir, templates.HarborKeepalivedConfig.Name()),
+		Data: util.Data{
+			"RouterID":        host.GetName(),
+			"CheckScript":     checkHarborPath,
+			"Interface":       interfaceName,
+			"VirtualRouterID": keepalived.VirtualRouterID,
+			"Priority":        priority,
+			"AuthPass":        authPass,
+			"SourceIP":        host.GetInternalIPv4Address(),
+			"Peers":           peers,
+			"VIP":             g.KubeConf.Cluster.Registry.Harbor.Address,
+		},
+	}
+	templateAction.Init(nil, nil)
+	if err := templateAction.Execute(runtime); err != nil {
+		return err
+	}
+
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chmod +x %s", checkHarborPath), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "chmod the harbor check script failed")
+	}
+	return nil
+}
+
+type EnableKeepalived struct {
+	common.KubeAction
+}
+
+func (e *EnableKeepalived) Execute(runtime connector.Runtime) error {
+	cmd := "systemctl daemon-reload && systemctl enable keepalived && systemctl reload-or-restart keepalived"
+	if _, err := runtime.GetRunner().SudoCmd(cmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "start keepalived failed")
+	}
+	return nil
+}
+
+// BootstrapHarbor creates the projects the images are pushed into, and the robot account in the auths of the
+// registry, so that the cluster created with the same config pushes and pulls the images without any manual step.
+// If the auths have no entry of the registry, the robot account is generated and its entry is saved in the work dir,
+// where the later commands merge it into the auths.
+type BootstrapHarbor struct {
+	common.KubeAction
+}
+
+func (b *BootstrapHarbor) Execute(runtime connector.Runtime) error {
+	cfg := &b.KubeConf.Cluster.Registry
+	api, err := newHarborAPI(runtime, templates.Password(b.KubeConf, cfg.GetHost()))
+	if err != nil {
+		return err
+	}
+	defer api.close()
+
+	if err := api.waitReady(); err != nil {
+		return err
+	}
+
+	projects := harborProjects(cfg)
+	for _, project := range projects {
+		code, body, err := api.call("POST", "/projects", map[string]interface{}{
+			"project_name": project,
+			"metadata":     map[string]string{"public": "true"},
+		})
+		if err != nil {
+			return err
+		}
+		switch code {
+		case 201:
+			logger.Log.Infof("Harbor project %s is created", project)
+		case 409:
+			logger.Log.Infof("Harbor project %s already exists", project)
+		default:
+			return errors.Errorf("create Harbor project %s failed: %d %s", project, code, body)
+		}
+	}
+
+	name, secret, ok := harborRobot(cfg)
+	generated := false
+	if !ok {
+		if hasRegistryAuths(cfg) {
+			return nil
+		}
+		generatedSecret, err := generateRobotSecret()
+		if err != nil {
+			return err
+		}
+		name, secret, generated = harborRobotName, generatedSecret, true
+	}
+	id, err := api.robotID(name)
+	if err != nil {
+		return err
+	}
+	if id == 0 {
+		if id, err = api.createRobot(name); err != nil {
+			return err
+		}
+	}
+	// the secret of the auths is set, since the one generated by Harbor is only returned once
+	code, body, err := api.call("PATCH", fmt.Sprintf("/robots/%d", id), map[string]string{"secret": secret})
+	if err != nil {
+		return err
+	}
+	if code != 200 {
+		return errors.Errorf("set the secret of the Harbor robot account %s%s failed: %d %s", templates.RobotPrefix, name, code, body)
+	}
+
+	fmt.Println()
+	fmt.Printf("Harbor robot account %s%s can push and pull the images of %s in projects %s.\n",
+		templates.RobotPrefix, name, cfg.GetHost(), strings.Join(projects, ", "))
+	if generated {
+		if err := registry.SaveRobotAuths(runtime.GetWorkDir(), cfg.GetHost(), templates.RobotPrefix+name, secret); err != nil {
+			return err
+		}
+		entry, err := robotAuthsEntry(cfg.GetHost(), templates.RobotPrefix+name, secret)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Its auths entry is saved in %s and used by the commands run from the same work dir, "+
+			"add it to registry.auths of the configuration file to use it elsewhere:\n\n%s",
+			filepath.Join(runtime.GetWorkDir(), registry.RobotAuthsFile), entry)
+	}
+	fmt.Println()
+	return nil
+}
+
+// robotAuthsEntry returns the auths entry of the robot account in the YAML of the configuration file.
+func robotAuthsEntry(domain, username, password string) (string, error) {
+	out, err := yaml.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			domain: map[string]string{"username": username, "password": password},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// harborAPI calls the Harbor API on the registry host as the admin. The credentials and the bodies of the requests
+// are passed to curl in the files readable by root only, so they are not seen in the command lines of the host.
+type harborAPI struct {
+	runtime connector.Runtime
+}
+
+// newHarborAPI writes the credentials of the admin into the curl config file on the registry host, the file is
+// removed by close.
+func newHarborAPI(runtime connector.Runtime, password string) (*harborAPI, error) {
+	h := &harborAPI{runtime: runtime}
+	if err := h.writeSecretFile(harborAPIConfig, []byte(harborCurlConfig("admin", password))); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *harborAPI) close() {
+	if _, err := h.runtime.GetRunner().SudoCmd(fmt.Sprintf("rm -f %s %s", harborAPIConfig, harborAPIData), false); err != nil {
+		logger.Log.Warnf("remove the Harbor API credentials on %s failed: %v", h.runtime.RemoteHost().GetName(), err)
+	}
+}
+
+// writeSecretFile writes the content into the file readable by root only on the registry host.
+func (h *harborAPI) writeSecretFile(dst string, content []byte) error {
+	local := filepath.Join(h.runtime.GetHostWorkDir(), filepath.Base(dst))
+	if err := os.WriteFile(local, content, 0600); err != nil {
+		return errors.Wrapf(err, "write file %s failed", local)
+	}
+	defer os.Remove(local)
+	if err := h.runtime.GetRunner().SudoScp(local, dst); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "sync %s failed", dst)
+	}
+	if _, err := h.runtime.GetRunner().SudoCmd(fmt.Sprintf("chmod 600 %s", dst), false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "chmod %s failed", dst)
+	}
+	return nil
+}
+
+// call returns the HTTP status code and the body of the response.
+func (h *harborAPI) call(method, path string, body interface{}) (int, string, error) {
+	cmd := fmt.Sprintf("curl -sk -K %s -X %s -H 'Content-Type: application/json' -w '\\n%%{http_code}' %s",
+		harborAPIConfig, method, shellQuote("https://127.0.0.1/api/v2.0"+path))
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, "", err
+		}
+		if err := h.writeSecretFile(harborAPIData, data); err != nil {
+			return 0, "", err
+		}
+		defer func() {
+			_, _ = h.runtime.GetRunner().SudoCmd(fmt.Sprintf("rm -f %s", harborAPIData), false)
+		}()
+		cmd = fmt.Sprintf("%s --data-binary @%s", cmd, harborAPIData)
+	}
+
+	out, err := h.runtime.GetRunner().SudoCmd(cmd, false)
+	if err != nil {
+		return 0, "", errors.Wrapf(errors.WithStack(err), "call the Harbor API %s %s failed", method, path)
+	}
+	return parseHarborResponse(out)
+}
+
+// waitReady waits for the Harbor API to answer the ping, it gives up once the task is timeout or interrupted.
+func (h *harborAPI) waitReady() error {
+	var (
+		code int
+		err  error
+	)
+	ctx := h.runtime.GetRunner().Context()
+	if wait.PollImmediateWithContext(ctx, harborReadyDelay, harborReadyRetries*harborReadyDelay, func(context.Context) (bool, error) {
+		code, _, err = h.call("GET", "/ping", nil)
+		return err == nil && code == 200, nil
+	}) == nil {
+		return nil
+	}
+	if err == nil {
+		err = errors.Errorf("the Harbor API answers %d", code)
+	}
+	return errors.Wrap(err, "Harbor is not ready")
+}
+
+// robotID returns the id of the robot account, or 0 if it does not exist. The pages of the robot accounts are
+// listed until it is found.
+func (h *harborAPI) robotID(name string) (int64, error) {
+	for page := 1; ; page++ {
+		code, body, err := h.call("GET", fmt.Sprintf("/robots?page=%d&page_size=%d", page, harborPageSize), nil)
+		if err != nil {
+			return 0, err
+		}
+		if code != 200 {
+			return 0, errors.Errorf("list the Harbor robot accounts failed: %d %s", code, body)
+		}
+		var robots []struct {
+			ID   int64  `json:"id"`
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(body), &robots); err != nil {
+			return 0, errors.Wrap(err, "parse the Harbor robot accounts failed")
+		}
+		for _, r := range robots {
+			if r.Name == templates.RobotPrefix+name {
+				return r.ID, nil
+			}
+		}
+		if len(robots) < harborPageSize {
+			return 0, nil
+		}
+	}
+}
+
+func (h *harborAPI) createRobot(name string) (int64, error) {
+	access := []map[string]string{
+		{"resource": "repository", "action": "pull"},
+		{"resource": "repository", "action": "push"},
+	}
+	code, body, err := h.call("POST", "/robots", map[string]interface{}{
+		"name":        name,
+		"description": "Created by KubeKey to push and pull the images of the cluster",
+		"level":       "system",
+		"duration":    -1,
+		"permissions": []map[string]interface{}{
+			{"kind": "project", "namespace": "*", "access": access},
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if code != 201 {
+		return 0, errors.Errorf("create the Harbor robot account %s%s failed: %d %s", templates.RobotPrefix, name, code, body)
+	}
+	var robot struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(body), &robot); err != nil {
+		return 0, errors.Wrapf(err, "parse the Harbor robot account %s%s failed", templates.RobotPrefix, name)
+	}
+	return robot.ID, nil
+}
+
+// parseHarborResponse splits the output of curl into the body and the HTTP status code written in the last line.
+func parseHarborResponse(out string) (int, string, error) {
+	out = strings.TrimSpace(strings.ReplaceAll(out, "\r\n", "\n"))
+	body, last := "", out
+	if i := strings.LastIndex(out, "\n"); i >= 0 {
+		body, last = strings.TrimSpace(out[:i]), out[i+1:]
+	}
+	code, err := strconv.Atoi(strings.TrimSpace(last))
+	if err != nil {
+		return 0, "", errors.Errorf("unexpected response of the Harbor API: %s", out)
+	}
+	return code, body, nil
+}
+
+// harborCurlConfig returns the curl config of the credentials, whose quoted value escapes the backslashes and quotes.
+func harborCurlConfig(user, password string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return fmt.Sprintf("user = \"%s\"\n", r.Replace(user+":"+password))
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
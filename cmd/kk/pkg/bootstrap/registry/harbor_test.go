@@ -0,0 +1,303 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package registry
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+)
+
+func haHarbor() kubekeyapiv1alpha2.HarborCfg {
+	return kubekeyapiv1alpha2.HarborCfg{
+		Address:              "172.16.0.100",
+		InternalLoadbalancer: kubekeyapiv1alpha2.Keepalived,
+		DataVolume:           "/mnt/nfs/harbor",
+		ExternalDatabase:     &kubekeyapiv1alpha2.HarborDatabaseCfg{Host: "172.16.0.10", Username: "harbor", Password: "pg-secret"},
+		ExternalRedis:        &kubekeyapiv1alpha2.HarborRedisCfg{Host: "172.16.0.11:6379"},
+	}
+}
+
+func TestValidateHarbor(t *testing.T) {
+	tests := []struct {
+		name         string
+		hosts        int
+		registryType string
+		harbor       func(*kubekeyapiv1alpha2.HarborCfg)
+		auths        string
+		wantErr      string
+	}{
+		{name: "single node", hosts: 1, harbor: func(h *kubekeyapiv1alpha2.HarborCfg) { *h = kubekeyapiv1alpha2.HarborCfg{} }},
+		{name: "ha", hosts: 2},
+		{
+			name:  "ha with s3 and an external load balancer",
+			hosts: 3,
+			harbor: func(h *kubekeyapiv1alpha2.HarborCfg) {
+				h.InternalLoadbalancer, h.DataVolume = "", ""
+				h.S3 = &kubekeyapiv1alpha2.HarborS3{Region: "us-east-1", Bucket: "harbor"}
+			},
+		},
+		{
+			name:    "ha without address",
+			hosts:   2,
+			harbor:  func(h *kubekeyapiv1alpha2.HarborCfg) { h.Address, h.InternalLoadbalancer = "", "" },
+			wantErr: "registry.harbor.address is required",
+		},
+		{
+			name:    "ha without shared storage",
+			hosts:   2,
+			harbor:  func(h *kubekeyapiv1alpha2.HarborCfg) { h.DataVolume = "" },
+			wantErr: "shared registry.harbor.dataVolume",
+		},
+		{
+			name:    "ha without external redis",
+			hosts:   2,
+			harbor:  func(h *kubekeyapiv1alpha2.HarborCfg) { h.ExternalRedis = nil },
+			wantErr: "registry.harbor.externalRedis are required",
+		},
+		{
+			name:    "keepalived without vip",
+			hosts:   1,
+			harbor:  func(h *kubekeyapiv1alpha2.HarborCfg) { h.Address = "" },
+			wantErr: "must be set as the VIP",
+		},
+		{
+			name:    "unsupported load balancer",
+			hosts:   2,
+			harbor:  func(h *kubekeyapiv1alpha2.HarborCfg) { h.InternalLoadbalancer = "haproxy" },
+			wantErr: "haproxy is not supported",
+		},
+		{
+			name:    "address is not an ip",
+			hosts:   2,
+			harbor:  func(h *kubekeyapiv1alpha2.HarborCfg) { h.Address = "lb.example.com" },
+			wantErr: "is not an IP address",
+		},
+		{
+			name:    "s3 without bucket",
+			hosts:   2,
+			harbor:  func(h *kubekeyapiv1alpha2.HarborCfg) { h.S3 = &kubekeyapiv1alpha2.HarborS3{Region: "us-east-1"} },
+			wantErr: "registry.harbor.s3.bucket are required",
+		},
+		{
+			name:  "robot account",
+			hosts: 2,
+			auths: `{"dockerhub.kubekey.local": {"username": "robot$kubekey", "password": "Kubekey123"}}`,
+		},
+		{
+			name:         "harbor-ha type",
+			hosts:        2,
+			registryType: "harbor-ha",
+			wantErr:      "registry.type harbor-ha is not supported anymore",
+		},
+		{
+			name:    "weak robot secret",
+			hosts:   2,
+			auths:   `{"dockerhub.kubekey.local": {"username": "robot$kubekey", "password": "kubekey"}}`,
+			wantErr: "robot$kubekey must be 8 to 128 characters long",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &kubekeyapiv1alpha2.RegistryConfig{PrivateRegistry: "dockerhub.kubekey.local", Harbor: haHarbor()}
+			cfg.Type = tt.registryType
+			if tt.harbor != nil {
+				tt.harbor(&cfg.Harbor)
+			}
+			if tt.auths != "" {
+				cfg.Auths = runtime.RawExtension{Raw: []byte(tt.auths)}
+			}
+
+			err := validateHarbor(cfg, tt.hosts)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("validateHarbor() = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("validateHarbor() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateRobotSecret(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		secret, err := generateRobotSecret()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !isValidRobotSecret(secret) || seen[secret] {
+			t.Errorf("generateRobotSecret() = %s", secret)
+		}
+		seen[secret] = true
+	}
+}
+
+func TestHarborProjects(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry kubekeyapiv1alpha2.RegistryConfig
+		want     []string
+	}{
+		{name: "none", registry: kubekeyapiv1alpha2.RegistryConfig{PrivateRegistry: "dockerhub.kubekey.local"}, want: []string{}},
+		{
+			name: "namespace override",
+			registry: kubekeyapiv1alpha2.RegistryConfig{PrivateRegistry: "dockerhub.kubekey.local", NamespaceOverride: "kubesphereio",
+				Harbor: kubekeyapiv1alpha2.HarborCfg{Projects: []string{"kubesphere", "kubesphereio"}}},
+			want: []string{"kubesphereio", "kubesphere"},
+		},
+		{
+			name:     "path of the private registry",
+			registry: kubekeyapiv1alpha2.RegistryConfig{PrivateRegistry: "dockerhub.kubekey.local/kse", NamespaceOverride: "kubesphereio"},
+			want:     []string{"kse"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := harborProjects(&tt.registry); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("harborProjects() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHarborConfigTemplate(t *testing.T) {
+	harborContent, err := f.ReadFile("templates/harbor.yml.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := template.Must(template.New("harbor.yml").Parse(string(harborContent)))
+
+	render := func(registry kubekeyapiv1alpha2.RegistryConfig) map[string]interface{} {
+		kubeConf := &common.KubeConf{Cluster: &kubekeyapiv1alpha2.ClusterSpec{Registry: registry}}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, harborConfigData(kubeConf)); err != nil {
+			t.Fatal(err)
+		}
+		config := make(map[string]interface{})
+		if err := yaml.Unmarshal(buf.Bytes(), &config); err != nil {
+			t.Fatalf("invalid harbor.yml: %v\n%s", err, buf.String())
+		}
+		return config
+	}
+
+	config := render(kubekeyapiv1alpha2.RegistryConfig{PrivateRegistry: "dockerhub.kubekey.local"})
+	if config["hostname"] != "dockerhub.kubekey.local" || config["harbor_admin_password"] != "Harbor12345" ||
+		config["data_volume"] != "/mnt/registry" {
+		t.Errorf("unexpected single node harbor.yml: %v", config)
+	}
+	for _, key := range []string{"storage_service", "external_database", "external_redis"} {
+		if _, ok := config[key]; ok {
+			t.Errorf("single node harbor.yml has %s", key)
+		}
+	}
+
+	harbor := haHarbor()
+	harbor.AdminPassword = `pa"ss'word`
+	harbor.S3 = &kubekeyapiv1alpha2.HarborS3{Region: "us-east-1", Bucket: "harbor", RegionEndpoint: "http://minio:9000"}
+	config = render(kubekeyapiv1alpha2.RegistryConfig{PrivateRegistry: "dockerhub.kubekey.local", Harbor: harbor})
+	if config["harbor_admin_password"] != `pa"ss'word` || config["data_volume"] != "/mnt/nfs/harbor" {
+		t.Errorf("unexpected HA harbor.yml: %v", config)
+	}
+	wantDB := map[string]interface{}{
+		"harbor": map[string]interface{}{"host": "172.16.0.10", "port": 5432, "db_name": "registry", "username": "harbor",
+			"password": "pg-secret", "ssl_mode": "disable", "max_idle_conns": 2, "max_open_conns": 0},
+	}
+	if !reflect.DeepEqual(config["external_database"], wantDB) {
+		t.Errorf("external_database = %v, want %v", config["external_database"], wantDB)
+	}
+	if redis, _ := config["external_redis"].(map[string]interface{}); redis["host"] != "172.16.0.11:6379" {
+		t.Errorf("external_redis = %v", config["external_redis"])
+	}
+	if storage, _ := config["storage_service"].(map[string]interface{}); storage["s3"] == nil {
+		t.Errorf("storage_service = %v", config["storage_service"])
+	}
+}
+
+func TestHarborPassword(t *testing.T) {
+	tests := []struct {
+		name   string
+		auths  string
+		harbor kubekeyapiv1alpha2.HarborCfg
+		want   string
+	}{
+		{name: "default", want: "Harbor12345"},
+		{name: "admin in the auths", auths: `{"dockerhub.kubekey.local": {"username": "admin", "password": "Admin123"}}`, want: "Admin123"},
+		{name: "robot in the auths", auths: `{"dockerhub.kubekey.local": {"username": "robot$kubekey", "password": "Kubekey123"}}`, want: "Harbor12345"},
+		{
+			name:   "admin password",
+			auths:  `{"dockerhub.kubekey.local": {"username": "admin", "password": "Admin123"}}`,
+			harbor: kubekeyapiv1alpha2.HarborCfg{AdminPassword: "Secret123"},
+			want:   "Secret123",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := kubekeyapiv1alpha2.RegistryConfig{PrivateRegistry: "dockerhub.kubekey.local", Harbor: tt.harbor}
+			if tt.auths != "" {
+				registry.Auths = runtime.RawExtension{Raw: []byte(tt.auths)}
+			}
+			kubeConf := &common.KubeConf{Cluster: &kubekeyapiv1alpha2.ClusterSpec{Registry: registry}}
+			if got := harborConfigData(kubeConf)["Password"]; got != tt.want {
+				t.Errorf("Password = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHarborResponse(t *testing.T) {
+	tests := []struct {
+		out      string
+		wantCode int
+		wantBody string
+		wantErr  bool
+	}{
+		{out: "Pong\r\n200", wantCode: 200, wantBody: "Pong"},
+		{out: "201", wantCode: 201},
+		{out: "{\"errors\":[{\"code\":\"CONFLICT\"}]}\r\n409\r\n", wantCode: 409, wantBody: `{"errors":[{"code":"CONFLICT"}]}`},
+		{out: "curl: (7) Failed to connect", wantErr: true},
+	}
+	for _, tt := range tests {
+		code, body, err := parseHarborResponse(tt.out)
+		if (err != nil) != tt.wantErr || code != tt.wantCode || body != tt.wantBody {
+			t.Errorf("parseHarborResponse(%q) = %d, %q, %v", tt.out, code, body, err)
+		}
+	}
+}
+
+func TestHarborCurlConfig(t *testing.T) {
+	tests := []struct {
+		password string
+		want     string
+	}{
+		{password: "Harbor12345", want: "user = \"admin:Harbor12345\"\n"},
+		{password: `pa"ss\word`, want: `user = "admin:pa\"ss\\word"` + "\n"},
+	}
+	for _, tt := range tests {
+		if got := harborCurlConfig("admin", tt.password); got != tt.want {
+			t.Errorf("harborCurlConfig(%q) = %q, want %q", tt.password, got, tt.want)
+		}
+	}
+}
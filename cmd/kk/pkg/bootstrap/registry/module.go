@@ -156,7 +156,15 @@ func InstallRegistry(i *InstallRegistryModule) []task.Interface {
 	}
 }
 
+// InstallHarbor installs Harbor on all the registry hosts. With more than one registry hosts, Harbor runs in HA mode
+// behind the VIP held by keepalived or an external load balancer.
 func InstallHarbor(i *InstallRegistryModule) []task.Interface {
+	checkHarborConfig := &task.LocalTask{
+		Name:   "CheckHarborConfig",
+		Desc:   "Check harbor config",
+		Action: new(CheckHarborConfig),
+	}
+
 	// Install docker
 	syncBinaries := &task.RemoteTask{
 		Name:  "SyncDockerBinaries",
@@ -283,16 +291,73 @@ func InstallHarbor(i *InstallRegistryModule) []task.Interface {
 		Retry:    1,
 	}
 
+	// one by one in HA mode, so that the shared database is initialized by the first registry host
 	startHarbor := &task.RemoteTask{
 		Name:     "StartHarbor",
 		Desc:     "start harbor",
 		Hosts:    i.Runtime.GetHostsByRole(common.Registry),
 		Action:   new(StartHarbor),
+		Parallel: !isHarborHA(i.Runtime),
+		Retry:    2,
+	}
+
+	installKeepalived := &task.RemoteTask{
+		Name:     "InstallKeepalived",
+		Desc:     "Install keepalived",
+		Hosts:    i.Runtime.GetHostsByRole(common.Registry),
+		Action:   new(InstallKeepalived),
 		Parallel: true,
 		Retry:    2,
 	}
 
-	return []task.Interface{
+	getInterface := &task.RemoteTask{
+		Name:     "GetNodeInterface",
+		Desc:     "Get Node Interface",
+		Hosts:    i.Runtime.GetHostsByRole(common.Registry),
+		Action:   new(GetInterfaceName),
+		Parallel: true,
+	}
+
+	generateCheckHarborScript := &task.RemoteTask{
+		Name:  "GenerateCheckHarborScript",
+		Desc:  "Generate check_harbor.sh",
+		Hosts: i.Runtime.GetHostsByRole(common.Registry),
+		Action: &action.Template{
+			Template: templates.CheckHarborScript,
+			Dst:      checkHarborPath,
+		},
+		Parallel: true,
+	}
+
+	keepalivedCfg := &task.RemoteTask{
+		Name:     "GenerateKeepalivedConfig",
+		Desc:     "Generate keepalived.conf",
+		Hosts:    i.Runtime.GetHostsByRole(common.Registry),
+		Action:   new(GenerateKeepalivedConfig),
+		Parallel: true,
+	}
+
+	// one by one, so that the VIP is always held by a registry host while the configs are refreshed
+	enableKeepalived := &task.RemoteTask{
+		Name:     "EnableKeepalived",
+		Desc:     "Enable keepalived",
+		Hosts:    i.Runtime.GetHostsByRole(common.Registry),
+		Action:   new(EnableKeepalived),
+		Parallel: false,
+	}
+
+	bootstrapHarbor := &task.RemoteTask{
+		Name:     "BootstrapHarbor",
+		Desc:     "Create harbor projects and robot account",
+		Hosts:    i.Runtime.GetHostsByRole(common.Registry),
+		Prepare:  new(FirstRegistryNode),
+		Action:   new(BootstrapHarbor),
+		Parallel: false,
+		Retry:    2,
+	}
+
+	tasks := []task.Interface{
+		checkHarborConfig,
 		syncBinaries,
 		generateContainerdService,
 		generateDockerService,
@@ -305,4 +370,12 @@ func InstallHarbor(i *InstallRegistryModule) []task.Interface {
 		generateHarborConfig,
 		startHarbor,
 	}
+	if i.KubeConf.Cluster.Registry.IsHarborKeepalivedEnabled() {
+		tasks = append(tasks, installKeepalived)
+		if i.KubeConf.Cluster.Registry.Harbor.Keepalived.Interface == "" {
+			tasks = append(tasks, getInterface)
+		}
+		tasks = append(tasks, generateCheckHarborScript, keepalivedCfg, enableKeepalived)
+	}
+	return append(tasks, bootstrapHarbor)
 }
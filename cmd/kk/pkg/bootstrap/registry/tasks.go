@@ -23,9 +23,7 @@ import (
 	"strings"
 	"text/template"
 
-	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/registry/templates"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/action"
-	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
 
 	"github.com/pkg/errors"
 
@@ -233,22 +231,10 @@ func (g *GenerateHarborConfig) Execute(runtime connector.Runtime) error {
 	}
 	harbor := template.Must(template.New("harbor.yml").Parse(string(harborContent)))
 
-	registryDomain := g.KubeConf.Cluster.Registry.GetHost()
-
-	if g.KubeConf.Cluster.Registry.Type == "harbor-ha" {
-		host := runtime.RemoteHost()
-		registryDomain = host.GetName()
-	}
-
 	templateAction := action.Template{
 		Template: harbor,
 		Dst:      "/opt/harbor/harbor.yml",
-		Data: util.Data{
-			"Domain":      registryDomain,
-			"Certificate": fmt.Sprintf("%s.pem", g.KubeConf.Cluster.Registry.GetHost()),
-			"Key":         fmt.Sprintf("%s-key.pem", g.KubeConf.Cluster.Registry.GetHost()),
-			"Password":    templates.Password(g.KubeConf, g.KubeConf.Cluster.Registry.GetHost()),
-		},
+		Data:     harborConfigData(g.KubeConf),
 	}
 	templateAction.Init(nil, nil)
 	if err := templateAction.Execute(runtime); err != nil {
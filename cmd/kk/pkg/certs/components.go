@@ -0,0 +1,114 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The components whose certs can be renewed by `kk certs renew --component`.
+const (
+	ComponentAll        = "all"
+	ComponentAPIServer  = "apiserver"
+	ComponentFrontProxy = "front-proxy"
+	ComponentKubeConfig = "kubeconfig"
+	ComponentEtcd       = "etcd"
+	ComponentKubelet    = "kubelet"
+)
+
+// componentCerts are the kubeadm certs of each component. The kubeconfig files are renewed by kubeadm as well.
+var componentCerts = map[string][]string{
+	ComponentAPIServer:  {"apiserver", "apiserver-kubelet-client"},
+	ComponentFrontProxy: {"front-proxy-client"},
+	ComponentKubeConfig: {"admin.conf", "controller-manager.conf", "scheduler.conf"},
+	ComponentEtcd:       nil,
+	ComponentKubelet:    nil,
+}
+
+// Components is the set of the components to renew.
+type Components map[string]bool
+
+// ParseComponents parses the components given by the user, all the components are selected if none is given.
+func ParseComponents(components []string) (Components, error) {
+	res := make(Components)
+	for _, c := range components {
+		for _, name := range strings.Split(c, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if name == ComponentAll {
+				for n := range componentCerts {
+					res[n] = true
+				}
+				continue
+			}
+			if _, ok := componentCerts[name]; !ok {
+				return nil, errors.Errorf("unknown component %q, it should be one of %s, %s", name,
+					strings.Join(componentNames(), ", "), ComponentAll)
+			}
+			res[name] = true
+		}
+	}
+	if len(res) == 0 {
+		for n := range componentCerts {
+			res[n] = true
+		}
+	}
+	return res, nil
+}
+
+func componentNames() []string {
+	names := make([]string, 0, len(componentCerts))
+	for n := range componentCerts {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Has reports whether the component is selected.
+func (c Components) Has(component string) bool {
+	return c[component]
+}
+
+// KubeadmCerts returns the kubeadm certs of the selected components in a stable order.
+func (c Components) KubeadmCerts() []string {
+	var res []string
+	for _, n := range []string{ComponentAPIServer, ComponentFrontProxy, ComponentKubeConfig} {
+		if c.Has(n) {
+			res = append(res, componentCerts[n]...)
+		}
+	}
+	return res
+}
+
+// RestartComponents returns the control-plane components which load the certs of the selected components.
+func (c Components) RestartComponents() []string {
+	var res []string
+	// kube-apiserver is the client of etcd as well
+	if c.Has(ComponentAPIServer) || c.Has(ComponentFrontProxy) || c.Has(ComponentEtcd) {
+		res = append(res, "kube-apiserver")
+	}
+	if c.Has(ComponentKubeConfig) {
+		res = append(res, "kube-controller-manager", "kube-scheduler")
+	}
+	return res
+}
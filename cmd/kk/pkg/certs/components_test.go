@@ -0,0 +1,106 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseComponents(t *testing.T) {
+	tests := []struct {
+		name       string
+		components []string
+		wantCerts  []string
+		wantHas    []string
+		wantErr    bool
+	}{
+		{
+			name:      "default to all",
+			wantCerts: []string{"apiserver", "apiserver-kubelet-client", "front-proxy-client", "admin.conf", "controller-manager.conf", "scheduler.conf"},
+			wantHas:   []string{ComponentEtcd, ComponentKubelet},
+		},
+		{
+			name:       "comma separated",
+			components: []string{"front-proxy,etcd"},
+			wantCerts:  []string{"front-proxy-client"},
+			wantHas:    []string{ComponentFrontProxy, ComponentEtcd},
+		},
+		{
+			name:       "kubelet only",
+			components: []string{"kubelet"},
+			wantHas:    []string{ComponentKubelet},
+		},
+		{
+			name:       "unknown",
+			components: []string{"scheduler"},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseComponents(tt.components)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseComponents() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if certs := got.KubeadmCerts(); !reflect.DeepEqual(certs, tt.wantCerts) {
+				t.Errorf("KubeadmCerts() = %v, want %v", certs, tt.wantCerts)
+			}
+			for _, c := range tt.wantHas {
+				if !got.Has(c) {
+					t.Errorf("%s is not selected", c)
+				}
+			}
+		})
+	}
+}
+
+func TestKubeletCertAction(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		notAfter    time.Time
+		rotate      bool
+		wantRestart bool
+		wantWarning bool
+		wantErr     bool
+	}{
+		{name: "valid with rotation", notAfter: now.AddDate(0, 6, 0), rotate: true},
+		{name: "valid without rotation", notAfter: now.AddDate(0, 6, 0), wantWarning: true},
+		{name: "expiring with rotation", notAfter: now.AddDate(0, 0, 7), rotate: true, wantRestart: true, wantWarning: true},
+		{name: "expiring without rotation", notAfter: now.AddDate(0, 0, 7), wantErr: true},
+		{name: "expired", notAfter: now.Add(-time.Hour), rotate: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restart, warning, err := kubeletCertAction(tt.notAfter, now, tt.rotate)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("kubeletCertAction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if restart != tt.wantRestart {
+				t.Errorf("kubeletCertAction() restart = %v, want %v", restart, tt.wantRestart)
+			}
+			if (warning != "") != tt.wantWarning {
+				t.Errorf("kubeletCertAction() warning = %q, want a warning %v", warning, tt.wantWarning)
+			}
+		})
+	}
+}
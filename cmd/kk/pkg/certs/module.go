@@ -17,6 +17,7 @@
 package certs
 
 import (
+	"fmt"
 	"path/filepath"
 
 	versionutil "k8s.io/apimachinery/pkg/util/version"
@@ -24,8 +25,10 @@ import (
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/certs/templates"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/action"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/task"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/etcd"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/kubernetes"
 )
 
@@ -71,17 +74,24 @@ func (p *PrintClusterCertsModule) Init() {
 
 type RenewCertsModule struct {
 	common.KubeModule
+	Skip       bool
+	Components Components
+}
+
+func (r *RenewCertsModule) IsSkip() bool {
+	return r.Skip
 }
 
 func (r *RenewCertsModule) Init() {
 	r.Name = "RenewCertsModule"
 	r.Desc = "Renew control-plane certs"
 
+	// one master after another, so that the control plane is available during the renewal
 	renew := &task.RemoteTask{
 		Name:     "RenewCerts",
 		Desc:     "Renew control-plane certs",
 		Hosts:    r.Runtime.GetHostsByRole(common.Master),
-		Action:   new(RenewCerts),
+		Action:   &RenewCerts{Components: r.Components},
 		Parallel: false,
 		Retry:    5,
 	}
@@ -101,6 +111,33 @@ func (r *RenewCertsModule) Init() {
 	}
 }
 
+// KubeletCertsModule checks the client certs of the kubelets, which are rotated by the kubelets themselves.
+type KubeletCertsModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (k *KubeletCertsModule) IsSkip() bool {
+	return k.Skip
+}
+
+func (k *KubeletCertsModule) Init() {
+	k.Name = "KubeletCertsModule"
+	k.Desc = "Check the kubelet client certs rotation"
+
+	check := &task.RemoteTask{
+		Name:     "CheckKubeletCerts",
+		Desc:     "Check the kubelet client certs rotation",
+		Hosts:    k.Runtime.GetHostsByRole(common.K8s),
+		Action:   new(CheckKubeletCerts),
+		Parallel: false,
+	}
+
+	k.Tasks = []task.Interface{
+		check,
+	}
+}
+
 type AutoRenewCertsModule struct {
 	common.KubeModule
 	Skip bool
@@ -188,3 +225,254 @@ func (u *UninstallAutoRenewCertsModule) Init() {
 		uninstall,
 	}
 }
+
+// RotateCAModule runs a phase of the CA rotation. The phases are run by the modules in order, and each one records
+// its completion in the state, so that a failed rotation is resumed from the failed phase.
+type RotateCAModule struct {
+	common.KubeModule
+	Skip  bool
+	Phase string
+	State *RotateCAState
+}
+
+func (r *RotateCAModule) IsSkip() bool {
+	return r.Skip || r.State.Done(r.Phase)
+}
+
+func (r *RotateCAModule) Init() {
+	r.Name = "RotateCAModule"
+	r.Desc = fmt.Sprintf("Rotate the CAs: %s", r.Phase)
+
+	switch r.Phase {
+	case PhasePrepare:
+		r.Tasks = r.prepareTasks()
+	case PhaseTrust:
+		r.Tasks = r.trustTasks()
+	case PhaseReissue:
+		r.Tasks = r.reissueTasks()
+	case PhaseDrop:
+		r.Tasks = r.dropTasks()
+	}
+
+	r.Tasks = append(r.Tasks, &task.LocalTask{
+		Name:   "CompleteRotateCAPhase",
+		Desc:   "Record the completed phase of the CA rotation",
+		Action: &CompleteRotateCAPhase{State: r.State, Phase: r.Phase},
+	})
+}
+
+func (r *RotateCAModule) prepareTasks() []task.Interface {
+	var tasks []task.Interface
+	if cas := WithoutCA(r.State.CAs, CAEtcd); len(cas) > 0 {
+		tasks = append(tasks, &task.RemoteTask{
+			Name:     "FetchOldCA",
+			Desc:     "Fetch the kubernetes CAs",
+			Hosts:    r.Runtime.GetHostsByRole(common.Master),
+			Prepare:  new(common.OnlyFirstMaster),
+			Action:   &FetchOldCA{CAs: cas},
+			Parallel: false,
+		})
+	}
+	if hasCA(r.State.CAs, CAEtcd) {
+		tasks = append(tasks, &task.RemoteTask{
+			Name:     "FetchOldETCDCA",
+			Desc:     "Fetch the etcd CA",
+			Hosts:    r.Runtime.GetHostsByRole(common.ETCD)[:1],
+			Action:   &FetchOldCA{CAs: []string{CAEtcd}},
+			Parallel: false,
+		})
+	}
+
+	generate := &task.LocalTask{
+		Name:   "GenerateNewCA",
+		Desc:   "Generate the new CAs",
+		Action: &GenerateNewCA{CAs: r.State.CAs},
+	}
+	return append(tasks, generate)
+}
+
+func (r *RotateCAModule) trustTasks() []task.Interface {
+	tasks := []task.Interface{r.syncCAFilesTask()}
+	if hasCA(r.State.CAs, CACluster) {
+		tasks = append(tasks, r.updateKubeConfigTask(), r.copyKubeConfigTask())
+	}
+	if hasCA(r.State.CAs, CAEtcd) {
+		tasks = append(tasks, r.restartETCDTask())
+	}
+	tasks = append(tasks, r.restartControlPlaneTask())
+	if hasCA(r.State.CAs, CACluster) {
+		// the pods trust kube-apiserver by the configmap, it must be updated before the serving cert is reissued
+		waitRootCA := &task.RemoteTask{
+			Name:     "WaitRootCAPublished",
+			Desc:     "Wait for the new cluster CA to be published",
+			Hosts:    r.Runtime.GetHostsByRole(common.Master),
+			Prepare:  new(common.OnlyFirstMaster),
+			Action:   new(WaitRootCAPublished),
+			Parallel: false,
+		}
+		tasks = append(tasks, waitRootCA, r.restartKubeletTask())
+	}
+	return tasks
+}
+
+func (r *RotateCAModule) reissueTasks() []task.Interface {
+	tasks := []task.Interface{r.syncCAFilesTask()}
+	if hasCA(r.State.CAs, CAEtcd) {
+		prepareETCDCerts := &task.LocalTask{
+			Name:   "PrepareETCDCerts",
+			Desc:   "Prepare the new etcd CA to sign the etcd certs",
+			Action: new(PrepareETCDCerts),
+		}
+
+		generateETCDCerts := &task.LocalTask{
+			Name:   "GenerateETCDCerts",
+			Desc:   "Generate etcd Certs",
+			Action: new(etcd.GenerateCerts),
+		}
+
+		syncETCDCerts := &task.RemoteTask{
+			Name:     "SyncCertsFile",
+			Desc:     "Synchronize certs file",
+			Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+			Action:   new(etcd.SyncCertsFile),
+			Parallel: true,
+			Retry:    1,
+		}
+
+		syncETCDCertsToMaster := &task.RemoteTask{
+			Name:     "SyncCertsFileToMaster",
+			Desc:     "Synchronize certs file to master",
+			Hosts:    r.Runtime.GetHostsByRole(common.Master),
+			Prepare:  &common.OnlyETCD{Not: true},
+			Action:   new(etcd.SyncCertsFile),
+			Parallel: true,
+			Retry:    1,
+		}
+
+		tasks = append(tasks, prepareETCDCerts, generateETCDCerts, syncETCDCerts, syncETCDCertsToMaster, r.restartETCDTask())
+	}
+
+	reissue := &task.RemoteTask{
+		Name:     "ReissueControlPlaneCerts",
+		Desc:     "Reissue control-plane certs with the new CAs",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   &ReissueControlPlaneCerts{Components: caComponents(r.State.CAs)},
+		Parallel: false,
+		Retry:    2,
+	}
+	tasks = append(tasks, reissue)
+
+	if hasCA(r.State.CAs, CACluster) {
+		reissueKubelet := &task.RemoteTask{
+			Name:     "ReissueKubeletCert",
+			Desc:     "Reissue the kubelet client cert with the new cluster CA",
+			Hosts:    r.Runtime.GetHostsByRole(common.K8s),
+			Action:   new(ReissueKubeletCert),
+			Parallel: false,
+		}
+		tasks = append(tasks, r.copyKubeConfigTask(), reissueKubelet)
+	}
+	return tasks
+}
+
+func (r *RotateCAModule) dropTasks() []task.Interface {
+	tasks := []task.Interface{r.syncCAFilesTask()}
+	if hasCA(r.State.CAs, CACluster) {
+		tasks = append(tasks, r.updateKubeConfigTask(), r.copyKubeConfigTask())
+	}
+	if hasCA(r.State.CAs, CAEtcd) {
+		tasks = append(tasks, r.restartETCDTask())
+	}
+	tasks = append(tasks, r.restartControlPlaneTask())
+	if hasCA(r.State.CAs, CACluster) {
+		r.PipelineCache.GetOrSet(common.ClusterStatus, kubernetes.NewKubernetesStatus())
+
+		clusterStatus := &task.RemoteTask{
+			Name:     "GetClusterStatus",
+			Desc:     "Get kubernetes cluster status",
+			Hosts:    r.Runtime.GetHostsByRole(common.Master),
+			Prepare:  new(common.OnlyFirstMaster),
+			Action:   new(kubernetes.GetClusterStatus),
+			Parallel: false,
+		}
+
+		saveKubeConfig := &task.LocalTask{
+			Name:   "SaveKubeConfig",
+			Desc:   "Save kube config as a configmap",
+			Action: new(kubernetes.SaveKubeConfig),
+			Retry:  5,
+		}
+
+		tasks = append(tasks, r.restartKubeletTask(), clusterStatus, saveKubeConfig)
+	}
+	return tasks
+}
+
+func (r *RotateCAModule) syncCAFilesTask() task.Interface {
+	var hosts []connector.Host
+	for _, host := range r.Runtime.GetAllHosts() {
+		if host.IsRole(common.K8s) || host.IsRole(common.ETCD) {
+			hosts = append(hosts, host)
+		}
+	}
+	return &task.RemoteTask{
+		Name:     "SyncCAFiles",
+		Desc:     fmt.Sprintf("Synchronize the CA files of the %s phase", r.Phase),
+		Hosts:    hosts,
+		Action:   &SyncCAFiles{CAs: r.State.CAs, Phase: r.Phase},
+		Parallel: true,
+		Retry:    1,
+	}
+}
+
+func (r *RotateCAModule) updateKubeConfigTask() task.Interface {
+	return &task.RemoteTask{
+		Name:     "UpdateKubeConfigCA",
+		Desc:     "Update the cluster CA in the kubeconfig files",
+		Hosts:    r.Runtime.GetHostsByRole(common.K8s),
+		Action:   &UpdateKubeConfigCA{Phase: r.Phase},
+		Parallel: true,
+		Retry:    1,
+	}
+}
+
+func (r *RotateCAModule) copyKubeConfigTask() task.Interface {
+	return &task.RemoteTask{
+		Name:     "CopyKubeConfig",
+		Desc:     "Copy admin.conf to ~/.kube/config",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   new(kubernetes.CopyKubeConfigForControlPlane),
+		Parallel: true,
+		Retry:    2,
+	}
+}
+
+func (r *RotateCAModule) restartETCDTask() task.Interface {
+	return &task.RemoteTask{
+		Name:     "RestartETCDMember",
+		Desc:     "Restart etcd member and wait for it to be healthy",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(etcd.RestartMember),
+		Parallel: false,
+	}
+}
+
+func (r *RotateCAModule) restartControlPlaneTask() task.Interface {
+	return &task.RemoteTask{
+		Name:     "RestartControlPlane",
+		Desc:     "Restart control-plane components one master after another",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   &RestartControlPlane{Components: caComponents(r.State.CAs)},
+		Parallel: false,
+	}
+}
+
+func (r *RotateCAModule) restartKubeletTask() task.Interface {
+	return &task.RemoteTask{
+		Name:     "RestartKubelet",
+		Desc:     "Restart kubelet one node after another",
+		Hosts:    r.Runtime.GetHostsByRole(common.K8s),
+		Action:   new(RestartKubelet),
+		Parallel: false,
+	}
+}
@@ -0,0 +1,657 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/clientcmd"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
+	pkiutil "github.com/kubesphere/kubekey/v3/cmd/kk/pkg/utils/certs"
+)
+
+// The CAs which can be rotated by `kk certs rotate-ca --ca`.
+const (
+	CACluster    = "cluster"
+	CAFrontProxy = "front-proxy"
+	CAEtcd       = "etcd"
+)
+
+// The phases of the CA rotation. They are run in order, and the completed ones are skipped when the rotation is
+// run again after a failure.
+const (
+	// PhasePrepare fetches the old CAs and generates the new ones locally.
+	PhasePrepare = "prepare"
+	// PhaseTrust distributes the bundles of the old and the new CAs, the old CAs still sign the certs.
+	PhaseTrust = "trust"
+	// PhaseReissue makes the new CAs sign the certs, and reissues all the leaf certs with them.
+	PhaseReissue = "reissue"
+	// PhaseDrop removes the old CAs from the bundles.
+	PhaseDrop = "drop"
+)
+
+var RotateCAPhases = []string{PhasePrepare, PhaseTrust, PhaseReissue, PhaseDrop}
+
+const (
+	rotateCAStateFile   = "state.json"
+	kubeletCertValidity = 365 * 24 * time.Hour
+)
+
+// rotatedCA is a CA and the places of its cert and key on the nodes.
+type rotatedCA struct {
+	cert string
+	key  string
+	// certRoles and keyRoles are the roles of the nodes which have the cert and the key.
+	certRoles []string
+	keyRoles  []string
+	// components are the components whose certs are signed by the CA.
+	components []string
+}
+
+var rotatedCAs = map[string]rotatedCA{
+	CACluster: {
+		cert:       filepath.Join(common.KubeCertDir, "ca.crt"),
+		key:        filepath.Join(common.KubeCertDir, "ca.key"),
+		certRoles:  []string{common.K8s},
+		keyRoles:   []string{common.Master},
+		components: []string{ComponentAPIServer, ComponentKubeConfig},
+	},
+	CAFrontProxy: {
+		cert:       filepath.Join(common.KubeCertDir, "front-proxy-ca.crt"),
+		key:        filepath.Join(common.KubeCertDir, "front-proxy-ca.key"),
+		certRoles:  []string{common.Master},
+		keyRoles:   []string{common.Master},
+		components: []string{ComponentFrontProxy},
+	},
+	CAEtcd: {
+		cert:       filepath.Join(common.ETCDCertDir, "ca.pem"),
+		key:        filepath.Join(common.ETCDCertDir, "ca-key.pem"),
+		certRoles:  []string{common.ETCD, common.Master},
+		keyRoles:   []string{common.ETCD, common.Master},
+		components: []string{ComponentEtcd},
+	},
+}
+
+var caNames = []string{CACluster, CAFrontProxy, CAEtcd}
+
+// ParseCAs parses the CAs given by the user in a stable order, all the CAs are selected if none is given.
+func ParseCAs(cas []string) ([]string, error) {
+	selected := make(map[string]bool)
+	for _, c := range cas {
+		for _, name := range strings.Split(c, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if name == ComponentAll {
+				for _, n := range caNames {
+					selected[n] = true
+				}
+				continue
+			}
+			if _, ok := rotatedCAs[name]; !ok {
+				return nil, errors.Errorf("unknown CA %q, it should be one of %s, %s", name,
+					strings.Join(caNames, ", "), ComponentAll)
+			}
+			selected[name] = true
+		}
+	}
+	res := make([]string, 0, len(caNames))
+	for _, n := range caNames {
+		if selected[n] || len(selected) == 0 {
+			res = append(res, n)
+		}
+	}
+	return res, nil
+}
+
+// WithoutCA returns the CAs except the given one.
+func WithoutCA(cas []string, name string) []string {
+	res := make([]string, 0, len(cas))
+	for _, c := range cas {
+		if c != name {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+func hasCA(cas []string, name string) bool {
+	for _, c := range cas {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// caComponents returns the components whose certs are signed by the CAs.
+func caComponents(cas []string) Components {
+	res := make(Components)
+	for _, name := range cas {
+		for _, c := range rotatedCAs[name].components {
+			res[c] = true
+		}
+	}
+	return res
+}
+
+// RotateCADir returns the local dir which keeps the old and the new CAs and the state of the rotation.
+func RotateCADir(workDir string) string {
+	return filepath.Join(workDir, "pki", "rotate-ca")
+}
+
+// RotateCAState records the CAs being rotated and the completed phases.
+type RotateCAState struct {
+	CAs    []string `json:"cas"`
+	Phases []string `json:"phases"`
+}
+
+// LoadRotateCAState loads the state of the rotation in the dir, it is empty if no rotation is in progress.
+func LoadRotateCAState(dir string) (*RotateCAState, error) {
+	state := &RotateCAState{}
+	content, err := os.ReadFile(filepath.Join(dir, rotateCAStateFile))
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "read the state of the CA rotation failed")
+	}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, errors.Wrapf(err, "parse the state of the CA rotation %s failed", filepath.Join(dir, rotateCAStateFile))
+	}
+	return state, nil
+}
+
+// Save writes the state into the dir.
+func (s *RotateCAState) Save(dir string) error {
+	if err := util.CreateDir(dir); err != nil {
+		return errors.Wrapf(err, "create dir %s failed", dir)
+	}
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, rotateCAStateFile), content, 0600)
+}
+
+// InProgress reports whether a phase of the rotation has been completed.
+func (s *RotateCAState) InProgress() bool {
+	return len(s.Phases) > 0
+}
+
+// Done reports whether the phase has been completed.
+func (s *RotateCAState) Done(phase string) bool {
+	for _, p := range s.Phases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// Resume starts a rotation of the CAs, or resumes the one in progress. The CAs of the rotation in progress can not
+// be changed, they are kept if the CAs are not given explicitly.
+func (s *RotateCAState) Resume(cas []string, explicit bool) error {
+	if !s.InProgress() {
+		s.CAs = cas
+		return nil
+	}
+	if explicit && strings.Join(s.CAs, ",") != strings.Join(cas, ",") {
+		return errors.Errorf("the rotation of the CAs %s is in progress, resume it with --ca %s",
+			strings.Join(s.CAs, ","), strings.Join(s.CAs, ","))
+	}
+	return nil
+}
+
+// caBundle returns the CA certs distributed in the phase. The first cert is the one which signs the certs, the
+// others are only trusted.
+func caBundle(oldCA, newCA []byte, phase string) []byte {
+	switch phase {
+	case PhaseTrust:
+		return append(append([]byte{}, oldCA...), newCA...)
+	case PhaseReissue:
+		return append(append([]byte{}, newCA...), oldCA...)
+	default:
+		return newCA
+	}
+}
+
+func caBundleFile(dir, name, phase string) string {
+	if phase == PhaseDrop {
+		return filepath.Join(dir, "new", name+".pem")
+	}
+	return filepath.Join(dir, phase, name+".pem")
+}
+
+// updateKubeConfig embeds the CA bundle in all the clusters of the kubeconfig, the clusters referring to a CA file
+// are kept. The client cert and key are replaced by the file if it is given.
+func updateKubeConfig(content, bundle []byte, clientCertFile string) ([]byte, error) {
+	config, err := clientcmd.Load(content)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse kubeconfig failed")
+	}
+	for _, cluster := range config.Clusters {
+		if cluster.CertificateAuthority == "" {
+			cluster.CertificateAuthorityData = bundle
+		}
+	}
+	if clientCertFile != "" {
+		for _, authInfo := range config.AuthInfos {
+			authInfo.ClientCertificate = clientCertFile
+			authInfo.ClientKey = clientCertFile
+			authInfo.ClientCertificateData = nil
+			authInfo.ClientKeyData = nil
+		}
+	}
+	return clientcmd.Write(*config)
+}
+
+// updateRemoteKubeConfig updates the kubeconfig on the node by updateKubeConfig, it is skipped if the file does not
+// exist.
+func updateRemoteKubeConfig(runtime connector.Runtime, path string, bundle []byte, clientCertFile string) error {
+	if exist, err := runtime.GetRunner().FileExist(path); err != nil || !exist {
+		return err
+	}
+	content, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("cat %s", path), false)
+	if err != nil {
+		return errors.Wrapf(err, "get %s failed", path)
+	}
+	updated, err := updateKubeConfig([]byte(strings.ReplaceAll(content, "\r\n", "\n")), bundle, clientCertFile)
+	if err != nil {
+		return errors.Wrapf(err, "update %s failed", path)
+	}
+
+	local := filepath.Join(RotateCADir(runtime.GetWorkDir()), runtime.RemoteHost().GetName(), filepath.Base(path))
+	if err := writeFile(local, updated); err != nil {
+		return err
+	}
+	if err := runtime.GetRunner().SudoScp(local, path); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "sync %s failed", path)
+	}
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chmod 600 %s", path), false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "chmod %s failed", path)
+	}
+	return nil
+}
+
+// updateKubeConfigs embeds the cluster CA bundle of the phase in the kubeconfig files on the node.
+func updateKubeConfigs(runtime connector.Runtime, phase string) error {
+	bundle, err := os.ReadFile(caBundleFile(RotateCADir(runtime.GetWorkDir()), CACluster, phase))
+	if err != nil {
+		return errors.Wrap(err, "read the cluster CA bundle failed")
+	}
+	files := []string{"kubelet.conf"}
+	if runtime.RemoteHost().IsRole(common.Master) {
+		files = append(files, "admin.conf", "super-admin.conf", "controller-manager.conf", "scheduler.conf")
+	}
+	for _, f := range files {
+		if err := updateRemoteKubeConfig(runtime, filepath.Join(common.KubeConfigDir, f), bundle, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, content []byte) error {
+	if err := util.CreateDir(filepath.Dir(path)); err != nil {
+		return errors.Wrapf(err, "create dir %s failed", filepath.Dir(path))
+	}
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		return errors.Wrapf(err, "write %s failed", path)
+	}
+	return nil
+}
+
+func hasAnyRole(host connector.Host, roles []string) bool {
+	for _, r := range roles {
+		if host.IsRole(r) {
+			return true
+		}
+	}
+	return false
+}
+
+type FetchOldCA struct {
+	common.KubeAction
+	CAs []string
+}
+
+func (f *FetchOldCA) Execute(runtime connector.Runtime) error {
+	dir := RotateCADir(runtime.GetWorkDir())
+	for _, name := range f.CAs {
+		ca := rotatedCAs[name]
+		content, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("cat %s", ca.cert), false)
+		if err != nil {
+			return errors.Wrapf(err, "get the %s CA failed", name)
+		}
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+		certs, err := certutil.ParseCertsPEM([]byte(content))
+		if err != nil {
+			return errors.Wrapf(err, "parse the %s CA failed", name)
+		}
+		if len(certs) != 1 {
+			return errors.Errorf("%s on %s contains %d certs, a previous rotation of the %s CA may not be finished",
+				ca.cert, runtime.RemoteHost().GetName(), len(certs), name)
+		}
+		if err := writeFile(filepath.Join(dir, "old", name+".pem"), pkiutil.EncodeCertPEM(certs[0])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type GenerateNewCA struct {
+	common.KubeAction
+	CAs []string
+}
+
+// Execute generates the new CAs with the subjects of the old ones. A new CA generated by a previous run is kept,
+// since it may have signed certs already.
+func (g *GenerateNewCA) Execute(runtime connector.Runtime) error {
+	dir := RotateCADir(runtime.GetWorkDir())
+	for _, name := range g.CAs {
+		oldCA, err := os.ReadFile(filepath.Join(dir, "old", name+".pem"))
+		if err != nil {
+			return errors.Wrapf(err, "read the old %s CA failed", name)
+		}
+
+		newCertFile, newKeyFile := pkiutil.PathsForCertAndKey(filepath.Join(dir, "new"), name)
+		if !util.IsExist(newCertFile) || !util.IsExist(newKeyFile) {
+			old, err := certutil.ParseCertsPEM(oldCA)
+			if err != nil {
+				return errors.Wrapf(err, "parse the old %s CA failed", name)
+			}
+			cert, key, err := pkiutil.NewCertificateAuthority(&pkiutil.CertConfig{
+				Config: certutil.Config{
+					CommonName:   old[0].Subject.CommonName,
+					Organization: old[0].Subject.Organization,
+				},
+				PublicKeyAlgorithm: old[0].PublicKeyAlgorithm,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "generate the new %s CA failed", name)
+			}
+			if err := pkiutil.WriteCertAndKey(filepath.Join(dir, "new"), name, cert, key); err != nil {
+				return errors.Wrapf(err, "write the new %s CA failed", name)
+			}
+			logger.Log.Infof("the new %s CA is generated, it expires at %s", name, cert.NotAfter.Format(time.RFC3339))
+		}
+
+		newCA, err := os.ReadFile(newCertFile)
+		if err != nil {
+			return errors.Wrapf(err, "read the new %s CA failed", name)
+		}
+		for _, phase := range []string{PhaseTrust, PhaseReissue} {
+			if err := writeFile(caBundleFile(dir, name, phase), caBundle(oldCA, newCA, phase)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type SyncCAFiles struct {
+	common.KubeAction
+	CAs   []string
+	Phase string
+}
+
+func (s *SyncCAFiles) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	dir := RotateCADir(runtime.GetWorkDir())
+	for _, name := range s.CAs {
+		ca := rotatedCAs[name]
+		if hasAnyRole(host, ca.certRoles) {
+			if err := runtime.GetRunner().SudoScp(caBundleFile(dir, name, s.Phase), ca.cert); err != nil {
+				return errors.Wrapf(errors.WithStack(err), "sync %s failed", ca.cert)
+			}
+		}
+		// the old CA keeps signing the certs until they are reissued
+		if s.Phase == PhaseTrust || !hasAnyRole(host, ca.keyRoles) {
+			continue
+		}
+		_, keyFile := pkiutil.PathsForCertAndKey(filepath.Join(dir, "new"), name)
+		if err := runtime.GetRunner().SudoScp(keyFile, ca.key); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "sync %s failed", ca.key)
+		}
+		if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chmod 600 %s", ca.key), false); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "chmod %s failed", ca.key)
+		}
+	}
+	return nil
+}
+
+type UpdateKubeConfigCA struct {
+	common.KubeAction
+	Phase string
+}
+
+func (u *UpdateKubeConfigCA) Execute(runtime connector.Runtime) error {
+	return updateKubeConfigs(runtime, u.Phase)
+}
+
+type RestartControlPlane struct {
+	common.KubeAction
+	Components Components
+}
+
+func (r *RestartControlPlane) Execute(runtime connector.Runtime) error {
+	return restartControlPlane(runtime, r.KubeConf, r.Components)
+}
+
+type ReissueControlPlaneCerts struct {
+	common.KubeAction
+	Components Components
+}
+
+// Execute renews the kubeadm certs with the new CAs. kubeadm embeds only the signing CA in the kubeconfig files, so
+// they are updated with the bundle again before the components are restarted.
+func (r *ReissueControlPlaneCerts) Execute(runtime connector.Runtime) error {
+	if err := renewKubeadmCerts(runtime, r.Components); err != nil {
+		return err
+	}
+	if r.Components.Has(ComponentKubeConfig) {
+		if err := updateKubeConfigs(runtime, PhaseReissue); err != nil {
+			return err
+		}
+	}
+	return restartControlPlane(runtime, r.KubeConf, r.Components)
+}
+
+type WaitRootCAPublished struct {
+	common.KubeAction
+}
+
+// Execute waits for kube-controller-manager to publish the new cluster CA in the kube-root-ca.crt configmaps, which
+// are mounted by the pods to trust kube-apiserver.
+func (w *WaitRootCAPublished) Execute(runtime connector.Runtime) error {
+	content, err := os.ReadFile(caBundleFile(RotateCADir(runtime.GetWorkDir()), CACluster, PhaseDrop))
+	if err != nil {
+		return errors.Wrap(err, "read the new cluster CA failed")
+	}
+	newCA, err := certutil.ParseCertsPEM(content)
+	if err != nil {
+		return errors.Wrap(err, "parse the new cluster CA failed")
+	}
+
+	cmd := "/usr/local/bin/kubectl -n kube-system get configmap kube-root-ca.crt -o jsonpath='{.data.ca\\.crt}'"
+	if err := wait.PollImmediateWithContext(runtime.GetRunner().Context(), healthCheckDelay, healthCheckTimeout, func(context.Context) (bool, error) {
+		output, err := runtime.GetRunner().SudoCmd(cmd, false)
+		if err != nil {
+			return false, nil
+		}
+		published, err := certutil.ParseCertsPEM([]byte(strings.ReplaceAll(output, "\r\n", "\n")))
+		if err != nil {
+			return false, nil
+		}
+		for _, c := range published {
+			if c.Equal(newCA[0]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}); err != nil {
+		return errors.New("the new cluster CA is not published in the configmap kube-system/kube-root-ca.crt, please check kube-controller-manager")
+	}
+	return nil
+}
+
+type RestartKubelet struct {
+	common.KubeAction
+}
+
+func (r *RestartKubelet) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd("systemctl restart kubelet", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "restart kubelet failed")
+	}
+	return waitHealthy(runtime, "kubelet", "curl -sf http://127.0.0.1:10248/healthz")
+}
+
+type ReissueKubeletCert struct {
+	common.KubeAction
+}
+
+// Execute signs a new kubelet client cert with the new cluster CA, and makes kubelet.conf refer to it, so that the
+// kubelet keeps rotating it by itself.
+func (r *ReissueKubeletCert) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	dir := RotateCADir(runtime.GetWorkDir())
+	caCert, caKey, err := pkiutil.TryLoadCertAndKeyFromDisk(filepath.Join(dir, "new"), CACluster)
+	if err != nil {
+		return errors.Wrap(err, "load the new cluster CA failed")
+	}
+
+	// keep the node name of the current cert, it is the host name only if the cert is embedded in kubelet.conf
+	commonName := fmt.Sprintf("system:node:%s", strings.ToLower(host.GetName()))
+	if current, err := kubeletClientCertificate(runtime); err == nil {
+		commonName = current.Subject.CommonName
+	}
+	notAfter := time.Now().Add(kubeletCertValidity).UTC()
+	cert, key, err := pkiutil.NewCertAndKey(caCert, caKey, &pkiutil.CertConfig{
+		Config: certutil.Config{
+			CommonName:   commonName,
+			Organization: []string{"system:nodes"},
+			Usages:       []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		},
+		NotAfter:           &notAfter,
+		PublicKeyAlgorithm: x509.ECDSA,
+	})
+	if err != nil {
+		return errors.Wrap(err, "sign the kubelet client cert failed")
+	}
+	encodedKey, err := keyutil.MarshalPrivateKeyToPEM(key)
+	if err != nil {
+		return errors.Wrap(err, "marshal the kubelet client key failed")
+	}
+
+	local := filepath.Join(dir, host.GetName(), "kubelet-client.pem")
+	if err := writeFile(local, bytes.Join([][]byte{pkiutil.EncodeCertPEM(cert), encodedKey}, nil)); err != nil {
+		return err
+	}
+	// the same name as the ones rotated by the kubelet
+	remote := filepath.Join(filepath.Dir(kubeletClientCert), fmt.Sprintf("kubelet-client-%s.pem", time.Now().Format("2006-01-02-15-04-05")))
+	if err := runtime.GetRunner().SudoScp(local, remote); err != nil {
+		return errors.Wrap(errors.WithStack(err), "sync the kubelet client cert failed")
+	}
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chmod 600 %s && ln -sf %s %s", remote, remote, kubeletClientCert), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "link the kubelet client cert failed")
+	}
+
+	bundle, err := os.ReadFile(caBundleFile(dir, CACluster, PhaseReissue))
+	if err != nil {
+		return errors.Wrap(err, "read the cluster CA bundle failed")
+	}
+	if err := updateRemoteKubeConfig(runtime, filepath.Join(common.KubeConfigDir, "kubelet.conf"), bundle, kubeletClientCert); err != nil {
+		return err
+	}
+
+	if _, err := runtime.GetRunner().SudoCmd("systemctl restart kubelet", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "restart kubelet failed")
+	}
+	return waitHealthy(runtime, "kubelet", "curl -sf http://127.0.0.1:10248/healthz")
+}
+
+type PrepareETCDCerts struct {
+	common.KubeAction
+}
+
+// Execute replaces the local etcd certs with the CA bundle of the reissue phase and the new CA key, so that the
+// etcd certs generated next are signed by the new CA.
+func (p *PrepareETCDCerts) Execute(runtime connector.Runtime) error {
+	dir := RotateCADir(runtime.GetWorkDir())
+	pkiPath := filepath.Join(runtime.GetWorkDir(), "pki", "etcd")
+	if err := os.RemoveAll(pkiPath); err != nil {
+		return errors.Wrapf(err, "remove %s failed", pkiPath)
+	}
+
+	bundle, err := os.ReadFile(caBundleFile(dir, CAEtcd, PhaseReissue))
+	if err != nil {
+		return errors.Wrap(err, "read the etcd CA bundle failed")
+	}
+	_, keyFile := pkiutil.PathsForCertAndKey(filepath.Join(dir, "new"), CAEtcd)
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		return errors.Wrap(err, "read the key of the new etcd CA failed")
+	}
+	if err := writeFile(filepath.Join(pkiPath, "ca.pem"), bundle); err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(pkiPath, "ca-key.pem"), key)
+}
+
+type CompleteRotateCAPhase struct {
+	common.KubeAction
+	State *RotateCAState
+	Phase string
+}
+
+func (c *CompleteRotateCAPhase) Execute(runtime connector.Runtime) error {
+	dir := RotateCADir(runtime.GetWorkDir())
+	c.State.Phases = append(c.State.Phases, c.Phase)
+	if err := c.State.Save(dir); err != nil {
+		return errors.Wrap(err, "save the state of the CA rotation failed")
+	}
+	logger.Log.Infof("the %s phase of the CA rotation is completed", c.Phase)
+	if c.Phase != PhaseDrop {
+		return nil
+	}
+
+	// archive the finished rotation, so that the next one starts from the beginning
+	archive := fmt.Sprintf("%s-%s", dir, time.Now().Format("20060102150405"))
+	if err := os.Rename(dir, archive); err != nil {
+		return errors.Wrapf(err, "archive %s failed", dir)
+	}
+	logger.Log.Infof("the CA rotation is finished, the old and the new CAs are kept in %s", archive)
+	return nil
+}
@@ -0,0 +1,211 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestParseCAs(t *testing.T) {
+	tests := []struct {
+		name    string
+		cas     []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "default to all", want: []string{CACluster, CAFrontProxy, CAEtcd}},
+		{name: "all", cas: []string{"all"}, want: []string{CACluster, CAFrontProxy, CAEtcd}},
+		{name: "stable order", cas: []string{"etcd,cluster"}, want: []string{CACluster, CAEtcd}},
+		{name: "unknown", cas: []string{"kubelet"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCAs(tt.cas)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCAs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCAs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotateCAStateResume(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    RotateCAState
+		cas      []string
+		explicit bool
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name: "new rotation",
+			cas:  []string{CACluster, CAEtcd},
+			want: []string{CACluster, CAEtcd},
+		},
+		{
+			name:  "keep the CAs in progress",
+			state: RotateCAState{CAs: []string{CAFrontProxy}, Phases: []string{PhasePrepare}},
+			cas:   []string{CACluster, CAFrontProxy, CAEtcd},
+			want:  []string{CAFrontProxy},
+		},
+		{
+			name:     "same CAs given",
+			state:    RotateCAState{CAs: []string{CAFrontProxy}, Phases: []string{PhasePrepare}},
+			cas:      []string{CAFrontProxy},
+			explicit: true,
+			want:     []string{CAFrontProxy},
+		},
+		{
+			name:     "other CAs given",
+			state:    RotateCAState{CAs: []string{CAFrontProxy}, Phases: []string{PhasePrepare, PhaseTrust}},
+			cas:      []string{CACluster},
+			explicit: true,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.state.Resume(tt.cas, tt.explicit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resume() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(tt.state.CAs, tt.want) {
+				t.Errorf("Resume() CAs = %v, want %v", tt.state.CAs, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotateCAStateSave(t *testing.T) {
+	dir := t.TempDir()
+	state, err := LoadRotateCAState(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.InProgress() {
+		t.Fatal("a rotation is in progress without the state file")
+	}
+
+	state.CAs = []string{CACluster}
+	state.Phases = []string{PhasePrepare, PhaseTrust}
+	if err := state.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadRotateCAState(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, state) {
+		t.Errorf("LoadRotateCAState() = %v, want %v", loaded, state)
+	}
+	if !loaded.Done(PhaseTrust) || loaded.Done(PhaseReissue) {
+		t.Errorf("Done() is wrong for the phases %v", loaded.Phases)
+	}
+}
+
+func TestCABundle(t *testing.T) {
+	oldCA, newCA := []byte("old\n"), []byte("new\n")
+	tests := []struct {
+		phase string
+		want  string
+	}{
+		{phase: PhaseTrust, want: "old\nnew\n"},
+		{phase: PhaseReissue, want: "new\nold\n"},
+		{phase: PhaseDrop, want: "new\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phase, func(t *testing.T) {
+			if got := string(caBundle(oldCA, newCA, tt.phase)); got != tt.want {
+				t.Errorf("caBundle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if string(oldCA) != "old\n" || string(newCA) != "new\n" {
+		t.Error("caBundle() modifies the CAs")
+	}
+}
+
+func TestUpdateKubeConfig(t *testing.T) {
+	kubeConfig := []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: kubernetes
+  cluster:
+    server: https://lb.kubesphere.local:6443
+    certificate-authority-data: b2xk
+- name: file
+  cluster:
+    server: https://127.0.0.1:6443
+    certificate-authority: /etc/kubernetes/pki/ca.crt
+users:
+- name: system:node:node1
+  user:
+    client-certificate-data: Y2VydA==
+    client-key-data: a2V5
+contexts:
+- name: default
+  context:
+    cluster: kubernetes
+    user: system:node:node1
+current-context: default
+`)
+	tests := []struct {
+		name           string
+		clientCertFile string
+	}{
+		{name: "CA only"},
+		{name: "client cert file", clientCertFile: kubeletClientCert},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := updateKubeConfig(kubeConfig, []byte("bundle"), tt.clientCertFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			config, err := clientcmd.Load(content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(config.Clusters["kubernetes"].CertificateAuthorityData); got != "bundle" {
+				t.Errorf("the CA data = %q, want bundle", got)
+			}
+			if got := config.Clusters["file"]; got.CertificateAuthority == "" || len(got.CertificateAuthorityData) != 0 {
+				t.Errorf("the cluster referring to the CA file is changed: %+v", got)
+			}
+			user := config.AuthInfos["system:node:node1"]
+			if tt.clientCertFile == "" {
+				if string(user.ClientCertificateData) != "cert" || string(user.ClientKeyData) != "key" {
+					t.Errorf("the client cert is changed: %+v", user)
+				}
+				return
+			}
+			if user.ClientCertificate != tt.clientCertFile || user.ClientKey != tt.clientCertFile ||
+				len(user.ClientCertificateData) != 0 || len(user.ClientKeyData) != 0 {
+				t.Errorf("the client cert does not refer to %s: %+v", tt.clientCertFile, user)
+			}
+			if config.Clusters["kubernetes"].Server != "https://lb.kubesphere.local:6443" {
+				t.Errorf("the server is changed: %s", config.Clusters["kubernetes"].Server)
+			}
+		})
+	}
+}
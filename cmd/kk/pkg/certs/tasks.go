@@ -17,6 +17,8 @@
 package certs
 
 import (
+	"context"
+	"crypto/x509"
 	"encoding/base64"
 	"fmt"
 	"os"
@@ -28,6 +30,7 @@ import (
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	versionutil "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apimachinery/pkg/util/wait"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	clientcmdlatest "k8s.io/client-go/tools/clientcmd/api/latest"
 	certutil "k8s.io/client-go/util/cert"
@@ -35,6 +38,7 @@ import (
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/certs/templates"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/utils"
 )
 
@@ -249,34 +253,18 @@ func (d *DisplayForm) Execute(runtime connector.Runtime) error {
 
 type RenewCerts struct {
 	common.KubeAction
+	Components Components
 }
 
 func (r *RenewCerts) Execute(runtime connector.Runtime) error {
-	var kubeadmAlphaList = []string{
-		"/usr/local/bin/kubeadm alpha certs renew apiserver",
-		"/usr/local/bin/kubeadm alpha certs renew apiserver-kubelet-client",
-		"/usr/local/bin/kubeadm alpha certs renew front-proxy-client",
-		"/usr/local/bin/kubeadm alpha certs renew admin.conf",
-		"/usr/local/bin/kubeadm alpha certs renew controller-manager.conf",
-		"/usr/local/bin/kubeadm alpha certs renew scheduler.conf",
-	}
-
-	var kubeadmList = []string{
-		"/usr/local/bin/kubeadm certs renew apiserver",
-		"/usr/local/bin/kubeadm certs renew apiserver-kubelet-client",
-		"/usr/local/bin/kubeadm certs renew front-proxy-client",
-		"/usr/local/bin/kubeadm certs renew admin.conf",
-		"/usr/local/bin/kubeadm certs renew controller-manager.conf",
-		"/usr/local/bin/kubeadm certs renew scheduler.conf",
-	}
-
-	var restartList = []string{
-		"docker ps -af name=k8s_kube-apiserver* -q | xargs --no-run-if-empty docker rm -f",
-		"docker ps -af name=k8s_kube-scheduler* -q | xargs --no-run-if-empty docker rm -f",
-		"docker ps -af name=k8s_kube-controller-manager* -q | xargs --no-run-if-empty docker rm -f",
-		"systemctl restart kubelet",
+	if err := renewKubeadmCerts(runtime, r.Components); err != nil {
+		return err
 	}
+	return restartControlPlane(runtime, r.KubeConf, r.Components)
+}
 
+// renewKubeadmCerts renews the kubeadm certs of the components with the CA in /etc/kubernetes/pki.
+func renewKubeadmCerts(runtime connector.Runtime, components Components) error {
 	version, err := runtime.GetRunner().SudoCmd("/usr/local/bin/kubeadm version -o short", true)
 	if err != nil {
 		return errors.Wrap(errors.WithStack(err), "kubeadm get version failed")
@@ -285,25 +273,174 @@ func (r *RenewCerts) Execute(runtime connector.Runtime) error {
 	if err != nil {
 		return errors.Wrap(errors.WithStack(err), "parse kubeadm version failed")
 	}
+	kubeadmCerts := "/usr/local/bin/kubeadm certs"
 	if cmp == -1 {
-		_, err := runtime.GetRunner().SudoCmd(strings.Join(kubeadmAlphaList, " && "), false)
-		if err != nil {
-			return errors.Wrap(err, "kubeadm alpha certs renew failed")
+		kubeadmCerts = "/usr/local/bin/kubeadm alpha certs"
+	}
+
+	certList := components.KubeadmCerts()
+	if components.Has(ComponentKubeConfig) {
+		// super-admin.conf is only generated by kubeadm v1.29+
+		if exist, _ := runtime.GetRunner().FileExist(filepath.Join(common.KubeConfigDir, "super-admin.conf")); exist {
+			certList = append(certList, "super-admin.conf")
 		}
-	} else {
-		_, err := runtime.GetRunner().SudoCmd(strings.Join(kubeadmList, " && "), false)
-		if err != nil {
-			return errors.Wrap(err, "kubeadm alpha certs renew failed")
+	}
+	var renewList []string
+	for _, c := range certList {
+		renewList = append(renewList, fmt.Sprintf("%s renew %s", kubeadmCerts, c))
+	}
+	if len(renewList) > 0 {
+		if _, err := runtime.GetRunner().SudoCmd(strings.Join(renewList, " && "), false); err != nil {
+			return errors.Wrap(err, "kubeadm certs renew failed")
+		}
+	}
+	return nil
+}
+
+// restartControlPlane restarts the control-plane components which load the certs of the components one by one.
+func restartControlPlane(runtime connector.Runtime, kubeConf *common.KubeConf, components Components) error {
+	host := runtime.RemoteHost()
+	for _, component := range components.RestartComponents() {
+		logger.Log.Infof("restarting %s on %s", component, host.GetName())
+		if _, err := runtime.GetRunner().SudoCmd(restartStaticPodCmd(kubeConf, component), false); err != nil {
+			return errors.Wrapf(err, "restart %s failed", component)
+		}
+		// go on with the next one only if the component is healthy with the renewed certs
+		if err := waitHealthy(runtime, component, staticPodHealthCmd[component]); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	_, err = runtime.GetRunner().SudoCmd(strings.Join(restartList, " && "), false)
+var staticPodHealthCmd = map[string]string{
+	"kube-apiserver": "/usr/local/bin/kubectl --kubeconfig /etc/kubernetes/admin.conf --server https://127.0.0.1:6443 get --raw /readyz",
+	// the healthz of them is always allowed without authentication
+	"kube-controller-manager": "curl -skf https://127.0.0.1:10257/healthz",
+	"kube-scheduler":          "curl -skf https://127.0.0.1:10259/healthz",
+}
+
+// restartStaticPodCmd removes the pod of the control-plane component, and kubelet recreates it which loads the
+// renewed certs. crictl works with all the CRI runtimes, only the docker without cri-dockerd is handled by docker.
+func restartStaticPodCmd(kubeConf *common.KubeConf, component string) string {
+	endpoint := kubeConf.Cluster.Kubernetes.ContainerRuntimeEndpoint
+	if endpoint == "" {
+		return fmt.Sprintf("docker ps -af name=k8s_%s* -q | xargs --no-run-if-empty docker rm -f", component)
+	}
+	crictl := fmt.Sprintf("crictl --runtime-endpoint %s", endpoint)
+	return fmt.Sprintf("%s pods --namespace kube-system --name '^%s-' -q | xargs --no-run-if-empty %s rmp -f",
+		crictl, component, crictl)
+}
+
+const (
+	healthCheckDelay   = 5 * time.Second
+	healthCheckTimeout = 150 * time.Second
+)
+
+// waitHealthy waits for the cmd to succeed, it gives up once the task is timeout or interrupted.
+func waitHealthy(runtime connector.Runtime, name, cmd string) error {
+	var checkErr error
+	if err := wait.PollImmediateWithContext(runtime.GetRunner().Context(), healthCheckDelay, healthCheckTimeout, func(context.Context) (bool, error) {
+		_, checkErr = runtime.GetRunner().SudoCmd(cmd, false)
+		return checkErr == nil, nil
+	}); err != nil {
+		return errors.Wrapf(errors.WithStack(checkErr), "%s on %s is not healthy after the certs renewal", name, runtime.RemoteHost().GetName())
+	}
+	return nil
+}
+
+const (
+	kubeletClientCert   = "/var/lib/kubelet/pki/kubelet-client-current.pem"
+	kubeletConfig       = "/var/lib/kubelet/config.yaml"
+	kubeletRotateBefore = 30 * 24 * time.Hour
+)
+
+// kubeletCertAction decides what to do with the kubelet client cert. The kubelet rotates the cert by itself
+// when rotateCertificates is enabled, and is restarted to retry when the cert is about to expire.
+func kubeletCertAction(notAfter, now time.Time, rotate bool) (restart bool, warning string, err error) {
+	if !notAfter.After(now) {
+		return false, "", errors.Errorf("the kubelet client cert expired at %s, it can not be rotated by the kubelet, "+
+			"please rejoin the node", notAfter.Format(time.RFC3339))
+	}
+	if notAfter.Sub(now) > kubeletRotateBefore {
+		if !rotate {
+			return false, fmt.Sprintf("the kubelet client cert rotation is disabled, the cert expires at %s", notAfter.Format(time.RFC3339)), nil
+		}
+		return false, "", nil
+	}
+	if !rotate {
+		return false, "", errors.Errorf("the kubelet client cert expires at %s and the rotation is disabled, "+
+			"please set rotateCertificates to true in %s", notAfter.Format(time.RFC3339), kubeletConfig)
+	}
+	return true, fmt.Sprintf("the kubelet client cert expires at %s but has not been rotated, restart the kubelet to retry", notAfter.Format(time.RFC3339)), nil
+}
+
+type CheckKubeletCerts struct {
+	common.KubeAction
+}
+
+func (c *CheckKubeletCerts) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	notAfter, err := kubeletCertNotAfter(runtime)
+	if err != nil {
+		return err
+	}
+	_, rotateErr := runtime.GetRunner().SudoCmd(fmt.Sprintf("grep -Eq '^rotateCertificates: *true' %s", kubeletConfig), false)
+	// the rotated cert is only used when kubelet.conf refers to it instead of the embedded one
+	_, linkErr := runtime.GetRunner().SudoCmd(fmt.Sprintf("grep -q %s %s", kubeletClientCert,
+		filepath.Join(common.KubeConfigDir, "kubelet.conf")), false)
+
+	restart, warning, err := kubeletCertAction(notAfter, time.Now(), rotateErr == nil && linkErr == nil)
 	if err != nil {
-		return errors.Wrap(err, "kube-apiserver, kube-schedule, kube-controller-manager or kubelet restart failed")
+		return errors.Wrapf(err, "check the kubelet client cert of %s failed", host.GetName())
+	}
+	if warning != "" {
+		logger.Log.Warnf("%s: %s", host.GetName(), warning)
+	}
+	if !restart {
+		return nil
+	}
+
+	if _, err := runtime.GetRunner().SudoCmd("systemctl restart kubelet", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "restart kubelet failed")
+	}
+	if err := waitHealthy(runtime, "kubelet", "curl -sf http://127.0.0.1:10248/healthz"); err != nil {
+		return err
+	}
+	// the new cert is requested by a CSR, which is approved and signed by kube-controller-manager
+	if err := wait.PollImmediateWithContext(runtime.GetRunner().Context(), healthCheckDelay, healthCheckTimeout, func(context.Context) (bool, error) {
+		rotated, err := kubeletCertNotAfter(runtime)
+		if err != nil || !rotated.After(notAfter) {
+			return false, nil
+		}
+		logger.Log.Infof("%s: the kubelet client cert is rotated, it expires at %s", host.GetName(), rotated.Format(time.RFC3339))
+		return true, nil
+	}); err != nil {
+		return errors.Errorf("the kubelet client cert of %s is not rotated, please check the pending CSRs by kubectl get csr", host.GetName())
 	}
 	return nil
 }
 
+func kubeletCertNotAfter(runtime connector.Runtime) (time.Time, error) {
+	cert, err := kubeletClientCertificate(runtime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cert.NotAfter, nil
+}
+
+func kubeletClientCertificate(runtime connector.Runtime) (*x509.Certificate, error) {
+	content, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("cat %s", kubeletClientCert), false)
+	if err != nil {
+		return nil, errors.Wrap(err, "get the kubelet client cert failed")
+	}
+	certs, err := certutil.ParseCertsPEM([]byte(content))
+	if err != nil {
+		return nil, errors.Wrap(err, "parse the kubelet client cert failed")
+	}
+	return certs[0], nil
+}
+
 type FetchKubeConfig struct {
 	common.KubeAction
 }
@@ -26,6 +26,7 @@ import (
 
 	kubekeyv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/files"
 )
 
 type ArtifactArgument struct {
@@ -35,9 +36,15 @@ type ArtifactArgument struct {
 	Debug              bool
 	IgnoreErr          bool
 	DownloadCommand    func(path, url string) string
+	Download           files.DownloadOptions
 	ImageStartIndex    int
 	ImageTransport     string
+	ImageConcurrency   int
 	SkipRemoveArtifact bool
+	// Base is the artifact a delta artifact is exported against.
+	Base string
+	// SignKey is the private key the content manifest of the artifact is signed with.
+	SignKey string
 }
 
 type ArtifactRuntime struct {
@@ -52,7 +59,22 @@ func NewArtifactRuntime(arg ArtifactArgument) (*ArtifactRuntime, error) {
 		return nil, err
 	}
 
-	fp, err := filepath.Abs(arg.ManifestFile)
+	manifest, err := LoadManifest(arg.ManifestFile)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &ArtifactRuntime{
+		Spec: &manifest.Spec,
+		Arg:  arg,
+	}
+	r.LocalRuntime = localRuntime
+	return r, nil
+}
+
+// LoadManifest loads the KubeKey manifest file.
+func LoadManifest(manifestFile string) (*kubekeyv1alpha2.Manifest, error) {
+	fp, err := filepath.Abs(manifestFile)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to look up current directory")
 	}
@@ -71,13 +93,7 @@ func NewArtifactRuntime(arg ArtifactArgument) (*ArtifactRuntime, error) {
 	if err := json.Unmarshal(contentToJson, manifest); err != nil {
 		return nil, errors.Wrapf(err, "Failed to json unmarshal")
 	}
-
-	r := &ArtifactRuntime{
-		Spec: &manifest.Spec,
-		Arg:  arg,
-	}
-	r.LocalRuntime = localRuntime
-	return r, nil
+	return manifest, nil
 }
 
 // Copy is used to create a copy for Runtime.
@@ -32,6 +32,7 @@ const (
 	ETCD          = "etcd"
 	K8s           = "k8s"
 	Registry      = "registry"
+	Loadbalancer  = "loadbalancer"
 	KubeKey       = "kubekey"
 	Harbor        = "harbor"
 	DockerCompose = "compose"
@@ -85,9 +86,10 @@ const (
 	NodeK8sVersion         = "NodeK8sVersion"
 
 	// ETCDModule
-	ETCDCluster = "etcdCluster"
-	ETCDName    = "etcdName"
-	ETCDExist   = "etcdExist"
+	ETCDCluster      = "etcdCluster"
+	ETCDName         = "etcdName"
+	ETCDExist        = "etcdExist"
+	ETCDMemberStatus = "etcdMemberStatus"
 
 	// KubernetesModule
 	ClusterStatus = "clusterStatus"
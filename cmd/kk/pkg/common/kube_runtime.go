@@ -19,6 +19,8 @@ package common
 import (
 	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/files"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/registry"
 )
 
 type KubeRuntime struct {
@@ -45,12 +47,16 @@ type Argument struct {
 	SecurityEnhancement bool
 	DeployLocalStorage  *bool
 	DownloadCommand     func(path, url string) string
+	Download            files.DownloadOptions
 	SkipConfirmCheck    bool
 	ContainerManager    string
 	FromCluster         bool
 	KubeConfig          string
 	Artifact            string
+	ArtifactVerifyKey   string
+	SkipArtifactVerify  bool
 	ImageTransport      string
+	ImageConcurrency    int
 	InstallPackages     bool
 	ImagesDir           string
 	Namespace           string
@@ -59,6 +65,17 @@ type Argument struct {
 	Type                string
 	EtcdUpgrade         bool
 	WithBuildx          bool
+	Resume              bool
+	DryRun              bool
+	ReportPath          string
+	NoRollback          bool
+	EtcdSnapshot        string
+	EtcdOutput          string
+	EtcdStatusOnly      bool
+	EtcdSchedule        string
+	CertsComponents     []string
+	CertsCAs            []string
+	RotateCAUntil       string
 }
 
 func NewKubeRuntime(flag string, arg Argument) (*KubeRuntime, error) {
@@ -68,11 +85,20 @@ func NewKubeRuntime(flag string, arg Argument) (*KubeRuntime, error) {
 		return nil, err
 	}
 
-	base := connector.NewBaseRuntime(cluster.Name, connector.NewDialer(), arg.Debug, arg.IgnoreErr)
+	var dialer connector.Connector = connector.NewDialer()
+	if arg.DryRun {
+		dialer = connector.NewDryRunDialer()
+	}
+	base := connector.NewBaseRuntime(cluster.Name, dialer, arg.Debug, arg.IgnoreErr)
 
 	clusterSpec := &cluster.Spec
 	defaultCluster, roleGroups := clusterSpec.SetDefaultClusterSpec()
 
+	// the robot account generated by "kk init registry"
+	if defaultCluster.Registry.Auths, err = registry.MergeRobotAuths(defaultCluster.Registry.Auths, base.GetWorkDir()); err != nil {
+		return nil, err
+	}
+
 	hostSet := make(map[string]struct{})
 	for _, role := range roleGroups {
 		for _, host := range role {
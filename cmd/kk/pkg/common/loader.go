@@ -263,6 +263,10 @@ func (f FileLoader) Load() (*kubekeyapiv1alpha2.Cluster, error) {
 		clusterCfg.Spec.Kubernetes.ContainerManager = f.arg.ContainerManager
 	}
 
+	if err := clusterCfg.Spec.Certificates.Validate(); err != nil {
+		return nil, errors.Wrap(err, "Invalid certificates configuration")
+	}
+
 	clusterCfg.Spec.Kubernetes.Version = normalizedBuildVersion(clusterCfg.Spec.Kubernetes.Version)
 	clusterCfg.Spec.KubeSphere.Version = normalizedBuildVersion(clusterCfg.Spec.KubeSphere.Version)
 	clusterCfg.Name = objName
@@ -23,6 +23,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/container/templates"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/action"
@@ -33,7 +34,6 @@ import (
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/files"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/images"
-	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/registry"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/utils"
 )
 
@@ -162,6 +162,7 @@ func (d *DisableContainerd) Execute(runtime connector.Runtime) error {
 		"/usr/bin/ctr",
 		filepath.Join("/etc/systemd/system", templates.ContainerdService.Name()),
 		filepath.Join("/etc/containerd", templates.ContainerdConfig.Name()),
+		ContainerdCertsDir,
 		filepath.Join("/etc", templates.CrictlConfig.Name()),
 	}
 	if d.KubeConf.Cluster.Registry.DataRoot != "" {
@@ -230,6 +231,10 @@ func (i *RestartCri) Execute(runtime connector.Runtime) error {
 		if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("systemctl daemon-reload && systemctl restart containerd"), true); err != nil {
 			return errors.Wrap(err, "restart containerd")
 		}
+	case common.Crio:
+		if _, err := runtime.GetRunner().SudoCmd("systemctl daemon-reload && systemctl restart crio", true); err != nil {
+			return errors.Wrap(err, "restart crio")
+		}
 
 	default:
 		logger.Log.Fatalf("Unsupported container runtime: %s", strings.TrimSpace(i.KubeConf.Arg.Type))
@@ -249,12 +254,19 @@ func (i *EditKubeletCri) Execute(runtime connector.Runtime) error {
 			true); err != nil {
 			return errors.Wrap(err, "Change KubeletTo Containerd failed")
 		}
-	case common.Containerd:
-		if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
-			"sed -i 's#--network-plugin=cni --pod#--network-plugin=cni --container-runtime=remote --container-runtime-endpoint=unix:///run/containerd/containerd.sock --pod#' /var/lib/kubelet/kubeadm-flags.env"),
+		if _, err := runtime.GetRunner().SudoCmd(
+			"sed -i 's#--container-runtime=remote --container-runtime-endpoint=unix:///var/run/crio/crio.sock --pod#--pod#' /var/lib/kubelet/kubeadm-flags.env",
 			true); err != nil {
+			return errors.Wrap(err, "Change KubeletTo Docker failed")
+		}
+	case common.Containerd:
+		if err := editKubeletCriEndpoint(runtime, kubekeyapiv1alpha2.DefaultContainerdEndpoint); err != nil {
 			return errors.Wrap(err, "Change KubeletTo Containerd failed")
 		}
+	case common.Crio:
+		if err := editKubeletCriEndpoint(runtime, kubekeyapiv1alpha2.DefaultCrioEndpoint); err != nil {
+			return errors.Wrap(err, "Change KubeletTo Crio failed")
+		}
 
 	default:
 		logger.Log.Fatalf("Unsupported container runtime: %s", strings.TrimSpace(i.KubeConf.Arg.Type))
@@ -262,6 +274,19 @@ func (i *EditKubeletCri) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+// editKubeletCriEndpoint points the kubelet to the endpoint of the CRI. The endpoint is replaced if the kubelet
+// uses a remote runtime, or it is added if the kubelet uses the dockershim.
+func editKubeletCriEndpoint(runtime connector.Runtime, endpoint string) error {
+	replaceCmd := fmt.Sprintf("sed -i 's#--container-runtime-endpoint=unix://[^ ]*\\.sock#--container-runtime-endpoint=%s#' %s",
+		endpoint, "/var/lib/kubelet/kubeadm-flags.env")
+	addCmd := fmt.Sprintf("sed -i 's#--network-plugin=cni --pod#--network-plugin=cni --container-runtime=remote --container-runtime-endpoint=%s --pod#' %s",
+		endpoint, "/var/lib/kubelet/kubeadm-flags.env")
+	if _, err := runtime.GetRunner().SudoCmd(replaceCmd+" && "+addCmd, true); err != nil {
+		return err
+	}
+	return nil
+}
+
 type RestartKubeletNode struct {
 	common.KubeAction
 }
@@ -345,6 +370,18 @@ func MigrateSelfNodeCriTasks(runtime connector.Runtime, kubeAction common.KubeAc
 			Parallel: false,
 		}
 		tasks = append(tasks, CordonNode, DrainNode, Uninstall)
+	case common.Crio:
+		Uninstall := &task.RemoteTask{
+			Name:  "UninstallCrio",
+			Desc:  "Uninstall cri-o",
+			Hosts: []connector.Host{host},
+			Prepare: &prepare.PrepareCollection{
+				&CrioExist{Not: false},
+			},
+			Action:   new(DisableCrio),
+			Parallel: false,
+		}
+		tasks = append(tasks, CordonNode, DrainNode, Uninstall)
 	}
 	if kubeAction.KubeConf.Arg.Type == common.Docker {
 		syncBinaries := &task.RemoteTask{
@@ -465,17 +502,22 @@ func MigrateSelfNodeCriTasks(runtime connector.Runtime, kubeAction common.KubeAc
 			Action: &action.Template{
 				Template: templates.ContainerdConfig,
 				Dst:      filepath.Join("/etc/containerd/", templates.ContainerdConfig.Name()),
-				Data: util.Data{
-					"Mirrors":            templates.Mirrors(kubeAction.KubeConf),
-					"InsecureRegistries": kubeAction.KubeConf.Cluster.Registry.InsecureRegistries,
-					"SandBoxImage":       images.GetImage(runtime, kubeAction.KubeConf, "pause").ImageName(),
-					"Auths":              registry.DockerRegistryAuthEntries(kubeAction.KubeConf.Cluster.Registry.Auths),
-					"DataRoot":           templates.DataRoot(kubeAction.KubeConf),
-				},
+				Data:     ContainerdConfigData(runtime, kubeAction.KubeConf),
 			},
 			Parallel: false,
 		}
 
+		generateContainerdRegistryHosts := &task.RemoteTask{
+			Name:  "GenerateContainerdRegistryHosts",
+			Desc:  "Generate containerd registry hosts",
+			Hosts: []connector.Host{host},
+			Prepare: &prepare.PrepareCollection{
+				&ContainerdExist{Not: true},
+			},
+			Action:   new(GenerateContainerdRegistryHosts),
+			Parallel: false,
+		}
+
 		generateCrictlConfig := &task.RemoteTask{
 			Name:  "GenerateCrictlConfig",
 			Desc:  "Generate crictl config",
@@ -504,13 +546,24 @@ func MigrateSelfNodeCriTasks(runtime connector.Runtime, kubeAction common.KubeAc
 			Parallel: false,
 		}
 		tasks = append(tasks, syncContainerd, syncCrictlBinaries, generateContainerdService, generateContainerdConfig,
-			generateCrictlConfig, enableContainerd, RestartCri, EditKubeletCri, RestartKubeletNode, UnCordonNode)
+			generateContainerdRegistryHosts, generateCrictlConfig, enableContainerd, RestartCri, EditKubeletCri,
+			RestartKubeletNode, UnCordonNode)
+	}
+	if kubeAction.KubeConf.Arg.Type == common.Crio {
+		// crictl is pointed to cri-o instead of the endpoint of the runtime being replaced
+		kubeConf := *kubeAction.KubeConf
+		cluster := *kubeConf.Cluster
+		cluster.Kubernetes.ContainerRuntimeEndpoint = kubekeyapiv1alpha2.DefaultCrioEndpoint
+		kubeConf.Cluster = &cluster
+		tasks = append(tasks, InstallCrioTasks(&kubeConf, images.GetImage(runtime, kubeAction.KubeConf, "pause").ImageName(),
+			[]connector.Host{host}, true)...)
+		tasks = append(tasks, RestartCri, EditKubeletCri, RestartKubeletNode, UnCordonNode)
 	}
 
 	for i := range tasks {
 		t := tasks[i]
 		t.Init(runtime, kubeAction.ModuleCache, kubeAction.PipelineCache)
-		if res := t.Execute(); res.IsFailed() {
+		if res := t.Execute(runtime.GetRunner().Context()); res.IsFailed() {
 			return res.CombineErr()
 		}
 	}
@@ -0,0 +1,270 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package container
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/container/templates"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/action"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/images"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/registry"
+)
+
+// ContainerdCertsDir is the config_path of containerd, every registry has a directory with its hosts.toml there.
+const ContainerdCertsDir = "/etc/containerd/certs.d"
+
+var (
+	serverCapabilities = []string{"pull", "resolve", "push"}
+	mirrorCapabilities = []string{"pull", "resolve"}
+)
+
+// ContainerdHost is the server or a host of a registry in hosts.toml.
+type ContainerdHost struct {
+	URL          string
+	Capabilities []string
+	SkipVerify   bool
+	// CA, Cert and Key are the files on the nodes, they are synced from the local files of the registry auths.
+	CA   string
+	Cert string
+	Key  string
+
+	localCA   string
+	localCert string
+	localKey  string
+}
+
+// ContainerdRegistryHosts is the hosts.toml of a registry.
+type ContainerdRegistryHosts struct {
+	Registry string
+	Server   ContainerdHost
+	Hosts    []ContainerdHost
+}
+
+// Dir returns the directory of the hosts.toml on the nodes.
+func (r *ContainerdRegistryHosts) Dir() string {
+	return filepath.Join(ContainerdCertsDir, r.Registry)
+}
+
+// ContainerdRegistries returns the hosts.toml of the registries in the config: docker.io with the registry mirrors,
+// the private registry, the insecure registries and the registries given in the auths. The TLS settings and the
+// capabilities of a mirror or a registry are taken from its entry in the auths.
+func ContainerdRegistries(kubeConf *common.KubeConf) []*ContainerdRegistryHosts {
+	entries := registry.DockerRegistryAuthEntries(kubeConf.Cluster.Registry.Auths)
+	insecure := make(map[string]bool)
+	for _, r := range kubeConf.Cluster.Registry.InsecureRegistries {
+		insecure[registryHost(r)] = true
+	}
+
+	registries := make(map[string]*ContainerdRegistryHosts)
+	get := func(name string) *ContainerdRegistryHosts {
+		if r, ok := registries[name]; ok {
+			return r
+		}
+		url := "https://" + name
+		if name == "docker.io" {
+			url = "https://registry-1.docker.io"
+		}
+		r := &ContainerdRegistryHosts{Registry: name}
+		r.Server = newContainerdHost(entries, name, url, serverCapabilities)
+		registries[name] = r
+		return r
+	}
+
+	dockerHub := get("docker.io")
+	for _, mirror := range kubeConf.Cluster.Registry.RegistryMirrors {
+		if registryHost(mirror) == "" {
+			continue
+		}
+		host := newContainerdHost(entries, dockerHub.Registry, mirror, mirrorCapabilities)
+		if insecure[registryHost(mirror)] {
+			host.SkipVerify = true
+		}
+		dockerHub.Hosts = append(dockerHub.Hosts, host)
+	}
+
+	if name := registryHost(kubeConf.Cluster.Registry.PrivateRegistry); name != "" {
+		get(name)
+	}
+	for repo := range entries {
+		if name := registryHost(repo); name != "" {
+			get(name)
+		}
+	}
+	for name := range insecure {
+		if name == "" {
+			continue
+		}
+		r := get(name)
+		r.Server.SkipVerify = true
+		// the registry is tried by plain http first as docker does for the insecure registries
+		if !strings.HasPrefix(r.Server.URL, "http://") {
+			r.Hosts = append([]ContainerdHost{{URL: "http://" + name, Capabilities: r.Server.Capabilities}}, r.Hosts...)
+		}
+	}
+
+	names := make([]string, 0, len(registries))
+	for name := range registries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res := make([]*ContainerdRegistryHosts, 0, len(names))
+	for _, name := range names {
+		res = append(res, registries[name])
+	}
+	return res
+}
+
+// newContainerdHost returns the host of the url in the directory of the registry, with the settings of its entry in
+// the auths.
+func newContainerdHost(entries map[string]*registry.DockerRegistryEntry, registryName, url string, capabilities []string) ContainerdHost {
+	name := registryHost(url)
+	entry, ok := entries[name]
+	if !ok {
+		entry = &registry.DockerRegistryEntry{}
+	}
+
+	if !strings.Contains(url, "://") {
+		url = "https://" + url
+	}
+	if entry.PlainHTTP {
+		url = "http://" + strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	}
+	host := ContainerdHost{
+		URL:          strings.TrimSuffix(url, "/"),
+		Capabilities: capabilities,
+		SkipVerify:   entry.SkipTLSVerify,
+	}
+	if len(entry.Capabilities) != 0 {
+		host.Capabilities = entry.Capabilities
+	}
+
+	dir := filepath.Join(ContainerdCertsDir, registryName)
+	if entry.CAFile != "" {
+		host.localCA = entry.CAFile
+		host.CA = filepath.Join(dir, name+".crt")
+	}
+	if entry.CertFile != "" && entry.KeyFile != "" {
+		host.localCert, host.localKey = entry.CertFile, entry.KeyFile
+		host.Cert = filepath.Join(dir, name+".cert")
+		host.Key = filepath.Join(dir, name+".key")
+	}
+	return host
+}
+
+// registryHost returns the host of the registry without the scheme and the path.
+func registryHost(registry string) string {
+	host, _ := trimScheme(registry)
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+	return host
+}
+
+// ContainerdConfigData returns the data of the config.toml of containerd.
+func ContainerdConfigData(runtime connector.ModuleRuntime, kubeConf *common.KubeConf) util.Data {
+	return util.Data{
+		"SandBoxImage": images.GetImage(runtime, kubeConf, "pause").ImageName(),
+		"Auths":        registry.DockerRegistryAuthEntries(kubeConf.Cluster.Registry.Auths),
+		"DataRoot":     templates.DataRoot(kubeConf),
+		"ConfigPath":   ContainerdCertsDir,
+	}
+}
+
+type GenerateContainerdRegistryHosts struct {
+	common.KubeAction
+}
+
+func (g *GenerateContainerdRegistryHosts) Execute(runtime connector.Runtime) error {
+	// the registries removed from the config are dropped with the other hosts.toml generated before
+	cleanCmd := fmt.Sprintf("grep -lsF '%s' %s/*/hosts.toml | xargs -r -n1 dirname | xargs -r rm -rf",
+		templates.ContainerdHostsHeader, ContainerdCertsDir)
+	if _, err := runtime.GetRunner().SudoCmd(cleanCmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "remove the hosts of the registries failed")
+	}
+
+	for _, r := range ContainerdRegistries(g.KubeConf) {
+		for _, host := range append([]ContainerdHost{r.Server}, r.Hosts...) {
+			for _, file := range [][2]string{{host.localCA, host.CA}, {host.localCert, host.Cert}, {host.localKey, host.Key}} {
+				if file[0] == "" {
+					continue
+				}
+				if err := runtime.GetRunner().SudoScp(file[0], file[1]); err != nil {
+					return errors.Wrapf(errors.WithStack(err), "sync %s of the registry %s failed", file[0], r.Registry)
+				}
+			}
+		}
+
+		templateAction := action.Template{
+			Template: templates.ContainerdHosts,
+			Dst:      filepath.Join(r.Dir(), templates.ContainerdHosts.Name()),
+			Data: util.Data{
+				"Header": templates.ContainerdHostsHeader,
+				"Server": r.Server,
+				"Hosts":  r.Hosts,
+			},
+		}
+		templateAction.Init(nil, nil)
+		if err := templateAction.Execute(runtime); err != nil {
+			return errors.Wrapf(err, "generate the hosts of the registry %s failed", r.Registry)
+		}
+	}
+	return nil
+}
+
+// ReloadContainerdRegistries re-renders config.toml and restarts containerd only if the auths in it are changed,
+// the hosts.toml files are read by containerd on every pull.
+type ReloadContainerdRegistries struct {
+	common.KubeAction
+}
+
+func (r *ReloadContainerdRegistries) Execute(runtime connector.Runtime) error {
+	config := filepath.Join("/etc/containerd", templates.ContainerdConfig.Name())
+	templateAction := action.Template{
+		Template: templates.ContainerdConfig,
+		Dst:      config + ".kubekey",
+		Data:     ContainerdConfigData(runtime, r.KubeConf),
+	}
+	templateAction.Init(nil, nil)
+	if err := templateAction.Execute(runtime); err != nil {
+		return err
+	}
+
+	output, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"if cmp -s %s.kubekey %s; then rm -f %s.kubekey; "+
+			"else mv -f %s.kubekey %s && echo 'changed'; fi", config, config, config, config, config), false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "update containerd config failed")
+	}
+	if !strings.Contains(output, "changed") {
+		return nil
+	}
+
+	// the containers are kept running by their shims while containerd is restarting
+	if _, err := runtime.GetRunner().SudoCmd("systemctl restart containerd", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "restart containerd failed")
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package container
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/container/templates"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/registry"
+)
+
+func TestContainerdRegistries(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry kubekeyapiv1alpha2.RegistryConfig
+		want     []*ContainerdRegistryHosts
+	}{
+		{
+			name: "default",
+			want: []*ContainerdRegistryHosts{
+				{Registry: "docker.io", Server: ContainerdHost{URL: "https://registry-1.docker.io", Capabilities: serverCapabilities}},
+			},
+		},
+		{
+			name: "mirrors, private and insecure registries",
+			registry: kubekeyapiv1alpha2.RegistryConfig{
+				RegistryMirrors:    []string{"https://mirror.example.com/", "http://10.0.0.1:5000"},
+				InsecureRegistries: []string{"harbor.example.com"},
+				PrivateRegistry:    "dockerhub.kubekey.local/kubesphere",
+				Auths: runtime.RawExtension{Raw: []byte(`{
+  "mirror.example.com": {"username": "admin", "caFile": "/tmp/mirror/ca.crt", "certFile": "/tmp/mirror/client.cert", "keyFile": "/tmp/mirror/client.key", "capabilities": ["pull"]},
+  "dockerhub.kubekey.local": {"skipTLSVerify": true},
+  "plain.local": {"plainHTTP": true}
+}`)},
+			},
+			want: []*ContainerdRegistryHosts{
+				{
+					Registry: "docker.io",
+					Server:   ContainerdHost{URL: "https://registry-1.docker.io", Capabilities: serverCapabilities},
+					Hosts: []ContainerdHost{
+						{
+							URL:          "https://mirror.example.com",
+							Capabilities: []string{"pull"},
+							CA:           "/etc/containerd/certs.d/docker.io/mirror.example.com.crt",
+							Cert:         "/etc/containerd/certs.d/docker.io/mirror.example.com.cert",
+							Key:          "/etc/containerd/certs.d/docker.io/mirror.example.com.key",
+							localCA:      "/tmp/mirror/ca.crt",
+							localCert:    "/tmp/mirror/client.cert",
+							localKey:     "/tmp/mirror/client.key",
+						},
+						{URL: "http://10.0.0.1:5000", Capabilities: mirrorCapabilities},
+					},
+				},
+				{
+					Registry: "dockerhub.kubekey.local",
+					Server:   ContainerdHost{URL: "https://dockerhub.kubekey.local", Capabilities: serverCapabilities, SkipVerify: true},
+				},
+				{
+					Registry: "harbor.example.com",
+					Server:   ContainerdHost{URL: "https://harbor.example.com", Capabilities: serverCapabilities, SkipVerify: true},
+					Hosts:    []ContainerdHost{{URL: "http://harbor.example.com", Capabilities: serverCapabilities}},
+				},
+				{
+					Registry: "mirror.example.com",
+					Server: ContainerdHost{
+						URL:          "https://mirror.example.com",
+						Capabilities: []string{"pull"},
+						CA:           "/etc/containerd/certs.d/mirror.example.com/mirror.example.com.crt",
+						Cert:         "/etc/containerd/certs.d/mirror.example.com/mirror.example.com.cert",
+						Key:          "/etc/containerd/certs.d/mirror.example.com/mirror.example.com.key",
+						localCA:      "/tmp/mirror/ca.crt",
+						localCert:    "/tmp/mirror/client.cert",
+						localKey:     "/tmp/mirror/client.key",
+					},
+				},
+				{
+					Registry: "plain.local",
+					Server:   ContainerdHost{URL: "http://plain.local", Capabilities: serverCapabilities, SkipVerify: true},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeConf := &common.KubeConf{Cluster: &kubekeyapiv1alpha2.ClusterSpec{Registry: tt.registry}}
+			if got := ContainerdRegistries(kubeConf); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ContainerdRegistries() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainerdHostsTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	err := templates.ContainerdHosts.Execute(&buf, map[string]interface{}{
+		"Header": templates.ContainerdHostsHeader,
+		"Server": ContainerdHost{URL: "https://harbor.example.com", Capabilities: serverCapabilities, SkipVerify: true},
+		"Hosts": []ContainerdHost{
+			{URL: "http://harbor.example.com", Capabilities: serverCapabilities},
+			{URL: "https://mirror.example.com", Capabilities: mirrorCapabilities, CA: "/etc/ca.crt", Cert: "/etc/c.cert", Key: "/etc/c.key"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := templates.ContainerdHostsHeader + `
+server = "https://harbor.example.com"
+capabilities = ["pull", "resolve", "push"]
+skip_verify = true
+
+[host."http://harbor.example.com"]
+  capabilities = ["pull", "resolve", "push"]
+
+[host."https://mirror.example.com"]
+  capabilities = ["pull", "resolve"]
+  ca = "/etc/ca.crt"
+  client = [["/etc/c.cert", "/etc/c.key"]]
+`
+	if got := strings.TrimRight(buf.String(), " \n") + "\n"; got != want {
+		t.Errorf("hosts.toml = \n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestContainerdConfigTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	auths := runtime.RawExtension{Raw: []byte(`{"harbor.example.com": {"username": "admin", "password": "Harbor12345"}, "plain.local": {"plainHTTP": true}}`)}
+	err := templates.ContainerdConfig.Execute(&buf, map[string]interface{}{
+		"SandBoxImage": "kubesphere/pause:3.9",
+		"Auths":        registry.DockerRegistryAuthEntries(auths),
+		"ConfigPath":   ContainerdCertsDir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := buf.String()
+	if !strings.Contains(got, `config_path = "/etc/containerd/certs.d"`) ||
+		!strings.Contains(got, `registry.configs."harbor.example.com".auth]`) ||
+		strings.Contains(got, "plain.local") || strings.Contains(got, "registry.mirrors") {
+		t.Errorf("unexpected config.toml:\n%s", got)
+	}
+}
@@ -0,0 +1,365 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package container
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/container/templates"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/action"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/prepare"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/task"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/files"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/kubernetes"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/registry"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/utils"
+)
+
+const (
+	// CrioAuthFile is the auth file of the private registries given in the config.
+	CrioAuthFile = "/etc/crio/auth.json"
+	// crioRuntimeDir keeps the OCI runtimes of the CRI-O bundle apart from the runc installed for containerd.
+	crioRuntimeDir = "/usr/libexec/crio"
+)
+
+// CrioRegistry is a registry in the registries.conf of CRI-O.
+type CrioRegistry struct {
+	Prefix   string
+	Location string
+	Insecure bool
+	Mirrors  []CrioMirror
+}
+
+// CrioMirror is a mirror of a registry in the registries.conf of CRI-O.
+type CrioMirror struct {
+	Location string
+	Insecure bool
+}
+
+// CrioRegistries returns the registries pulled by CRI-O: docker.io with the registry mirrors, the insecure
+// registries, and the private registries whose TLS verification is skipped.
+func CrioRegistries(kubeConf *common.KubeConf) []CrioRegistry {
+	dockerHub := CrioRegistry{Prefix: "docker.io", Location: "docker.io"}
+	for _, mirror := range kubeConf.Cluster.Registry.RegistryMirrors {
+		location, insecure := trimScheme(mirror)
+		if location == "" {
+			continue
+		}
+		dockerHub.Mirrors = append(dockerHub.Mirrors, CrioMirror{Location: location, Insecure: insecure})
+	}
+	res := []CrioRegistry{dockerHub}
+
+	insecure := make(map[string]bool)
+	for _, r := range kubeConf.Cluster.Registry.InsecureRegistries {
+		location, _ := trimScheme(r)
+		insecure[location] = true
+	}
+	for repo, entry := range registry.DockerRegistryAuthEntries(kubeConf.Cluster.Registry.Auths) {
+		if entry.SkipTLSVerify {
+			insecure[repo] = true
+		}
+	}
+	delete(insecure, "")
+
+	names := make([]string, 0, len(insecure))
+	for name := range insecure {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name == dockerHub.Prefix {
+			res[0].Insecure = true
+			continue
+		}
+		res = append(res, CrioRegistry{Prefix: name, Location: name, Insecure: true})
+	}
+	return res
+}
+
+// trimScheme returns the location of the registry without the scheme, which is insecure if it is plain http.
+func trimScheme(registry string) (string, bool) {
+	registry = strings.TrimSuffix(strings.TrimSpace(registry), "/")
+	if strings.HasPrefix(registry, "http://") {
+		return strings.TrimPrefix(registry, "http://"), true
+	}
+	return strings.TrimPrefix(registry, "https://"), false
+}
+
+// CrioAuths returns the containers-auth.json of the private registries, it is empty if no registry has a user.
+func CrioAuths(kubeConf *common.KubeConf) string {
+	auths := make(map[string]map[string]string)
+	for repo, entry := range registry.DockerRegistryAuthEntries(kubeConf.Cluster.Registry.Auths) {
+		if entry.Username == "" && entry.Password == "" {
+			continue
+		}
+		auth := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", entry.Username, entry.Password)))
+		auths[repo] = map[string]string{"auth": auth}
+	}
+	if len(auths) == 0 {
+		return ""
+	}
+	// a map of strings is always marshaled
+	data, _ := json.MarshalIndent(map[string]interface{}{"auths": auths}, "", "  ")
+	return string(data)
+}
+
+// InstallCrioTasks returns the tasks installing CRI-O and crictl on the hosts. The nodes in the cluster are
+// skipped unless the container runtime of them is being migrated.
+func InstallCrioTasks(kubeConf *common.KubeConf, sandboxImage string, hosts []connector.Host, migrate bool) []task.Interface {
+	prepares := func(p ...prepare.Prepare) *prepare.PrepareCollection {
+		c := prepare.PrepareCollection{}
+		if !migrate {
+			c = append(c, &kubernetes.NodeInCluster{Not: true})
+		}
+		c = append(c, p...)
+		return &c
+	}
+
+	auths := CrioAuths(kubeConf)
+	authFile := ""
+	if auths != "" {
+		authFile = CrioAuthFile
+	}
+
+	syncCrio := &task.RemoteTask{
+		Name:     "SyncCrio",
+		Desc:     "Sync cri-o binaries",
+		Hosts:    hosts,
+		Prepare:  prepares(&CrioExist{Not: true}),
+		Action:   new(SyncCrio),
+		Parallel: !migrate,
+		Retry:    2,
+	}
+
+	generateCrioService := &task.RemoteTask{
+		Name:    "GenerateCrioService",
+		Desc:    "Generate cri-o service",
+		Hosts:   hosts,
+		Prepare: prepares(&CrioExist{Not: true}),
+		Action: &action.Template{
+			Template: templates.CrioService,
+			Dst:      filepath.Join("/etc/systemd/system", templates.CrioService.Name()),
+		},
+		Parallel: !migrate,
+	}
+
+	generateCrioConfig := &task.RemoteTask{
+		Name:    "GenerateCrioConfig",
+		Desc:    "Generate cri-o config",
+		Hosts:   hosts,
+		Prepare: prepares(&CrioExist{Not: true}),
+		Action: &action.Template{
+			Template: templates.CrioConfig,
+			Dst:      filepath.Join("/etc/crio", templates.CrioConfig.Name()),
+			Data: util.Data{
+				"DataRoot":     templates.DataRoot(kubeConf),
+				"SandBoxImage": sandboxImage,
+				"AuthFile":     authFile,
+			},
+		},
+		Parallel: !migrate,
+	}
+
+	generateCrioRegistries := &task.RemoteTask{
+		Name:    "GenerateCrioRegistries",
+		Desc:    "Generate cri-o registries config",
+		Hosts:   hosts,
+		Prepare: prepares(&CrioExist{Not: true}),
+		Action: &action.Template{
+			Template: templates.CrioRegistries,
+			Dst:      filepath.Join("/etc/containers", templates.CrioRegistries.Name()),
+			Data: util.Data{
+				"Registries": CrioRegistries(kubeConf),
+			},
+		},
+		Parallel: !migrate,
+	}
+
+	generateCrioPolicy := &task.RemoteTask{
+		Name:    "GenerateCrioPolicy",
+		Desc:    "Generate cri-o image policy",
+		Hosts:   hosts,
+		Prepare: prepares(&CrioExist{Not: true}),
+		Action: &action.Template{
+			Template: templates.CrioPolicy,
+			Dst:      filepath.Join("/etc/containers", templates.CrioPolicy.Name()),
+		},
+		Parallel: !migrate,
+	}
+
+	generateCrioAuth := &task.RemoteTask{
+		Name:    "GenerateCrioAuth",
+		Desc:    "Add auths to container runtime",
+		Hosts:   hosts,
+		Prepare: prepares(&CrioExist{Not: true}),
+		Action: &action.Template{
+			Template: templates.CrioAuth,
+			Dst:      CrioAuthFile,
+			Data: util.Data{
+				"Auths": auths,
+			},
+		},
+		Parallel: !migrate,
+	}
+
+	enableCrio := &task.RemoteTask{
+		Name:     "EnableCrio",
+		Desc:     "Enable cri-o",
+		Hosts:    hosts,
+		Prepare:  prepares(&CrioExist{Not: true}),
+		Action:   new(EnableCrio),
+		Parallel: !migrate,
+	}
+
+	syncCrictlBinaries := &task.RemoteTask{
+		Name:     "SyncCrictlBinaries",
+		Desc:     "Sync crictl binaries",
+		Hosts:    hosts,
+		Prepare:  prepares(&CrictlExist{Not: true}),
+		Action:   new(SyncCrictlBinaries),
+		Parallel: !migrate,
+		Retry:    2,
+	}
+
+	generateCrictlConfig := &task.RemoteTask{
+		Name:    "GenerateCrictlConfig",
+		Desc:    "Generate crictl config",
+		Hosts:   hosts,
+		Prepare: prepares(&CrictlExist{}),
+		Action: &action.Template{
+			Template: templates.CrictlConfig,
+			Dst:      filepath.Join("/etc/", templates.CrictlConfig.Name()),
+			Data: util.Data{
+				"Endpoint": kubeConf.Cluster.Kubernetes.ContainerRuntimeEndpoint,
+			},
+		},
+		Parallel: !migrate,
+	}
+
+	tasks := []task.Interface{
+		syncCrio,
+		generateCrioService,
+		generateCrioConfig,
+		generateCrioRegistries,
+		generateCrioPolicy,
+	}
+	if authFile != "" {
+		tasks = append(tasks, generateCrioAuth)
+	}
+	return append(tasks,
+		enableCrio,
+		syncCrictlBinaries,
+		generateCrictlConfig,
+	)
+}
+
+type SyncCrio struct {
+	common.KubeAction
+}
+
+func (s *SyncCrio) Execute(runtime connector.Runtime) error {
+	if err := utils.ResetTmpDir(runtime); err != nil {
+		return err
+	}
+
+	binariesMapObj, ok := s.PipelineCache.Get(common.KubeBinaries + "-" + runtime.RemoteHost().GetArch())
+	if !ok {
+		return errors.New("get KubeBinary by pipeline cache failed")
+	}
+	binariesMap := binariesMapObj.(map[string]*files.KubeBinary)
+
+	crio, ok := binariesMap[common.Crio]
+	if !ok {
+		return errors.New("get KubeBinary key crio by pipeline cache failed")
+	}
+
+	dst := filepath.Join(common.TmpDir, crio.FileName)
+	if err := runtime.GetRunner().Scp(crio.Path(), dst); err != nil {
+		return errors.Wrap(errors.WithStack(err), "sync cri-o binaries failed")
+	}
+
+	// crictl is installed from its own release, like the other container runtimes
+	installCmd := fmt.Sprintf("cd %s && tar -zxf %s && "+
+		"install -m 755 cri-o/bin/crio cri-o/bin/pinns cri-o/bin/conmon /usr/bin && "+
+		"mkdir -p %s && install -m 755 cri-o/bin/runc cri-o/bin/crun %s && rm -rf cri-o",
+		common.TmpDir, dst, crioRuntimeDir, crioRuntimeDir)
+	if _, err := runtime.GetRunner().SudoCmd(installCmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "install cri-o binaries failed")
+	}
+	return nil
+}
+
+type EnableCrio struct {
+	common.KubeAction
+}
+
+func (e *EnableCrio) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(
+		"systemctl daemon-reload && systemctl enable crio && systemctl start crio",
+		false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "enable and start cri-o failed")
+	}
+	return nil
+}
+
+type DisableCrio struct {
+	common.KubeAction
+}
+
+func (d *DisableCrio) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(
+		"systemctl disable crio && systemctl stop crio", true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "disable and stop cri-o failed")
+	}
+
+	// remove cri-o related files
+	files := []string{
+		"/usr/bin/crio",
+		"/usr/bin/pinns",
+		"/usr/bin/conmon",
+		"/usr/bin/crictl",
+		crioRuntimeDir,
+		"/etc/crio",
+		filepath.Join("/etc/systemd/system", templates.CrioService.Name()),
+		filepath.Join("/etc/containers", templates.CrioRegistries.Name()),
+		filepath.Join("/etc/containers", templates.CrioPolicy.Name()),
+		filepath.Join("/etc", templates.CrictlConfig.Name()),
+		"/var/lib/crio",
+		"/var/run/crio",
+		"/var/run/containers/storage",
+	}
+	if d.KubeConf.Cluster.Registry.DataRoot != "" {
+		files = append(files, d.KubeConf.Cluster.Registry.DataRoot)
+	} else {
+		files = append(files, "/var/lib/containers/storage")
+	}
+
+	for _, file := range files {
+		_, _ = runtime.GetRunner().SudoCmd(fmt.Sprintf("rm -rf %s", file), true)
+	}
+	return nil
+}
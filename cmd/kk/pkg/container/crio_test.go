@@ -0,0 +1,106 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package container
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/container/templates"
+)
+
+func TestCrioRegistries(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry kubekeyapiv1alpha2.RegistryConfig
+		want     []CrioRegistry
+	}{
+		{
+			name: "default",
+			want: []CrioRegistry{{Prefix: "docker.io", Location: "docker.io"}},
+		},
+		{
+			name: "mirrors and insecure registries",
+			registry: kubekeyapiv1alpha2.RegistryConfig{
+				RegistryMirrors:    []string{"https://mirror.example.com/", "http://10.0.0.1:5000"},
+				InsecureRegistries: []string{"harbor.example.com", "http://docker.io"},
+				Auths:              runtime.RawExtension{Raw: []byte(`{"dockerhub.kubekey.local": {"skipTLSVerify": true}}`)},
+			},
+			want: []CrioRegistry{
+				{Prefix: "docker.io", Location: "docker.io", Insecure: true, Mirrors: []CrioMirror{
+					{Location: "mirror.example.com"},
+					{Location: "10.0.0.1:5000", Insecure: true},
+				}},
+				{Prefix: "dockerhub.kubekey.local", Location: "dockerhub.kubekey.local", Insecure: true},
+				{Prefix: "harbor.example.com", Location: "harbor.example.com", Insecure: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeConf := &common.KubeConf{Cluster: &kubekeyapiv1alpha2.ClusterSpec{Registry: tt.registry}}
+			if got := CrioRegistries(kubeConf); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CrioRegistries() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrioAuths(t *testing.T) {
+	kubeConf := &common.KubeConf{Cluster: &kubekeyapiv1alpha2.ClusterSpec{}}
+	if got := CrioAuths(kubeConf); got != "" {
+		t.Errorf("CrioAuths() = %q, want empty", got)
+	}
+
+	kubeConf.Cluster.Registry.Auths = runtime.RawExtension{Raw: []byte(
+		`{"dockerhub.kubekey.local": {"username": "admin", "password": "Harbor12345"}, "insecure.local": {"plainHTTP": true}}`)}
+	got := CrioAuths(kubeConf)
+	// admin:Harbor12345
+	if !strings.Contains(got, `"dockerhub.kubekey.local": {`) || !strings.Contains(got, `"auth": "YWRtaW46SGFyYm9yMTIzNDU="`) ||
+		strings.Contains(got, "insecure.local") {
+		t.Errorf("CrioAuths() = %s", got)
+	}
+}
+
+func TestCrioRegistriesTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	err := templates.CrioRegistries.Execute(&buf, map[string]interface{}{
+		"Registries": []CrioRegistry{
+			{Prefix: "docker.io", Location: "docker.io", Mirrors: []CrioMirror{{Location: "mirror.example.com"}}},
+			{Prefix: "harbor.example.com", Location: "harbor.example.com", Insecure: true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"[[registry]]\nprefix = \"docker.io\"\nlocation = \"docker.io\"\ninsecure = false\n\n" +
+			"[[registry.mirror]]\nlocation = \"mirror.example.com\"\ninsecure = false\n",
+		"[[registry]]\nprefix = \"harbor.example.com\"\nlocation = \"harbor.example.com\"\ninsecure = true\n",
+	}
+	for _, w := range want {
+		if !strings.Contains(buf.String(), w) {
+			t.Errorf("registries.conf does not contain %q:\n%s", w, buf.String())
+		}
+	}
+}
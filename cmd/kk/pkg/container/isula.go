@@ -0,0 +1,188 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package container
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	versionutil "k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/container/templates"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/registry"
+)
+
+const (
+	// isulaPackage is the package of iSulad in the repositories of openEuler.
+	isulaPackage = "iSulad"
+	// isulaConfigDir is where the daemon.json of iSulad is placed.
+	isulaConfigDir = "/etc/isulad"
+)
+
+// IsulaMirrors returns the registry mirrors of iSulad. iSulad only accepts the hosts of the registries and tries
+// them in order, so docker.io is kept as the last one.
+func IsulaMirrors(kubeConf *common.KubeConf) []string {
+	var mirrors []string
+	for _, mirror := range kubeConf.Cluster.Registry.RegistryMirrors {
+		location, _ := trimScheme(mirror)
+		if location == "" || location == "docker.io" {
+			continue
+		}
+		mirrors = append(mirrors, location)
+	}
+	return append(mirrors, "docker.io")
+}
+
+// IsulaInsecureRegistries returns the registries iSulad connects without TLS verification: the insecure
+// registries, the mirrors served by plain http, and the private registries whose TLS verification is skipped.
+func IsulaInsecureRegistries(kubeConf *common.KubeConf) []string {
+	insecure := make(map[string]bool)
+	for _, r := range kubeConf.Cluster.Registry.InsecureRegistries {
+		location, _ := trimScheme(r)
+		insecure[location] = true
+	}
+	for _, mirror := range kubeConf.Cluster.Registry.RegistryMirrors {
+		if location, plainHTTP := trimScheme(mirror); plainHTTP {
+			insecure[location] = true
+		}
+	}
+	for repo, entry := range registry.DockerRegistryAuthEntries(kubeConf.Cluster.Registry.Auths) {
+		if entry.SkipTLSVerify || entry.PlainHTTP {
+			insecure[repo] = true
+		}
+	}
+	delete(insecure, "")
+
+	res := make([]string, 0, len(insecure))
+	for name := range insecure {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// IsulaEnableCRIV1 reports whether the CRI v1 services of iSulad are needed, kubelet talks CRI v1 since v1.23 and
+// drops v1alpha2 in v1.26.
+func IsulaEnableCRIV1(kubeConf *common.KubeConf) bool {
+	v, err := versionutil.ParseGeneric(kubeConf.Cluster.Kubernetes.Version)
+	if err != nil {
+		return true
+	}
+	return v.AtLeast(versionutil.MustParseGeneric("v1.23.0"))
+}
+
+func quoteJoin(items []string) string {
+	quoted := make([]string, 0, len(items))
+	for _, item := range items {
+		quoted = append(quoted, fmt.Sprintf("\"%s\"", item))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+type InstallIsulad struct {
+	common.KubeAction
+}
+
+func (i *InstallIsulad) Execute(runtime connector.Runtime) error {
+	// the package is installed from the local repository of the artifact by the RepositoryModule if it is given
+	if output, err := runtime.GetRunner().SudoCmd("command -v isulad", false); err == nil && strings.TrimSpace(output) != "" {
+		return nil
+	}
+
+	output, err := runtime.GetRunner().SudoCmd("command -v dnf || command -v yum", false)
+	if err != nil || strings.TrimSpace(output) == "" {
+		return errors.Errorf("iSulad is not installed and no rpm package manager is found on %s, "+
+			"install the %s package before creating the cluster", runtime.RemoteHost().GetName(), isulaPackage)
+	}
+	installCmd := fmt.Sprintf("%s install -y %s", strings.TrimSpace(output), isulaPackage)
+	if _, err := runtime.GetRunner().SudoCmd(installCmd, false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "install %s from the repository failed", isulaPackage)
+	}
+	return nil
+}
+
+type EnableIsulad struct {
+	common.KubeAction
+}
+
+func (e *EnableIsulad) Execute(runtime connector.Runtime) error {
+	// the package may have started iSulad with its own daemon.json, so it is restarted
+	if _, err := runtime.GetRunner().SudoCmd(
+		"systemctl daemon-reload && systemctl enable isulad && systemctl restart isulad",
+		false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "enable and start isulad failed")
+	}
+	return nil
+}
+
+type IsulaLoginRegistry struct {
+	common.KubeAction
+}
+
+func (i *IsulaLoginRegistry) Execute(runtime connector.Runtime) error {
+	auths := registry.DockerRegistryAuthEntries(i.KubeConf.Cluster.Registry.Auths)
+
+	for repo, entry := range auths {
+		if len(entry.Username) == 0 || len(entry.Password) == 0 {
+			continue
+		}
+		cmd := fmt.Sprintf("isula login --username '%s' --password '%s' %s", escapeSpecialCharacters(entry.Username), escapeSpecialCharacters(entry.Password), repo)
+		if _, err := runtime.GetRunner().SudoCmd(cmd, false); err != nil {
+			return errors.Wrapf(err, "login registry %s failed", repo)
+		}
+	}
+	return nil
+}
+
+type DisableIsulad struct {
+	common.KubeAction
+}
+
+func (d *DisableIsulad) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd("systemctl disable isulad && systemctl stop isulad",
+		false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "disable and stop isulad failed")
+	}
+
+	removeCmd := fmt.Sprintf("if command -v dnf > /dev/null; then dnf remove -y %s; else yum remove -y %s; fi",
+		isulaPackage, isulaPackage)
+	if _, err := runtime.GetRunner().SudoCmd(removeCmd, false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "remove %s failed", isulaPackage)
+	}
+
+	// remove isulad related files
+	files := []string{
+		"/usr/bin/crictl",
+		isulaConfigDir,
+		filepath.Join("/etc", templates.CrictlConfig.Name()),
+		"/var/run/isulad",
+		"/var/lib/isulad",
+	}
+	if d.KubeConf.Cluster.Registry.DataRoot != "" {
+		files = append(files, d.KubeConf.Cluster.Registry.DataRoot)
+	}
+
+	for _, file := range files {
+		_, _ = runtime.GetRunner().SudoCmd(fmt.Sprintf("rm -rf %s", file), true)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package container
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/container/templates"
+)
+
+func TestIsulaRegistries(t *testing.T) {
+	tests := []struct {
+		name         string
+		registry     kubekeyapiv1alpha2.RegistryConfig
+		wantMirrors  []string
+		wantInsecure []string
+	}{
+		{
+			name:         "default",
+			wantMirrors:  []string{"docker.io"},
+			wantInsecure: []string{},
+		},
+		{
+			name: "mirrors and insecure registries",
+			registry: kubekeyapiv1alpha2.RegistryConfig{
+				RegistryMirrors:    []string{"https://mirror.example.com/", "http://10.0.0.1:5000", "docker.io"},
+				InsecureRegistries: []string{"harbor.example.com"},
+				Auths: runtime.RawExtension{Raw: []byte(
+					`{"dockerhub.kubekey.local": {"skipTLSVerify": true}, "plain.local": {"plainHTTP": true}, "secure.local": {"username": "admin"}}`)},
+			},
+			wantMirrors:  []string{"mirror.example.com", "10.0.0.1:5000", "docker.io"},
+			wantInsecure: []string{"10.0.0.1:5000", "dockerhub.kubekey.local", "harbor.example.com", "plain.local"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeConf := &common.KubeConf{Cluster: &kubekeyapiv1alpha2.ClusterSpec{Registry: tt.registry}}
+			if got := IsulaMirrors(kubeConf); !reflect.DeepEqual(got, tt.wantMirrors) {
+				t.Errorf("IsulaMirrors() = %v, want %v", got, tt.wantMirrors)
+			}
+			if got := IsulaInsecureRegistries(kubeConf); !reflect.DeepEqual(got, tt.wantInsecure) {
+				t.Errorf("IsulaInsecureRegistries() = %v, want %v", got, tt.wantInsecure)
+			}
+		})
+	}
+}
+
+func TestIsulaEnableCRIV1(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{version: "v1.22.12", want: false},
+		{version: "v1.23.0", want: true},
+		{version: "v1.26.5", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			kubeConf := &common.KubeConf{Cluster: &kubekeyapiv1alpha2.ClusterSpec{
+				Kubernetes: kubekeyapiv1alpha2.Kubernetes{Version: tt.version},
+			}}
+			if got := IsulaEnableCRIV1(kubeConf); got != tt.want {
+				t.Errorf("IsulaEnableCRIV1() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsulaConfigTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		dataRoot string
+		mirrors  []string
+		insecure []string
+	}{
+		{
+			name:    "default",
+			mirrors: []string{"docker.io"},
+		},
+		{
+			name:     "data root and registries",
+			dataRoot: `"/data/isulad"`,
+			mirrors:  []string{"mirror.example.com", "docker.io"},
+			insecure: []string{"harbor.example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := templates.IsulaConfig.Execute(&buf, map[string]interface{}{
+				"Mirrors":            quoteJoin(tt.mirrors),
+				"InsecureRegistries": quoteJoin(tt.insecure),
+				"SandBoxImage":       "kubesphere/pause:3.9",
+				"DataRoot":           tt.dataRoot,
+				"EnableCRIV1":        true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config := struct {
+				Graph              string   `json:"graph"`
+				RegistryMirrors    []string `json:"registry-mirrors"`
+				InsecureRegistries []string `json:"insecure-registries"`
+				PodSandboxImage    string   `json:"pod-sandbox-image"`
+				EnableCRIV1        bool     `json:"enable-cri-v1"`
+			}{}
+			if err := json.Unmarshal(buf.Bytes(), &config); err != nil {
+				t.Fatalf("daemon.json is invalid: %v\n%s", err, buf.String())
+			}
+			if !reflect.DeepEqual(config.RegistryMirrors, tt.mirrors) {
+				t.Errorf("registry-mirrors = %v, want %v", config.RegistryMirrors, tt.mirrors)
+			}
+			if len(config.InsecureRegistries) != len(tt.insecure) {
+				t.Errorf("insecure-registries = %v, want %v", config.InsecureRegistries, tt.insecure)
+			}
+			if tt.dataRoot != "" && config.Graph != "/data/isulad" {
+				t.Errorf("graph = %s, want /data/isulad", config.Graph)
+			}
+			if config.PodSandboxImage != "kubesphere/pause:3.9" || !config.EnableCRIV1 {
+				t.Errorf("unexpected daemon.json:\n%s", buf.String())
+			}
+		})
+	}
+}
@@ -29,7 +29,6 @@ import (
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/images"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/kubernetes"
-	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/registry"
 )
 
 type InstallContainerModule struct {
@@ -51,9 +50,10 @@ func (i *InstallContainerModule) Init() {
 	case common.Containerd:
 		i.Tasks = InstallContainerd(i)
 	case common.Crio:
-		// TODO: Add the steps of cri-o's installation.
+		i.Tasks = InstallCrioTasks(i.KubeConf, images.GetImage(i.Runtime, i.KubeConf, "pause").ImageName(),
+			i.Runtime.GetHostsByRole(common.K8s), false)
 	case common.Isula:
-		// TODO: Add the steps of iSula's installation.
+		i.Tasks = InstallIsula(i)
 	default:
 		logger.Log.Fatalf("Unsupported container runtime: %s", strings.TrimSpace(i.KubeConf.Cluster.Kubernetes.ContainerManager))
 	}
@@ -226,14 +226,20 @@ func InstallContainerd(m *InstallContainerModule) []task.Interface {
 		Action: &action.Template{
 			Template: templates.ContainerdConfig,
 			Dst:      filepath.Join("/etc/containerd/", templates.ContainerdConfig.Name()),
-			Data: util.Data{
-				"Mirrors":            templates.Mirrors(m.KubeConf),
-				"InsecureRegistries": m.KubeConf.Cluster.Registry.InsecureRegistries,
-				"SandBoxImage":       images.GetImage(m.Runtime, m.KubeConf, "pause").ImageName(),
-				"Auths":              registry.DockerRegistryAuthEntries(m.KubeConf.Cluster.Registry.Auths),
-				"DataRoot":           templates.DataRoot(m.KubeConf),
-			},
+			Data:     ContainerdConfigData(m.Runtime, m.KubeConf),
+		},
+		Parallel: true,
+	}
+
+	generateContainerdRegistryHosts := &task.RemoteTask{
+		Name:  "GenerateContainerdRegistryHosts",
+		Desc:  "Generate containerd registry hosts",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&ContainerdExist{Not: true},
 		},
+		Action:   new(GenerateContainerdRegistryHosts),
 		Parallel: true,
 	}
 
@@ -284,12 +290,115 @@ func InstallContainerd(m *InstallContainerModule) []task.Interface {
 		syncContainerd,
 		generateContainerdService,
 		generateContainerdConfig,
+		generateContainerdRegistryHosts,
 		enableContainerd,
 		syncCrictlBinaries,
 		generateCrictlConfig,
 	}
 }
 
+func InstallIsula(m *InstallContainerModule) []task.Interface {
+	installIsulad := &task.RemoteTask{
+		Name:  "InstallIsulad",
+		Desc:  "Install isulad",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&IsuladExist{Not: true},
+		},
+		Action:   new(InstallIsulad),
+		Parallel: true,
+		Retry:    2,
+	}
+
+	generateIsuladConfig := &task.RemoteTask{
+		Name:  "GenerateIsuladConfig",
+		Desc:  "Generate isulad config",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&IsuladExist{Not: true},
+		},
+		Action: &action.Template{
+			Template: templates.IsulaConfig,
+			Dst:      filepath.Join(isulaConfigDir, templates.IsulaConfig.Name()),
+			Data: util.Data{
+				"Mirrors":            quoteJoin(IsulaMirrors(m.KubeConf)),
+				"InsecureRegistries": quoteJoin(IsulaInsecureRegistries(m.KubeConf)),
+				"SandBoxImage":       images.GetImage(m.Runtime, m.KubeConf, "pause").ImageName(),
+				"DataRoot":           templates.DataRoot(m.KubeConf),
+				"EnableCRIV1":        IsulaEnableCRIV1(m.KubeConf),
+			},
+		},
+		Parallel: true,
+	}
+
+	enableIsulad := &task.RemoteTask{
+		Name:  "EnableIsulad",
+		Desc:  "Enable isulad",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&IsuladExist{Not: true},
+		},
+		Action:   new(EnableIsulad),
+		Parallel: true,
+	}
+
+	isulaLoginRegistry := &task.RemoteTask{
+		Name:  "LoginPrivateRegistry",
+		Desc:  "Add auths to container runtime",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&IsuladExist{},
+			&PrivateRegistryAuth{},
+		},
+		Action:   new(IsulaLoginRegistry),
+		Parallel: true,
+	}
+
+	syncCrictlBinaries := &task.RemoteTask{
+		Name:  "SyncCrictlBinaries",
+		Desc:  "Sync crictl binaries",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&CrictlExist{Not: true},
+		},
+		Action:   new(SyncCrictlBinaries),
+		Parallel: true,
+		Retry:    2,
+	}
+
+	generateCrictlConfig := &task.RemoteTask{
+		Name:  "GenerateCrictlConfig",
+		Desc:  "Generate crictl config",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&CrictlExist{Not: false},
+		},
+		Action: &action.Template{
+			Template: templates.CrictlConfig,
+			Dst:      filepath.Join("/etc/", templates.CrictlConfig.Name()),
+			Data: util.Data{
+				"Endpoint": m.KubeConf.Cluster.Kubernetes.ContainerRuntimeEndpoint,
+			},
+		},
+		Parallel: true,
+	}
+
+	return []task.Interface{
+		installIsulad,
+		generateIsuladConfig,
+		enableIsulad,
+		isulaLoginRegistry,
+		syncCrictlBinaries,
+		generateCrictlConfig,
+	}
+}
+
 type InstallCriDockerdModule struct {
 	common.KubeModule
 	Skip bool
@@ -404,9 +513,9 @@ func (i *UninstallContainerModule) Init() {
 	case common.Containerd:
 		i.Tasks = UninstallContainerd(i)
 	case common.Crio:
-		// TODO: Add the steps of cri-o's installation.
+		i.Tasks = UninstallCrio(i)
 	case common.Isula:
-		// TODO: Add the steps of iSula's installation.
+		i.Tasks = UninstallIsula(i)
 	default:
 		logger.Log.Fatalf("Unsupported container runtime: %s", strings.TrimSpace(i.KubeConf.Cluster.Kubernetes.ContainerManager))
 	}
@@ -447,6 +556,40 @@ func UninstallContainerd(m *UninstallContainerModule) []task.Interface {
 	}
 }
 
+func UninstallCrio(m *UninstallContainerModule) []task.Interface {
+	disableCrio := &task.RemoteTask{
+		Name:  "UninstallCrio",
+		Desc:  "Uninstall cri-o",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&CrioExist{Not: false},
+		},
+		Action:   new(DisableCrio),
+		Parallel: true,
+	}
+
+	return []task.Interface{
+		disableCrio,
+	}
+}
+
+func UninstallIsula(m *UninstallContainerModule) []task.Interface {
+	disableIsulad := &task.RemoteTask{
+		Name:  "UninstallIsulad",
+		Desc:  "Uninstall isulad",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&IsuladExist{Not: false},
+		},
+		Action:   new(DisableIsulad),
+		Parallel: true,
+	}
+
+	return []task.Interface{
+		disableIsulad,
+	}
+}
+
 type CriMigrateModule struct {
 	common.KubeModule
 
@@ -524,3 +667,46 @@ func MigrateACri(p *CriMigrateModule) []task.Interface {
 
 	return p.Tasks
 }
+
+// ReloadRegistriesModule applies the registries in the config to the containerd running on the nodes.
+type ReloadRegistriesModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (r *ReloadRegistriesModule) IsSkip() bool {
+	return r.Skip
+}
+
+func (r *ReloadRegistriesModule) Init() {
+	r.Name = "ReloadRegistriesModule"
+	r.Desc = "Reload the registries of containerd"
+
+	generateContainerdRegistryHosts := &task.RemoteTask{
+		Name:  "GenerateContainerdRegistryHosts",
+		Desc:  "Generate containerd registry hosts",
+		Hosts: r.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&ContainerdExist{},
+		},
+		Action:   new(GenerateContainerdRegistryHosts),
+		Parallel: true,
+	}
+
+	reloadContainerdRegistries := &task.RemoteTask{
+		Name:  "ReloadContainerdRegistries",
+		Desc:  "Reload containerd registry auths",
+		Hosts: r.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&ContainerdExist{},
+		},
+		Action: new(ReloadContainerdRegistries),
+		// containerd is restarted on one node at a time, so the failure of a node does not affect the others
+		Parallel: false,
+	}
+
+	r.Tasks = []task.Interface{
+		generateContainerdRegistryHosts,
+		reloadContainerdRegistries,
+	}
+}
@@ -120,3 +120,41 @@ func (p *PrivateRegistryAuth) PreCheck(runtime connector.Runtime) (bool, error)
 	}
 	return true, nil
 }
+
+type CrioExist struct {
+	common.KubePrepare
+	Not bool
+}
+
+func (c *CrioExist) PreCheck(runtime connector.Runtime) (bool, error) {
+	output, err := runtime.GetRunner().SudoCmd(
+		"if [ -z $(command -v crio) ] || [ ! -e /var/run/crio/crio.sock ]; "+
+			"then echo 'not exist'; "+
+			"fi", false)
+	if err != nil {
+		return false, err
+	}
+	if strings.Contains(output, "not exist") {
+		return c.Not, nil
+	}
+	return !c.Not, nil
+}
+
+type IsuladExist struct {
+	common.KubePrepare
+	Not bool
+}
+
+func (i *IsuladExist) PreCheck(runtime connector.Runtime) (bool, error) {
+	output, err := runtime.GetRunner().SudoCmd(
+		"if [ -z $(command -v isulad) ] || [ ! -e /var/run/isulad.sock ]; "+
+			"then echo 'not exist'; "+
+			"fi", false)
+	if err != nil {
+		return false, err
+	}
+	if strings.Contains(output, "not exist") {
+		return i.Not, nil
+	}
+	return !i.Not, nil
+}
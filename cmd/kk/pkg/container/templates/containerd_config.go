@@ -22,6 +22,8 @@ import (
 	"github.com/lithammer/dedent"
 )
 
+// ContainerdConfig is the config.toml of containerd. The registries are configured by the hosts.toml files under
+// config_path, only their auths are left here as hosts.toml has no place for them.
 var ContainerdConfig = template.Must(template.New("config.toml").Parse(
 	dedent.Dedent(`version = 2
 {{- if .DataRoot }}
@@ -75,30 +77,15 @@ state = "/run/containerd"
       max_conf_num = 1
       conf_template = ""
     [plugins."io.containerd.grpc.v1.cri".registry]
-      [plugins."io.containerd.grpc.v1.cri".registry.mirrors]
-        {{- if .Mirrors }}
-        [plugins."io.containerd.grpc.v1.cri".registry.mirrors."docker.io"]
-          endpoint = [{{ .Mirrors }}, "https://registry-1.docker.io"]
-        {{ else }}
-        [plugins."io.containerd.grpc.v1.cri".registry.mirrors."docker.io"]
-          endpoint = ["https://registry-1.docker.io"]
+      config_path = "{{ .ConfigPath }}"
+      {{- if .Auths }}
+      [plugins."io.containerd.grpc.v1.cri".registry.configs]
+        {{- range $repo, $entry := .Auths }}
+        {{- if or $entry.Username $entry.Password }}
+        [plugins."io.containerd.grpc.v1.cri".registry.configs."{{$repo}}".auth]
+          username = "{{$entry.Username}}"
+          password = "{{$entry.Password}}"
         {{- end}}
-        {{- range $value := .InsecureRegistries }}
-        [plugins."io.containerd.grpc.v1.cri".registry.mirrors."{{$value}}"]
-          endpoint = ["http://{{$value}}"]
-        {{- end}}
-      
-        {{- if .Auths }}
-        [plugins."io.containerd.grpc.v1.cri".registry.configs]
-          {{- range $repo, $entry := .Auths }}
-          [plugins."io.containerd.grpc.v1.cri".registry.configs."{{$repo}}".auth]
-            username = "{{$entry.Username}}"
-            password = "{{$entry.Password}}"
-            [plugins."io.containerd.grpc.v1.cri".registry.configs."{{$repo}}".tls]
-              ca_file = "{{$entry.CAFile}}"
-              cert_file = "{{$entry.CertFile}}"
-              key_file = "{{$entry.KeyFile}}"
-              insecure_skip_verify = {{$entry.SkipTLSVerify}}
-          {{- end}}
         {{- end}}
+      {{- end}}
     `)))
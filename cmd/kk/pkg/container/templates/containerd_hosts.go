@@ -0,0 +1,60 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package templates
+
+import (
+	"text/template"
+
+	"github.com/lithammer/dedent"
+)
+
+// ContainerdHostsHeader marks the hosts.toml generated by KubeKey, the ones without it are left alone when the
+// registries are reloaded.
+const ContainerdHostsHeader = "# Generated by KubeKey, do not edit it manually."
+
+// ContainerdHosts is the hosts.toml of a registry under the config_path of containerd. The hosts are tried in order
+// before the server.
+var ContainerdHosts = template.Must(template.New("hosts.toml").Parse(
+	dedent.Dedent(`{{ .Header }}
+{{- with .Server }}
+server = "{{ .URL }}"
+capabilities = [{{ range $i, $c := .Capabilities }}{{ if $i }}, {{ end }}"{{ $c }}"{{ end }}]
+{{- if .SkipVerify }}
+skip_verify = true
+{{- end }}
+{{- if .CA }}
+ca = "{{ .CA }}"
+{{- end }}
+{{- if .Cert }}
+client = [["{{ .Cert }}", "{{ .Key }}"]]
+{{- end }}
+{{- end }}
+{{- range .Hosts }}
+
+[host."{{ .URL }}"]
+  capabilities = [{{ range $i, $c := .Capabilities }}{{ if $i }}, {{ end }}"{{ $c }}"{{ end }}]
+  {{- if .SkipVerify }}
+  skip_verify = true
+  {{- end }}
+  {{- if .CA }}
+  ca = "{{ .CA }}"
+  {{- end }}
+  {{- if .Cert }}
+  client = [["{{ .Cert }}", "{{ .Key }}"]]
+  {{- end }}
+{{- end }}
+    `)))
@@ -0,0 +1,99 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package templates
+
+import (
+	"text/template"
+
+	"github.com/lithammer/dedent"
+)
+
+// CrioConfig runs the containers by the runc and crun shipped in the CRI-O bundle, conmon and pinns are found in
+// the PATH.
+var CrioConfig = template.Must(template.New("crio.conf").Parse(
+	dedent.Dedent(`# Generated by KubeKey, do not edit it manually.
+[crio]
+{{- if .DataRoot }}
+root = {{ .DataRoot }}
+{{- else }}
+root = "/var/lib/containers/storage"
+{{- end }}
+runroot = "/var/run/containers/storage"
+storage_driver = "overlay"
+
+[crio.api]
+listen = "/var/run/crio/crio.sock"
+
+[crio.runtime]
+default_runtime = "runc"
+cgroup_manager = "systemd"
+conmon_cgroup = "pod"
+
+[crio.runtime.runtimes.runc]
+runtime_path = "/usr/libexec/crio/runc"
+runtime_type = "oci"
+runtime_root = "/run/runc"
+
+[crio.runtime.runtimes.crun]
+runtime_path = "/usr/libexec/crio/crun"
+runtime_type = "oci"
+runtime_root = "/run/crun"
+
+[crio.image]
+pause_image = "{{ .SandBoxImage }}"
+{{- if .AuthFile }}
+global_auth_file = "{{ .AuthFile }}"
+{{- end }}
+
+[crio.network]
+network_dir = "/etc/cni/net.d/"
+plugin_dirs = ["/opt/cni/bin/"]
+    `)))
+
+// CrioRegistries is the registries.conf of containers/image used by CRI-O to pull the images.
+var CrioRegistries = template.Must(template.New("registries.conf").Parse(
+	dedent.Dedent(`# Generated by KubeKey, do not edit it manually.
+unqualified-search-registries = ["docker.io"]
+{{- range .Registries }}
+
+[[registry]]
+prefix = "{{ .Prefix }}"
+location = "{{ .Location }}"
+insecure = {{ .Insecure }}
+{{- range .Mirrors }}
+
+[[registry.mirror]]
+location = "{{ .Location }}"
+insecure = {{ .Insecure }}
+{{- end }}
+{{- end }}
+    `)))
+
+// CrioPolicy accepts all the images as the default policy of CRI-O does.
+var CrioPolicy = template.Must(template.New("policy.json").Parse(
+	dedent.Dedent(`{
+  "default": [
+    {
+      "type": "insecureAcceptAnything"
+    }
+  ]
+}
+    `)))
+
+// CrioAuth is the auth file of the registries in the format of containers-auth.json.
+var CrioAuth = template.Must(template.New("auth.json").Parse(`{{ .Auths }}
+`))
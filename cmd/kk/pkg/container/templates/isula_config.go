@@ -0,0 +1,69 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package templates
+
+import (
+	"text/template"
+
+	"github.com/lithammer/dedent"
+)
+
+// IsulaConfig is the daemon.json of iSulad. The registry mirrors are searched in order for the images without a
+// registry, so docker.io is always the last one.
+var IsulaConfig = template.Must(template.New("daemon.json").Parse(
+	dedent.Dedent(`{
+    "group": "isula",
+    "default-runtime": "runc",
+    {{- if .DataRoot }}
+    "graph": {{ .DataRoot }},
+    {{- else }}
+    "graph": "/var/lib/isulad",
+    {{- end }}
+    "state": "/var/run/isulad",
+    "engine": "lcr",
+    "log-level": "ERROR",
+    "pidfile": "/var/run/isulad.pid",
+    "log-opts": {
+        "log-file-mode": "0600",
+        "log-path": "/var/lib/isulad",
+        "max-file": "1",
+        "max-size": "30KB"
+    },
+    "log-driver": "stdout",
+    "container-log": {
+        "driver": "json-file"
+    },
+    "hook-spec": "/etc/default/isulad/hooks/default.json",
+    "start-timeout": "2m",
+    "storage-driver": "overlay2",
+    "storage-opts": [
+        "overlay2.override_kernel_check=true"
+    ],
+    "registry-mirrors": [{{ .Mirrors }}],
+    "insecure-registries": [{{ .InsecureRegistries }}],
+    "pod-sandbox-image": "{{ .SandBoxImage }}",
+    "native.umask": "secure",
+    "network-plugin": "cni",
+    "cni-bin-dir": "/opt/cni/bin",
+    "cni-conf-dir": "/etc/cni/net.d",
+    "image-layer-check": false,
+    "use-decrypted-key": true,
+    "insecure-skip-verify-enforce": false,
+    "systemd-cgroup": true,
+    "enable-cri-v1": {{ .EnableCRIV1 }}
+}
+    `)))
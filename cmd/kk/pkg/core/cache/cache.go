@@ -16,10 +16,14 @@
 
 package cache
 
-import "sync"
+import (
+	"sync"
+	"sync/atomic"
+)
 
 type Cache struct {
-	store sync.Map
+	store  sync.Map
+	writes uint64
 }
 
 func NewCache() *Cache {
@@ -29,13 +33,18 @@ func NewCache() *Cache {
 
 func (c *Cache) Set(k string, v interface{}) {
 	c.store.Store(k, v)
+	atomic.AddUint64(&c.writes, 1)
 }
 
 // GetOrSet returns the existing value for the key if present.
 // Otherwise, it stores and returns the given value.
 // The loaded result is true if the value was loaded, false if stored.
 func (c *Cache) GetOrSet(k string, v interface{}) (interface{}, bool) {
-	return c.store.LoadOrStore(k, v)
+	actual, loaded := c.store.LoadOrStore(k, v)
+	if !loaded {
+		atomic.AddUint64(&c.writes, 1)
+	}
+	return actual, loaded
 }
 
 func (c *Cache) Get(k string) (interface{}, bool) {
@@ -48,6 +57,12 @@ func (c *Cache) Range(f func(key, value interface{}) bool) {
 
 func (c *Cache) Delete(k string) {
 	c.store.Delete(k)
+	atomic.AddUint64(&c.writes, 1)
+}
+
+// Writes returns the number of the changes made to the cache, it tells whether the cache has been changed.
+func (c *Cache) Writes() uint64 {
+	return atomic.LoadUint64(&c.writes)
 }
 
 func (c *Cache) Clean() {
@@ -21,11 +21,13 @@ import (
 	"time"
 
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+	"github.com/kubesphere/kubekey/v3/util/hostkey"
 )
 
 type Dialer struct {
 	lock        sync.Mutex
 	connections map[string]Connection
+	knownHosts  *hostkey.KnownHosts
 }
 
 func NewDialer() *Dialer {
@@ -50,6 +52,24 @@ func (d *Dialer) Connect(host Host) (Connection, error) {
 			PrivateKey: host.GetPrivateKey(),
 			KeyFile:    host.GetPrivateKeyPath(),
 			Timeout:    time.Duration(host.GetTimeout()) * time.Second,
+
+			MaxSessions:       host.GetMaxSessions(),
+			KeepAliveInterval: time.Duration(host.GetKeepAliveInterval()) * time.Second,
+
+			HostKeyChecking:    host.GetHostKeyChecking(),
+			HostKeyFingerprint: host.GetHostKeyFingerprint(),
+			KnownHosts:         d.knownHosts,
+		}
+		for _, hop := range host.GetBastion() {
+			opts.ProxyJump = append(opts.ProxyJump, Cfg{
+				Username:    hop.User,
+				Password:    hop.Password,
+				Address:     hop.Address,
+				Port:        hop.Port,
+				PrivateKey:  hop.PrivateKey,
+				KeyFile:     hop.PrivateKeyPath,
+				AgentSocket: hop.AgentSocket,
+			})
 		}
 		conn, err = NewConnection(opts)
 		if err != nil {
@@ -0,0 +1,215 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package connector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	ExecOp  = "exec"
+	PExecOp = "pexec"
+	ScpOp   = "scp"
+	FetchOp = "fetch"
+	StatOp  = "stat"
+	MkDirOp = "mkdir"
+	ChmodOp = "chmod"
+	LocalOp = "local"
+)
+
+// Step is a remote operation recorded by a dry-run connection.
+type Step struct {
+	Section string
+	Host    string
+	Op      string
+	Command string
+}
+
+// Plan is the ordered list of the remote operations recorded during a dry run.
+type Plan struct {
+	mu       sync.Mutex
+	section  string
+	sections []string
+	steps    []Step
+	notes    map[string]string
+}
+
+func NewPlan() *Plan {
+	return &Plan{
+		sections: make([]string, 0),
+		steps:    make([]Step, 0),
+		notes:    make(map[string]string),
+	}
+}
+
+// SetSection starts a new section of the plan, all the following steps belong to it.
+func (p *Plan) SetSection(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.section = name
+	p.sections = append(p.sections, name)
+}
+
+// Note attaches a message to the current section, e.g. why the section is incomplete.
+func (p *Plan) Note(msg string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.notes[p.section] = msg
+}
+
+func (p *Plan) Record(host, op, command string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.steps = append(p.steps, Step{Section: p.section, Host: host, Op: op, Command: command})
+}
+
+func (p *Plan) Steps() []Step {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	steps := make([]Step, len(p.steps))
+	copy(steps, p.steps)
+	return steps
+}
+
+// Print writes the plan grouped by section and host. The steps of one host keep the order
+// they were issued in, the hosts of a section are printed in the order they first appeared.
+func (p *Plan) Print(w io.Writer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, section := range p.sections {
+		hosts := make([]string, 0)
+		byHost := make(map[string][]Step)
+		for _, s := range p.steps {
+			if s.Section != section {
+				continue
+			}
+			if _, ok := byHost[s.Host]; !ok {
+				hosts = append(hosts, s.Host)
+			}
+			byHost[s.Host] = append(byHost[s.Host], s)
+		}
+
+		note, hasNote := p.notes[section]
+		if len(hosts) == 0 && !hasNote {
+			continue
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\n", section)
+		if hasNote {
+			_, _ = fmt.Fprintf(w, "  # %s\n", note)
+		}
+		for _, host := range hosts {
+			for i, s := range byHost[host] {
+				_, _ = fmt.Fprintf(w, "  [%s] %d. %s: %s\n", host, i+1, s.Op, s.Command)
+			}
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+}
+
+// DryRunDialer is a Connector which never connects to the hosts. Its connections record every
+// remote operation into a Plan instead of executing it.
+type DryRunDialer struct {
+	lock        sync.Mutex
+	connections map[string]Connection
+	plan        *Plan
+}
+
+func NewDryRunDialer() *DryRunDialer {
+	return &DryRunDialer{
+		connections: make(map[string]Connection),
+		plan:        NewPlan(),
+	}
+}
+
+func (d *DryRunDialer) Connect(host Host) (Connection, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	conn, ok := d.connections[host.GetName()]
+	if !ok {
+		conn = &dryRunConnection{host: host.GetName(), plan: d.plan}
+		d.connections[host.GetName()] = conn
+	}
+	return conn, nil
+}
+
+func (d *DryRunDialer) Close(host Host) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.connections, host.GetName())
+}
+
+func (d *DryRunDialer) Plan() *Plan {
+	return d.plan
+}
+
+// dryRunConnection pretends every operation succeeded. Commands print nothing and files
+// or directories never exist on the remote, so the tasks take the path of a fresh host.
+type dryRunConnection struct {
+	host string
+	plan *Plan
+}
+
+func (c *dryRunConnection) Exec(_ context.Context, cmd string, host Host) (stdout string, code int, err error) {
+	c.plan.Record(host.GetName(), ExecOp, cmd)
+	return "", 0, nil
+}
+
+func (c *dryRunConnection) PExec(_ context.Context, cmd string, stdin io.Reader, stdout io.Writer, stderr io.Writer, host Host) (code int, err error) {
+	c.plan.Record(host.GetName(), PExecOp, cmd)
+	return 0, nil
+}
+
+func (c *dryRunConnection) Fetch(_ context.Context, local, remote string, host Host) error {
+	c.plan.Record(host.GetName(), FetchOp, fmt.Sprintf("%s -> %s", remote, local))
+	return nil
+}
+
+func (c *dryRunConnection) Scp(_ context.Context, local, remote string, host Host) error {
+	c.plan.Record(host.GetName(), ScpOp, fmt.Sprintf("%s -> %s", local, remote))
+	return nil
+}
+
+func (c *dryRunConnection) RemoteFileExist(_ context.Context, remote string, host Host) bool {
+	c.plan.Record(host.GetName(), StatOp, remote)
+	return false
+}
+
+func (c *dryRunConnection) RemoteDirExist(_ context.Context, remote string, host Host) (bool, error) {
+	c.plan.Record(host.GetName(), StatOp, remote)
+	return false, nil
+}
+
+func (c *dryRunConnection) MkDirAll(_ context.Context, path string, mode string, host Host) error {
+	c.plan.Record(host.GetName(), MkDirOp, strings.TrimSpace(fmt.Sprintf("%s %s", mode, path)))
+	return nil
+}
+
+func (c *dryRunConnection) Chmod(path string, mode os.FileMode) error {
+	c.plan.Record(c.host, ChmodOp, fmt.Sprintf("%o %s", mode, path))
+	return nil
+}
+
+func (c *dryRunConnection) Close() {
+}
@@ -0,0 +1,71 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package connector
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestDryRunDialer(t *testing.T) {
+	d := NewDryRunDialer()
+	node1 := &BaseHost{Name: "node1"}
+	node2 := &BaseHost{Name: "node2"}
+
+	d.Plan().SetSection("Module[first]")
+	for _, host := range []Host{node1, node2} {
+		conn, err := d.Connect(host)
+		if err != nil {
+			t.Fatalf("connect %s failed: %v", host.GetName(), err)
+		}
+		if _, _, err := conn.Exec(context.Background(), "hostname", host); err != nil {
+			t.Fatalf("exec failed: %v", err)
+		}
+		if conn.RemoteFileExist(context.Background(), "/etc/kubernetes/admin.conf", host) {
+			t.Errorf("remote file should never exist in a dry run")
+		}
+	}
+
+	d.Plan().SetSection("Module[empty]")
+
+	d.Plan().SetSection("Module[second]")
+	conn, _ := d.Connect(node1)
+	_ = conn.Scp(context.Background(), "/tmp/kubelet", "/usr/local/bin/kubelet", node1)
+	d.Plan().Note("incomplete")
+
+	if len(d.Plan().Steps()) != 5 {
+		t.Fatalf("expected 5 steps, got %d", len(d.Plan().Steps()))
+	}
+
+	buf := &bytes.Buffer{}
+	d.Plan().Print(buf)
+	want := `Module[first]
+  [node1] 1. exec: hostname
+  [node1] 2. stat: /etc/kubernetes/admin.conf
+  [node2] 1. exec: hostname
+  [node2] 2. stat: /etc/kubernetes/admin.conf
+
+Module[second]
+  # incomplete
+  [node1] 1. scp: /tmp/kubelet -> /usr/local/bin/kubelet
+
+`
+	if buf.String() != want {
+		t.Errorf("unexpected plan:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
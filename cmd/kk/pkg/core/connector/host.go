@@ -33,11 +33,29 @@ type BaseHost struct {
 	Arch            string `yaml:"arch,omitempty" json:"arch,omitempty"`
 	Timeout         int64  `yaml:"timeout,omitempty" json:"timeout,omitempty"`
 
+	HostKeyChecking    string     `yaml:"hostKeyChecking,omitempty" json:"hostKeyChecking,omitempty"`
+	HostKeyFingerprint string     `yaml:"hostKeyFingerprint,omitempty" json:"hostKeyFingerprint,omitempty"`
+	Bastion            []JumpHost `yaml:"bastion,omitempty" json:"bastion,omitempty"`
+	MaxSessions        int        `yaml:"maxSessions,omitempty" json:"maxSessions,omitempty"`
+	KeepAliveInterval  int64      `yaml:"keepAliveInterval,omitempty" json:"keepAliveInterval,omitempty"`
+
 	Roles     []string        `json:"-"`
 	RoleTable map[string]bool `json:"-"`
 	Cache     *cache.Cache    `json:"-"`
 }
 
+// JumpHost is a hop of the ssh connection to a host, the hops are connected in order like the ProxyJump of OpenSSH.
+// A hop without any credentials uses the user and the credentials of the target host.
+type JumpHost struct {
+	Address        string `yaml:"address,omitempty" json:"address,omitempty"`
+	Port           int    `yaml:"port,omitempty" json:"port,omitempty"`
+	User           string `yaml:"user,omitempty" json:"user,omitempty"`
+	Password       string `yaml:"password,omitempty" json:"password,omitempty"`
+	PrivateKey     string `yaml:"privateKey,omitempty" json:"privateKey,omitempty"`
+	PrivateKeyPath string `yaml:"privateKeyPath,omitempty" json:"privateKeyPath,omitempty"`
+	AgentSocket    string `yaml:"agentSocket,omitempty" json:"agentSocket,omitempty"`
+}
+
 func NewHost() *BaseHost {
 	return &BaseHost{
 		Roles:     make([]string, 0, 0),
@@ -139,6 +157,46 @@ func (b *BaseHost) SetTimeout(timeout int64) {
 	b.Timeout = timeout
 }
 
+func (b *BaseHost) GetHostKeyChecking() string {
+	return b.HostKeyChecking
+}
+
+func (b *BaseHost) SetHostKeyChecking(mode string) {
+	b.HostKeyChecking = mode
+}
+
+func (b *BaseHost) GetHostKeyFingerprint() string {
+	return b.HostKeyFingerprint
+}
+
+func (b *BaseHost) SetHostKeyFingerprint(fingerprint string) {
+	b.HostKeyFingerprint = fingerprint
+}
+
+func (b *BaseHost) GetBastion() []JumpHost {
+	return b.Bastion
+}
+
+func (b *BaseHost) SetBastion(hops []JumpHost) {
+	b.Bastion = hops
+}
+
+func (b *BaseHost) GetMaxSessions() int {
+	return b.MaxSessions
+}
+
+func (b *BaseHost) SetMaxSessions(n int) {
+	b.MaxSessions = n
+}
+
+func (b *BaseHost) GetKeepAliveInterval() int64 {
+	return b.KeepAliveInterval
+}
+
+func (b *BaseHost) SetKeepAliveInterval(interval int64) {
+	b.KeepAliveInterval = interval
+}
+
 func (b *BaseHost) GetRoles() []string {
 	return b.Roles
 }
@@ -17,19 +17,21 @@
 package connector
 
 import (
-	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/cache"
+	"context"
 	"io"
 	"os"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/cache"
 )
 
 type Connection interface {
-	Exec(cmd string, host Host) (stdout string, code int, err error)
-	PExec(cmd string, stdin io.Reader, stdout io.Writer, stderr io.Writer, host Host) (code int, err error)
-	Fetch(local, remote string, host Host) error
-	Scp(local, remote string, host Host) error
-	RemoteFileExist(remote string, host Host) bool
-	RemoteDirExist(remote string, host Host) (bool, error)
-	MkDirAll(path string, mode string, host Host) error
+	Exec(ctx context.Context, cmd string, host Host) (stdout string, code int, err error)
+	PExec(ctx context.Context, cmd string, stdin io.Reader, stdout io.Writer, stderr io.Writer, host Host) (code int, err error)
+	Fetch(ctx context.Context, local, remote string, host Host) error
+	Scp(ctx context.Context, local, remote string, host Host) error
+	RemoteFileExist(ctx context.Context, remote string, host Host) bool
+	RemoteDirExist(ctx context.Context, remote string, host Host) (bool, error)
+	MkDirAll(ctx context.Context, path string, mode string, host Host) error
 	Chmod(path string, mode os.FileMode) error
 	Close()
 }
@@ -87,6 +89,16 @@ type Host interface {
 	SetArch(arch string)
 	GetTimeout() int64
 	SetTimeout(timeout int64)
+	GetHostKeyChecking() string
+	SetHostKeyChecking(mode string)
+	GetHostKeyFingerprint() string
+	SetHostKeyFingerprint(fingerprint string)
+	GetBastion() []JumpHost
+	SetBastion(hops []JumpHost)
+	GetMaxSessions() int
+	SetMaxSessions(n int)
+	GetKeepAliveInterval() int64
+	SetKeepAliveInterval(interval int64)
 	GetRoles() []string
 	SetRoles(roles []string)
 	IsRole(role string) bool
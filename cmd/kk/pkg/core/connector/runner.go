@@ -17,6 +17,7 @@
 package connector
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -32,6 +33,16 @@ type Runner struct {
 	Debug bool
 	Host  Host
 	Index int
+	// Ctx is done when the task is timeout or the pipeline is interrupted,
+	// the remote commands which are still running will be stopped.
+	Ctx context.Context
+}
+
+func (r *Runner) Context() context.Context {
+	if r.Ctx == nil {
+		return context.Background()
+	}
+	return r.Ctx
 }
 
 func (r *Runner) Exec(cmd string, printOutput bool) (string, int, error) {
@@ -39,7 +50,7 @@ func (r *Runner) Exec(cmd string, printOutput bool) (string, int, error) {
 		return "", 1, errors.New("no ssh connection available")
 	}
 
-	stdout, code, err := r.Conn.Exec(cmd, r.Host)
+	stdout, code, err := r.Conn.Exec(r.Context(), cmd, r.Host)
 	logger.Log.Debugf("command: [%s]\n%s", r.Host.GetName(), cmd)
 	if stdout != "" {
 		logger.Log.Debugf("stdout: [%s]\n%s", r.Host.GetName(), stdout)
@@ -77,7 +88,7 @@ func (r *Runner) Fetch(local, remote string) error {
 		return errors.New("no ssh connection available")
 	}
 
-	if err := r.Conn.Fetch(local, remote, r.Host); err != nil {
+	if err := r.Conn.Fetch(r.Context(), local, remote, r.Host); err != nil {
 		logger.Log.Debugf("fetch remote file %s to local %s failed: %v", remote, local, err)
 		return err
 	}
@@ -90,7 +101,7 @@ func (r *Runner) Scp(local, remote string) error {
 		return errors.New("no ssh connection available")
 	}
 
-	if err := r.Conn.Scp(local, remote, r.Host); err != nil {
+	if err := r.Conn.Scp(r.Context(), local, remote, r.Host); err != nil {
 		logger.Log.Debugf("scp local file %s to remote %s failed: %v", local, remote, err)
 		return err
 	}
@@ -114,7 +125,7 @@ func (r *Runner) SudoScp(local, remote string) error {
 	if !util.IsDir(local) {
 		baseRemotePath = filepath.Dir(remote)
 	}
-	if err := r.Conn.MkDirAll(baseRemotePath, "", r.Host); err != nil {
+	if err := r.Conn.MkDirAll(r.Context(), baseRemotePath, "", r.Host); err != nil {
 		return err
 	}
 
@@ -133,7 +144,7 @@ func (r *Runner) FileExist(remote string) (bool, error) {
 		return false, errors.New("no ssh connection available")
 	}
 
-	ok := r.Conn.RemoteFileExist(remote, r.Host)
+	ok := r.Conn.RemoteFileExist(r.Context(), remote, r.Host)
 	logger.Log.Debugf("check remote file exist: %v", ok)
 	return ok, nil
 }
@@ -143,7 +154,7 @@ func (r *Runner) DirExist(remote string) (bool, error) {
 		return false, errors.New("no ssh connection available")
 	}
 
-	ok, err := r.Conn.RemoteDirExist(remote, r.Host)
+	ok, err := r.Conn.RemoteDirExist(r.Context(), remote, r.Host)
 	if err != nil {
 		logger.Log.Debugf("check remote dir exist failed: %v", err)
 		return false, err
@@ -157,7 +168,7 @@ func (r *Runner) MkDir(path string) error {
 		return errors.New("no ssh connection available")
 	}
 
-	if err := r.Conn.MkDirAll(path, "", r.Host); err != nil {
+	if err := r.Conn.MkDirAll(r.Context(), path, "", r.Host); err != nil {
 		logger.Log.Errorf("make remote dir %s failed: %v", path, err)
 		return err
 	}
@@ -182,7 +193,7 @@ func (r *Runner) FileMd5(path string) (string, error) {
 	}
 
 	cmd := fmt.Sprintf("md5sum %s | cut -d\" \" -f1", path)
-	out, _, err := r.Conn.Exec(cmd, r.Host)
+	out, _, err := r.Conn.Exec(r.Context(), cmd, r.Host)
 	if err != nil {
 		logger.Log.Errorf("count remote %s md5 failed: %v", path, err)
 		return "", err
@@ -26,8 +26,13 @@ import (
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/common"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
+	"github.com/kubesphere/kubekey/v3/util/hostkey"
 )
 
+// KnownHostsFile is the known_hosts file managed by KubeKey in its work dir. It is shared by all the commands run
+// from the same work dir, so a host key which changes between "create cluster" and "add nodes" is refused.
+const KnownHostsFile = "known_hosts"
+
 type BaseRuntime struct {
 	ObjName         string
 	connector       Connector
@@ -58,6 +63,11 @@ func NewBaseRuntime(name string, connector Connector, verbose bool, ignoreErr bo
 		fmt.Printf("[ERRO]: Failed to init KubeKey log entry: %s\n", err)
 		os.Exit(1)
 	}
+	if d, ok := connector.(*Dialer); ok {
+		d.knownHosts = hostkey.New(&hostkey.FileStore{Path: filepath.Join(base.workDir, KnownHostsFile)}, func(format string, args ...interface{}) {
+			logger.Log.Debugf(format, args...)
+		})
+	}
 	return base
 }
 
@@ -223,3 +233,19 @@ func (b *BaseRuntime) RoleMapDelete(host Host) {
 		b.roleHosts[role] = hosts
 	}
 }
+
+// RoleMapDeleteRole removes the host from the hosts of the role, and the other roles of the host are kept.
+func (b *BaseRuntime) RoleMapDeleteRole(host Host, role string) {
+	hosts, ok := b.roleHosts[role]
+	if !ok {
+		return
+	}
+	i := 0
+	for j := range hosts {
+		if hosts[j].GetName() != host.GetName() {
+			hosts[i] = hosts[j]
+			i++
+		}
+	}
+	b.roleHosts[role] = hosts[:i]
+}
@@ -0,0 +1,99 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package connector
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+)
+
+// session is a ssh session which releases its slot of the connection once it is closed.
+type session struct {
+	*ssh.Session
+	once    sync.Once
+	release func()
+}
+
+func (s *session) Close() error {
+	err := s.Session.Close()
+	s.once.Do(s.release)
+	return err
+}
+
+// sessionLimiter caps the concurrent sessions multiplexed on a connection. The cap is lowered when the server
+// refuses a new session, which means the MaxSessions of the server is lower than the cap.
+type sessionLimiter struct {
+	mu    sync.Mutex
+	limit int
+	inUse int
+	// released is closed and renewed once a session is released, to wake up the ones waiting for a slot
+	released chan struct{}
+}
+
+func newSessionLimiter(limit int) *sessionLimiter {
+	if limit <= 0 {
+		limit = DefaultMaxSessions
+	}
+	return &sessionLimiter{limit: limit, released: make(chan struct{})}
+}
+
+// acquire waits for a slot of the connection, it gives up once the ctx is done.
+func (l *sessionLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inUse < l.limit {
+			l.inUse++
+			l.mu.Unlock()
+			return nil
+		}
+		released := l.released
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+func (l *sessionLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inUse--
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// shrink lowers the cap to the sessions in use. It returns false if no session is in use, then there is nothing
+// to wait for and the refusal is not caused by the MaxSessions of the server.
+func (l *sessionLimiter) shrink() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inUse == 0 {
+		return false
+	}
+	if l.inUse < l.limit {
+		logger.Log.Debugf("the server refuses more than %d sessions, lower the cap from %d", l.inUse, l.limit)
+		l.limit = l.inUse
+	}
+	return true
+}
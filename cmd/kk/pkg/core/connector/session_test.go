@@ -0,0 +1,88 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package connector
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+)
+
+func TestSessionLimiter(t *testing.T) {
+	logger.Log = logger.NewLogger(t.TempDir(), false)
+
+	l := newSessionLimiter(3)
+	var running, peak int32
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.acquire(context.Background()); err != nil {
+				t.Error(err)
+				return
+			}
+			defer l.release()
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+	wg.Wait()
+	if peak > 3 {
+		t.Errorf("expected at most 3 concurrent sessions, got %d", peak)
+	}
+
+	if l.shrink() {
+		t.Errorf("shrink should fail without any session in use")
+	}
+	_ = l.acquire(context.Background())
+	_ = l.acquire(context.Background())
+	if !l.shrink() || l.limit != 2 {
+		t.Errorf("expected the cap to be lowered to 2, got %d", l.limit)
+	}
+
+	// the waiting session gives up once the ctx is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.acquire(ctx); err != context.DeadlineExceeded {
+		t.Errorf("acquire() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		_ = l.acquire(context.Background())
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatalf("the third session should wait for the lowered cap")
+	case <-time.After(10 * time.Millisecond):
+	}
+	l.release()
+	<-acquired
+}
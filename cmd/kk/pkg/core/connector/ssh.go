@@ -39,6 +39,7 @@ import (
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/common"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
+	"github.com/kubesphere/kubekey/v3/util/hostkey"
 )
 
 type Cfg struct {
@@ -53,16 +54,46 @@ type Cfg struct {
 	Bastion     string
 	BastionPort int
 	BastionUser string
+	// ProxyJump is the chain of the hops to the host, each hop is dialed through the previous one.
+	// It takes precedence over the single Bastion.
+	ProxyJump []Cfg
+	// MaxSessions caps the concurrent sessions on the connection, it is lowered if the server allows fewer.
+	MaxSessions int
+	// KeepAliveInterval is the interval of the keepalive probes, the connection is re-established after
+	// keepAliveCountMax probes fail in a row.
+	KeepAliveInterval time.Duration
+
+	HostKeyChecking    string
+	HostKeyFingerprint string
+	KnownHosts         *hostkey.KnownHosts
 }
 
-const socketEnvPrefix = "env:"
+const (
+	socketEnvPrefix = "env:"
+
+	// DefaultMaxSessions is the default MaxSessions of OpenSSH.
+	DefaultMaxSessions       = 10
+	DefaultKeepAliveInterval = 15 * time.Second
+
+	keepAliveRequest  = "keepalive@openssh.com"
+	keepAliveCountMax = 3
+	reconnectRetries  = 5
+	reconnectBackoff  = time.Second
+)
 
 type connection struct {
 	mu         sync.Mutex
+	cfg        Cfg
+	hops       []Cfg
 	sftpclient *sftp.Client
 	sshclient  *ssh.Client
-	ctx        context.Context
-	cancel     context.CancelFunc
+	// jumps are the clients of the hops which the ssh client is dialed through, in order
+	jumps []*ssh.Client
+	// broken is set once the ssh client is dropped, the next session re-establishes the connection
+	broken   bool
+	sessions *sessionLimiter
+	ctx      context.Context
+	cancel   context.CancelFunc
 }
 
 func NewConnection(cfg Cfg) (Connection, error) {
@@ -71,6 +102,199 @@ func NewConnection(cfg Cfg) (Connection, error) {
 		return nil, errors.Wrap(err, "Failed to validate ssh connection parameters")
 	}
 
+	hops, err := proxyJump(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to validate ssh connection parameters")
+	}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	sshConn := &connection{
+		cfg:      cfg,
+		hops:     hops,
+		sessions: newSessionLimiter(cfg.MaxSessions),
+		ctx:      ctx,
+		cancel:   cancelFn,
+	}
+	if err := sshConn.connect(); err != nil {
+		cancelFn()
+		return nil, err
+	}
+	return sshConn, nil
+}
+
+// connect dials the host through the hops and starts the keepalive probes. The caller must hold the lock
+// unless the connection is being created.
+func (c *connection) connect() error {
+	jumps := make([]*ssh.Client, 0, len(c.hops))
+	closeJumps := func() {
+		for i := len(jumps) - 1; i >= 0; i-- {
+			_ = jumps[i].Close()
+		}
+	}
+
+	var through *ssh.Client
+	for _, hop := range c.hops {
+		jump, err := dial(through, hop)
+		if err != nil {
+			closeJumps()
+			return errors.Wrap(err, "failed to connect to the bastion")
+		}
+		jumps = append(jumps, jump)
+		through = jump
+	}
+
+	client, err := dial(through, c.cfg)
+	if err != nil {
+		closeJumps()
+		return err
+	}
+
+	sftpClient, err := sftp.NewClient(client)
+	if err != nil {
+		_ = client.Close()
+		closeJumps()
+		return errors.Wrapf(err, "new sftp client failed: %v", err)
+	}
+
+	c.sshclient = client
+	c.sftpclient = sftpClient
+	c.jumps = jumps
+	c.broken = false
+	go c.keepAlive(client)
+	return nil
+}
+
+// disconnect closes the clients of the connection, the caller must hold the lock.
+func (c *connection) disconnect() {
+	if c.sftpclient != nil {
+		c.sftpclient.Close()
+		c.sftpclient = nil
+	}
+	if c.sshclient != nil {
+		c.sshclient.Close()
+		c.sshclient = nil
+	}
+	for i := len(c.jumps) - 1; i >= 0; i-- {
+		c.jumps[i].Close()
+	}
+	c.jumps = nil
+}
+
+// reconnect re-establishes the dropped connection, retrying with an exponential backoff. The caller must hold the lock.
+func (c *connection) reconnect() error {
+	c.disconnect()
+
+	backoff := reconnectBackoff
+	var err error
+	for i := 0; i < reconnectRetries; i++ {
+		if i > 0 {
+			logger.Log.Warnf("reconnect to %s failed: %v, retry in %s", c.cfg.Address, err, backoff)
+			timer := time.NewTimer(backoff)
+			select {
+			case <-c.ctx.Done():
+				timer.Stop()
+				return errors.New("connection closed")
+			case <-timer.C:
+			}
+			backoff *= 2
+		}
+		if err = c.connect(); err == nil {
+			logger.Log.Infof("reconnected to %s", c.cfg.Address)
+			return nil
+		}
+	}
+	return errors.Wrapf(err, "failed to reconnect to %s after %d retries", c.cfg.Address, reconnectRetries)
+}
+
+// keepAlive probes the server periodically like the ServerAliveInterval of OpenSSH. The client is closed after
+// keepAliveCountMax probes fail in a row, so the running commands return at once instead of hanging on a dead
+// TCP connection, and the next session reconnects.
+func (c *connection) keepAlive(client *ssh.Client) {
+	dropped := make(chan struct{})
+	go func() {
+		_ = client.Wait()
+		close(dropped)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.sshclient == client {
+			c.broken = true
+		}
+	}()
+
+	ticker := time.NewTicker(c.cfg.KeepAliveInterval)
+	defer ticker.Stop()
+	failed := 0
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-dropped:
+			return
+		case <-ticker.C:
+		}
+
+		if err := probe(client, c.cfg.KeepAliveInterval); err != nil {
+			failed++
+			logger.Log.Debugf("keepalive probe to %s failed (%d/%d): %v", c.cfg.Address, failed, keepAliveCountMax, err)
+			if failed >= keepAliveCountMax {
+				logger.Log.Warnf("the connection to %s is not responding, it will be re-established by the next command", c.cfg.Address)
+				_ = client.Close()
+				return
+			}
+			continue
+		}
+		failed = 0
+	}
+}
+
+func probe(client *ssh.Client, timeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		_, _, err := client.SendRequest(keepAliveRequest, true, nil)
+		errCh <- err
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-errCh:
+		return err
+	case <-timer.C:
+		return errors.Errorf("no reply in %s", timeout)
+	}
+}
+
+// dial connects to the host of the cfg directly, or through the client of the previous hop.
+func dial(through *ssh.Client, cfg Cfg) (*ssh.Client, error) {
+	sshConfig, err := clientConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := net.JoinHostPort(cfg.Address, strconv.Itoa(cfg.Port))
+	if through == nil {
+		client, err := ssh.Dial("tcp", endpoint, sshConfig)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not establish connection to %s", endpoint)
+		}
+		return client, nil
+	}
+
+	conn, err := through.Dial("tcp", endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not establish connection to %s", endpoint)
+	}
+
+	ncc, chans, reqs, err := ssh.NewClientConn(conn, endpoint, sshConfig)
+	if err != nil {
+		_ = conn.Close()
+		return nil, errors.Wrapf(err, "could not establish connection to %s", endpoint)
+	}
+	return ssh.NewClient(ncc, chans, reqs), nil
+}
+
+func clientConfig(cfg Cfg) (*ssh.ClientConfig, error) {
 	authMethods := make([]ssh.AuthMethod, 0)
 
 	if len(cfg.Password) > 0 {
@@ -112,65 +336,54 @@ func NewConnection(cfg Cfg) (Connection, error) {
 		authMethods = append(authMethods, ssh.PublicKeys(signers...))
 	}
 
-	sshConfig := &ssh.ClientConfig{
+	hostKeyCallback, err := cfg.KnownHosts.HostKeyCallback(cfg.HostKeyChecking, cfg.HostKeyFingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ssh.ClientConfig{
 		User:            cfg.Username,
 		Timeout:         cfg.Timeout,
 		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	}
-
-	targetHost := cfg.Address
-	targetPort := strconv.Itoa(cfg.Port)
-
-	if cfg.Bastion != "" {
-		targetHost = cfg.Bastion
-		targetPort = strconv.Itoa(cfg.BastionPort)
-		sshConfig.User = cfg.BastionUser
-	}
-
-	endpoint := net.JoinHostPort(targetHost, targetPort)
+		HostKeyCallback: hostKeyCallback,
+	}, nil
+}
 
-	client, err := ssh.Dial("tcp", endpoint, sshConfig)
-	if err != nil {
-		return nil, errors.Wrapf(err, "could not establish connection to %s", endpoint)
+// proxyJump returns the validated hops to the host of the cfg. A hop inherits the host key checking mode and the
+// timeout of the host, and also its user and credentials if the hop has none. The fingerprint is pinned for the host
+// itself, so it is not verified on the hops.
+func proxyJump(cfg Cfg) ([]Cfg, error) {
+	hops := cfg.ProxyJump
+	if len(hops) == 0 && cfg.Bastion != "" {
+		hops = []Cfg{{Address: cfg.Bastion, Port: cfg.BastionPort, Username: cfg.BastionUser}}
 	}
 
-	ctx, cancelFn := context.WithCancel(context.Background())
-	sshConn := &connection{
-		ctx:    ctx,
-		cancel: cancelFn,
-	}
+	res := make([]Cfg, 0, len(hops))
+	for _, hop := range hops {
+		if hop.Username == "" {
+			hop.Username = cfg.Username
+		}
+		if hop.Password == "" && hop.PrivateKey == "" && hop.KeyFile == "" && hop.AgentSocket == "" {
+			hop.Password = cfg.Password
+			hop.PrivateKey = cfg.PrivateKey
+			hop.AgentSocket = cfg.AgentSocket
+		}
+		if hop.Timeout == 0 {
+			hop.Timeout = cfg.Timeout
+		}
+		hop.HostKeyChecking = cfg.HostKeyChecking
+		hop.HostKeyFingerprint = ""
+		hop.KnownHosts = cfg.KnownHosts
+		hop.Bastion = ""
+		hop.ProxyJump = nil
 
-	if cfg.Bastion == "" {
-		sshConn.sshclient = client
-		sftpClient, err := sftp.NewClient(sshConn.sshclient)
+		validated, err := validateOptions(hop)
 		if err != nil {
-			return nil, errors.Wrapf(err, "new sftp client failed: %v", err)
+			return nil, errors.Wrapf(err, "invalid bastion %s", hop.Address)
 		}
-		sshConn.sftpclient = sftpClient
-		return sshConn, nil
-	}
-
-	endpointBehindBastion := net.JoinHostPort(cfg.Address, strconv.Itoa(cfg.Port))
-
-	conn, err := client.Dial("tcp", endpointBehindBastion)
-	if err != nil {
-		return nil, errors.Wrapf(err, "could not establish connection to %s", endpointBehindBastion)
+		res = append(res, validated)
 	}
-
-	sshConfig.User = cfg.Username
-	ncc, chans, reqs, err := ssh.NewClientConn(conn, endpointBehindBastion, sshConfig)
-	if err != nil {
-		return nil, errors.Wrapf(err, "could not establish connection to %s", endpointBehindBastion)
-	}
-
-	sshConn.sshclient = ssh.NewClient(ncc, chans, reqs)
-	sftpClient, err := sftp.NewClient(sshConn.sshclient)
-	if err != nil {
-		return nil, errors.Wrapf(err, "new sftp client failed: %v", err)
-	}
-	sshConn.sftpclient = sftpClient
-	return sshConn, nil
+	return res, nil
 }
 
 func validateOptions(cfg Cfg) (Cfg, error) {
@@ -212,39 +425,78 @@ func validateOptions(cfg Cfg) (Cfg, error) {
 		cfg.Timeout = 15 * time.Second
 	}
 
+	if cfg.MaxSessions <= 0 {
+		cfg.MaxSessions = DefaultMaxSessions
+	}
+
+	if cfg.KeepAliveInterval <= 0 {
+		cfg.KeepAliveInterval = DefaultKeepAliveInterval
+	}
+
 	return cfg, nil
 }
 
 func (c *connection) Close() {
+	// cancel first to stop a reconnecting session which holds the lock
+	c.cancel()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.disconnect()
+}
 
-	if c.sshclient == nil && c.sftpclient == nil {
-		return
-	}
-	c.cancel()
+// session opens a new session with a pty, it waits while the sessions of the connection reach the cap unless
+// the ctx is done. The slot of the session is released when it is closed.
+func (c *connection) session(ctx context.Context) (*session, error) {
+	for {
+		if err := c.sessions.acquire(ctx); err != nil {
+			return nil, errors.Wrap(err, "wait for a session of the connection failed")
+		}
+		sess, err := c.newSession()
+		if err == nil {
+			return &session{Session: sess, release: c.sessions.release}, nil
+		}
+		c.sessions.release()
 
-	if c.sshclient != nil {
-		c.sshclient.Close()
-		c.sshclient = nil
-	}
-	if c.sftpclient != nil {
-		c.sftpclient.Close()
-		c.sftpclient = nil
+		var openErr *ssh.OpenChannelError
+		if !errors.As(err, &openErr) || openErr.Reason != ssh.Prohibited {
+			return nil, err
+		}
+		// the MaxSessions of the server is lower than the cap, wait for a running session instead
+		if !c.sessions.shrink() {
+			return nil, err
+		}
 	}
 }
 
-func (c *connection) session() (*ssh.Session, error) {
+func (c *connection) newSession() (*ssh.Session, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.sshclient == nil {
+	if c.ctx.Err() != nil {
 		return nil, errors.New("connection closed")
 	}
 
+	if c.broken || c.sshclient == nil {
+		if err := c.reconnect(); err != nil {
+			return nil, err
+		}
+	}
+
 	sess, err := c.sshclient.NewSession()
 	if err != nil {
-		return nil, err
+		var openErr *ssh.OpenChannelError
+		if errors.As(err, &openErr) {
+			return nil, err
+		}
+		// the connection has been dropped before the keepalive probes notice it
+		logger.Log.Debugf("open session to %s failed: %v, reconnecting", c.cfg.Address, err)
+		if err := c.reconnect(); err != nil {
+			return nil, err
+		}
+		if sess, err = c.sshclient.NewSession(); err != nil {
+			return nil, err
+		}
 	}
 
 	modes := ssh.TerminalModes{
@@ -255,18 +507,55 @@ func (c *connection) session() (*ssh.Session, error) {
 
 	err = sess.RequestPty("xterm", 100, 50, modes)
 	if err != nil {
+		_ = sess.Close()
 		return nil, err
 	}
 
 	return sess, nil
 }
 
-func (c *connection) PExec(cmd string, stdin io.Reader, stdout io.Writer, stderr io.Writer, host Host) (int, error) {
-	sess, err := c.session()
+// sftp returns the sftp client, the connection is re-established first if it has been dropped.
+func (c *connection) sftp() (*sftp.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ctx.Err() != nil {
+		return nil, errors.New("connection closed")
+	}
+
+	if c.broken || c.sftpclient == nil {
+		if err := c.reconnect(); err != nil {
+			return nil, err
+		}
+	}
+	return c.sftpclient, nil
+}
+
+// stopOnDone stops the remote command of the session when the context is done. The command
+// is signaled first, closing the session then hangs up its pty in case the signal is not supported
+// by the ssh server. The returned function has to be called once the command exits.
+func stopOnDone(ctx context.Context, sess *ssh.Session) func() {
+	exited := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = sess.Signal(ssh.SIGTERM)
+			_ = sess.Close()
+		case <-exited:
+		}
+	}()
+	return func() {
+		close(exited)
+	}
+}
+
+func (c *connection) PExec(ctx context.Context, cmd string, stdin io.Reader, stdout io.Writer, stderr io.Writer, host Host) (int, error) {
+	sess, err := c.session(ctx)
 	if err != nil {
 		return 1, errors.Wrap(err, "failed to get SSH session")
 	}
 	defer sess.Close()
+	defer stopOnDone(ctx, sess.Session)()
 
 	sess.Stdin = stdin
 	sess.Stdout = stdout
@@ -320,17 +609,21 @@ func (c *connection) PExec(cmd string, stdin io.Reader, stdout io.Writer, stderr
 			exitCode = exitErr.ExitStatus()
 		}
 	}
+	if ctx.Err() != nil {
+		return exitCode, errors.Wrapf(ctx.Err(), "Command has been stopped: %s", cmd)
+	}
 
 	// preserve original error
 	return exitCode, err
 }
 
-func (c *connection) Exec(cmd string, host Host) (stdout string, code int, err error) {
-	sess, err := c.session()
+func (c *connection) Exec(ctx context.Context, cmd string, host Host) (stdout string, code int, err error) {
+	sess, err := c.session(ctx)
 	if err != nil {
 		return "", 1, errors.Wrap(err, "failed to get SSH session")
 	}
 	defer sess.Close()
+	defer stopOnDone(ctx, sess.Session)()
 
 	exitCode := 0
 
@@ -382,12 +675,15 @@ func (c *connection) Exec(cmd string, host Host) (stdout string, code int, err e
 		}
 	}
 	outStr := strings.TrimPrefix(string(output), fmt.Sprintf("[sudo] password for %s:", host.GetUser()))
+	if ctx.Err() != nil {
+		return strings.TrimSpace(outStr), exitCode, errors.Wrapf(ctx.Err(), "Command has been stopped: %s \n%s", cmd, strings.TrimSpace(outStr))
+	}
 
 	// preserve original error
 	return strings.TrimSpace(outStr), exitCode, errors.Wrapf(err, "Failed to exec command: %s \n%s", cmd, strings.TrimSpace(outStr))
 }
 
-func (c *connection) Fetch(local, remote string, host Host) error {
+func (c *connection) Fetch(ctx context.Context, local, remote string, host Host) error {
 	//srcFile, err := c.sftpclient.Open(remote)
 	//if err != nil {
 	//	return fmt.Errorf("open remote file failed %v, remote path: %s", err, remote)
@@ -395,7 +691,7 @@ func (c *connection) Fetch(local, remote string, host Host) error {
 	//defer srcFile.Close()
 
 	// Base64 encoding is performed on the contents of the file to prevent garbled code in the target file.
-	output, _, err := c.Exec(SudoPrefix(fmt.Sprintf("cat %s | base64 -w 0", remote)), host)
+	output, _, err := c.Exec(ctx, SudoPrefix(fmt.Sprintf("cat %s | base64 -w 0", remote)), host)
 	if err != nil {
 		return fmt.Errorf("open remote file failed %v, remote path: %s", err, remote)
 	}
@@ -427,10 +723,10 @@ type scpErr struct {
 	err error
 }
 
-func (c *connection) Scp(src, dst string, host Host) error {
+func (c *connection) Scp(ctx context.Context, src, dst string, host Host) error {
 	baseRemotePath := filepath.Dir(dst)
 
-	if err := c.MkDirAll(baseRemotePath, "777", host); err != nil {
+	if err := c.MkDirAll(ctx, baseRemotePath, "777", host); err != nil {
 		return err
 	}
 	f, err := os.Stat(src)
@@ -449,26 +745,26 @@ func (c *connection) Scp(src, dst string, host Host) error {
 
 	scpErr := new(scpErr)
 	if f.IsDir() {
-		c.copyDirToRemote(src, dst, scpErr, host)
+		c.copyDirToRemote(ctx, src, dst, scpErr, host)
 		if scpErr.err != nil {
 			return scpErr.err
 		}
 	} else {
-		if err := c.copyFileToRemote(src, dst, host); err != nil {
+		if err := c.copyFileToRemote(ctx, src, dst, host); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (c *connection) copyDirToRemote(src, dst string, scrErr *scpErr, host Host) {
+func (c *connection) copyDirToRemote(ctx context.Context, src, dst string, scrErr *scpErr, host Host) {
 	localFiles, err := os.ReadDir(src)
 	if err != nil {
 		logger.Log.Errorf("read local path dir %s failed %v", src, err)
 		scrErr.err = err
 		return
 	}
-	if err = c.MkDirAll(dst, "", host); err != nil {
+	if err = c.MkDirAll(ctx, dst, "", host); err != nil {
 		logger.Log.Errorf("failed to create remote path %s:%v", dst, err)
 		scrErr.err = err
 		return
@@ -477,14 +773,14 @@ func (c *connection) copyDirToRemote(src, dst string, scrErr *scpErr, host Host)
 		local := path.Join(src, file.Name())
 		remote := path.Join(dst, file.Name())
 		if file.IsDir() {
-			if err = c.MkDirAll(remote, "", host); err != nil {
+			if err = c.MkDirAll(ctx, remote, "", host); err != nil {
 				logger.Log.Errorf("failed to create remote path %s:%v", remote, err)
 				scrErr.err = err
 				return
 			}
-			c.copyDirToRemote(local, remote, scrErr, host)
+			c.copyDirToRemote(ctx, local, remote, scrErr, host)
 		} else {
-			err := c.copyFileToRemote(local, remote, host)
+			err := c.copyFileToRemote(ctx, local, remote, host)
 			if err != nil {
 				logger.Log.Errorf("copy local file %s to remote file %s failed %v ", local, remote, err)
 				scrErr.err = err
@@ -494,14 +790,17 @@ func (c *connection) copyDirToRemote(src, dst string, scrErr *scpErr, host Host)
 	}
 }
 
-func (c *connection) copyFileToRemote(src, dst string, host Host) error {
+func (c *connection) copyFileToRemote(ctx context.Context, src, dst string, host Host) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	// check remote file md5 first
 	var (
 		srcMd5, dstMd5 string
 	)
 	srcMd5 = util.LocalMd5Sum(src)
-	if c.RemoteFileExist(dst, host) {
-		dstMd5 = c.RemoteMd5Sum(dst, host)
+	if c.RemoteFileExist(ctx, dst, host) {
+		dstMd5 = c.RemoteMd5Sum(ctx, dst, host)
 		if srcMd5 == dstMd5 {
 			logger.Log.Debug("remote file %s md5 value is the same as local file, skip scp", dst)
 			return nil
@@ -514,7 +813,11 @@ func (c *connection) copyFileToRemote(src, dst string, host Host) error {
 	}
 	defer srcFile.Close()
 	// the dst file mod will be 0666
-	dstFile, err := c.sftpclient.Create(dst)
+	sftpClient, err := c.sftp()
+	if err != nil {
+		return err
+	}
+	dstFile, err := sftpClient.Create(dst)
 	if err != nil {
 		return err
 	}
@@ -526,33 +829,46 @@ func (c *connection) copyFileToRemote(src, dst string, host Host) error {
 		return fmt.Errorf("chmod remote file failed %v", err)
 	}
 	defer dstFile.Close()
-	_, err = io.Copy(dstFile, srcFile)
+	_, err = io.Copy(dstFile, &contextReader{ctx: ctx, r: srcFile})
 	if err != nil {
 		return err
 	}
-	dstMd5 = c.RemoteMd5Sum(dst, host)
+	dstMd5 = c.RemoteMd5Sum(ctx, dst, host)
 	if srcMd5 != dstMd5 {
 		return fmt.Errorf("validate md5sum failed %s != %s", srcMd5, dstMd5)
 	}
 	return nil
 }
 
-func (c *connection) RemoteMd5Sum(dst string, host Host) string {
+// contextReader stops copying a file once the context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+func (c *connection) RemoteMd5Sum(ctx context.Context, dst string, host Host) string {
 	cmd := fmt.Sprintf("md5sum %s | cut -d\" \" -f1", dst)
-	remoteMd5, _, err := c.Exec(cmd, host)
+	remoteMd5, _, err := c.Exec(ctx, cmd, host)
 	if err != nil {
 		logger.Log.Errorf("exec countRemoteMd5Command %s failed: %v", cmd, err)
 	}
 	return remoteMd5
 }
 
-func (c *connection) RemoteFileExist(dst string, host Host) bool {
+func (c *connection) RemoteFileExist(ctx context.Context, dst string, host Host) bool {
 	remoteFileName := path.Base(dst)
 	remoteFileDirName := path.Dir(dst)
 
 	remoteFileCommand := fmt.Sprintf(SudoPrefix("ls -l %s/%s 2>/dev/null |wc -l"), remoteFileDirName, remoteFileName)
 
-	out, _, err := c.Exec(remoteFileCommand, host)
+	out, _, err := c.Exec(ctx, remoteFileCommand, host)
 	defer func() {
 		if r := recover(); r != nil {
 			logger.Log.Errorf("exec remoteFileCommand %s err: %v", remoteFileCommand, err)
@@ -573,14 +889,21 @@ func (c *connection) RemoteFileExist(dst string, host Host) bool {
 	return count != 0
 }
 
-func (c *connection) RemoteDirExist(dst string, host Host) (bool, error) {
-	if _, err := c.sftpclient.ReadDir(dst); err != nil {
+func (c *connection) RemoteDirExist(ctx context.Context, dst string, host Host) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	sftpClient, err := c.sftp()
+	if err != nil {
+		return false, err
+	}
+	if _, err := sftpClient.ReadDir(dst); err != nil {
 		return false, err
 	}
 	return true, nil
 }
 
-func (c *connection) MkDirAll(path string, mode string, host Host) error {
+func (c *connection) MkDirAll(ctx context.Context, path string, mode string, host Host) error {
 	if mode == "" {
 		mode = "775"
 	}
@@ -588,7 +911,7 @@ func (c *connection) MkDirAll(path string, mode string, host Host) error {
 	if strings.Contains(path, common.TmpDir) {
 		mkDstDir = fmt.Sprintf("mkdir -p  %s && chmod -R  %s  %s || true", path, mode, common.TmpDir)
 	}
-	if _, _, err := c.Exec(SudoPrefix(mkDstDir), host); err != nil {
+	if _, _, err := c.Exec(ctx, SudoPrefix(mkDstDir), host); err != nil {
 		return err
 	}
 
@@ -597,7 +920,11 @@ func (c *connection) MkDirAll(path string, mode string, host Host) error {
 
 func (c *connection) Chmod(path string, mode os.FileMode) error {
 	remotePath := filepath.Dir(path)
-	if err := c.sftpclient.Chmod(remotePath, mode); err != nil {
+	sftpClient, err := c.sftp()
+	if err != nil {
+		return err
+	}
+	if err := sftpClient.Chmod(remotePath, mode); err != nil {
 		return err
 	}
 	return nil
@@ -0,0 +1,180 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package connector
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+	"github.com/kubesphere/kubekey/v3/util/hostkey"
+)
+
+func TestProxyJump(t *testing.T) {
+	knownHosts := hostkey.New(&hostkey.FileStore{Path: KnownHostsFile}, nil)
+	host := Cfg{
+		Username:           "root",
+		Password:           "secret",
+		Address:            "172.16.0.2",
+		Port:               22,
+		Timeout:            30 * time.Second,
+		HostKeyChecking:    hostkey.CheckingStrict,
+		HostKeyFingerprint: "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8",
+		KnownHosts:         knownHosts,
+	}
+	hop := func(address string, port int, user, password, agent string) Cfg {
+		return Cfg{
+			Username:          user,
+			Password:          password,
+			Address:           address,
+			Port:              port,
+			AgentSocket:       agent,
+			Timeout:           30 * time.Second,
+			BastionPort:       22,
+			BastionUser:       user,
+			MaxSessions:       DefaultMaxSessions,
+			KeepAliveInterval: DefaultKeepAliveInterval,
+			HostKeyChecking:   hostkey.CheckingStrict,
+			KnownHosts:        knownHosts,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		cfg     func(cfg Cfg) Cfg
+		want    []Cfg
+		wantErr bool
+	}{
+		{
+			name: "no bastion",
+			cfg:  func(cfg Cfg) Cfg { return cfg },
+			want: []Cfg{},
+		},
+		{
+			name: "legacy bastion uses the credentials of the host",
+			cfg: func(cfg Cfg) Cfg {
+				cfg.Bastion, cfg.BastionPort, cfg.BastionUser = "10.0.0.1", 2222, "jump"
+				return cfg
+			},
+			want: []Cfg{hop("10.0.0.1", 2222, "jump", "secret", "")},
+		},
+		{
+			name: "chain of hops with their own credentials",
+			cfg: func(cfg Cfg) Cfg {
+				cfg.ProxyJump = []Cfg{
+					{Address: "10.0.0.1", Username: "ops", AgentSocket: "env:SSH_AUTH_SOCK"},
+					{Address: "192.168.0.1", Port: 2222},
+				}
+				return cfg
+			},
+			want: []Cfg{
+				hop("10.0.0.1", 22, "ops", "", "env:SSH_AUTH_SOCK"),
+				hop("192.168.0.1", 2222, "root", "secret", ""),
+			},
+		},
+		{
+			name: "hop without address",
+			cfg: func(cfg Cfg) Cfg {
+				cfg.ProxyJump = []Cfg{{Username: "ops"}}
+				return cfg
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := proxyJump(tt.cfg(host))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("proxyJump() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("proxyJump() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextReader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &contextReader{ctx: ctx, r: bytes.NewReader(make([]byte, 1024))}
+
+	buf := make([]byte, 512)
+	if n, err := r.Read(buf); n != 512 || err != nil {
+		t.Fatalf("Read() = %d, %v", n, err)
+	}
+	cancel()
+	if _, err := io.Copy(io.Discard, r); err != context.Canceled {
+		t.Errorf("copy after the context is done = %v, want %v", err, context.Canceled)
+	}
+}
+
+// ctxConnection records the contexts the file operations are called with.
+type ctxConnection struct {
+	dryRunConnection
+	contexts []context.Context
+}
+
+func (c *ctxConnection) Fetch(ctx context.Context, local, remote string, host Host) error {
+	c.contexts = append(c.contexts, ctx)
+	return nil
+}
+
+func (c *ctxConnection) Scp(ctx context.Context, local, remote string, host Host) error {
+	c.contexts = append(c.contexts, ctx)
+	return nil
+}
+
+func (c *ctxConnection) RemoteFileExist(ctx context.Context, remote string, host Host) bool {
+	c.contexts = append(c.contexts, ctx)
+	return false
+}
+
+func (c *ctxConnection) MkDirAll(ctx context.Context, path string, mode string, host Host) error {
+	c.contexts = append(c.contexts, ctx)
+	return nil
+}
+
+func TestRunnerPassesTaskContext(t *testing.T) {
+	logger.Log = logger.NewLogger(t.TempDir(), false)
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "task")
+	conn := &ctxConnection{dryRunConnection: dryRunConnection{plan: NewPlan()}}
+	r := &Runner{Conn: conn, Host: &BaseHost{Name: "node1"}, Ctx: ctx}
+
+	_ = r.Fetch("/tmp/admin.conf", "/etc/kubernetes/admin.conf")
+	_ = r.Scp("/tmp/kubelet", "/usr/local/bin/kubelet")
+	_, _ = r.FileExist("/usr/local/bin/kubelet")
+	_ = r.MkDir("/etc/kubernetes")
+
+	if len(conn.contexts) != 4 {
+		t.Fatalf("expected 4 file operations, got %d", len(conn.contexts))
+	}
+	for i, c := range conn.contexts {
+		if c.Value(key{}) != "task" {
+			t.Errorf("file operation %d is not called with the task context", i)
+		}
+	}
+}
@@ -26,6 +26,7 @@ type ActionResult struct {
 	Host      connector.Host
 	Status    ResultStatus
 	Error     error
+	Reason    string
 	StartTime time.Time
 	EndTime   time.Time
 }
@@ -28,10 +28,14 @@ import (
 
 type TaskResult struct {
 	mu            sync.Mutex
+	Name          string
+	Desc          string
 	ActionResults []*ActionResult
-	Status        ResultStatus
-	StartTime     time.Time
-	EndTime       time.Time
+	// RollbackResults are the results of undoing the task on the hosts it has been run on.
+	RollbackResults []*ActionResult
+	Status          ResultStatus
+	StartTime       time.Time
+	EndTime         time.Time
 }
 
 func NewTaskResult() *TaskResult {
@@ -62,7 +66,7 @@ func (t *TaskResult) SkippedResult() {
 	t.Status = SKIPPED
 }
 
-func (t *TaskResult) AppendSkip(host connector.Host) {
+func (t *TaskResult) AppendSkip(host connector.Host, reason string) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	now := time.Now()
@@ -70,6 +74,7 @@ func (t *TaskResult) AppendSkip(host connector.Host) {
 		Host:      host,
 		Status:    SKIPPED,
 		Error:     nil,
+		Reason:    reason,
 		StartTime: t.StartTime,
 		EndTime:   now,
 	}
@@ -111,6 +116,23 @@ func (t *TaskResult) AppendErr(host connector.Host, err error) {
 	t.Status = FAILED
 }
 
+func (t *TaskResult) AppendRollback(host connector.Host, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	status := SUCCESS
+	if err != nil {
+		status = FAILED
+	}
+	now := time.Now()
+	t.RollbackResults = append(t.RollbackResults, &ActionResult{
+		Host:      host,
+		Status:    status,
+		Error:     err,
+		StartTime: now,
+		EndTime:   now,
+	})
+}
+
 func (t *TaskResult) IsFailed() bool {
 	t.mu.Lock()
 	defer t.mu.Unlock()
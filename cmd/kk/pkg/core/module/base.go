@@ -17,6 +17,8 @@
 package module
 
 import (
+	"context"
+
 	"github.com/pkg/errors"
 
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/cache"
@@ -55,7 +57,7 @@ func (b *BaseModule) Is() string {
 	return BaseModuleType
 }
 
-func (b *BaseModule) Run(result *ending.ModuleResult) {
+func (b *BaseModule) Run(ctx context.Context, result *ending.ModuleResult) {
 	panic("implement me")
 }
 
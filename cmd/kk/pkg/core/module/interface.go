@@ -17,6 +17,8 @@
 package module
 
 import (
+	"context"
+
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/cache"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/ending"
@@ -27,10 +29,22 @@ type Module interface {
 	Default(runtime connector.Runtime, pipelineCache *cache.Cache, moduleCache *cache.Cache)
 	Init()
 	Is() string
-	Run(result *ending.ModuleResult)
+	Run(ctx context.Context, result *ending.ModuleResult)
 	Until() (*bool, error)
 	Slogan()
 	AutoAssert()
 	AppendPostHook(h PostHookInterface)
 	CallPostHook(result *ending.ModuleResult) error
 }
+
+// Replayable is implemented by modules that have to run again when a pipeline is resumed
+// from its checkpoint, e.g. the confirmation. The modules which change the pipeline cache or
+// the host caches run again anyway, because the caches are not kept in the checkpoint.
+type Replayable interface {
+	AlwaysRun() bool
+}
+
+// Rollbackable is implemented by modules that can undo their tasks when they fail.
+type Rollbackable interface {
+	Rollback()
+}
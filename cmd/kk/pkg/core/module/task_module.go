@@ -17,6 +17,8 @@
 package module
 
 import (
+	"context"
+
 	"github.com/pkg/errors"
 
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
@@ -28,6 +30,8 @@ import (
 type BaseTaskModule struct {
 	BaseModule
 	Tasks []task.Interface
+
+	executed []task.Interface
 }
 
 func (b *BaseTaskModule) Init() {
@@ -40,13 +44,21 @@ func (b *BaseTaskModule) Is() string {
 	return TaskModuleType
 }
 
-func (b *BaseTaskModule) Run(result *ending.ModuleResult) {
+func (b *BaseTaskModule) Run(ctx context.Context, result *ending.ModuleResult) {
+	b.executed = b.executed[:0]
 	for i := range b.Tasks {
+		if err := ctx.Err(); err != nil {
+			result.ErrResult(errors.Wrapf(err, "Module[%s] has been interrupted", b.Name))
+			return
+		}
+
 		t := b.Tasks[i]
 		t.Init(b.Runtime.(connector.Runtime), b.ModuleCache, b.PipelineCache)
 
 		logger.Log.Infof("[%s] %s", b.Name, t.GetDesc())
-		res := t.Execute()
+		res := t.Execute(ctx)
+		b.executed = append(b.executed, t)
+		result.AppendTaskResult(res)
 		for j := range res.ActionResults {
 			ac := res.ActionResults[j]
 			logger.Log.Infof("%s: [%s]", ac.Status.String(), ac.Host.GetName())
@@ -68,10 +80,23 @@ func (b *BaseTaskModule) Run(result *ending.ModuleResult) {
 		}
 
 		if res.IsFailed() {
-			t.ExecuteRollback()
 			result.ErrResult(errors.Wrapf(res.CombineErr(), "Module[%s] exec failed", b.Name))
 			return
 		}
 	}
+	// the last task may have been stopped on some of the hosts
+	if err := ctx.Err(); err != nil {
+		result.ErrResult(errors.Wrapf(err, "Module[%s] has been interrupted", b.Name))
+		return
+	}
 	result.NormalResult()
 }
+
+// Rollback undoes the tasks of the last run in reverse order, starting with the failed one.
+// Every task is undone on the hosts it has been run on, so each host is rolled back in the
+// reverse order of its own tasks.
+func (b *BaseTaskModule) Rollback() {
+	for i := len(b.executed) - 1; i >= 0; i-- {
+		b.executed[i].ExecuteRollback()
+	}
+}
@@ -0,0 +1,109 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/action"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/cache"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/ending"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/rollback"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/task"
+)
+
+type testAction struct {
+	action.BaseAction
+	err error
+}
+
+func (a *testAction) Execute(runtime connector.Runtime) error {
+	return a.err
+}
+
+type testRollback struct {
+	rollback.BaseRollback
+	name   string
+	mu     *sync.Mutex
+	undone *[]string
+}
+
+func (r *testRollback) Execute(runtime connector.Runtime, result *ending.ActionResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*r.undone = append(*r.undone, fmt.Sprintf("%s[%s]", r.name, runtime.RemoteHost().GetName()))
+	return nil
+}
+
+func TestBaseTaskModule_Rollback(t *testing.T) {
+	logger.Log = logger.NewLogger(t.TempDir(), false)
+
+	runtime := &connector.BaseRuntime{}
+	runtime.SetConnector(connector.NewDryRunDialer())
+	hosts := []connector.Host{&connector.BaseHost{Name: "node1"}, &connector.BaseHost{Name: "node2"}}
+
+	mu := &sync.Mutex{}
+	undone := make([]string, 0)
+	newTask := func(name string, err error, withRollback bool) *task.RemoteTask {
+		rt := &task.RemoteTask{
+			Name:   name,
+			Hosts:  hosts,
+			Action: &testAction{err: err},
+			Retry:  1,
+		}
+		if withRollback {
+			rt.Rollback = &testRollback{name: name, mu: mu, undone: &undone}
+		}
+		return rt
+	}
+
+	m := &BaseTaskModule{
+		Tasks: []task.Interface{
+			newTask("Install", nil, true),
+			newTask("Configure", nil, false),
+			newTask("Start", errors.New("start failed"), true),
+			newTask("Never", nil, true),
+		},
+	}
+	m.Default(runtime, cache.NewCache(), cache.NewCache())
+	m.Init()
+
+	result := ending.NewModuleResult()
+	m.Run(context.Background(), result)
+	if !result.IsFailed() {
+		t.Fatalf("the module should be failed")
+	}
+
+	m.Rollback()
+	want := []string{"Start[node1]", "Start[node2]", "Install[node1]", "Install[node2]"}
+	if !reflect.DeepEqual(undone, want) {
+		t.Errorf("unexpected rollback order: %v, want %v", undone, want)
+	}
+	if n := len(result.TaskResults[0].RollbackResults); n != 2 {
+		t.Errorf("expected 2 rollback results of Install, got %d", n)
+	}
+	if n := len(result.TaskResults[1].RollbackResults); n != 0 {
+		t.Errorf("Configure has no rollback, got %d results", n)
+	}
+}
@@ -0,0 +1,211 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/ending"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/module"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
+)
+
+const CheckpointDir = "checkpoints"
+
+// Checkpoint is the journal of a pipeline run. It is written to the work dir after
+// every module, so that a failed pipeline can be resumed from the failed module.
+type Checkpoint struct {
+	Pipeline  string             `json:"pipeline"`
+	Hosts     []string           `json:"hosts"`
+	Modules   []ModuleCheckpoint `json:"modules"`
+	UpdatedAt time.Time          `json:"updatedAt"`
+}
+
+type ModuleCheckpoint struct {
+	Index       int                       `json:"index"`
+	Module      string                    `json:"module"`
+	Status      string                    `json:"status"`
+	Hosts       []string                  `json:"hosts"`
+	HostResults map[string]HostCheckpoint `json:"hostResults,omitempty"`
+	StartTime   time.Time                 `json:"startTime"`
+	EndTime     time.Time                 `json:"endTime"`
+	// WritesCache is set if the module has changed the pipeline cache or the host caches, which are kept in memory
+	// only. Such a module runs again on resume, so that the later modules find the state they read.
+	WritesCache bool `json:"writesCache,omitempty"`
+}
+
+type HostCheckpoint struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+}
+
+func NewCheckpoint(name string, hosts []connector.Host) *Checkpoint {
+	return &Checkpoint{
+		Pipeline: name,
+		Hosts:    hostNames(hosts),
+		Modules:  make([]ModuleCheckpoint, 0),
+	}
+}
+
+func CheckpointPath(workDir, name string) string {
+	return filepath.Join(workDir, CheckpointDir, fmt.Sprintf("%s.json", name))
+}
+
+// LoadCheckpoint reads the journal from the given path. A missing journal is not an error,
+// a nil checkpoint is returned instead.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "read checkpoint %s failed", path)
+	}
+
+	c := &Checkpoint{}
+	if err := json.Unmarshal(content, c); err != nil {
+		return nil, errors.Wrapf(err, "parse checkpoint %s failed", path)
+	}
+	return c, nil
+}
+
+func (c *Checkpoint) Save(path string) error {
+	if err := util.CreateDir(filepath.Dir(path)); err != nil {
+		return errors.Wrap(err, "create checkpoint dir failed")
+	}
+
+	c.UpdatedAt = time.Now()
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshal checkpoint failed")
+	}
+
+	// write to a temporary file first, a half-written journal must never be resumed from
+	tmp := fmt.Sprintf("%s.tmp", path)
+	if err := os.WriteFile(tmp, content, common.FileMode0644); err != nil {
+		return errors.Wrapf(err, "write checkpoint %s failed", tmp)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Record appends or replaces the journal entry of the module at the given index.
+func (c *Checkpoint) Record(index int, m module.Module, result *ending.ModuleResult, hosts []connector.Host, writesCache bool) {
+	entry := ModuleCheckpoint{
+		Index:       index,
+		Module:      moduleType(m),
+		Status:      result.Status.String(),
+		Hosts:       hostNames(hosts),
+		HostResults: make(map[string]HostCheckpoint, len(result.HostResults)),
+		StartTime:   result.StartTime,
+		EndTime:     result.EndTime,
+		WritesCache: writesCache,
+	}
+	for name, r := range result.HostResults {
+		hc := HostCheckpoint{
+			Status:    r.GetStatus().String(),
+			StartTime: r.GetStartTime(),
+			EndTime:   r.GetEndTime(),
+		}
+		if r.GetErr() != nil {
+			hc.Error = r.GetErr().Error()
+		}
+		entry.HostResults[name] = hc
+	}
+
+	for i := range c.Modules {
+		if c.Modules[i].Index == index {
+			c.Modules[i] = entry
+			return
+		}
+	}
+	c.Modules = append(c.Modules, entry)
+}
+
+// Finished reports whether the module at the given index has already finished successfully
+// on every one of the given hosts in a previous run, and does not have to run again to restore the caches.
+func (c *Checkpoint) Finished(index int, m module.Module, hosts []connector.Host) bool {
+	if c == nil {
+		return false
+	}
+
+	for i := range c.Modules {
+		entry := c.Modules[i]
+		if entry.Index != index {
+			continue
+		}
+		if entry.Module != moduleType(m) || entry.Status != ending.SUCCESS.String() || entry.WritesCache {
+			return false
+		}
+		for _, r := range entry.HostResults {
+			if r.Status == ending.FAILED.String() {
+				return false
+			}
+		}
+
+		finished := make(map[string]struct{}, len(entry.Hosts))
+		for _, name := range entry.Hosts {
+			finished[name] = struct{}{}
+		}
+		for _, host := range hosts {
+			if _, ok := finished[host.GetName()]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Match reports whether the journal was written by the same pipeline for the same hosts.
+func (c *Checkpoint) Match(name string, hosts []connector.Host) bool {
+	if c == nil || c.Pipeline != name || len(c.Hosts) != len(hosts) {
+		return false
+	}
+
+	names := make(map[string]struct{}, len(c.Hosts))
+	for _, n := range c.Hosts {
+		names[n] = struct{}{}
+	}
+	for _, host := range hosts {
+		if _, ok := names[host.GetName()]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func moduleType(m module.Module) string {
+	return fmt.Sprintf("%T", m)
+}
+
+func hostNames(hosts []connector.Host) []string {
+	names := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		names = append(names, host.GetName())
+	}
+	return names
+}
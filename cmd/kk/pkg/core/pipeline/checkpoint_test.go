@@ -0,0 +1,103 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/ending"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/module"
+)
+
+type testModule struct {
+	module.BaseTaskModule
+}
+
+func TestCheckpoint(t *testing.T) {
+	node1 := &connector.BaseHost{Name: "node1"}
+	node2 := &connector.BaseHost{Name: "node2"}
+	node3 := &connector.BaseHost{Name: "node3"}
+	hosts := []connector.Host{node1, node2}
+
+	success := ending.NewModuleResult()
+	success.AppendHostResult(&ending.ActionResult{Host: node1, Status: ending.SUCCESS})
+	success.AppendHostResult(&ending.ActionResult{Host: node2, Status: ending.SKIPPED})
+	success.NormalResult()
+
+	failed := ending.NewModuleResult()
+	failed.AppendHostResult(&ending.ActionResult{Host: node1, Status: ending.SUCCESS})
+	failed.AppendHostResult(&ending.ActionResult{Host: node2, Status: ending.FAILED, Error: errors.New("failed")})
+	failed.ErrResult(errors.New("failed"))
+
+	c := NewCheckpoint("TestPipeline", hosts)
+	c.Record(0, &testModule{}, success, hosts, false)
+	c.Record(1, &testModule{}, failed, hosts, false)
+	c.Record(2, &testModule{}, success, hosts, true)
+
+	path := CheckpointPath(t.TempDir(), "TestPipeline")
+	if err := c.Save(path); err != nil {
+		t.Fatalf("save checkpoint failed: %v", err)
+	}
+	if filepath.Base(path) != "TestPipeline.json" {
+		t.Fatalf("unexpected checkpoint path %s", path)
+	}
+
+	loaded, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("load checkpoint failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		index int
+		m     module.Module
+		hosts []connector.Host
+		want  bool
+	}{
+		{name: "finished", index: 0, m: &testModule{}, hosts: hosts, want: true},
+		{name: "failed", index: 1, m: &testModule{}, hosts: hosts, want: false},
+		{name: "writes cache", index: 2, m: &testModule{}, hosts: hosts, want: false},
+		{name: "not recorded", index: 3, m: &testModule{}, hosts: hosts, want: false},
+		{name: "different module", index: 0, m: &module.BaseTaskModule{}, hosts: hosts, want: false},
+		{name: "new host", index: 0, m: &testModule{}, hosts: []connector.Host{node1, node2, node3}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := loaded.Finished(tt.index, tt.m, tt.hosts); got != tt.want {
+				t.Errorf("Finished() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !loaded.Match("TestPipeline", hosts) {
+		t.Errorf("Match() = false, want true")
+	}
+	if loaded.Match("TestPipeline", []connector.Host{node1, node3}) {
+		t.Errorf("Match() = true with different hosts, want false")
+	}
+	if loaded.Match("OtherPipeline", hosts) {
+		t.Errorf("Match() = true with different pipeline, want false")
+	}
+
+	missing, err := LoadCheckpoint(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || missing != nil {
+		t.Errorf("LoadCheckpoint() of a missing file = %v, %v, want nil, nil", missing, err)
+	}
+}
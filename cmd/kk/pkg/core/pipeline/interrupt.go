@@ -0,0 +1,78 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+)
+
+// withInterrupt returns a context which is cancelled when the pipeline is interrupted by SIGINT or SIGTERM,
+// or by a failed goroutine module. Only the first signal is handled, a second one terminates KubeKey at once.
+// The returned function releases the signal handler.
+func (p *Pipeline) withInterrupt(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	p.cancel = cancel
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-sigCh:
+			signal.Stop(sigCh)
+			logger.Log.Warnf("Pipeline[%s] received signal %s, stop running new tasks and wait for the running ones to roll back. "+
+				"Press Ctrl-C again to force exit", p.Name, sig)
+			p.interrupt(errors.Errorf("received signal %s", sig))
+		case <-done:
+		}
+	}()
+
+	return ctx, func() {
+		close(done)
+		signal.Stop(sigCh)
+		cancel()
+	}
+}
+
+// interrupt cancels the pipeline, the first reason is kept.
+func (p *Pipeline) interrupt(reason error) {
+	p.interruptMu.Lock()
+	defer p.interruptMu.Unlock()
+	if p.interruptErr == nil {
+		p.interruptErr = reason
+	}
+	if p.cancel != nil {
+		p.cancel()
+	}
+}
+
+// interrupted returns the reason why the pipeline is interrupted, or nil if it is still running.
+func (p *Pipeline) interrupted(ctx context.Context) error {
+	p.interruptMu.Lock()
+	defer p.interruptMu.Unlock()
+	if p.interruptErr != nil {
+		return p.interruptErr
+	}
+	return ctx.Err()
+}
@@ -17,8 +17,10 @@
 package pipeline
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -52,6 +54,18 @@ type Pipeline struct {
 	ModuleCachePool sync.Pool
 	ModulePostHooks []module.PostHookInterface
 	SkipPrintLogo   bool
+	Resume          bool
+	ReportPath      string
+	NoRollback      bool
+
+	checkpoint     *Checkpoint
+	checkpointPath string
+	plan           *connector.Plan
+	report         *Report
+
+	cancel       context.CancelFunc
+	interruptMu  sync.Mutex
+	interruptErr error
 }
 
 func (p *Pipeline) Init() error {
@@ -59,7 +73,14 @@ func (p *Pipeline) Init() error {
 		fmt.Print(logo)
 	}
 	p.PipelineCache = cache.NewCache()
+	p.report = NewReport(p.Name)
 	p.SpecHosts = len(p.Runtime.GetAllHosts())
+	if d, ok := p.Runtime.GetConnector().(*connector.DryRunDialer); ok {
+		p.plan = d.Plan()
+	}
+	if err := p.initCheckpoint(); err != nil {
+		return err
+	}
 	//if err := p.Runtime.GenerateWorkDir(); err != nil {
 	//	return err
 	//}
@@ -70,15 +91,43 @@ func (p *Pipeline) Init() error {
 }
 
 func (p *Pipeline) Start() error {
+	return p.StartContext(context.Background())
+}
+
+// StartContext runs the modules until the context is done or the pipeline is interrupted by a signal.
+// The running tasks are stopped and rolled back, the report and the checkpoint cover the finished modules.
+func (p *Pipeline) StartContext(ctx context.Context) (err error) {
+	defer func() {
+		p.writeReport(err)
+	}()
+
+	ctx, release := p.withInterrupt(ctx)
+	defer release()
+
 	if err := p.Init(); err != nil {
 		return errors.Wrapf(err, "Pipeline[%s] execute failed", p.Name)
 	}
 	for i := range p.Modules {
+		if err := p.interrupted(ctx); err != nil {
+			p.skipRest(i)
+			return errors.Wrapf(err, "Pipeline[%s] has been interrupted", p.Name)
+		}
+
 		m := p.Modules[i]
 		if m.IsSkip() {
+			p.report.AddSkipped(m, SkippedByConfig)
+			continue
+		}
+		if p.skipFinished(i, m) {
+			logger.Log.Infof("Module[%T] has been finished on all hosts, skipped by checkpoint", m)
+			p.report.AddSkipped(m, SkippedByCheckpoint)
 			continue
 		}
 
+		if p.plan != nil {
+			p.plan.SetSection(fmt.Sprintf("Module[%T]", m))
+		}
+
 		moduleCache := p.newModuleCache()
 		m.Default(p.Runtime, p.PipelineCache, moduleCache)
 		m.AutoAssert()
@@ -87,9 +136,35 @@ func (p *Pipeline) Start() error {
 			m.AppendPostHook(p.ModulePostHooks[j])
 		}
 
-		res := p.RunModule(m)
+		writes := p.cacheWrites()
+		res := p.RunModule(ctx, m)
+		if res.IsFailed() {
+			p.rollback(m, res)
+		}
 		err := m.CallPostHook(res)
+		// a module running in the background is reported by its goroutine once it finishes
+		if m.Is() != module.GoroutineModuleType {
+			p.report.AddModule(m, res)
+		}
+		if p.plan != nil {
+			// the remote commands are not executed in a dry run, so the modules which depend on
+			// their output may fail. Keep going to show the rest of the plan.
+			if res.IsFailed() {
+				p.plan.Note(fmt.Sprintf("incomplete, the module depends on the result of remote commands: %v", res.CombineResult))
+			} else if err != nil {
+				p.plan.Note(fmt.Sprintf("incomplete: %v", err))
+			}
+			p.releaseModuleCache(moduleCache)
+			continue
+		}
+		if err == nil || res.IsFailed() {
+			p.saveCheckpoint(i, m, res, p.cacheWrites() != writes)
+		}
 		if res.IsFailed() {
+			if e := p.interrupted(ctx); e != nil {
+				p.skipRest(i + 1)
+				return errors.Wrapf(res.CombineResult, "Pipeline[%s] has been interrupted: %v", p.Name, e)
+			}
 			return errors.Wrapf(res.CombineResult, "Pipeline[%s] execute failed", p.Name)
 		}
 		if err != nil {
@@ -104,34 +179,47 @@ func (p *Pipeline) Start() error {
 		p.Runtime.GetConnector().Close(host)
 	}
 
+	if p.plan != nil {
+		fmt.Printf("\nPipeline[%s] dry-run plan, no command has been executed on the hosts:\n\n", p.Name)
+		p.plan.Print(os.Stdout)
+		return nil
+	}
+
 	if p.SpecHosts != len(p.Runtime.GetAllHosts()) {
 		return errors.Errorf("Pipeline[%s] execute failed: there are some error in your spec hosts", p.Name)
 	}
+	p.removeCheckpoint()
 	logger.Log.Infof("Pipeline[%s] execute successfully", p.Name)
 	return nil
 }
 
-func (p *Pipeline) RunModule(m module.Module) *ending.ModuleResult {
+func (p *Pipeline) RunModule(ctx context.Context, m module.Module) *ending.ModuleResult {
 	m.Slogan()
 
 	result := ending.NewModuleResult()
 	for {
 		switch m.Is() {
 		case module.TaskModuleType:
-			m.Run(result)
+			m.Run(ctx, result)
 			if result.IsFailed() {
 				return result
 			}
 
 		case module.GoroutineModuleType:
+			// the result is written by the goroutine, so it is not shared with the caller
+			bgResult := ending.NewModuleResult()
 			go func() {
-				m.Run(result)
-				if result.IsFailed() {
-					os.Exit(1)
+				m.Run(ctx, bgResult)
+				if p.report != nil {
+					p.report.AddModule(m, bgResult)
+				}
+				if bgResult.IsFailed() {
+					// stop the whole pipeline, the failure of a background module can not be returned
+					p.interrupt(errors.Wrapf(bgResult.CombineResult, "Module[%T] exec failed", m))
 				}
 			}()
 		default:
-			m.Run(result)
+			m.Run(ctx, result)
 			if result.IsFailed() {
 				return result
 			}
@@ -149,6 +237,136 @@ func (p *Pipeline) RunModule(m module.Module) *ending.ModuleResult {
 	return result
 }
 
+func (p *Pipeline) writeReport(err error) {
+	if p.ReportPath == "" || p.report == nil {
+		return
+	}
+	p.report.Finish(err)
+	if e := p.report.Write(p.ReportPath); e != nil {
+		logger.Log.Warnf("Pipeline[%s] write report failed: %v", p.Name, e)
+		return
+	}
+	logger.Log.Infof("Pipeline[%s] report has been saved to %s and %s", p.Name, p.ReportPath, JUnitPath(p.ReportPath))
+}
+
+// rollback undoes the tasks of the failed module and prints what has been undone on every host.
+func (p *Pipeline) rollback(m module.Module, res *ending.ModuleResult) {
+	r, ok := m.(module.Rollbackable)
+	if !ok || p.plan != nil {
+		return
+	}
+	if p.NoRollback {
+		logger.Log.Warnf("Module[%T] failed, rollback is disabled, the completed tasks are left as they are", m)
+		return
+	}
+
+	logger.Log.Infof("Module[%T] failed, rolling back the completed tasks in reverse order", m)
+	r.Rollback()
+
+	hosts := make([]string, 0)
+	undone := make(map[string][]string)
+	failed := make(map[string][]string)
+	for i := len(res.TaskResults) - 1; i >= 0; i-- {
+		t := res.TaskResults[i]
+		for _, ar := range t.RollbackResults {
+			name := ar.Host.GetName()
+			if _, ok := undone[name]; !ok {
+				hosts = append(hosts, name)
+				undone[name] = make([]string, 0)
+			}
+			if ar.Status == ending.FAILED {
+				failed[name] = append(failed[name], t.Name)
+				continue
+			}
+			undone[name] = append(undone[name], t.Name)
+		}
+	}
+	if len(hosts) == 0 {
+		logger.Log.Infof("Module[%T] has no task to roll back", m)
+		return
+	}
+
+	var summary strings.Builder
+	summary.WriteString(fmt.Sprintf("Module[%T] rollback summary:", m))
+	for _, host := range hosts {
+		parts := make([]string, 0, 2)
+		if len(undone[host]) > 0 {
+			parts = append(parts, fmt.Sprintf("undone %s", strings.Join(undone[host], ", ")))
+		}
+		if len(failed[host]) > 0 {
+			parts = append(parts, fmt.Sprintf("failed to undo %s", strings.Join(failed[host], ", ")))
+		}
+		summary.WriteString(fmt.Sprintf("\n  [%s] %s", host, strings.Join(parts, "; ")))
+	}
+	logger.Log.Infof("%s", summary.String())
+}
+
+// skipRest records the modules which will not be run because of the interruption.
+func (p *Pipeline) skipRest(from int) {
+	for _, m := range p.Modules[from:] {
+		p.report.AddSkipped(m, SkippedByInterrupt)
+	}
+}
+
+func (p *Pipeline) initCheckpoint() error {
+	if p.plan != nil {
+		return nil
+	}
+	p.checkpointPath = CheckpointPath(p.Runtime.GetWorkDir(), p.Name)
+	if p.Resume {
+		c, err := LoadCheckpoint(p.checkpointPath)
+		if err != nil {
+			return err
+		}
+		if c != nil && c.Match(p.Name, p.Runtime.GetAllHosts()) {
+			logger.Log.Infof("Pipeline[%s] resume from checkpoint %s", p.Name, p.checkpointPath)
+			p.checkpoint = c
+			return nil
+		}
+		logger.Log.Warnf("Pipeline[%s] no matching checkpoint found in %s, start from the beginning", p.Name, p.checkpointPath)
+	}
+	p.checkpoint = NewCheckpoint(p.Name, p.Runtime.GetAllHosts())
+	return nil
+}
+
+func (p *Pipeline) skipFinished(index int, m module.Module) bool {
+	if !p.Resume {
+		return false
+	}
+	if r, ok := m.(module.Replayable); ok && r.AlwaysRun() {
+		return false
+	}
+	return p.checkpoint.Finished(index, m, p.Runtime.GetAllHosts())
+}
+
+func (p *Pipeline) saveCheckpoint(index int, m module.Module, res *ending.ModuleResult, writesCache bool) {
+	p.checkpoint.Record(index, m, res, p.Runtime.GetAllHosts(), writesCache)
+	if err := p.checkpoint.Save(p.checkpointPath); err != nil {
+		logger.Log.Warnf("Pipeline[%s] save checkpoint failed: %v", p.Name, err)
+		return
+	}
+	if res.IsFailed() {
+		logger.Log.Infof("Pipeline[%s] checkpoint has been saved to %s, it can be resumed by the --resume flag", p.Name, p.checkpointPath)
+	}
+}
+
+// cacheWrites counts the changes of the pipeline cache and the host caches, which are lost when the pipeline exits.
+func (p *Pipeline) cacheWrites() uint64 {
+	writes := p.PipelineCache.Writes()
+	for _, host := range p.Runtime.GetAllHosts() {
+		if c := host.GetCache(); c != nil {
+			writes += c.Writes()
+		}
+	}
+	return writes
+}
+
+func (p *Pipeline) removeCheckpoint() {
+	if err := os.Remove(p.checkpointPath); err != nil && !os.IsNotExist(err) {
+		logger.Log.Warnf("Pipeline[%s] remove checkpoint failed: %v", p.Name, err)
+	}
+}
+
 func (p *Pipeline) newModuleCache() *cache.Cache {
 	moduleCache, ok := p.ModuleCachePool.Get().(*cache.Cache)
 	if ok {
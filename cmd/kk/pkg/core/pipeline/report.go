@@ -0,0 +1,333 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/ending"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/module"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
+)
+
+const (
+	SkippedByConfig     = "skipped by the configuration"
+	SkippedByCheckpoint = "finished on all hosts in a previous run"
+	SkippedByInterrupt  = "the pipeline has been interrupted"
+)
+
+// Report is the machine-readable result of a pipeline run. The modules running in the background are added by their
+// goroutines, so it is guarded by the lock.
+type Report struct {
+	mu sync.Mutex
+
+	Pipeline  string         `json:"pipeline"`
+	Status    string         `json:"status"`
+	Error     string         `json:"error,omitempty"`
+	StartTime time.Time      `json:"startTime"`
+	EndTime   time.Time      `json:"endTime"`
+	Duration  float64        `json:"duration"`
+	Modules   []ModuleReport `json:"modules"`
+}
+
+type ModuleReport struct {
+	Module     string       `json:"module"`
+	Status     string       `json:"status"`
+	SkipReason string       `json:"skipReason,omitempty"`
+	Error      string       `json:"error,omitempty"`
+	StartTime  time.Time    `json:"startTime"`
+	EndTime    time.Time    `json:"endTime"`
+	Duration   float64      `json:"duration"`
+	Tasks      []TaskReport `json:"tasks,omitempty"`
+}
+
+type TaskReport struct {
+	Name      string       `json:"name"`
+	Desc      string       `json:"desc"`
+	Status    string       `json:"status"`
+	StartTime time.Time    `json:"startTime"`
+	EndTime   time.Time    `json:"endTime"`
+	Duration  float64      `json:"duration"`
+	Hosts     []HostReport `json:"hosts"`
+	Rollbacks []HostReport `json:"rollbacks,omitempty"`
+}
+
+type HostReport struct {
+	Host       string    `json:"host"`
+	Status     string    `json:"status"`
+	SkipReason string    `json:"skipReason,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	StartTime  time.Time `json:"startTime"`
+	EndTime    time.Time `json:"endTime"`
+	Duration   float64   `json:"duration"`
+}
+
+func NewReport(name string) *Report {
+	return &Report{
+		Pipeline:  name,
+		Status:    ending.NULL.String(),
+		StartTime: time.Now(),
+		Modules:   make([]ModuleReport, 0),
+	}
+}
+
+// AddSkipped records a module which has not been run at all.
+func (r *Report) AddSkipped(m module.Module, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.Modules = append(r.Modules, ModuleReport{
+		Module:     moduleType(m),
+		Status:     ending.SKIPPED.String(),
+		SkipReason: reason,
+		StartTime:  now,
+		EndTime:    now,
+	})
+}
+
+func (r *Report) AddModule(m module.Module, result *ending.ModuleResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	mr := ModuleReport{
+		Module:    moduleType(m),
+		Status:    result.Status.String(),
+		StartTime: result.StartTime,
+		EndTime:   endTime(result.EndTime),
+		Tasks:     make([]TaskReport, 0, len(result.TaskResults)),
+	}
+	mr.Duration = duration(mr.StartTime, mr.EndTime)
+	if result.CombineResult != nil {
+		mr.Error = result.CombineResult.Error()
+	}
+
+	for _, t := range result.TaskResults {
+		tr := TaskReport{
+			Name:      t.Name,
+			Desc:      t.Desc,
+			Status:    t.Status.String(),
+			StartTime: t.StartTime,
+			EndTime:   endTime(t.EndTime),
+			Hosts:     make([]HostReport, 0, len(t.ActionResults)),
+		}
+		tr.Duration = duration(tr.StartTime, tr.EndTime)
+
+		for _, a := range t.ActionResults {
+			tr.Hosts = append(tr.Hosts, hostReport(a))
+		}
+		for _, a := range t.RollbackResults {
+			tr.Rollbacks = append(tr.Rollbacks, hostReport(a))
+		}
+		mr.Tasks = append(mr.Tasks, tr)
+	}
+	r.Modules = append(r.Modules, mr)
+}
+
+// Finish sets the final status of the pipeline by the error it returned.
+func (r *Report) Finish(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.EndTime = time.Now()
+	r.Duration = duration(r.StartTime, r.EndTime)
+	if err != nil {
+		r.Status = ending.FAILED.String()
+		r.Error = err.Error()
+		return
+	}
+	r.Status = ending.SUCCESS.String()
+}
+
+// Write saves the report as JSON to the given path, and as JUnit XML next to it with the ".xml" extension.
+func (r *Report) Write(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := util.CreateDir(filepath.Dir(path)); err != nil {
+		return errors.Wrap(err, "create report dir failed")
+	}
+
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshal json report failed")
+	}
+	if err := os.WriteFile(path, content, common.FileMode0644); err != nil {
+		return errors.Wrapf(err, "write report %s failed", path)
+	}
+
+	junit, err := xml.MarshalIndent(r.JUnit(), "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshal junit report failed")
+	}
+	junitPath := JUnitPath(path)
+	if err := os.WriteFile(junitPath, append([]byte(xml.Header), junit...), common.FileMode0644); err != nil {
+		return errors.Wrapf(err, "write report %s failed", junitPath)
+	}
+	return nil
+}
+
+// JUnitPath returns the path of the JUnit report next to the report. The .junit.xml suffix keeps a report path ending
+// with .xml from being overwritten.
+func JUnitPath(path string) string {
+	return fmt.Sprintf("%s.junit.xml", strings.TrimSuffix(path, filepath.Ext(path)))
+}
+
+type JUnitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+type JUnitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []JUnitTestCase `xml:"testcase"`
+}
+
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Skipped   *JUnitMessage `xml:"skipped,omitempty"`
+	Failure   *JUnitMessage `xml:"failure,omitempty"`
+}
+
+type JUnitMessage struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+// JUnit converts the report to JUnit test suites: a suite per module and a case per task and host.
+func (r *Report) JUnit() *JUnitTestSuites {
+	suites := &JUnitTestSuites{
+		Name: r.Pipeline,
+		Time: seconds(r.Duration),
+	}
+
+	for _, m := range r.Modules {
+		suite := JUnitTestSuite{
+			Name:      m.Module,
+			Time:      seconds(m.Duration),
+			Timestamp: m.StartTime.Format(time.RFC3339),
+		}
+
+		if m.SkipReason != "" {
+			suite.Cases = append(suite.Cases, JUnitTestCase{
+				Name:      m.Module,
+				ClassName: m.Module,
+				Time:      seconds(0),
+				Skipped:   &JUnitMessage{Message: m.SkipReason},
+			})
+		}
+
+		for _, t := range m.Tasks {
+			for _, h := range t.Hosts {
+				tc := JUnitTestCase{
+					Name:      fmt.Sprintf("%s [%s]", t.Name, h.Host),
+					ClassName: m.Module,
+					Time:      seconds(h.Duration),
+				}
+				switch h.Status {
+				case ending.SKIPPED.String():
+					tc.Skipped = &JUnitMessage{Message: h.SkipReason}
+				case ending.FAILED.String():
+					tc.Failure = &JUnitMessage{Message: fmt.Sprintf("%s failed on %s", t.Desc, h.Host), Content: h.Error}
+				}
+				suite.Cases = append(suite.Cases, tc)
+			}
+		}
+
+		failed := false
+		for _, tc := range suite.Cases {
+			failed = failed || tc.Failure != nil
+		}
+		if m.Status == ending.FAILED.String() && !failed {
+			suite.Cases = append(suite.Cases, JUnitTestCase{
+				Name:      m.Module,
+				ClassName: m.Module,
+				Time:      seconds(m.Duration),
+				Failure:   &JUnitMessage{Message: fmt.Sprintf("%s failed", m.Module), Content: m.Error},
+			})
+		}
+
+		for _, tc := range suite.Cases {
+			suite.Tests++
+			if tc.Skipped != nil {
+				suite.Skipped++
+			}
+			if tc.Failure != nil {
+				suite.Failures++
+			}
+		}
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+	return suites
+}
+
+func hostReport(a *ending.ActionResult) HostReport {
+	hr := HostReport{
+		Host:       common.LocalHost,
+		Status:     a.Status.String(),
+		SkipReason: a.Reason,
+		StartTime:  a.StartTime,
+		EndTime:    a.EndTime,
+		Duration:   duration(a.StartTime, a.EndTime),
+	}
+	if a.Host != nil {
+		hr.Host = a.Host.GetName()
+	}
+	if a.Error != nil {
+		hr.Error = a.Error.Error()
+	}
+	return hr
+}
+
+func endTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Now()
+	}
+	return t
+}
+
+func duration(start, end time.Time) float64 {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start).Seconds()
+}
+
+func seconds(d float64) string {
+	return fmt.Sprintf("%.3f", d)
+}
@@ -0,0 +1,156 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/ending"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/module"
+)
+
+func TestReport(t *testing.T) {
+	node1 := &connector.BaseHost{Name: "node1"}
+	node2 := &connector.BaseHost{Name: "node2"}
+
+	task := ending.NewTaskResult()
+	task.Name = "InstallKubelet"
+	task.Desc = "Install kubelet"
+	task.AppendSuccess(node1)
+	task.AppendErr(node2, errors.New("permission denied"))
+	task.ErrResult()
+
+	skipped := ending.NewTaskResult()
+	skipped.Name = "JoinNode"
+	skipped.Desc = "Join node"
+	skipped.AppendSkip(node1, "pre-check not passed: *kubernetes.NodeInCluster")
+	skipped.NormalResult()
+
+	result := ending.NewModuleResult()
+	result.AppendTaskResult(skipped)
+	result.AppendTaskResult(task)
+	result.ErrResult(task.CombineErr())
+
+	r := NewReport("TestPipeline")
+	r.AddSkipped(&testModule{}, SkippedByConfig)
+	r.AddModule(&testModule{}, result)
+	r.Finish(errors.New("Pipeline[TestPipeline] execute failed"))
+
+	junit := r.JUnit()
+	if junit.Tests != 4 || junit.Failures != 1 || junit.Skipped != 2 {
+		t.Errorf("unexpected junit counters: tests=%d failures=%d skipped=%d", junit.Tests, junit.Failures, junit.Skipped)
+	}
+	if len(junit.Suites) != 2 {
+		t.Fatalf("expected 2 suites, got %d", len(junit.Suites))
+	}
+	failure := junit.Suites[1].Cases[2].Failure
+	if failure == nil || failure.Content != "permission denied" {
+		t.Errorf("unexpected failure of node2: %+v", failure)
+	}
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := r.Write(path); err != nil {
+		t.Fatalf("write report failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), "report.junit.xml")); err != nil {
+		t.Errorf("junit report not found: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read report failed: %v", err)
+	}
+	loaded := &Report{}
+	if err := json.Unmarshal(content, loaded); err != nil {
+		t.Fatalf("parse report failed: %v", err)
+	}
+	if loaded.Status != ending.FAILED.String() || loaded.Modules[1].Tasks[0].Hosts[0].SkipReason == "" {
+		t.Errorf("unexpected report: %s", content)
+	}
+}
+
+type testGoroutineModule struct {
+	module.BaseModule
+	done chan struct{}
+}
+
+func (m *testGoroutineModule) Is() string {
+	return module.GoroutineModuleType
+}
+
+func (m *testGoroutineModule) Run(_ context.Context, result *ending.ModuleResult) {
+	defer close(m.done)
+	for _, name := range []string{"Watch", "Report"} {
+		task := ending.NewTaskResult()
+		task.Name = name
+		task.NormalResult()
+		result.AppendTaskResult(task)
+	}
+	result.NormalResult()
+}
+
+func TestReportGoroutineModule(t *testing.T) {
+	p := &Pipeline{report: NewReport("TestPipeline")}
+	m := &testGoroutineModule{done: make(chan struct{})}
+
+	res := p.RunModule(context.Background(), m)
+	if len(res.TaskResults) != 0 {
+		t.Errorf("the result of the background module is shared with the caller: %v", res.TaskResults)
+	}
+	// the report is written while the module is running
+	p.report.Finish(nil)
+	if err := p.report.Write(filepath.Join(t.TempDir(), "report.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	<-m.done
+	for i := 0; i < 100; i++ {
+		p.report.mu.Lock()
+		n := len(p.report.Modules)
+		p.report.mu.Unlock()
+		if n != 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(p.report.Modules) != 1 || len(p.report.Modules[0].Tasks) != 2 {
+		t.Errorf("the background module is not reported once it finishes: %+v", p.report.Modules)
+	}
+}
+
+func TestJUnitPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "report.json", want: "report.junit.xml"},
+		{path: "/tmp/run.xml", want: "/tmp/run.junit.xml"},
+		{path: "report", want: "report.junit.xml"},
+	}
+	for _, tt := range tests {
+		if got := JUnitPath(tt.path); got != tt.want {
+			t.Errorf("JUnitPath(%s) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
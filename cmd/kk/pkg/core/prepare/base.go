@@ -17,6 +17,9 @@
 package prepare
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/cache"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
 )
@@ -64,3 +67,15 @@ func (p *PrepareCollection) AutoAssert(runtime connector.Runtime) {
 		v.AutoAssert(runtime)
 	}
 }
+
+// Describe returns a readable reason for a task being skipped by the given prepare.
+func Describe(p Prepare) string {
+	if c, ok := p.(*PrepareCollection); ok {
+		names := make([]string, 0, len(*c))
+		for _, v := range *c {
+			names = append(names, fmt.Sprintf("%T", v))
+		}
+		return fmt.Sprintf("pre-check not passed: %s", strings.Join(names, ", "))
+	}
+	return fmt.Sprintf("pre-check not passed: %T", p)
+}
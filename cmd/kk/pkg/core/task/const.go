@@ -21,4 +21,7 @@ const (
 	DefaultCon     = 10
 
 	DefaultTaskName = "DefaultTask"
+
+	InterruptedReason = "the task has been interrupted before it started"
+	DryRunReason      = "dry run, the local task is not executed"
 )
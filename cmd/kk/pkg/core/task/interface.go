@@ -17,6 +17,8 @@
 package task
 
 import (
+	"context"
+
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/cache"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/ending"
@@ -25,6 +27,6 @@ import (
 type Interface interface {
 	GetDesc() string
 	Init(runtime connector.Runtime, moduleCache *cache.Cache, pipelineCache *cache.Cache)
-	Execute() *ending.TaskResult
+	Execute(ctx context.Context) *ending.TaskResult
 	ExecuteRollback()
 }
@@ -68,6 +68,8 @@ func (l *LocalTask) Default() {
 	if l.Name == "" {
 		l.Name = DefaultTaskName
 	}
+	l.TaskResult.Name = l.Name
+	l.TaskResult.Desc = l.Desc
 
 	if l.Prepare == nil {
 		l.Prepare = new(prepare.BasePrepare)
@@ -91,7 +93,7 @@ func (l *LocalTask) Default() {
 	}
 }
 
-func (l *LocalTask) Execute() *ending.TaskResult {
+func (l *LocalTask) Execute(ctx context.Context) *ending.TaskResult {
 	if l.TaskResult.IsFailed() {
 		return l.TaskResult
 	}
@@ -100,8 +102,14 @@ func (l *LocalTask) Execute() *ending.TaskResult {
 		Name: common.LocalHost,
 	}
 
+	if ctx.Err() != nil {
+		l.TaskResult.AppendSkip(host, InterruptedReason)
+		l.TaskResult.NormalResult()
+		return l.TaskResult
+	}
+
 	selfRuntime := l.Runtime.Copy()
-	l.RunWithTimeout(selfRuntime, host)
+	l.RunWithTimeout(ctx, selfRuntime, host)
 
 	if l.TaskResult.IsFailed() {
 		l.TaskResult.ErrResult()
@@ -112,15 +120,21 @@ func (l *LocalTask) Execute() *ending.TaskResult {
 	return l.TaskResult
 }
 
-func (l *LocalTask) RunWithTimeout(runtime connector.Runtime, host connector.Host) {
-	ctx, cancel := context.WithTimeout(context.Background(), l.Timeout)
+func (l *LocalTask) RunWithTimeout(ctx context.Context, runtime connector.Runtime, host connector.Host) {
+	ctx, cancel := context.WithTimeout(ctx, l.Timeout)
 	defer cancel()
 
-	resCh := make(chan error)
+	resCh := make(chan error, 1)
 
-	go l.Run(runtime, host, resCh)
+	go l.Run(ctx, runtime, host, resCh)
 	select {
 	case <-ctx.Done():
+		if ctx.Err() == context.Canceled {
+			if e := <-resCh; e != nil {
+				l.TaskResult.AppendErr(host, e)
+			}
+			break
+		}
 		l.TaskResult.AppendErr(host, fmt.Errorf("execute task timeout, Timeout=%s", util.ShortDur(l.Timeout)))
 	case e := <-resCh:
 		if e != nil {
@@ -129,7 +143,7 @@ func (l *LocalTask) RunWithTimeout(runtime connector.Runtime, host connector.Hos
 	}
 }
 
-func (l *LocalTask) Run(runtime connector.Runtime, host connector.Host, resCh chan error) {
+func (l *LocalTask) Run(ctx context.Context, runtime connector.Runtime, host connector.Host, resCh chan error) {
 	var res error
 	defer func() {
 		resCh <- res
@@ -140,8 +154,17 @@ func (l *LocalTask) Run(runtime connector.Runtime, host connector.Host, resCh ch
 		Conn: nil,
 		//Debug: runtime.Arg.Debug,
 		Host: host,
+		Ctx:  ctx,
 	})
 
+	// the local actions download the binaries, unarchive the artifact or push the images, they only describe
+	// themselves in a dry run.
+	if d, ok := runtime.GetConnector().(*connector.DryRunDialer); ok {
+		d.Plan().Record(host.GetName(), connector.LocalOp, describe(l.Name, l.Desc, l.Action))
+		l.TaskResult.AppendSkip(host, DryRunReason)
+		return
+	}
+
 	l.Prepare.Init(l.ModuleCache, l.PipelineCache)
 	l.Prepare.AutoAssert(runtime)
 	if ok, err := l.WhenWithRetry(runtime, host); !ok {
@@ -149,7 +172,7 @@ func (l *LocalTask) Run(runtime connector.Runtime, host connector.Host, resCh ch
 			res = err
 			return
 		} else {
-			l.TaskResult.AppendSkip(host)
+			l.TaskResult.AppendSkip(host, prepare.Describe(l.Prepare))
 			return
 		}
 	}
@@ -163,6 +186,14 @@ func (l *LocalTask) Run(runtime connector.Runtime, host connector.Host, resCh ch
 	l.TaskResult.AppendSuccess(host)
 }
 
+// describe returns the description of a task and the type of its action.
+func describe(name, desc string, a action.Action) string {
+	if desc == "" {
+		desc = name
+	}
+	return fmt.Sprintf("%s (%T)", desc, a)
+}
+
 func (l *LocalTask) WhenWithRetry(runtime connector.Runtime, host connector.Host) (bool, error) {
 	pass := false
 	err := fmt.Errorf("pre-check exec failed after %d retries", l.Retry)
@@ -175,7 +206,10 @@ func (l *LocalTask) WhenWithRetry(runtime connector.Runtime, host connector.Host
 				continue
 			}
 			logger.Log.Infof("retry: [%s]", host.GetName())
-			time.Sleep(l.Delay)
+			if ctxErr := wait(runtime.GetRunner().Context(), l.Delay); ctxErr != nil {
+				err = errors.Wrapf(ctxErr, "pre-check exec stopped: %s", e.Error())
+				break
+			}
 			continue
 		} else {
 			err = nil
@@ -211,7 +245,10 @@ func (l *LocalTask) ExecuteWithRetry(runtime connector.Runtime, host connector.H
 				continue
 			}
 			logger.Log.Infof("retry: [%s]", host.GetName())
-			time.Sleep(l.Delay)
+			if ctxErr := wait(runtime.GetRunner().Context(), l.Delay); ctxErr != nil {
+				err = errors.Wrapf(ctxErr, "[%s] exec stopped: %s", l.Name, e.Error())
+				break
+			}
 			continue
 		} else {
 			err = nil
@@ -225,7 +262,26 @@ func (l *LocalTask) ExecuteRollback() {
 	if l.Rollback == nil {
 		return
 	}
-	if !l.TaskResult.IsFailed() {
-		return
+
+	for _, ar := range l.TaskResult.ActionResults {
+		if ar.Host == nil || ar.Status == ending.SKIPPED {
+			continue
+		}
+
+		runtime := l.Runtime.Copy()
+		runtime.SetRunner(&connector.Runner{
+			Conn: nil,
+			Host: ar.Host,
+		})
+
+		logger.Log.Infof("rollback: [%s]", ar.Host.GetName())
+		l.Rollback.Init(l.ModuleCache, l.PipelineCache)
+		l.Rollback.AutoAssert(runtime)
+		err := l.Rollback.Execute(runtime, ar)
+		if err != nil {
+			logger.Log.Errorf("rollback-failed: [%s]", ar.Host.GetName())
+			logger.Log.Messagef(ar.Host.GetName(), err.Error())
+		}
+		l.TaskResult.AppendRollback(ar.Host, err)
 	}
 }
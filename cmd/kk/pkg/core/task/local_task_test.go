@@ -0,0 +1,71 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/action"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/cache"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/ending"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+)
+
+type countAction struct {
+	action.BaseAction
+	count int
+}
+
+func (a *countAction) Execute(runtime connector.Runtime) error {
+	a.count++
+	return nil
+}
+
+func TestLocalTask_DryRun(t1 *testing.T) {
+	logger.Log = logger.NewLogger(t1.TempDir(), false)
+
+	dialer := connector.NewDryRunDialer()
+	runtime := &connector.BaseRuntime{}
+	runtime.SetConnector(dialer)
+
+	a := &countAction{}
+	t := &LocalTask{
+		Name:   "DownloadBinaries",
+		Desc:   "Download installation binaries",
+		Action: a,
+	}
+	t.Init(runtime, cache.NewCache(), cache.NewCache())
+
+	res := t.Execute(context.Background())
+	if res.IsFailed() {
+		t1.Fatalf("the local task should not fail in a dry run")
+	}
+	if a.count != 0 {
+		t1.Errorf("the action should not be executed in a dry run")
+	}
+	if len(res.ActionResults) != 1 || res.ActionResults[0].Status != ending.SKIPPED || res.ActionResults[0].Reason != DryRunReason {
+		t1.Errorf("the local task should be skipped, got %v", res.ActionResults)
+	}
+
+	steps := dialer.Plan().Steps()
+	want := "Download installation binaries (*task.countAction)"
+	if len(steps) != 1 || steps[0].Op != connector.LocalOp || steps[0].Command != want {
+		t1.Errorf("the plan should describe the local task, got %v", steps)
+	}
+}
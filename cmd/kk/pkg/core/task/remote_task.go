@@ -65,7 +65,7 @@ func (t *RemoteTask) Init(runtime connector.Runtime, moduleCache *cache.Cache, p
 	t.Default()
 }
 
-func (t *RemoteTask) Execute() *ending.TaskResult {
+func (t *RemoteTask) Execute(ctx context.Context) *ending.TaskResult {
 	if t.TaskResult.IsFailed() {
 		return t.TaskResult
 	}
@@ -73,7 +73,7 @@ func (t *RemoteTask) Execute() *ending.TaskResult {
 	routinePool := make(chan struct{}, DefaultCon)
 	defer close(routinePool)
 
-	ctx, cancel := context.WithTimeout(context.Background(), t.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, t.Timeout)
 	defer cancel()
 	wg := &sync.WaitGroup{}
 	for i := range t.Hosts {
@@ -104,24 +104,38 @@ func (t *RemoteTask) RunWithTimeout(ctx context.Context, runtime connector.Runti
 	wg *sync.WaitGroup, pool chan struct{}) {
 
 	pool <- struct{}{}
+	defer func() {
+		<-pool
+		wg.Done()
+	}()
 
-	resCh := make(chan error)
-	go t.Run(runtime, host, index, resCh)
+	// the task is interrupted while waiting for its turn, it has not been started on the host
+	if ctx.Err() == context.Canceled {
+		t.TaskResult.AppendSkip(host, InterruptedReason)
+		return
+	}
+
+	resCh := make(chan error, 1)
+	go t.Run(ctx, runtime, host, index, resCh)
 
 	select {
 	case <-ctx.Done():
+		if ctx.Err() == context.Canceled {
+			// the remote commands are being stopped, wait for the action to return before rolling back
+			if e := <-resCh; e != nil {
+				t.TaskResult.AppendErr(host, e)
+			}
+			break
+		}
 		t.TaskResult.AppendErr(host, fmt.Errorf("execute task timeout, Timeout=%s", util.ShortDur(t.Timeout)))
 	case e := <-resCh:
 		if e != nil {
 			t.TaskResult.AppendErr(host, e)
 		}
 	}
-
-	<-pool
-	wg.Done()
 }
 
-func (t *RemoteTask) Run(runtime connector.Runtime, host connector.Host, index int, resCh chan error) {
+func (t *RemoteTask) Run(ctx context.Context, runtime connector.Runtime, host connector.Host, index int, resCh chan error) {
 	var res error
 	defer func() {
 		//runtime.GetConnector().Close(host)
@@ -130,7 +144,7 @@ func (t *RemoteTask) Run(runtime connector.Runtime, host connector.Host, index i
 		close(resCh)
 	}()
 
-	if err := t.ConfigureSelfRuntime(runtime, host, index); err != nil {
+	if err := t.ConfigureSelfRuntime(ctx, runtime, host, index); err != nil {
 		res = err
 		return
 	}
@@ -142,7 +156,7 @@ func (t *RemoteTask) Run(runtime connector.Runtime, host connector.Host, index i
 			res = err
 			return
 		} else {
-			t.TaskResult.AppendSkip(host)
+			t.TaskResult.AppendSkip(host, prepare.Describe(t.Prepare))
 			return
 		}
 	}
@@ -158,7 +172,7 @@ func (t *RemoteTask) Run(runtime connector.Runtime, host connector.Host, index i
 	return
 }
 
-func (t *RemoteTask) ConfigureSelfRuntime(runtime connector.Runtime, host connector.Host, index int) error {
+func (t *RemoteTask) ConfigureSelfRuntime(ctx context.Context, runtime connector.Runtime, host connector.Host, index int) error {
 	conn, err := runtime.GetConnector().Connect(host)
 	if err != nil {
 		return errors.Wrapf(err, "failed to connect to %s", host.GetAddress())
@@ -169,6 +183,7 @@ func (t *RemoteTask) ConfigureSelfRuntime(runtime connector.Runtime, host connec
 		//Debug: runtime.Arg.Debug,
 		Host:  host,
 		Index: index,
+		Ctx:   ctx,
 	}
 	runtime.SetRunner(r)
 	return nil
@@ -198,7 +213,10 @@ func (t *RemoteTask) WhenWithRetry(runtime connector.Runtime) (bool, error) {
 				continue
 			}
 			logger.Log.Infof("retry: [%s]", runtime.GetRunner().Host.GetName())
-			time.Sleep(t.Delay)
+			if ctxErr := wait(runtime.GetRunner().Context(), t.Delay); ctxErr != nil {
+				err = errors.Wrapf(ctxErr, "pre-check exec stopped: %s", e.Error())
+				break
+			}
 			continue
 		} else {
 			err = nil
@@ -222,7 +240,10 @@ func (t *RemoteTask) ExecuteWithRetry(runtime connector.Runtime) error {
 				continue
 			}
 			logger.Log.Infof("retry: [%s]", runtime.GetRunner().Host.GetName())
-			time.Sleep(t.Delay)
+			if ctxErr := wait(runtime.GetRunner().Context(), t.Delay); ctxErr != nil {
+				err = errors.Wrapf(ctxErr, "[%s] exec stopped: %s", t.Name, e.Error())
+				break
+			}
 			continue
 		} else {
 			err = nil
@@ -236,9 +257,6 @@ func (t *RemoteTask) ExecuteRollback() {
 	if t.Rollback == nil {
 		return
 	}
-	if !t.TaskResult.IsFailed() {
-		return
-	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), t.Timeout)
 	defer cancel()
@@ -250,14 +268,18 @@ func (t *RemoteTask) ExecuteRollback() {
 		if ar.Host == nil || t.Runtime.HostIsDeprecated(ar.Host) {
 			continue
 		}
+		// the action has not been run on the skipped hosts, there is nothing to roll back
+		if ar.Status == ending.SKIPPED {
+			continue
+		}
 		selfRuntime := t.Runtime.Copy()
 
+		rwg.Add(1)
 		if t.Parallel {
 			go t.RollbackWithTimeout(ctx, selfRuntime, ar.Host, i, ar, rwg, routinePool)
 		} else {
 			t.RollbackWithTimeout(ctx, selfRuntime, ar.Host, i, ar, rwg, routinePool)
 		}
-		rwg.Add(1)
 	}
 	rwg.Wait()
 }
@@ -267,25 +289,28 @@ func (t *RemoteTask) RollbackWithTimeout(ctx context.Context, runtime connector.
 
 	pool <- struct{}{}
 
-	resCh := make(chan error)
-	go t.RunRollback(runtime, host, index, result, resCh)
+	resCh := make(chan error, 1)
+	go t.RunRollback(ctx, runtime, host, index, result, resCh)
 
 	select {
 	case <-ctx.Done():
-		logger.Log.Errorf("rollback-failed: [%s]", runtime.GetRunner().Host.GetName())
-		logger.Log.Messagef(runtime.RemoteHost().GetName(), fmt.Sprintf("execute task timeout, Timeout=%d", t.Timeout))
+		err := fmt.Errorf("execute rollback timeout, Timeout=%s", util.ShortDur(t.Timeout))
+		logger.Log.Errorf("rollback-failed: [%s]", host.GetName())
+		logger.Log.Messagef(host.GetName(), err.Error())
+		t.TaskResult.AppendRollback(host, err)
 	case e := <-resCh:
 		if e != nil {
-			logger.Log.Errorf("rollback-failed: [%s]", runtime.GetRunner().Host.GetName())
-			logger.Log.Messagef(runtime.RemoteHost().GetName(), e.Error())
+			logger.Log.Errorf("rollback-failed: [%s]", host.GetName())
+			logger.Log.Messagef(host.GetName(), e.Error())
 		}
+		t.TaskResult.AppendRollback(host, e)
 	}
 
 	<-pool
 	wg.Done()
 }
 
-func (t *RemoteTask) RunRollback(runtime connector.Runtime, host connector.Host, index int, result *ending.ActionResult, resCh chan error) {
+func (t *RemoteTask) RunRollback(ctx context.Context, runtime connector.Runtime, host connector.Host, index int, result *ending.ActionResult, resCh chan error) {
 	var res error
 	defer func() {
 		//runtime.GetConnector().Close(host)
@@ -294,7 +319,7 @@ func (t *RemoteTask) RunRollback(runtime connector.Runtime, host connector.Host,
 		close(resCh)
 	}()
 
-	if err := t.ConfigureSelfRuntime(runtime, host, index); err != nil {
+	if err := t.ConfigureSelfRuntime(ctx, runtime, host, index); err != nil {
 		res = err
 		return
 	}
@@ -315,6 +340,8 @@ func (t *RemoteTask) Default() {
 	if t.Name == "" {
 		t.Name = DefaultTaskName
 	}
+	t.TaskResult.Name = t.Name
+	t.TaskResult.Desc = t.Desc
 
 	if t.Prepare == nil {
 		t.Prepare = new(prepare.BasePrepare)
@@ -345,3 +372,15 @@ func (t *RemoteTask) calculateConcurrency() int {
 	}
 	return res
 }
+
+// wait sleeps for the retry delay, it returns early with the error of the context once it is done.
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -17,9 +17,16 @@
 package task
 
 import (
+	"context"
+	"sync"
 	"testing"
 
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/action"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/cache"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/ending"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/rollback"
 )
 
 func TestTask_calculateConcurrency(t1 *testing.T) {
@@ -139,3 +146,67 @@ func TestTask_calculateConcurrency(t1 *testing.T) {
 		})
 	}
 }
+
+// interruptAction interrupts the pipeline on its first host and waits for the remote commands to be stopped.
+type interruptAction struct {
+	action.BaseAction
+	cancel context.CancelFunc
+}
+
+func (a *interruptAction) Execute(runtime connector.Runtime) error {
+	a.cancel()
+	<-runtime.GetRunner().Context().Done()
+	return runtime.GetRunner().Context().Err()
+}
+
+type recordRollback struct {
+	rollback.BaseRollback
+	mu    sync.Mutex
+	hosts []string
+}
+
+func (r *recordRollback) Execute(runtime connector.Runtime, result *ending.ActionResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hosts = append(r.hosts, runtime.RemoteHost().GetName())
+	return nil
+}
+
+func TestRemoteTask_Interrupt(t1 *testing.T) {
+	logger.Log = logger.NewLogger(t1.TempDir(), false)
+
+	runtime := &connector.BaseRuntime{}
+	runtime.SetConnector(connector.NewDryRunDialer())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rb := &recordRollback{}
+	t := &RemoteTask{
+		Name:     "Interrupt",
+		Hosts:    []connector.Host{&connector.BaseHost{Name: "node1"}, &connector.BaseHost{Name: "node2"}},
+		Action:   &interruptAction{cancel: cancel},
+		Rollback: rb,
+		Parallel: false,
+	}
+	t.Init(runtime, cache.NewCache(), cache.NewCache())
+
+	res := t.Execute(ctx)
+	if !res.IsFailed() {
+		t1.Fatalf("the interrupted task should be failed")
+	}
+	if len(res.ActionResults) != 2 {
+		t1.Fatalf("expected 2 action results, got %d", len(res.ActionResults))
+	}
+	if r := res.ActionResults[0]; r.Host.GetName() != "node1" || r.Status != ending.FAILED {
+		t1.Errorf("node1 should be failed, got %s", r.Status.String())
+	}
+	if r := res.ActionResults[1]; r.Host.GetName() != "node2" || r.Status != ending.SKIPPED || r.Reason != InterruptedReason {
+		t1.Errorf("node2 should not be started, got %s: %s", r.Status.String(), r.Reason)
+	}
+
+	t.ExecuteRollback()
+	if len(rb.hosts) != 1 || rb.hosts[0] != "node1" {
+		t1.Errorf("only node1 should be rolled back, got %v", rb.hosts)
+	}
+}
@@ -137,6 +137,12 @@ func WriteFile(fileName string, content []byte) error {
 }
 
 func Tar(src, dst, trimPrefix string) error {
+	return TarWithFilter(src, dst, trimPrefix, nil)
+}
+
+// TarWithFilter archives the regular files under src like Tar, except the ones the filter returns false for. The
+// filter is called with the names of the files in the archive.
+func TarWithFilter(src, dst, trimPrefix string, filter func(name string, info fs.FileInfo) bool) error {
 	fw, err := os.Create(dst)
 	if err != nil {
 		return err
@@ -163,16 +169,20 @@ func Tar(src, dst, trimPrefix string) error {
 			return nil
 		}
 
+		name := strings.TrimPrefix(strings.TrimPrefix(path, trimPrefix), string(filepath.Separator))
+		if filter != nil && !filter(name, info) {
+			return nil
+		}
+
 		fr, err := os.Open(path)
 		defer fr.Close()
 		if err != nil {
 			return err
 		}
 
-		path = strings.TrimPrefix(path, trimPrefix)
-		fmt.Println(strings.TrimPrefix(path, string(filepath.Separator)))
+		fmt.Println(name)
 
-		hdr.Name = strings.TrimPrefix(path, string(filepath.Separator))
+		hdr.Name = name
 		if err := tw.WriteHeader(hdr); err != nil {
 			return err
 		}
@@ -227,7 +237,7 @@ func Untar(src, dst string) error {
 				}
 			}
 
-			file, err := os.OpenFile(dstPath, os.O_CREATE|os.O_RDWR, os.FileMode(hdr.Mode))
+			file, err := os.OpenFile(dstPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(hdr.Mode))
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,200 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package etcd
+
+import (
+	"bufio"
+	"context"
+	"embed"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/etcd/uploader"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/s3"
+)
+
+const (
+	// BackupTargetConfigPath is where the config of the backup target is placed on the etcd nodes for the uploader.
+	BackupTargetConfigPath = uploader.DefaultConfigPath
+	backupTargetCAPath     = "/etc/kubekey/etcd/backup-target-ca.pem"
+
+	// backupRetentionRuleID is the id of the lifecycle rule which expires the snapshots under the prefix.
+	backupRetentionRuleID = "kubekey-etcd-backup"
+)
+
+// uploaderBinaries are the etcd-uploader built for the etcd nodes by make etcd-uploader, named
+// etcd-uploader-linux-<arch>.
+//
+//go:embed all:bin
+var uploaderBinaries embed.FS
+
+// uploaderBinary returns the etcd-uploader built for the arch.
+func uploaderBinary(arch string) ([]byte, error) {
+	data, err := uploaderBinaries.ReadFile(path.Join("bin", "etcd-uploader-linux-"+arch))
+	if err != nil {
+		return nil, errors.Errorf("the etcd-uploader for linux/%s is not built into KubeKey, build KubeKey by make kk", arch)
+	}
+	return data, nil
+}
+
+// BackupTargetConfig is the config of the object storage that the etcd snapshots are uploaded to, with the credentials.
+type BackupTargetConfig = uploader.Config
+
+// NewBackupTargetConfig returns the config of the backup target, the credentials are read from the credentials file
+// of the target or the environment variables.
+func NewBackupTargetConfig(target *kubekeyapiv1alpha2.BackupTarget) (*BackupTargetConfig, error) {
+	if target == nil {
+		return nil, errors.New("the backup target of etcd is not configured")
+	}
+	cfg := &BackupTargetConfig{
+		Config: s3.Config{
+			Endpoint:           target.Endpoint,
+			Region:             target.Region,
+			Bucket:             target.Bucket,
+			VirtualHostedStyle: target.VirtualHostedStyle,
+			CAFile:             target.CAFile,
+			InsecureSkipVerify: target.InsecureSkipVerify,
+		},
+		Prefix: strings.Trim(target.Prefix, "/"),
+	}
+
+	if target.CredentialsFile != "" {
+		f, err := os.Open(target.CredentialsFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "open the credentials file %s failed", target.CredentialsFile)
+		}
+		defer f.Close()
+		cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken = parseCredentials(f)
+	} else {
+		cfg.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		cfg.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		cfg.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+
+	// validate the config before it is written to the nodes
+	if _, err := s3.NewClient(cfg.Config); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// parseCredentials parses the credentials in the form of AWS_ACCESS_KEY_ID=<id> lines, or the first profile of an
+// AWS credentials file whose lines are like aws_access_key_id = <id>.
+func parseCredentials(r io.Reader) (id, secret, token string) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && id != "" {
+			break
+		}
+		k, v, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok || strings.HasPrefix(line, "#") {
+			continue
+		}
+		v = strings.Trim(strings.TrimSpace(v), `"'`)
+		switch strings.ToLower(strings.TrimSpace(k)) {
+		case "aws_access_key_id":
+			id = v
+		case "aws_secret_access_key":
+			secret = v
+		case "aws_session_token":
+			token = v
+		}
+	}
+	return id, secret, token
+}
+
+func snapshotPrefix(prefix, node string) string {
+	return path.Join(prefix, node) + "/"
+}
+
+func isSnapshot(key string) bool {
+	base := path.Base(key)
+	return strings.HasPrefix(base, "etcd-") && strings.HasSuffix(base, ".db")
+}
+
+// SetSnapshotRetention sets the lifecycle rule of the bucket which expires the snapshots under the prefix after the
+// days, so the old snapshots are deleted by the object storage rather than by the etcd nodes. The rule of the same
+// prefix is replaced, and the other rules of the bucket are kept.
+func SetSnapshotRetention(ctx context.Context, cfg *BackupTargetConfig, days int) error {
+	if cfg.Prefix == "" {
+		return errors.New("the lifecycle rule of the etcd backups needs the prefix of the backup target, " +
+			"set the prefix or set retentionDays to -1 to manage the rules of the bucket by yourself")
+	}
+	client, err := s3.NewClient(cfg.Config)
+	if err != nil {
+		return err
+	}
+	return client.PutExpirationRule(ctx, backupRetentionRuleID+"-"+strings.ReplaceAll(cfg.Prefix, "/", "-"), cfg.Prefix+"/", days)
+}
+
+// ListSnapshots lists the snapshots in the backup target from the oldest, only the ones of the node are listed if
+// the node is not empty.
+func ListSnapshots(ctx context.Context, cfg *BackupTargetConfig, node string) ([]s3.Object, error) {
+	client, err := s3.NewClient(cfg.Config)
+	if err != nil {
+		return nil, err
+	}
+	prefix := cfg.Prefix
+	if prefix != "" {
+		prefix += "/"
+	}
+	if node != "" {
+		prefix = snapshotPrefix(cfg.Prefix, node)
+	}
+	return listSnapshots(ctx, client, prefix)
+}
+
+// listSnapshots lists the snapshots under the prefix, sorted by the time in their names.
+func listSnapshots(ctx context.Context, client *s3.Client, prefix string) ([]s3.Object, error) {
+	objects, err := client.ListObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]s3.Object, 0, len(objects))
+	for _, o := range objects {
+		if isSnapshot(o.Key) {
+			snapshots = append(snapshots, o)
+		}
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		bi, bj := path.Base(snapshots[i].Key), path.Base(snapshots[j].Key)
+		if bi != bj {
+			return bi < bj
+		}
+		return snapshots[i].Key < snapshots[j].Key
+	})
+	return snapshots, nil
+}
+
+// DownloadSnapshot downloads the snapshot from the backup target to the dst.
+func DownloadSnapshot(ctx context.Context, cfg *BackupTargetConfig, key, dst string) error {
+	if !isSnapshot(key) {
+		return errors.Errorf("%s is not an etcd snapshot uploaded by KubeKey", key)
+	}
+	client, err := s3.NewClient(cfg.Config)
+	if err != nil {
+		return err
+	}
+	return client.GetObject(ctx, key, dst)
+}
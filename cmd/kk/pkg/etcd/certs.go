@@ -167,6 +167,10 @@ func (g *GenerateCerts) Execute(runtime connector.Runtime) error {
 		}
 	}
 
+	if err := certs.UseExternalCerts(&g.KubeConf.Cluster.Certificates.Etcd, pkiPath, "ca", files); err != nil {
+		return errors.Wrap(err, "failed to use the external etcd certs")
+	}
+
 	var lastCACert *certs.KubekeyCert
 	for _, c := range certsList {
 		if c.CAName == "" {
@@ -183,6 +187,17 @@ func (g *GenerateCerts) Execute(runtime connector.Runtime) error {
 		}
 	}
 
+	// the key of an external CA may be kept by the user
+	if !util.IsExist(filepath.Join(pkiPath, "ca-key.pem")) {
+		synced := make([]string, 0, len(files))
+		for _, f := range files {
+			if f != "ca-key.pem" {
+				synced = append(synced, f)
+			}
+		}
+		files = synced
+	}
+
 	g.ModuleCache.Set(LocalCertsDir, pkiPath)
 	g.ModuleCache.Set(CertsFileList, files)
 
@@ -211,6 +226,7 @@ func GenerateAltName(k *common.KubeConf, runtime *connector.Runtime) *cert.AltNa
 
 	altName.DNSNames = dnsList
 	altName.IPs = ipList
+	certs.AppendSANs(&altName, k.Cluster.Certificates.Etcd.ExtraSANs)
 
 	return &altName
 }
@@ -260,3 +276,33 @@ func (f *FetchCertsForExternalEtcd) Execute(runtime connector.Runtime) error {
 
 	return nil
 }
+
+type RemoveCerts struct {
+	common.KubeAction
+}
+
+// Execute removes the certs of the members and clients fetched from the etcd node, so that GenerateCerts signs
+// them again with the existing CA. The CA is kept, or the members could not trust each other during the renewal.
+func (r *RemoveCerts) Execute(runtime connector.Runtime) error {
+	pkiPath := fmt.Sprintf("%s/pki/etcd", runtime.GetWorkDir())
+	// the key of an external CA is copied again by GenerateCerts, and is not needed for the pre-issued certs
+	hasKey := r.KubeConf.Cluster.Certificates.Etcd.IsExternal() || util.IsExist(filepath.Join(pkiPath, "ca-key.pem"))
+	if !util.IsExist(filepath.Join(pkiPath, "ca.pem")) || !hasKey {
+		return errors.Errorf("the etcd CA is not found in %s, the etcd certs can not be renewed", pkiPath)
+	}
+
+	entries, err := os.ReadDir(pkiPath)
+	if err != nil {
+		return errors.Wrapf(err, "read %s failed", pkiPath)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == "ca.pem" || name == "ca-key.pem" {
+			continue
+		}
+		if err := os.Remove(filepath.Join(pkiPath, name)); err != nil {
+			return errors.Wrapf(err, "remove %s failed", name)
+		}
+	}
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/action"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/task"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/etcd/templates"
@@ -56,6 +57,8 @@ func (p *PreCheckModule) Init() {
 type CertsModule struct {
 	common.KubeModule
 	Skip bool
+	// Renew signs the certs of the members and clients again with the existing CA.
+	Renew bool
 }
 
 func (p *CertsModule) IsSkip() bool {
@@ -85,6 +88,12 @@ func CertsModuleForKubeKey(c *CertsModule) []task.Interface {
 		Parallel: false,
 	}
 
+	removeCerts := &task.LocalTask{
+		Name:   "RemoveETCDCerts",
+		Desc:   "Remove the fetched etcd certs to renew them",
+		Action: new(RemoveCerts),
+	}
+
 	generateCerts := &task.LocalTask{
 		Name:   "GenerateETCDCerts",
 		Desc:   "Generate etcd Certs",
@@ -110,6 +119,15 @@ func CertsModuleForKubeKey(c *CertsModule) []task.Interface {
 		Retry:    1,
 	}
 
+	if c.Renew {
+		return []task.Interface{
+			fetchCerts,
+			removeCerts,
+			generateCerts,
+			syncCertsFile,
+			syncCertsToMaster,
+		}
+	}
 	return []task.Interface{
 		fetchCerts,
 		generateCerts,
@@ -118,6 +136,33 @@ func CertsModuleForKubeKey(c *CertsModule) []task.Interface {
 	}
 }
 
+// RollingRestartModule restarts the etcd members one by one, each one is healthy before the next is restarted.
+type RollingRestartModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (r *RollingRestartModule) IsSkip() bool {
+	return r.Skip
+}
+
+func (r *RollingRestartModule) Init() {
+	r.Name = "ETCDRollingRestartModule"
+	r.Desc = "Restart etcd members one by one"
+
+	restart := &task.RemoteTask{
+		Name:     "RestartETCDMember",
+		Desc:     "Restart etcd member and wait for it to be healthy",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(RestartMember),
+		Parallel: false,
+	}
+
+	r.Tasks = []task.Interface{
+		restart,
+	}
+}
+
 func CertsModuleForExternal(c *CertsModule) []task.Interface {
 	fetchCerts := &task.LocalTask{
 		Name:   "FetchETCDCerts",
@@ -435,4 +480,477 @@ func (b *BackupModule) Init() {
 		generateBackupETCDTimer,
 		enable,
 	}
+
+	if b.KubeConf.Cluster.Etcd.BackupTarget != nil {
+		syncBackupTarget := &task.RemoteTask{
+			Name:     "SyncETCDBackupTarget",
+			Desc:     "Sync the backup target config and uploader to etcd",
+			Hosts:    b.Runtime.GetHostsByRole(common.ETCD),
+			Action:   new(SyncBackupTarget),
+			Parallel: true,
+			Retry:    1,
+		}
+		setBackupRetention := &task.LocalTask{
+			Name:   "SetETCDBackupRetention",
+			Desc:   "Set the lifecycle rule of the etcd backup target",
+			Action: new(SetBackupRetention),
+			Retry:  2,
+		}
+		b.Tasks = append([]task.Interface{syncBackupTarget, setBackupRetention}, b.Tasks...)
+	}
+}
+
+type RestoreModule struct {
+	common.KubeModule
+	Snapshot string
+}
+
+func (r *RestoreModule) Init() {
+	r.Name = "ETCDRestoreModule"
+	r.Desc = "Restore ETCD cluster from a snapshot"
+
+	checkMember := &task.RemoteTask{
+		Name:     "CheckETCDInstalled",
+		Desc:     "Check etcd is installed on all etcd",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(CheckInstalled),
+		Parallel: true,
+	}
+
+	syncSnapshot := &task.RemoteTask{
+		Name:     "SyncETCDSnapshot",
+		Desc:     "Synchronize etcd snapshot",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   &SyncSnapshot{Snapshot: r.Snapshot},
+		Parallel: true,
+		Retry:    1,
+	}
+
+	accessAddress := &task.RemoteTask{
+		Name:     "GenerateAccessAddress",
+		Desc:     "Generate access address",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(FirstETCDNode),
+		Action:   new(GenerateAccessAddress),
+		Parallel: true,
+		Retry:    1,
+	}
+
+	stopAPIServer := &task.RemoteTask{
+		Name:     "StopKubeAPIServer",
+		Desc:     "Stop kube-apiserver",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   new(StopKubeAPIServer),
+		Parallel: true,
+		Rollback: new(StartKubeAPIServerRollback),
+	}
+
+	waitAPIServerStopped := &task.RemoteTask{
+		Name:     "WaitKubeAPIServerStopped",
+		Desc:     "Wait for kube-apiserver to stop",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   new(CheckKubeAPIServerStopped),
+		Parallel: true,
+		Retry:    24,
+	}
+
+	stopETCD := &task.RemoteTask{
+		Name:     "StopETCD",
+		Desc:     "Stop etcd",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(StopETCD),
+		Parallel: true,
+		Rollback: new(StartETCDRollback),
+	}
+
+	restoreSnapshot := &task.RemoteTask{
+		Name:     "RestoreETCDSnapshot",
+		Desc:     "Restore etcd data from the snapshot",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(RestoreSnapshot),
+		Parallel: true,
+		Rollback: new(RecoverDataDirRollback),
+	}
+
+	start := &task.RemoteTask{
+		Name:     "StartETCD",
+		Desc:     "Start etcd members in order",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(StartETCD),
+		Parallel: false,
+	}
+
+	allETCDNodeHealthCheck := &task.RemoteTask{
+		Name:     "AllETCDNodeHealthCheck",
+		Desc:     "Health check on all etcd",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(HealthCheck),
+		Parallel: true,
+		Retry:    20,
+	}
+
+	startAPIServer := &task.RemoteTask{
+		Name:     "StartKubeAPIServer",
+		Desc:     "Start kube-apiserver",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   new(StartKubeAPIServer),
+		Parallel: true,
+	}
+
+	r.Tasks = []task.Interface{
+		checkMember,
+		syncSnapshot,
+		accessAddress,
+		stopAPIServer,
+		waitAPIServerStopped,
+		stopETCD,
+		restoreSnapshot,
+		start,
+		allETCDNodeHealthCheck,
+		startAPIServer,
+	}
+}
+
+type RemoveMemberModule struct {
+	common.KubeModule
+	Skip bool
+	// Member is the name of the host whose etcd member is removed, the host must not be in the etcd role group.
+	Member string
+	// Clean stops etcd and removes its files on the host of the member.
+	Clean bool
+}
+
+func (r *RemoveMemberModule) IsSkip() bool {
+	return r.Skip
+}
+
+func (r *RemoveMemberModule) Init() {
+	r.Name = "ETCDRemoveMemberModule"
+	r.Desc = "Remove an ETCD member"
+
+	accessAddress := &task.RemoteTask{
+		Name:     "GenerateAccessAddress",
+		Desc:     "Generate access address",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(FirstETCDNode),
+		Action:   new(GenerateAccessAddress),
+		Parallel: true,
+		Retry:    1,
+	}
+
+	removeMember := &task.RemoteTask{
+		Name:     "RemoveETCDMember",
+		Desc:     "Remove etcd member",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(FirstETCDNode),
+		Action:   &RemoveMember{Member: r.Member},
+		Parallel: false,
+		Retry:    2,
+	}
+
+	r.Tasks = []task.Interface{
+		accessAddress,
+		removeMember,
+	}
+
+	if !r.Clean {
+		return
+	}
+	for _, host := range r.Runtime.GetAllHosts() {
+		if host.GetName() != r.Member {
+			continue
+		}
+		uninstall := &task.RemoteTask{
+			Name:     "UninstallRemovedETCD",
+			Desc:     "Uninstall etcd on the removed member",
+			Hosts:    []connector.Host{host},
+			Action:   new(UninstallETCD),
+			Parallel: true,
+		}
+		r.Tasks = append(r.Tasks, uninstall)
+	}
+}
+
+type RefreshMembersModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (r *RefreshMembersModule) IsSkip() bool {
+	return r.Skip
+}
+
+func (r *RefreshMembersModule) Init() {
+	r.Name = "ETCDRefreshMembersModule"
+	r.Desc = "Refresh the ETCD members on etcd and kube-apiserver"
+
+	refreshConfig := &task.RemoteTask{
+		Name:     "RefreshETCDConfig",
+		Desc:     "Refresh etcd.env config on all etcd",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   &RefreshConfig{ToExisting: true},
+		Parallel: false,
+	}
+
+	allETCDNodeHealthCheck := &task.RemoteTask{
+		Name:     "AllETCDNodeHealthCheck",
+		Desc:     "Health check on all etcd",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(HealthCheck),
+		Parallel: true,
+		Retry:    20,
+	}
+
+	updateETCDServers := &task.RemoteTask{
+		Name:     "UpdateKubeAPIServerETCDServers",
+		Desc:     "Update the etcd servers of kube-apiserver one by one",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   new(UpdateETCDServers),
+		Parallel: false,
+		Retry:    1,
+	}
+
+	r.Tasks = []task.Interface{
+		refreshConfig,
+		allETCDNodeHealthCheck,
+		updateETCDServers,
+	}
+}
+
+type MaintainModule struct {
+	common.KubeModule
+	// StatusOnly reports the status of the members without maintaining them.
+	StatusOnly bool
+	// Output is the format of the report, table or json.
+	Output string
+}
+
+func (m *MaintainModule) Init() {
+	m.Name = "ETCDMaintainModule"
+	m.Desc = "Maintain ETCD cluster"
+
+	accessAddress := &task.RemoteTask{
+		Name:     "GenerateAccessAddress",
+		Desc:     "Generate access address",
+		Hosts:    m.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(FirstETCDNode),
+		Action:   new(GenerateAccessAddress),
+		Parallel: true,
+		Retry:    1,
+	}
+
+	healthCheck := &task.RemoteTask{
+		Name:     "ETCDHealthCheck",
+		Desc:     "Health check on all etcd before the maintenance",
+		Hosts:    m.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(HealthCheck),
+		Parallel: true,
+		Retry:    3,
+	}
+
+	compact := &task.RemoteTask{
+		Name:     "CompactETCD",
+		Desc:     "Compact etcd to the current revision",
+		Hosts:    m.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(FirstETCDNode),
+		Action:   new(CompactETCD),
+		Parallel: false,
+		Retry:    1,
+	}
+
+	defrag := &task.RemoteTask{
+		Name:     "DefragETCD",
+		Desc:     "Defragment etcd members one by one",
+		Hosts:    m.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(FirstETCDNode),
+		Action:   new(DefragETCD),
+		Parallel: false,
+	}
+
+	disarm := &task.RemoteTask{
+		Name:     "DisarmETCDAlarms",
+		Desc:     "Disarm etcd alarms",
+		Hosts:    m.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(FirstETCDNode),
+		Action:   new(DisarmAlarms),
+		Parallel: false,
+		Retry:    1,
+	}
+
+	allETCDNodeHealthCheck := &task.RemoteTask{
+		Name:     "AllETCDNodeHealthCheck",
+		Desc:     "Health check on all etcd",
+		Hosts:    m.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(HealthCheck),
+		Parallel: true,
+		Retry:    20,
+	}
+
+	memberStatus := &task.RemoteTask{
+		Name:     "GetETCDMemberStatus",
+		Desc:     "Get etcd member status",
+		Hosts:    m.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(GetMemberStatus),
+		Parallel: true,
+	}
+
+	display := &task.LocalTask{
+		Name:   "DisplayETCDMemberStatus",
+		Desc:   "Display etcd member status",
+		Action: &DisplayMemberStatus{Output: m.Output},
+	}
+
+	if m.StatusOnly {
+		m.Tasks = []task.Interface{
+			memberStatus,
+			display,
+		}
+		return
+	}
+
+	m.Tasks = []task.Interface{
+		accessAddress,
+		healthCheck,
+		compact,
+		defrag,
+		disarm,
+		allETCDNodeHealthCheck,
+		memberStatus,
+		display,
+	}
+}
+
+type MaintainTimerModule struct {
+	common.KubeModule
+	// Schedule is the OnCalendar of the timer.
+	Schedule string
+}
+
+func (m *MaintainTimerModule) Init() {
+	m.Name = "ETCDMaintainTimerModule"
+	m.Desc = "Maintain ETCD cluster regularly"
+
+	accessAddress := &task.RemoteTask{
+		Name:     "GenerateAccessAddress",
+		Desc:     "Generate access address",
+		Hosts:    m.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(FirstETCDNode),
+		Action:   new(GenerateAccessAddress),
+		Parallel: true,
+		Retry:    1,
+	}
+
+	checkSchedule := &task.RemoteTask{
+		Name:     "CheckMaintainSchedule",
+		Desc:     "Check the schedule of etcd maintenance",
+		Hosts:    m.Runtime.GetHostsByRole(common.ETCD),
+		Action:   &CheckMaintainSchedule{Schedule: m.Schedule},
+		Parallel: true,
+	}
+
+	generateScript := &task.RemoteTask{
+		Name:     "GenerateMaintainETCDScript",
+		Desc:     "Generate maintain ETCD script",
+		Hosts:    m.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(GenerateMaintainScript),
+		Parallel: true,
+	}
+
+	generateService := &task.RemoteTask{
+		Name:  "GenerateMaintainETCDService",
+		Desc:  "Generate maintain ETCD service",
+		Hosts: m.Runtime.GetHostsByRole(common.ETCD),
+		Action: &action.Template{
+			Template: templates.MaintainETCDService,
+			Dst:      filepath.Join("/etc/systemd/system/", templates.MaintainETCDService.Name()),
+			Data: util.Data{
+				"ScriptPath": filepath.Join(m.KubeConf.Cluster.Etcd.BackupScriptDir, "etcd-maintain.sh"),
+			},
+		},
+		Parallel: true,
+	}
+
+	generateTimer := &task.RemoteTask{
+		Name:  "GenerateMaintainETCDTimer",
+		Desc:  "Generate maintain ETCD timer",
+		Hosts: m.Runtime.GetHostsByRole(common.ETCD),
+		Action: &action.Template{
+			Template: templates.MaintainETCDTimer,
+			Dst:      filepath.Join("/etc/systemd/system/", templates.MaintainETCDTimer.Name()),
+			Data: util.Data{
+				"OnCalendarStr": m.Schedule,
+			},
+		},
+		Parallel: true,
+	}
+
+	enable := &task.RemoteTask{
+		Name:     "EnableMaintainETCDService",
+		Desc:     "Enable maintain etcd service",
+		Hosts:    m.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(EnableMaintainETCDService),
+		Parallel: true,
+	}
+
+	m.Tasks = []task.Interface{
+		accessAddress,
+		checkSchedule,
+		generateScript,
+		generateService,
+		generateTimer,
+		enable,
+	}
+}
+
+type ListBackupsModule struct {
+	common.KubeModule
+	// Node lists the snapshots of the node only if it is not empty.
+	Node string
+}
+
+func (l *ListBackupsModule) Init() {
+	l.Name = "ETCDListBackupsModule"
+	l.Desc = "List the ETCD backups in the backup target"
+
+	list := &task.LocalTask{
+		Name:   "ListETCDBackups",
+		Desc:   "List etcd backups",
+		Action: &ListBackups{Node: l.Node},
+	}
+
+	l.Tasks = []task.Interface{
+		list,
+	}
+}
+
+type DownloadBackupModule struct {
+	common.KubeModule
+	// Snapshot is the key of the snapshot in the backup target.
+	Snapshot string
+}
+
+func (d *DownloadBackupModule) Init() {
+	d.Name = "ETCDDownloadBackupModule"
+	d.Desc = "Download an ETCD backup from the backup target"
+
+	download := &task.LocalTask{
+		Name:   "DownloadETCDBackup",
+		Desc:   "Download etcd backup",
+		Action: &DownloadBackup{Snapshot: d.Snapshot},
+		Retry:  2,
+	}
+
+	sync := &task.RemoteTask{
+		Name:     "SyncETCDBackup",
+		Desc:     "Sync etcd backup to all etcd",
+		Hosts:    d.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(SyncDownloadedBackup),
+		Parallel: true,
+		Retry:    1,
+	}
+
+	d.Tasks = []task.Interface{
+		download,
+		sync,
+	}
 }
@@ -0,0 +1,67 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package etcd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/ending"
+)
+
+type StartKubeAPIServerRollback struct {
+	common.KubeRollback
+}
+
+func (s *StartKubeAPIServerRollback) Execute(runtime connector.Runtime, result *ending.ActionResult) error {
+	return startKubeAPIServer(s.KubeConf, runtime)
+}
+
+type StartETCDRollback struct {
+	common.KubeRollback
+}
+
+func (s *StartETCDRollback) Execute(runtime connector.Runtime, result *ending.ActionResult) error {
+	// the members of the previous data wait for each other to reach the quorum, so they are not started one by one
+	if _, err := runtime.GetRunner().SudoCmd("systemctl start --no-block etcd", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "start etcd failed")
+	}
+	return nil
+}
+
+// RecoverDataDirRollback moves the etcd data which was kept before the restore back.
+type RecoverDataDirRollback struct {
+	common.KubeRollback
+}
+
+func (r *RecoverDataDirRollback) Execute(runtime connector.Runtime, result *ending.ActionResult) error {
+	backupDir, ok := runtime.RemoteHost().GetCache().GetMustString(DataBackupDir)
+	if !ok {
+		// the data has not been touched
+		return nil
+	}
+
+	dataDir := DataDir(r.KubeConf)
+	recoverCmd := fmt.Sprintf("if [ -d %s ]; then systemctl stop etcd && rm -rf %s && mv %s %s; fi", backupDir, dataDir, backupDir, dataDir)
+	if _, err := runtime.GetRunner().SudoCmd(recoverCmd, false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "recover etcd data dir %s from %s failed", dataDir, backupDir)
+	}
+	return nil
+}
@@ -17,16 +17,23 @@
 package etcd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/action"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/etcd/templates"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/files"
@@ -51,8 +58,27 @@ const (
 
 	NewCluster   = "new"
 	ExistCluster = "existing"
+
+	// DownloadedSnapshot is the module cache key of the local path of the snapshot downloaded from the backup target.
+	DownloadedSnapshot = "etcdDownloadedSnapshot"
+
+	// DataBackupDir is the host cache key of the directory that the etcd data is moved to before a restore.
+	DataBackupDir = "etcdDataBackupDir"
+	// StartedMembers is the module cache key of the hosts whose etcd member has been started after a restore.
+	StartedMembers = "etcdStartedMembers"
+
+	defaultDataDir = "/var/lib/etcd"
+
+	kubeAPIServerManifest       = "/etc/kubernetes/manifests/kube-apiserver.yaml"
+	kubeAPIServerParkedManifest = "/etc/kubernetes/kube-apiserver.yaml.restore"
+
+	// defragTimeout is the timeout of defragmenting a member, which blocks the member and takes a while for a large db.
+	defragTimeout = "5m"
 )
 
+// SnapshotPath is where the snapshot to restore is placed on the etcd hosts.
+var SnapshotPath = filepath.Join(common.TmpDir, "etcd-snapshot.db")
+
 type GetStatus struct {
 	common.KubeAction
 }
@@ -84,12 +110,12 @@ func (g *GetStatus) Execute(runtime connector.Runtime) error {
 
 		if v, ok := g.PipelineCache.Get(common.ETCDCluster); ok {
 			c := v.(*EtcdCluster)
-			c.peerAddresses = append(c.peerAddresses, fmt.Sprintf("%s=https://%s:2380", etcdName, host.GetInternalIPv4Address()))
+			c.peerAddresses = append(c.peerAddresses, fmt.Sprintf("%s=%s", etcdName, PeerURL(g.KubeConf, host)))
 			c.clusterExist = true
 			// type: *EtcdCluster
 			g.PipelineCache.Set(common.ETCDCluster, c)
 		} else {
-			cluster.peerAddresses = append(cluster.peerAddresses, fmt.Sprintf("%s=https://%s:2380", etcdName, host.GetInternalIPv4Address()))
+			cluster.peerAddresses = append(cluster.peerAddresses, fmt.Sprintf("%s=%s", etcdName, PeerURL(g.KubeConf, host)))
 			cluster.clusterExist = true
 			g.PipelineCache.Set(common.ETCDCluster, cluster)
 		}
@@ -232,7 +258,7 @@ func (g *GenerateConfig) Execute(runtime connector.Runtime) error {
 			peerAddressesMap[v] = v
 		}
 
-		newPeerAddress := fmt.Sprintf("%s=https://%s:2380", etcdName, host.GetInternalIPv4Address())
+		newPeerAddress := fmt.Sprintf("%s=%s", etcdName, PeerURL(g.KubeConf, host))
 
 		if _, ok := peerAddressesMap[newPeerAddress]; !ok {
 			cluster.peerAddresses = append(cluster.peerAddresses, newPeerAddress)
@@ -324,6 +350,8 @@ func refreshConfig(KubeConf *common.KubeConf, runtime connector.Runtime, endpoin
 			"MaxWals":             KubeConf.Cluster.Etcd.MaxWals,
 			"ElectionTimeout":     KubeConf.Cluster.Etcd.ElectionTimeout,
 			"HeartbeatInterval":   KubeConf.Cluster.Etcd.HeartbeatInterval,
+			"PeerPort":            PeerPort(KubeConf),
+			"InitialClusterToken": InitialClusterToken(KubeConf),
 		},
 	}
 
@@ -353,7 +381,7 @@ func (j *JoinMember) Execute(runtime connector.Runtime) error {
 			"export ETCDCTL_CA_FILE='/etc/ssl/etcd/ssl/ca.pem';"+
 			"%s/etcdctl --endpoints=%s member add %s %s",
 			host.GetName(), host.GetName(), common.BinDir, cluster.accessAddresses, etcdName,
-			fmt.Sprintf("https://%s:2380", host.GetInternalIPv4Address()))
+			PeerURL(j.KubeConf, host))
 
 		if _, err := runtime.GetRunner().SudoCmd(joinMemberCmd, true); err != nil {
 			return errors.Wrap(errors.WithStack(err), "add etcd member failed")
@@ -407,11 +435,32 @@ func (r *RestartETCD) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+type RestartMember struct {
+	common.KubeAction
+}
+
+func (r *RestartMember) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd("systemctl restart etcd", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "restart etcd failed")
+	}
+	endpoint := fmt.Sprintf("https://%s:2379", runtime.RemoteHost().GetInternalIPv4Address())
+	if err := waitMemberHealthy(runtime, endpoint); err != nil {
+		return errors.Wrapf(err, "etcd member %s is unhealthy after the restart", endpoint)
+	}
+	return nil
+}
+
 type BackupETCD struct {
 	common.KubeAction
 }
 
 func (b *BackupETCD) Execute(runtime connector.Runtime) error {
+	var uploadCmd string
+	if b.KubeConf.Cluster.Etcd.BackupTarget != nil {
+		uploadCmd = fmt.Sprintf("%s --config %s --node %s",
+			filepath.Join(b.KubeConf.Cluster.Etcd.BackupScriptDir, "etcd-uploader"), BackupTargetConfigPath, runtime.RemoteHost().GetName())
+	}
+
 	templateAction := action.Template{
 		Template: templates.EtcdBackupScript,
 		Dst:      filepath.Join(b.KubeConf.Cluster.Etcd.BackupScriptDir, "etcd-backup.sh"),
@@ -422,6 +471,7 @@ func (b *BackupETCD) Execute(runtime connector.Runtime) error {
 			"Backupdir":           b.KubeConf.Cluster.Etcd.BackupDir,
 			"KeepbackupNumber":    b.KubeConf.Cluster.Etcd.KeepBackupNumber + 1,
 			"EtcdBackupScriptDir": b.KubeConf.Cluster.Etcd.BackupScriptDir,
+			"UploadCmd":           uploadCmd,
 		},
 	}
 
@@ -447,3 +497,843 @@ func (e *EnableBackupETCDService) Execute(runtime connector.Runtime) error {
 	}
 	return nil
 }
+
+type CheckInstalled struct {
+	common.KubeAction
+}
+
+func (c *CheckInstalled) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	exist, ok := host.GetCache().GetMustBool(common.ETCDExist)
+	if !ok {
+		return errors.New("get etcd node status by host label failed")
+	}
+	if !exist {
+		return errors.Errorf("etcd is not installed on %s, a snapshot can only be restored to an installed etcd cluster", host.GetName())
+	}
+	return nil
+}
+
+type SyncSnapshot struct {
+	common.KubeAction
+	Snapshot string
+}
+
+func (s *SyncSnapshot) Execute(runtime connector.Runtime) error {
+	if err := runtime.GetRunner().SudoScp(s.Snapshot, SnapshotPath); err != nil {
+		return errors.Wrap(errors.WithStack(err), "sync etcd snapshot failed")
+	}
+
+	// check the snapshot before the cluster is stopped
+	statusCmd := etcdutlCmd(fmt.Sprintf("snapshot status %s -w table", SnapshotPath))
+	if _, err := runtime.GetRunner().SudoCmd(statusCmd, true); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "the etcd snapshot %s is invalid", s.Snapshot)
+	}
+	return nil
+}
+
+type StopKubeAPIServer struct {
+	common.KubeAction
+}
+
+func (s *StopKubeAPIServer) Execute(runtime connector.Runtime) error {
+	var stopCmd string
+	switch s.KubeConf.Cluster.Kubernetes.Type {
+	case common.K3s, common.K8e:
+		stopCmd = fmt.Sprintf("systemctl stop %s", s.KubeConf.Cluster.Kubernetes.Type)
+	default:
+		// kubelet stops the static pod once its manifest is moved away
+		stopCmd = fmt.Sprintf("if [ -f %s ]; then mv -f %s %s; fi", kubeAPIServerManifest, kubeAPIServerManifest, kubeAPIServerParkedManifest)
+	}
+	if _, err := runtime.GetRunner().SudoCmd(stopCmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "stop kube-apiserver failed")
+	}
+	return nil
+}
+
+type CheckKubeAPIServerStopped struct {
+	common.KubeAction
+}
+
+func (c *CheckKubeAPIServerStopped) Execute(runtime connector.Runtime) error {
+	out, err := runtime.GetRunner().SudoCmd("pgrep -x kube-apiserver || true", false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "check kube-apiserver process failed")
+	}
+	if strings.TrimSpace(out) != "" {
+		return errors.New("kube-apiserver is still running")
+	}
+	return nil
+}
+
+type StartKubeAPIServer struct {
+	common.KubeAction
+}
+
+func (s *StartKubeAPIServer) Execute(runtime connector.Runtime) error {
+	return startKubeAPIServer(s.KubeConf, runtime)
+}
+
+func startKubeAPIServer(kubeConf *common.KubeConf, runtime connector.Runtime) error {
+	var startCmd string
+	switch kubeConf.Cluster.Kubernetes.Type {
+	case common.K3s, common.K8e:
+		startCmd = fmt.Sprintf("systemctl start %s", kubeConf.Cluster.Kubernetes.Type)
+	default:
+		startCmd = fmt.Sprintf("if [ -f %s ]; then mv -f %s %s; fi", kubeAPIServerParkedManifest, kubeAPIServerParkedManifest, kubeAPIServerManifest)
+	}
+	if _, err := runtime.GetRunner().SudoCmd(startCmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "start kube-apiserver failed")
+	}
+	return nil
+}
+
+type StopETCD struct {
+	common.KubeAction
+}
+
+func (s *StopETCD) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd("systemctl stop etcd", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "stop etcd failed")
+	}
+	return nil
+}
+
+type RestoreSnapshot struct {
+	common.KubeAction
+}
+
+func (r *RestoreSnapshot) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	etcdName, ok := host.GetCache().GetMustString(common.ETCDName)
+	if !ok {
+		return errors.New("get etcd node status by host label failed")
+	}
+	// keep the current data, so that it can be recovered if the restore fails
+	dataDir := DataDir(r.KubeConf)
+	backupDir := fmt.Sprintf("%s-bak-%s", dataDir, time.Now().Format("20060102150405"))
+	host.GetCache().Set(DataBackupDir, backupDir)
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("if [ -d %s ]; then mv %s %s; fi", dataDir, dataDir, backupDir), false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "backup etcd data dir %s failed", dataDir)
+	}
+
+	// the snapshot is restored as a single member cluster on the first etcd node, the other members join it one by one
+	// when they are started, so that each member can be started and checked before the next one.
+	if host.GetName() == runtime.GetHostsByRole(common.ETCD)[0].GetName() {
+		restoreCmd := etcdutlCmd(fmt.Sprintf("snapshot restore %s --name %s --initial-cluster %s=%s "+
+			"--initial-cluster-token %s --initial-advertise-peer-urls %s --data-dir %s",
+			SnapshotPath, etcdName, etcdName, PeerURL(r.KubeConf, host), InitialClusterToken(r.KubeConf),
+			PeerURL(r.KubeConf, host), dataDir))
+		if _, err := runtime.GetRunner().SudoCmd(restoreCmd, true); err != nil {
+			return errors.Wrap(errors.WithStack(err), "restore etcd snapshot failed")
+		}
+		if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chmod 700 %s", dataDir), false); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "chmod etcd data dir %s failed", dataDir)
+		}
+	}
+
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("rm -f %s", SnapshotPath), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "clean up etcd snapshot failed")
+	}
+	logger.Log.Infof("%s: the previous etcd data is kept in %s", host.GetName(), backupDir)
+	return nil
+}
+
+type StartETCD struct {
+	common.KubeAction
+}
+
+func (s *StartETCD) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	etcdName, ok := host.GetCache().GetMustString(common.ETCDName)
+	if !ok {
+		return errors.New("get etcd node status by host label failed")
+	}
+
+	var started []connector.Host
+	if v, ok := s.ModuleCache.Get(StartedMembers); ok {
+		started = v.([]connector.Host)
+	}
+
+	state := NewCluster
+	peerAddresses := make([]string, 0, len(started)+1)
+	endpoints := make([]string, 0, len(started))
+	for _, h := range started {
+		name, _ := h.GetCache().GetMustString(common.ETCDName)
+		peerAddresses = append(peerAddresses, fmt.Sprintf("%s=%s", name, PeerURL(s.KubeConf, h)))
+		endpoints = append(endpoints, fmt.Sprintf("https://%s:2379", h.GetInternalIPv4Address()))
+	}
+	if len(started) > 0 {
+		// the member joins the cluster restored on the first etcd node with an empty data dir
+		addCmd := etcdctlCmd(host, strings.Join(endpoints, ","), fmt.Sprintf("member add %s --peer-urls=%s", etcdName, PeerURL(s.KubeConf, host)))
+		if _, err := runtime.GetRunner().SudoCmd(addCmd, true); err != nil {
+			return errors.Wrap(errors.WithStack(err), "add etcd member failed")
+		}
+		state = ExistCluster
+	}
+	peerAddresses = append(peerAddresses, fmt.Sprintf("%s=%s", etcdName, PeerURL(s.KubeConf, host)))
+
+	if err := refreshConfig(s.KubeConf, runtime, peerAddresses, state, etcdName); err != nil {
+		return err
+	}
+	if _, err := runtime.GetRunner().SudoCmd("systemctl daemon-reload && systemctl enable etcd && systemctl start etcd", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "start etcd failed")
+	}
+	if err := waitMemberHealthy(runtime, fmt.Sprintf("https://%s:2379", host.GetInternalIPv4Address())); err != nil {
+		return errors.Wrapf(err, "etcd member %s is not healthy", etcdName)
+	}
+
+	s.ModuleCache.Set(StartedMembers, append(started, host))
+	return nil
+}
+
+// DataDir returns the data dir of etcd.
+func DataDir(kubeConf *common.KubeConf) string {
+	if dir := kubeConf.Cluster.Etcd.DataDir; dir != nil && *dir != "" {
+		return *dir
+	}
+	return defaultDataDir
+}
+
+// PeerPort returns the port etcd listens on for the peer traffic.
+func PeerPort(kubeConf *common.KubeConf) int {
+	if port := kubeConf.Cluster.Etcd.PeerPort; port != 0 {
+		return port
+	}
+	return kubekeyapiv1alpha2.DefaultEtcdPeerPort
+}
+
+// PeerURL returns the peer URL of the etcd member on the host.
+func PeerURL(kubeConf *common.KubeConf, host connector.Host) string {
+	return fmt.Sprintf("https://%s:%d", host.GetInternalIPv4Address(), PeerPort(kubeConf))
+}
+
+// InitialClusterToken returns the initial cluster token of etcd.
+func InitialClusterToken(kubeConf *common.KubeConf) string {
+	if token := kubeConf.Cluster.Etcd.InitialClusterToken; token != "" {
+		return token
+	}
+	return kubekeyapiv1alpha2.DefaultEtcdClusterToken
+}
+
+// etcdutlCmd returns the command running etcdutl, etcdctl is used instead if etcdutl is not shipped with the etcd.
+func etcdutlCmd(args string) string {
+	return fmt.Sprintf("if [ -x %s/etcdutl ]; then %s/etcdutl %s; else ETCDCTL_API=3 %s/etcdctl %s; fi",
+		common.BinDir, common.BinDir, args, common.BinDir, args)
+}
+
+type RemoveMember struct {
+	common.KubeAction
+	Member string
+}
+
+func (r *RemoveMember) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	endpoint := fmt.Sprintf("https://%s:2379", host.GetInternalIPv4Address())
+
+	memberList, err := runtime.GetRunner().SudoCmd(etcdctlCmd(host, endpoint, "member list"), true)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "list etcd member failed")
+	}
+
+	// the member is named after its host by KubeKey, the peer URL is matched too in case the host is still known
+	names := []string{fmt.Sprintf("etcd-%s", r.Member), r.Member}
+	var peerURL string
+	for _, h := range runtime.GetAllHosts() {
+		if h.GetName() == r.Member {
+			peerURL = PeerURL(r.KubeConf, h)
+		}
+	}
+
+	id, ok := findMember(memberList, names, peerURL)
+	if !ok {
+		logger.Log.Infof("etcd member of %s is not found, it may have been removed", r.Member)
+		return nil
+	}
+	if _, err := runtime.GetRunner().SudoCmd(etcdctlCmd(host, endpoint, fmt.Sprintf("member remove %s", id)), true); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "remove etcd member %s failed", r.Member)
+	}
+	return nil
+}
+
+// findMember returns the ID of the member with one of the names or the peer URL in the output of "etcdctl member list",
+// whose lines are like "8e9e05c52164694d, started, etcd-node1, https://172.16.0.2:2380, https://172.16.0.2:2379, false".
+func findMember(memberList string, names []string, peerURL string) (string, bool) {
+	for _, line := range strings.Split(memberList, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ", ")
+		if len(fields) < 4 {
+			continue
+		}
+		for _, name := range names {
+			if fields[2] == name {
+				return fields[0], true
+			}
+		}
+		if peerURL == "" {
+			continue
+		}
+		for _, u := range strings.Split(fields[3], ",") {
+			if u == peerURL {
+				return fields[0], true
+			}
+		}
+	}
+	return "", false
+}
+
+type UninstallETCD struct {
+	common.KubeAction
+}
+
+func (u *UninstallETCD) Execute(runtime connector.Runtime) error {
+	// the member has been removed, so etcd may have stopped already
+	_, _ = runtime.GetRunner().SudoCmd("systemctl disable --now backup-etcd.timer", false)
+	_, _ = runtime.GetRunner().SudoCmd("systemctl disable --now maintain-etcd.timer", false)
+	_, _ = runtime.GetRunner().SudoCmd("systemctl disable --now etcd", false)
+
+	etcdFiles := []string{
+		"/etc/etcd.env",
+		"/etc/systemd/system/etcd.service",
+		"/etc/systemd/system/backup-etcd.service",
+		"/etc/systemd/system/backup-etcd.timer",
+		"/etc/systemd/system/maintain-etcd.service",
+		"/etc/systemd/system/maintain-etcd.timer",
+		filepath.Dir(BackupTargetConfigPath),
+		common.ETCDCertDir,
+		DataDir(u.KubeConf),
+	}
+	for _, file := range etcdFiles {
+		if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("rm -rf %s", file), false); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "remove %s failed", file)
+		}
+	}
+	if _, err := runtime.GetRunner().SudoCmd("systemctl daemon-reload", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "systemctl daemon-reload failed")
+	}
+	return nil
+}
+
+type UpdateETCDServers struct {
+	common.KubeAction
+}
+
+func (u *UpdateETCDServers) Execute(runtime connector.Runtime) error {
+	v, ok := u.PipelineCache.Get(common.ETCDCluster)
+	if !ok {
+		return errors.New("get etcd cluster status by pipeline cache failed")
+	}
+	servers := v.(*EtcdCluster).accessAddresses
+
+	var updateCmd, checkCmd string
+	switch u.KubeConf.Cluster.Kubernetes.Type {
+	case common.K3s, common.K8e:
+		key := fmt.Sprintf("%s_DATASTORE_ENDPOINT", strings.ToUpper(u.KubeConf.Cluster.Kubernetes.Type))
+		env := fmt.Sprintf("/etc/systemd/system/%s.service.env", u.KubeConf.Cluster.Kubernetes.Type)
+		updateCmd = fmt.Sprintf("sed -i 's#^%s=.*#%s=%s#' %s && systemctl daemon-reload && systemctl restart %s",
+			key, key, servers, env, u.KubeConf.Cluster.Kubernetes.Type)
+	default:
+		// kubelet restarts kube-apiserver once its manifest is changed
+		updateCmd = fmt.Sprintf("sed -i 's#--etcd-servers=.*#--etcd-servers=%s#' %s", servers, kubeAPIServerManifest)
+		// the previous kube-apiserver may still be serving until kubelet restarts it
+		checkCmd = fmt.Sprintf("pgrep -a -x kube-apiserver | grep -q -- '--etcd-servers=%s' && ", servers)
+	}
+	if _, err := runtime.GetRunner().SudoCmd(updateCmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "update the etcd servers of kube-apiserver failed")
+	}
+
+	// the masters are updated one by one, so that the next kube-apiserver is restarted only after this one is healthy
+	checkCmd += fmt.Sprintf("curl -sfk --max-time 5 -o /dev/null https://127.0.0.1:%d/healthz", kubekeyapiv1alpha2.DefaultApiserverPort)
+	var checkErr error
+	if err := wait.PollImmediateWithContext(runtime.GetRunner().Context(), 5*time.Second, 2*time.Minute, func(context.Context) (bool, error) {
+		_, checkErr = runtime.GetRunner().SudoCmd(checkCmd, false)
+		return checkErr == nil, nil
+	}); err != nil {
+		return errors.Wrap(errors.WithStack(checkErr), "kube-apiserver is not healthy after updating the etcd servers")
+	}
+	return nil
+}
+
+func etcdctlCmd(host connector.Host, endpoints, args string) string {
+	return fmt.Sprintf("export ETCDCTL_API=3;"+
+		"export ETCDCTL_CERT='/etc/ssl/etcd/ssl/admin-%s.pem';"+
+		"export ETCDCTL_KEY='/etc/ssl/etcd/ssl/admin-%s-key.pem';"+
+		"export ETCDCTL_CACERT='/etc/ssl/etcd/ssl/ca.pem';"+
+		"%s/etcdctl --endpoints=%s %s", host.GetName(), host.GetName(), common.BinDir, endpoints, args)
+}
+
+// waitMemberHealthy waits for the etcd member to be healthy, it gives up once the task is timeout or interrupted.
+func waitMemberHealthy(runtime connector.Runtime, endpoint string) error {
+	var checkErr error
+	if err := wait.PollImmediateWithContext(runtime.GetRunner().Context(), 5*time.Second, time.Minute, func(context.Context) (bool, error) {
+		_, checkErr = runtime.GetRunner().SudoCmd(etcdctlCmd(runtime.RemoteHost(), endpoint, "endpoint health"), false)
+		return checkErr == nil, nil
+	}); err != nil {
+		return errors.WithStack(checkErr)
+	}
+	return nil
+}
+
+// MemberStatus is the status of an etcd member in the maintenance report.
+type MemberStatus struct {
+	Node        string   `json:"node"`
+	Endpoint    string   `json:"endpoint"`
+	ID          string   `json:"id,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	DBSize      int64    `json:"dbSize"`
+	DBSizeInUse int64    `json:"dbSizeInUse"`
+	Leader      bool     `json:"leader"`
+	RaftTerm    uint64   `json:"raftTerm"`
+	RaftIndex   uint64   `json:"raftIndex"`
+	Revision    int64    `json:"revision"`
+	Healthy     bool     `json:"healthy"`
+	Alarms      []string `json:"alarms,omitempty"`
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// endpointStatus is an item in the output of "etcdctl endpoint status -w json".
+type endpointStatus struct {
+	Endpoint string `json:"Endpoint"`
+	Status   struct {
+		Header struct {
+			MemberID uint64 `json:"member_id"`
+			Revision int64  `json:"revision"`
+		} `json:"header"`
+		Version     string   `json:"version"`
+		DBSize      int64    `json:"dbSize"`
+		DBSizeInUse int64    `json:"dbSizeInUse"`
+		Leader      uint64   `json:"leader"`
+		RaftIndex   uint64   `json:"raftIndex"`
+		RaftTerm    uint64   `json:"raftTerm"`
+		Errors      []string `json:"errors"`
+	} `json:"Status"`
+}
+
+func (e endpointStatus) isLeader() bool {
+	return e.Status.Leader != 0 && e.Status.Leader == e.Status.Header.MemberID
+}
+
+func parseEndpointStatus(out string) ([]endpointStatus, error) {
+	// skip anything printed before the JSON, e.g. the warnings of etcdctl
+	if i := strings.Index(out, "["); i > 0 {
+		out = out[i:]
+	}
+	var statuses []endpointStatus
+	if err := json.Unmarshal([]byte(out), &statuses); err != nil {
+		return nil, errors.Wrapf(err, "parse etcd endpoint status %q failed", out)
+	}
+	return statuses, nil
+}
+
+func getEndpointStatus(runtime connector.Runtime, endpoints string) ([]endpointStatus, error) {
+	out, err := runtime.GetRunner().SudoCmd(etcdctlCmd(runtime.RemoteHost(), endpoints, "endpoint status -w json"), false)
+	if err != nil {
+		return nil, errors.Wrap(errors.WithStack(err), "get etcd endpoint status failed")
+	}
+	return parseEndpointStatus(out)
+}
+
+// defragOrder returns the endpoints to defragment one by one, the leader is the last one so that it is
+// elected only once if the defragmentation of a member causes a leader change.
+func defragOrder(statuses []endpointStatus) []string {
+	var endpoints []string
+	var leaders []string
+	for _, s := range statuses {
+		if s.isLeader() {
+			leaders = append(leaders, s.Endpoint)
+			continue
+		}
+		endpoints = append(endpoints, s.Endpoint)
+	}
+	return append(endpoints, leaders...)
+}
+
+// parseAlarms parses the output of "etcdctl alarm list" whose lines are like "memberID:10276657743932975437 alarm:NOSPACE",
+// and returns the alarms keyed by the member ID.
+func parseAlarms(out string) map[uint64][]string {
+	alarms := make(map[uint64][]string)
+	for _, line := range strings.Split(out, "\n") {
+		var id uint64
+		var alarm string
+		if _, err := fmt.Sscanf(strings.TrimSpace(line), "memberID:%d alarm:%s", &id, &alarm); err != nil {
+			continue
+		}
+		alarms[id] = append(alarms[id], alarm)
+	}
+	return alarms
+}
+
+type CompactETCD struct {
+	common.KubeAction
+}
+
+func (c *CompactETCD) Execute(runtime connector.Runtime) error {
+	endpoint := fmt.Sprintf("https://%s:2379", runtime.RemoteHost().GetInternalIPv4Address())
+	statuses, err := getEndpointStatus(runtime, endpoint)
+	if err != nil {
+		return err
+	}
+	if len(statuses) == 0 {
+		return errors.Errorf("no status of etcd endpoint %s", endpoint)
+	}
+
+	revision := statuses[0].Status.Header.Revision
+	compactCmd := etcdctlCmd(runtime.RemoteHost(), endpoint, fmt.Sprintf("compact %d --physical", revision))
+	if out, err := runtime.GetRunner().SudoCmd(compactCmd, false); err != nil {
+		// nothing has been written since the last compaction
+		if strings.Contains(out, "has been compacted") {
+			logger.Log.Infof("etcd has been compacted to revision %d", revision)
+			return nil
+		}
+		return errors.Wrapf(errors.WithStack(err), "compact etcd to revision %d failed", revision)
+	}
+	logger.Log.Infof("etcd is compacted to revision %d", revision)
+	return nil
+}
+
+type DefragETCD struct {
+	common.KubeAction
+}
+
+func (d *DefragETCD) Execute(runtime connector.Runtime) error {
+	v, ok := d.PipelineCache.Get(common.ETCDCluster)
+	if !ok {
+		return errors.New("get etcd cluster status by pipeline cache failed")
+	}
+	statuses, err := getEndpointStatus(runtime, v.(*EtcdCluster).accessAddresses)
+	if err != nil {
+		return err
+	}
+
+	host := runtime.RemoteHost()
+	for _, endpoint := range defragOrder(statuses) {
+		logger.Log.Infof("defragmenting etcd member %s", endpoint)
+		defragCmd := etcdctlCmd(host, endpoint, fmt.Sprintf("--command-timeout=%s defrag", defragTimeout))
+		if _, err := runtime.GetRunner().SudoCmd(defragCmd, false); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "defragment etcd member %s failed", endpoint)
+		}
+		// the member is blocked during the defragmentation, wait for it before going on with the next one
+		if err := waitMemberHealthy(runtime, endpoint); err != nil {
+			return errors.Wrapf(err, "etcd member %s is unhealthy after the defragmentation", endpoint)
+		}
+	}
+	return nil
+}
+
+type DisarmAlarms struct {
+	common.KubeAction
+}
+
+func (d *DisarmAlarms) Execute(runtime connector.Runtime) error {
+	v, ok := d.PipelineCache.Get(common.ETCDCluster)
+	if !ok {
+		return errors.New("get etcd cluster status by pipeline cache failed")
+	}
+	endpoints := v.(*EtcdCluster).accessAddresses
+
+	host := runtime.RemoteHost()
+	out, err := runtime.GetRunner().SudoCmd(etcdctlCmd(host, endpoints, "alarm list"), false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "list etcd alarms failed")
+	}
+	alarms := parseAlarms(out)
+	if len(alarms) == 0 {
+		return nil
+	}
+	for id, a := range alarms {
+		logger.Log.Warnf("etcd member %x has alarms %s", id, strings.Join(a, ","))
+	}
+	if _, err := runtime.GetRunner().SudoCmd(etcdctlCmd(host, endpoints, "alarm disarm"), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "disarm etcd alarms failed")
+	}
+	return nil
+}
+
+type GetMemberStatus struct {
+	common.KubeAction
+}
+
+func (g *GetMemberStatus) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	endpoint := fmt.Sprintf("https://%s:2379", host.GetInternalIPv4Address())
+	status := &MemberStatus{Node: host.GetName(), Endpoint: endpoint}
+	// type: *MemberStatus
+	host.GetCache().Set(common.ETCDMemberStatus, status)
+
+	// an unavailable member is reported rather than failing the task
+	if _, err := runtime.GetRunner().SudoCmd(etcdctlCmd(host, endpoint, "endpoint health"), false); err != nil {
+		status.Errors = append(status.Errors, "unhealthy")
+	} else {
+		status.Healthy = true
+	}
+
+	statuses, err := getEndpointStatus(runtime, endpoint)
+	if err != nil || len(statuses) == 0 {
+		status.Healthy = false
+		status.Errors = append(status.Errors, "failed to get the endpoint status")
+		return nil
+	}
+	s := statuses[0]
+	status.ID = fmt.Sprintf("%x", s.Status.Header.MemberID)
+	status.Version = s.Status.Version
+	status.DBSize = s.Status.DBSize
+	status.DBSizeInUse = s.Status.DBSizeInUse
+	status.Leader = s.isLeader()
+	status.RaftTerm = s.Status.RaftTerm
+	status.RaftIndex = s.Status.RaftIndex
+	status.Revision = s.Status.Header.Revision
+	status.Errors = append(status.Errors, s.Status.Errors...)
+
+	if out, err := runtime.GetRunner().SudoCmd(etcdctlCmd(host, endpoint, "alarm list"), false); err == nil {
+		status.Alarms = parseAlarms(out)[s.Status.Header.MemberID]
+	}
+	return nil
+}
+
+type DisplayMemberStatus struct {
+	common.KubeAction
+	Output string
+}
+
+func (d *DisplayMemberStatus) Execute(runtime connector.Runtime) error {
+	statuses := make([]*MemberStatus, 0)
+	for _, host := range runtime.GetHostsByRole(common.ETCD) {
+		v, ok := host.GetCache().Get(common.ETCDMemberStatus)
+		if !ok {
+			return errors.Errorf("get the etcd member status of %s by host cache failed", host.GetName())
+		}
+		statuses = append(statuses, v.(*MemberStatus))
+	}
+
+	if d.Output == "json" {
+		out, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "marshal etcd member status failed")
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 10, 4, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NODE\tENDPOINT\tID\tVERSION\tDB SIZE\tIN USE\tLEADER\tRAFT TERM\tRAFT INDEX\tHEALTH\tALARMS\tERRORS")
+	for _, s := range statuses {
+		health := "healthy"
+		if !s.Healthy {
+			health = "unhealthy"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%t\t%d\t%d\t%s\t%s\t%s\n",
+			s.Node,
+			s.Endpoint,
+			s.ID,
+			s.Version,
+			files.FormatBytes(s.DBSize),
+			files.FormatBytes(s.DBSizeInUse),
+			s.Leader,
+			s.RaftTerm,
+			s.RaftIndex,
+			health,
+			strings.Join(s.Alarms, ","),
+			strings.Join(s.Errors, ", "),
+		)
+	}
+	return w.Flush()
+}
+
+type GenerateMaintainScript struct {
+	common.KubeAction
+}
+
+func (g *GenerateMaintainScript) Execute(runtime connector.Runtime) error {
+	v, ok := g.PipelineCache.Get(common.ETCDCluster)
+	if !ok {
+		return errors.New("get etcd cluster status by pipeline cache failed")
+	}
+
+	scriptPath := filepath.Join(g.KubeConf.Cluster.Etcd.BackupScriptDir, "etcd-maintain.sh")
+	templateAction := action.Template{
+		Template: templates.EtcdMaintainScript,
+		Dst:      scriptPath,
+		Data: util.Data{
+			"Hostname":      runtime.RemoteHost().GetName(),
+			"Endpoint":      fmt.Sprintf("https://%s:2379", runtime.RemoteHost().GetInternalIPv4Address()),
+			"Endpoints":     v.(*EtcdCluster).accessAddresses,
+			"DefragTimeout": defragTimeout,
+		},
+	}
+
+	templateAction.Init(nil, nil)
+	if err := templateAction.Execute(runtime); err != nil {
+		return err
+	}
+
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chmod +x %s", scriptPath), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "chmod etcd maintain script failed")
+	}
+	return nil
+}
+
+type CheckMaintainSchedule struct {
+	common.KubeAction
+	Schedule string
+}
+
+func (c *CheckMaintainSchedule) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("systemd-analyze calendar '%s'", c.Schedule), false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "invalid etcd maintain schedule %q", c.Schedule)
+	}
+	return nil
+}
+
+type EnableMaintainETCDService struct {
+	common.KubeAction
+}
+
+func (e *EnableMaintainETCDService) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd("systemctl daemon-reload && systemctl enable --now maintain-etcd.timer",
+		false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "enable maintain-etcd.timer failed")
+	}
+	return nil
+}
+
+type SyncBackupTarget struct {
+	common.KubeAction
+}
+
+func (s *SyncBackupTarget) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	// the snapshots are uploaded by the etcd-uploader built for the arch of the node
+	binary, err := uploaderBinary(host.GetArch())
+	if err != nil {
+		return err
+	}
+
+	cfg, err := NewBackupTargetConfig(s.KubeConf.Cluster.Etcd.BackupTarget)
+	if err != nil {
+		return err
+	}
+	if cfg.CAFile != "" {
+		if err := runtime.GetRunner().SudoScp(cfg.CAFile, backupTargetCAPath); err != nil {
+			return errors.Wrap(errors.WithStack(err), "sync the CA file of the etcd backup target failed")
+		}
+		cfg.CAFile = backupTargetCAPath
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return errors.Wrap(err, "marshal the etcd backup target config failed")
+	}
+	configFile := filepath.Join(runtime.GetHostWorkDir(), filepath.Base(BackupTargetConfigPath))
+	if err := os.WriteFile(configFile, data, 0600); err != nil {
+		return errors.Wrapf(err, "write file %s failed", configFile)
+	}
+	defer os.Remove(configFile)
+	if err := runtime.GetRunner().SudoScp(configFile, BackupTargetConfigPath); err != nil {
+		return errors.Wrap(errors.WithStack(err), "sync the etcd backup target config failed")
+	}
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chmod 600 %s", BackupTargetConfigPath), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "chmod the etcd backup target config failed")
+	}
+
+	uploaderFile := filepath.Join(runtime.GetHostWorkDir(), "etcd-uploader")
+	if err := os.WriteFile(uploaderFile, binary, 0755); err != nil {
+		return errors.Wrapf(err, "write file %s failed", uploaderFile)
+	}
+	defer os.Remove(uploaderFile)
+	dst := filepath.Join(s.KubeConf.Cluster.Etcd.BackupScriptDir, "etcd-uploader")
+	if err := runtime.GetRunner().SudoScp(uploaderFile, dst); err != nil {
+		return errors.Wrap(errors.WithStack(err), "sync the etcd backup uploader failed")
+	}
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chmod +x %s", dst), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "chmod the etcd backup uploader failed")
+	}
+	return nil
+}
+
+type SetBackupRetention struct {
+	common.KubeAction
+}
+
+func (s *SetBackupRetention) Execute(runtime connector.Runtime) error {
+	days := s.KubeConf.Cluster.Etcd.BackupTarget.RetentionDays
+	if days < 0 {
+		logger.Log.Infof("the retention of the etcd backups is left to the lifecycle rules of the bucket")
+		return nil
+	}
+	cfg, err := NewBackupTargetConfig(s.KubeConf.Cluster.Etcd.BackupTarget)
+	if err != nil {
+		return err
+	}
+	return SetSnapshotRetention(runtime.GetRunner().Context(), cfg, days)
+}
+
+type ListBackups struct {
+	common.KubeAction
+	Node string
+}
+
+func (l *ListBackups) Execute(runtime connector.Runtime) error {
+	cfg, err := NewBackupTargetConfig(l.KubeConf.Cluster.Etcd.BackupTarget)
+	if err != nil {
+		return err
+	}
+	snapshots, err := ListSnapshots(runtime.GetRunner().Context(), cfg, l.Node)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 10, 4, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SNAPSHOT\tSIZE\tLAST MODIFIED")
+	for _, s := range snapshots {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, files.FormatBytes(s.Size), s.LastModified.Local().Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+type DownloadBackup struct {
+	common.KubeAction
+	Snapshot string
+}
+
+func (d *DownloadBackup) Execute(runtime connector.Runtime) error {
+	cfg, err := NewBackupTargetConfig(d.KubeConf.Cluster.Etcd.BackupTarget)
+	if err != nil {
+		return err
+	}
+
+	dst := filepath.Join(runtime.GetWorkDir(), "etcd-backup", filepath.FromSlash(d.Snapshot))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return errors.Wrapf(err, "create dir %s failed", filepath.Dir(dst))
+	}
+	if err := DownloadSnapshot(runtime.GetRunner().Context(), cfg, d.Snapshot, dst); err != nil {
+		return err
+	}
+	logger.Log.Infof("etcd snapshot %s is downloaded to %s", d.Snapshot, dst)
+	// type: string
+	d.ModuleCache.Set(DownloadedSnapshot, dst)
+	return nil
+}
+
+type SyncDownloadedBackup struct {
+	common.KubeAction
+}
+
+func (s *SyncDownloadedBackup) Execute(runtime connector.Runtime) error {
+	local, ok := s.ModuleCache.GetMustString(DownloadedSnapshot)
+	if !ok {
+		return errors.New("get the downloaded etcd snapshot by module cache failed")
+	}
+
+	dst := DownloadedBackupPath(s.KubeConf, filepath.Base(local))
+	if err := runtime.GetRunner().SudoScp(local, dst); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "sync etcd snapshot to %s failed", dst)
+	}
+	logger.Log.Infof("etcd snapshot is synced to %s:%s", runtime.RemoteHost().GetName(), dst)
+	return nil
+}
+
+// DownloadedBackupPath returns where a snapshot downloaded from the backup target is placed on the etcd nodes. It is
+// out of the backupDir, otherwise it may be removed by the backup script as an old backup.
+func DownloadedBackupPath(kubeConf *common.KubeConf, name string) string {
+	dir := strings.TrimSuffix(kubeConf.Cluster.Etcd.BackupDir, "/") + "-remote"
+	return filepath.Join(dir, strings.TrimSuffix(name, ".db"), "snapshot.db")
+}
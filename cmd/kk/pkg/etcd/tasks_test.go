@@ -0,0 +1,100 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package etcd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindMember(t *testing.T) {
+	memberList := "8e9e05c52164694d, started, etcd-node1, https://172.16.0.2:2380, https://172.16.0.2:2379, false\r\n" +
+		"91bc3c398fb3c146, started, etcd-node2, https://172.16.0.3:2380, https://172.16.0.3:2379, false\r\n" +
+		"fd422379fda50e48, started, node3, https://172.16.0.4:2380,https://10.0.0.4:2380, https://172.16.0.4:2379, false"
+
+	tests := []struct {
+		name    string
+		names   []string
+		peerURL string
+		wantID  string
+		wantOK  bool
+	}{
+		{name: "named by kubekey", names: []string{"etcd-node2", "node2"}, wantID: "91bc3c398fb3c146", wantOK: true},
+		{name: "named after the host", names: []string{"etcd-node3", "node3"}, wantID: "fd422379fda50e48", wantOK: true},
+		{name: "matched by peer url", names: []string{"etcd-old"}, peerURL: "https://10.0.0.4:2380", wantID: "fd422379fda50e48", wantOK: true},
+		{name: "not found", names: []string{"etcd-node4", "node4"}, peerURL: "https://172.16.0.5:2380"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, ok := findMember(memberList, tt.names, tt.peerURL)
+			if id != tt.wantID || ok != tt.wantOK {
+				t.Errorf("findMember() = %s, %v, want %s, %v", id, ok, tt.wantID, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDefragOrder(t *testing.T) {
+	out := "{\"level\":\"warn\",\"msg\":\"retrying of unary invoker failed\"}\r\n" +
+		`[{"Endpoint":"https://172.16.0.2:2379","Status":{"header":{"cluster_id":14841639068965178418,"member_id":10276657743932975437,"revision":1024,"raft_term":3},"version":"3.5.6","dbSize":25001984,"leader":10501334649042878790,"raftIndex":2048,"raftTerm":3,"raftAppliedIndex":2048,"dbSizeInUse":8192000}},` +
+		`{"Endpoint":"https://172.16.0.3:2379","Status":{"header":{"cluster_id":14841639068965178418,"member_id":10501334649042878790,"revision":1024,"raft_term":3},"version":"3.5.6","dbSize":25001984,"leader":10501334649042878790,"raftIndex":2048,"raftTerm":3,"raftAppliedIndex":2048,"dbSizeInUse":8192000}},` +
+		`{"Endpoint":"https://172.16.0.4:2379","Status":{"header":{"cluster_id":14841639068965178418,"member_id":18249187646912138824,"revision":1024,"raft_term":3},"version":"3.5.6","dbSize":25001984,"leader":10501334649042878790,"raftIndex":2048,"raftTerm":3,"raftAppliedIndex":2048,"dbSizeInUse":8192000,"errors":["NOSPACE"]}}]`
+
+	statuses, err := parseEndpointStatus(out)
+	if err != nil {
+		t.Fatalf("parseEndpointStatus() error = %v", err)
+	}
+	if len(statuses) != 3 || statuses[0].Status.Header.Revision != 1024 || statuses[2].Status.Errors[0] != "NOSPACE" {
+		t.Fatalf("parseEndpointStatus() = %+v", statuses)
+	}
+
+	want := []string{"https://172.16.0.2:2379", "https://172.16.0.4:2379", "https://172.16.0.3:2379"}
+	if got := defragOrder(statuses); !reflect.DeepEqual(got, want) {
+		t.Errorf("defragOrder() = %v, want %v", got, want)
+	}
+
+	if _, err := parseEndpointStatus("Error: context deadline exceeded"); err == nil {
+		t.Errorf("parseEndpointStatus() should fail on an error message")
+	}
+}
+
+func TestParseAlarms(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want map[uint64][]string
+	}{
+		{name: "no alarm", out: "", want: map[uint64][]string{}},
+		{
+			name: "alarms of members",
+			out:  "memberID:10276657743932975437 alarm:NOSPACE\r\nmemberID:10276657743932975437 alarm:CORRUPT\r\nmemberID:18249187646912138824 alarm:NOSPACE",
+			want: map[uint64][]string{
+				10276657743932975437: {"NOSPACE", "CORRUPT"},
+				18249187646912138824: {"NOSPACE"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseAlarms(tt.out); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAlarms() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
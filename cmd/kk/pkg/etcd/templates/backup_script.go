@@ -61,5 +61,9 @@ export ETCDCTL_API=3;$ETCDCTL_PATH --endpoints="$ENDPOINTS" snapshot save $BACKU
 sleep 3
 
 cd $BACKUP_DIR/../ && ls -lt |awk '{if(NR > '$KEEPBACKUPNUMBER'){print "rm -rf "$9}}'|sh
+{{- if .UploadCmd }}
+
+{{ .UploadCmd }} --file $BACKUP_DIR/snapshot.db
+{{- end }}
 
 `)))
@@ -31,12 +31,12 @@ ETCD_DATA_DIR={{ .DataDir }}
 ETCD_DATA_DIR=/var/lib/etcd
 {{- end }}
 ETCD_ADVERTISE_CLIENT_URLS=https://{{ .Ip }}:2379
-ETCD_INITIAL_ADVERTISE_PEER_URLS=https://{{ .Ip }}:2380
+ETCD_INITIAL_ADVERTISE_PEER_URLS=https://{{ .Ip }}:{{ .PeerPort }}
 ETCD_INITIAL_CLUSTER_STATE={{ .State }}
 ETCD_METRICS=basic
 ETCD_LISTEN_CLIENT_URLS=https://{{ .Ip }}:2379,https://127.0.0.1:2379
-ETCD_INITIAL_CLUSTER_TOKEN=k8s_etcd
-ETCD_LISTEN_PEER_URLS=https://{{ .Ip }}:2380
+ETCD_INITIAL_CLUSTER_TOKEN={{ .InitialClusterToken }}
+ETCD_LISTEN_PEER_URLS=https://{{ .Ip }}:{{ .PeerPort }}
 ETCD_NAME={{ .Name }}
 ETCD_PROXY=off
 ETCD_ENABLE_V2=true
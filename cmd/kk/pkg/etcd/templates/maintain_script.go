@@ -0,0 +1,70 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package templates
+
+import (
+	"text/template"
+
+	"github.com/lithammer/dedent"
+)
+
+// EtcdMaintainScript defines the template of etcd maintain script. The script is run by the timer on every etcd
+// node, but only the one of the leader maintains the cluster, so the members are defragmented one at a time.
+var EtcdMaintainScript = template.Must(template.New("etcd-maintain.sh").Parse(
+	dedent.Dedent(`#!/bin/bash
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+ETCDCTL_PATH='/usr/local/bin/etcdctl'
+LOCAL_ENDPOINT='{{ .Endpoint }}'
+ENDPOINTS='{{ .Endpoints }}'
+
+export ETCDCTL_API=3
+export ETCDCTL_CERT="/etc/ssl/etcd/ssl/admin-{{ .Hostname }}.pem"
+export ETCDCTL_KEY="/etc/ssl/etcd/ssl/admin-{{ .Hostname }}-key.pem"
+export ETCDCTL_CACERT="/etc/ssl/etcd/ssl/ca.pem"
+
+STATUS=$($ETCDCTL_PATH --endpoints="$LOCAL_ENDPOINT" endpoint status -w fields)
+MEMBER_ID=$(echo "$STATUS" | awk -F' : ' '/^"MemberID"/{print $2}')
+LEADER=$(echo "$STATUS" | awk -F' : ' '/^"Leader"/{print $2}')
+REVISION=$(echo "$STATUS" | awk -F' : ' '/^"Revision"/{print $2}')
+
+if [ "$MEMBER_ID" != "$LEADER" ]; then
+  echo "$LOCAL_ENDPOINT is not the leader, skip the maintenance"
+  exit 0
+fi
+
+$ETCDCTL_PATH --endpoints="$ENDPOINTS" endpoint health
+
+if ! OUTPUT=$($ETCDCTL_PATH --endpoints="$LOCAL_ENDPOINT" compact "$REVISION" --physical 2>&1); then
+  echo "$OUTPUT" | grep -q 'has been compacted' || { echo "$OUTPUT" >&2; exit 1; }
+fi
+
+# defragment the followers first and the leader last
+for EP in ${ENDPOINTS//,/ }; do
+  [ "$EP" == "$LOCAL_ENDPOINT" ] && continue
+  $ETCDCTL_PATH --endpoints="$EP" --command-timeout={{ .DefragTimeout }} defrag
+  $ETCDCTL_PATH --endpoints="$EP" endpoint health
+done
+$ETCDCTL_PATH --endpoints="$LOCAL_ENDPOINT" --command-timeout={{ .DefragTimeout }} defrag
+$ETCDCTL_PATH --endpoints="$LOCAL_ENDPOINT" endpoint health
+
+$ETCDCTL_PATH --endpoints="$ENDPOINTS" alarm disarm
+
+`)))
@@ -0,0 +1,51 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package templates
+
+import (
+	"text/template"
+
+	"github.com/lithammer/dedent"
+)
+
+var (
+	// MaintainETCDService defines the template of maintain-etcd service for systemd.
+	MaintainETCDService = template.Must(template.New("maintain-etcd.service").Parse(
+		dedent.Dedent(`[Unit]
+Description=Maintain ETCD
+After=etcd.service
+[Service]
+Type=oneshot
+ExecStart={{ .ScriptPath }}
+    `)))
+
+	// MaintainETCDTimer defines the template of maintain-etcd timer for systemd.
+	MaintainETCDTimer = template.Must(template.New("maintain-etcd.timer").Parse(
+		dedent.Dedent(`[Unit]
+Description=Timer to maintain ETCD
+[Timer]
+{{- if .OnCalendarStr }}
+OnCalendar={{ .OnCalendarStr }}
+{{- else }}
+OnCalendar=Sun *-*-* 03:00:00
+{{- end }}
+Persistent=true
+Unit=maintain-etcd.service
+[Install]
+WantedBy=multi-user.target
+    `)))
+)
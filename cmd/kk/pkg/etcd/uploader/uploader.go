@@ -0,0 +1,55 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package uploader uploads the etcd snapshots to the backup target. It is built into the etcd-uploader, which the
+// backup service runs on the etcd nodes, so it only depends on the s3 client.
+package uploader
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/s3"
+)
+
+const (
+	// DefaultConfigPath is where the config of the backup target is placed on the etcd nodes.
+	DefaultConfigPath = "/etc/kubekey/etcd/backup-target.json"
+
+	snapshotTimeFormat = "2006-01-02-15-04-05"
+)
+
+// Config is the config of the object storage that the etcd snapshots are uploaded to, with the credentials.
+type Config struct {
+	s3.Config
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// Upload uploads the snapshot of the node as <prefix>/<node>/etcd-<time>.db and returns the key. The old snapshots
+// are expired by the lifecycle rule of the bucket, so the uploader only needs to put the objects.
+func Upload(ctx context.Context, cfg *Config, file, node string) (string, error) {
+	client, err := s3.NewClient(cfg.Config)
+	if err != nil {
+		return "", err
+	}
+
+	key := path.Join(cfg.Prefix, node, "etcd-"+time.Now().Format(snapshotTimeFormat)+".db")
+	if err := client.PutObject(ctx, key, file); err != nil {
+		return "", err
+	}
+	return key, nil
+}
@@ -0,0 +1,407 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package files
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
+)
+
+const (
+	DefaultDownloadParallel = 4
+	DefaultDownloadRetries  = 5
+
+	partialSuffix    = ".part"
+	progressInterval = 5 * time.Second
+	retryDelay       = 3 * time.Second
+)
+
+// DownloadOptions configures the built-in downloader, which is used when no download command is given.
+type DownloadOptions struct {
+	// Proxy is the URL of the HTTP(S) proxy. The proxy environment variables are used if it is empty.
+	Proxy string
+	// CACert is the path of a PEM file of the CAs to trust besides the system ones.
+	CACert string
+	// Mirrors are the fallback URLs of the components keyed by the component name, e.g. kubeadm. They are tried
+	// in order after the default URL, and the placeholders {version}, {arch} and {file} in them are replaced.
+	Mirrors map[string][]string
+	// Parallel is the number of the components downloaded at the same time.
+	Parallel int
+	// Retries is the number of the attempts on each URL, a failed attempt is resumed by the next one.
+	Retries int
+}
+
+// ParseMirrors parses the mirrors in the form of <component>=<url>.
+func ParseMirrors(mirrors []string) (map[string][]string, error) {
+	res := make(map[string][]string)
+	for _, m := range mirrors {
+		name, u, ok := strings.Cut(m, "=")
+		name, u = strings.TrimSpace(name), strings.TrimSpace(u)
+		if !ok || name == "" || u == "" {
+			return nil, errors.Errorf("invalid download mirror %q, it should be in the form of <component>=<url>", m)
+		}
+		res[name] = append(res[name], u)
+	}
+	return res, nil
+}
+
+// Downloader downloads the files over HTTP(S) natively. An interrupted download is resumed by a Range request,
+// and the file is verified against its sha256 checksum while streaming.
+type Downloader struct {
+	client   *http.Client
+	mirrors  map[string][]string
+	parallel int
+	retries  int
+}
+
+func NewDownloader(opts DownloadOptions) (*Downloader, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.ResponseHeaderTimeout = time.Minute
+
+	if opts.Proxy != "" {
+		proxy, err := url.Parse(opts.Proxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid download proxy %s", opts.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if opts.CACert != "" {
+		pem, err := os.ReadFile(opts.CACert)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read CA file %s failed", opts.CACert)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificate found in CA file %s", opts.CACert)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	d := &Downloader{
+		client:   &http.Client{Transport: transport},
+		mirrors:  opts.Mirrors,
+		parallel: opts.Parallel,
+		retries:  opts.Retries,
+	}
+	if d.parallel <= 0 {
+		d.parallel = DefaultDownloadParallel
+	}
+	if d.retries <= 0 {
+		d.retries = DefaultDownloadRetries
+	}
+	return d, nil
+}
+
+// DownloadAll downloads the binaries in parallel. The binaries which exist with the right checksum are skipped.
+func (d *Downloader) DownloadAll(ctx context.Context, binaries []*KubeBinary) error {
+	pool := make(chan struct{}, d.parallel)
+	wg := &sync.WaitGroup{}
+	mu := &sync.Mutex{}
+	var errs []string
+
+	for _, binary := range binaries {
+		if err := binary.CreateBaseDir(); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "create file %s base dir failed", binary.FileName)
+		}
+		if binary.Downloader == nil {
+			binary.Downloader = d
+		}
+
+		wg.Add(1)
+		go func(b *KubeBinary) {
+			pool <- struct{}{}
+			defer func() {
+				<-pool
+				wg.Done()
+			}()
+
+			if err := b.ResolveSha256(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Sprintf("Failed to download %s binary: %s error: %v", b.ID, b.Source(), err))
+				mu.Unlock()
+				return
+			}
+			if util.IsExist(b.Path()) {
+				// download it again if it's incorrect
+				if err := b.SHA256Check(); err == nil {
+					logger.Log.Infof("%s %s %s exists", b.Arch, b.ID, b.Version)
+					return
+				}
+				_ = os.Remove(b.Path())
+			}
+
+			logger.Log.Infof("downloading %s %s %s ...", b.Arch, b.ID, b.Version)
+			if err := b.DownloadContext(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Sprintf("Failed to download %s binary: %s error: %v", b.ID, b.Source(), err))
+				mu.Unlock()
+			}
+		}(binary)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// URLs returns the default URL of the binary followed by its mirrors.
+func (d *Downloader) URLs(b *KubeBinary) []string {
+	urls := []string{b.Url}
+	r := strings.NewReplacer("{version}", b.Version, "{arch}", b.Arch, "{file}", b.FileName)
+	for _, m := range d.mirrors[b.ID] {
+		urls = append(urls, r.Replace(m))
+	}
+	return urls
+}
+
+// Download downloads the file from the URLs in order until one succeeds. Each URL is tried several times, and
+// the partial file is resumed by the next attempt. The checksum is skipped if it is empty.
+func (d *Downloader) Download(ctx context.Context, name string, urls []string, path, checksum string) error {
+	var lastErr error
+	for _, u := range urls {
+		for i := 0; i < d.retries; i++ {
+			if i > 0 {
+				logger.Log.Warnf("download %s from %s failed: %v, retry in %s", name, u, lastErr, retryDelay)
+				timer := time.NewTimer(retryDelay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+
+			lastErr = d.fetch(ctx, name, u, path, checksum)
+			if lastErr == nil {
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			var statusErr *httpStatusError
+			if errors.As(lastErr, &statusErr) && !statusErr.temporary() {
+				// the file is not on this server, try the next mirror
+				break
+			}
+		}
+		if len(urls) > 1 {
+			logger.Log.Warnf("download %s from %s failed: %v", name, u, lastErr)
+		}
+	}
+	return lastErr
+}
+
+// Checksum gets the sha256 checksum from the checksum file at the url, in the form of "<checksum>  <file>".
+func (d *Downloader) Checksum(ctx context.Context, u string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", &httpStatusError{url: u, code: resp.StatusCode}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", errors.Wrapf(err, "read %s failed", u)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", errors.Errorf("no checksum found in %s", u)
+	}
+	if b, err := hex.DecodeString(fields[0]); err != nil || len(b) != sha256.Size {
+		return "", errors.Errorf("invalid sha256 checksum %q in %s", fields[0], u)
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+type httpStatusError struct {
+	url  string
+	code int
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("GET %s: unexpected status %d %s", e.url, e.code, http.StatusText(e.code))
+}
+
+func (e *httpStatusError) temporary() bool {
+	return e.code == http.StatusRequestTimeout || e.code == http.StatusTooManyRequests || e.code >= 500
+}
+
+// fetch downloads the url into the partial file next to the path, and moves it to the path once it is complete
+// and verified. The content of an existing partial file is kept and only the rest is requested.
+func (d *Downloader) fetch(ctx context.Context, name, u, path, checksum string) error {
+	part := path + partialSuffix
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "open %s failed", part)
+	}
+	defer f.Close()
+
+	// hash the downloaded part first, the rest is hashed while streaming
+	hasher := sha256.New()
+	offset, err := io.Copy(hasher, f)
+	if err != nil {
+		return errors.Wrapf(err, "read %s failed", part)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		logger.Log.Debugf("resume downloading %s from %d bytes", name, offset)
+	case http.StatusOK:
+		// the server ignores the range, download it from the beginning
+		if offset > 0 {
+			if err := restart(f, hasher); err != nil {
+				return err
+			}
+			offset = 0
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the partial file is complete or larger than the file on the server
+		if err := verify(hasher, checksum); err == nil {
+			return os.Rename(part, path)
+		}
+		_ = os.Remove(part)
+		return errors.Errorf("the partial file of %s is invalid, download it again", name)
+	default:
+		return &httpStatusError{url: u, code: resp.StatusCode}
+	}
+
+	total := resp.ContentLength
+	if total >= 0 {
+		total += offset
+	}
+	p := &progress{name: name, done: offset, total: total, last: time.Now()}
+	if _, err := io.Copy(io.MultiWriter(f, hasher, p), resp.Body); err != nil {
+		return errors.Wrapf(err, "download %s interrupted at %s", name, FormatBytes(p.done))
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := verify(hasher, checksum); err != nil {
+		_ = os.Remove(part)
+		return errors.Wrapf(err, "verify %s failed", name)
+	}
+	if err := os.Rename(part, path); err != nil {
+		return errors.Wrapf(err, "move %s to %s failed", part, path)
+	}
+	logger.Log.Infof("%s downloaded, %s", name, FormatBytes(p.done))
+	return nil
+}
+
+func restart(f *os.File, hasher hash.Hash) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	hasher.Reset()
+	return nil
+}
+
+func verify(hasher hash.Hash, checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+	if actual := hex.EncodeToString(hasher.Sum(nil)); actual != checksum {
+		return errors.Errorf("SHA256 no match. %s not equal %s", checksum, actual)
+	}
+	return nil
+}
+
+// progress prints the progress of a download periodically.
+type progress struct {
+	name  string
+	done  int64
+	total int64
+	last  time.Time
+}
+
+func (p *progress) Write(b []byte) (int, error) {
+	p.done += int64(len(b))
+	if now := time.Now(); now.Sub(p.last) >= progressInterval {
+		p.last = now
+		if p.total > 0 {
+			logger.Log.Infof("downloading %s: %s / %s (%d%%)", p.name, FormatBytes(p.done), FormatBytes(p.total), p.done*100/p.total)
+		} else {
+			logger.Log.Infof("downloading %s: %s", p.name, FormatBytes(p.done))
+		}
+	}
+	return len(b), nil
+}
+
+// FormatBytes formats the size in bytes with the binary units, e.g. 1.5 MiB.
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
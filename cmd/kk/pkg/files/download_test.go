@@ -0,0 +1,178 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package files
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+)
+
+func TestDownloader_Download(t *testing.T) {
+	logger.Log = logger.NewLogger(t.TempDir(), false)
+
+	content := bytes.Repeat([]byte("kubekey"), 4096)
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	var ranged int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/file", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			atomic.AddInt32(&ranged, 1)
+		}
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	})
+	mux.HandleFunc("/corrupted", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("corrupted"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		urls       []string
+		partial    []byte
+		wantErr    bool
+		wantRanged bool
+	}{
+		{name: "download", urls: []string{server.URL + "/file"}},
+		{name: "resume the partial file", urls: []string{server.URL + "/file"}, partial: content[:1000], wantRanged: true},
+		{name: "fall back to the mirror", urls: []string{server.URL + "/missing", server.URL + "/file"}},
+		{name: "checksum mismatch", urls: []string{server.URL + "/corrupted"}, wantErr: true},
+	}
+
+	d, err := NewDownloader(DownloadOptions{Retries: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&ranged, 0)
+			path := filepath.Join(t.TempDir(), "file")
+			if tt.partial != nil {
+				if err := os.WriteFile(path+partialSuffix, tt.partial, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := d.Download(context.Background(), "file", tt.urls, path, checksum)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Download() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if _, err := os.Stat(path); !os.IsNotExist(err) {
+					t.Errorf("the file should not exist after a failed download")
+				}
+				return
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, content) {
+				t.Errorf("the downloaded content does not match")
+			}
+			if (atomic.LoadInt32(&ranged) > 0) != tt.wantRanged {
+				t.Errorf("range requested = %v, want %v", ranged > 0, tt.wantRanged)
+			}
+		})
+	}
+}
+
+func TestParseMirrors(t *testing.T) {
+	got, err := ParseMirrors([]string{"kubeadm=https://a/{version}/{arch}/{file}", "kubeadm = https://b/{file}", "etcd=https://c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"kubeadm": {"https://a/{version}/{arch}/{file}", "https://b/{file}"},
+		"etcd":    {"https://c"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseMirrors() = %v, want %v", got, want)
+	}
+
+	if _, err := ParseMirrors([]string{"https://no-component"}); err == nil || !strings.Contains(err.Error(), "invalid") {
+		t.Errorf("expected an invalid mirror error, got %v", err)
+	}
+
+	d, _ := NewDownloader(DownloadOptions{Mirrors: got})
+	b := &KubeBinary{ID: "kubeadm", Version: "v1.26.5", Arch: "amd64", FileName: "kubeadm", Url: "https://default/kubeadm"}
+	urls := d.URLs(b)
+	wantURLs := []string{"https://default/kubeadm", "https://a/v1.26.5/amd64/kubeadm", "https://b/kubeadm"}
+	if !reflect.DeepEqual(urls, wantURLs) {
+		t.Errorf("URLs() = %v, want %v", urls, wantURLs)
+	}
+}
+
+func TestResolveSha256(t *testing.T) {
+	logger.Log = logger.NewLogger(t.TempDir(), false)
+
+	checksum := strings.Repeat("ab", sha256.Size)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/cri-o.amd64.v0.0.1.tar.gz.sha256sum", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(checksum + "  cri-o.amd64.v0.0.1.tar.gz\n"))
+	})
+	mux.HandleFunc("/cri-o.arm64.v0.0.1.tar.gz.sha256sum", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("not a checksum\n"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	d, err := NewDownloader(DownloadOptions{Retries: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		arch    string
+		want    string
+		wantErr bool
+	}{
+		{name: "published", arch: "amd64", want: checksum},
+		{name: "invalid", arch: "arm64", wantErr: true},
+		{name: "missing", arch: "riscv64", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &KubeBinary{ID: crio, Arch: tt.arch, Version: "0.0.1", Downloader: d,
+				Url: fmt.Sprintf("%s/cri-o.%s.v0.0.1.tar.gz", server.URL, tt.arch)}
+			err := b.ResolveSha256(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveSha256() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := b.GetSha256(); got != tt.want {
+				t.Errorf("GetSha256() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -17,6 +17,9 @@
 package files
 
 import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"io"
@@ -50,6 +53,7 @@ const (
 	harbor     = "harbor"
 	compose    = "compose"
 	containerd = "containerd"
+	crio       = "crio"
 	runc       = "runc"
 	calicoctl  = "calicoctl"
 	buildx     = "buildx"
@@ -66,6 +70,7 @@ const (
 	KUBE       = "kube"
 	REGISTRY   = "registry"
 	CONTAINERD = "containerd"
+	CRIO       = "crio"
 	RUNC       = "runc"
 	BUILD      = "buildx"
 )
@@ -89,6 +94,10 @@ type KubeBinary struct {
 	BaseDir  string
 	Zone     string
 	getCmd   func(path, url string) string
+	// Downloader is the built-in downloader used when no download command is given.
+	Downloader *Downloader
+	// publishedSha256 is the checksum published next to the file, it is used if components.json has none.
+	publishedSha256 string
 }
 
 func NewKubeBinary(name, arch, version, prePath string, getCmd func(path, url string) string) *KubeBinary {
@@ -213,6 +222,14 @@ func NewKubeBinary(name, arch, version, prePath string, getCmd func(path, url st
 		if component.Zone == "cn" {
 			component.Url = fmt.Sprintf("https://kubernetes-release.pek3b.qingstor.com/containerd/containerd/releases/download/v%s/containerd-%s-linux-%s.tar.gz", version, version, arch)
 		}
+	case crio:
+		// the static bundle contains crio, conmon, pinns and the OCI runtimes
+		component.Type = CRIO
+		component.FileName = fmt.Sprintf("cri-o.%s.v%s.tar.gz", arch, version)
+		component.Url = fmt.Sprintf("https://github.com/cri-o/cri-o/releases/download/v%s/cri-o.%s.v%s.tar.gz", version, arch, version)
+		if component.Zone == "cn" {
+			component.Url = fmt.Sprintf("https://kubernetes-release.pek3b.qingstor.com/cri-o/cri-o/releases/download/v%s/cri-o.%s.v%s.tar.gz", version, arch, version)
+		}
 	case runc:
 		component.Type = RUNC
 		component.FileName = fmt.Sprintf("runc.%s", arch)
@@ -254,6 +271,9 @@ func (b *KubeBinary) Path() string {
 }
 
 func (b *KubeBinary) GetCmd() string {
+	if b.getCmd == nil {
+		return ""
+	}
 	cmd := b.getCmd(b.Path(), b.Url)
 
 	if b.ID == helm && b.Zone != "cn" {
@@ -265,11 +285,156 @@ func (b *KubeBinary) GetCmd() string {
 }
 
 func (b *KubeBinary) GetSha256() string {
-	s := FileSha256[b.ID][b.Arch][b.Version]
-	return s
+	if s := FileSha256[b.ID][b.Arch][b.Version]; s != "" {
+		return s
+	}
+	return b.publishedSha256
+}
+
+// ResolveSha256 fetches the checksum published next to the CRI-O bundle, which is used if components.json has
+// none for its version.
+func (b *KubeBinary) ResolveSha256(ctx context.Context) error {
+	if b.ID != crio || strings.TrimSpace(b.GetSha256()) != "" {
+		return nil
+	}
+
+	d := b.Downloader
+	if d == nil {
+		var err error
+		if d, err = NewDownloader(DownloadOptions{}); err != nil {
+			return err
+		}
+	}
+	var err error
+	for _, u := range d.URLs(b) {
+		var checksum string
+		if checksum, err = d.Checksum(ctx, u+".sha256sum"); err == nil {
+			b.publishedSha256 = checksum
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+	return errors.Wrapf(err, "get the published SHA256 of %s %s failed", b.ID, b.Version)
+}
+
+// Source describes where the binary is downloaded from, it is the download command if there is one.
+func (b *KubeBinary) Source() string {
+	if b.getCmd != nil {
+		return b.GetCmd()
+	}
+	return b.Url
 }
 
+// Download downloads the binary by the download command, or by the built-in downloader if there is no command.
 func (b *KubeBinary) Download() error {
+	return b.DownloadContext(context.Background())
+}
+
+// DownloadContext is like Download, the built-in downloader stops once the context is done.
+func (b *KubeBinary) DownloadContext(ctx context.Context) error {
+	if err := b.ResolveSha256(ctx); err != nil {
+		return err
+	}
+	if b.getCmd != nil {
+		return b.downloadByCmd()
+	}
+
+	d := b.Downloader
+	if d == nil {
+		var err error
+		if d, err = NewDownloader(DownloadOptions{}); err != nil {
+			return err
+		}
+	}
+
+	checksum := strings.TrimSpace(b.GetSha256())
+	if checksum == "" {
+		return errors.New(fmt.Sprintf("No SHA256 found for %s. %s is not supported.", b.ID, b.Version))
+	}
+
+	var err error
+	urls := d.URLs(b)
+	for i, u := range urls {
+		if err = b.downloadFrom(ctx, d, u, checksum); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		if i < len(urls)-1 {
+			logger.Log.Warnf("download %s from %s failed: %v, try the next mirror", b.ID, u, err)
+		}
+	}
+	if os.Getenv("KKZONE") != "cn" {
+		logger.Log.Warningln("Having a problem with accessing https://storage.googleapis.com? You can try again after setting environment 'export KKZONE=cn'")
+	}
+	return err
+}
+
+func (b *KubeBinary) downloadFrom(ctx context.Context, d *Downloader, u, checksum string) error {
+	// helm is released in a tarball, but its checksum is the one of the binary in it
+	if b.ID != helm || !(strings.HasSuffix(u, ".tar.gz") || strings.HasSuffix(u, ".tgz")) {
+		return d.Download(ctx, b.ID, []string{u}, b.Path(), checksum)
+	}
+
+	archive := filepath.Join(b.BaseDir, fmt.Sprintf("helm-%s-linux-%s.tar.gz", b.Version, b.Arch))
+	if err := d.Download(ctx, b.ID, []string{u}, archive, ""); err != nil {
+		return err
+	}
+	defer os.Remove(archive)
+
+	if err := extractFile(archive, fmt.Sprintf("linux-%s/helm", b.Arch), b.Path()); err != nil {
+		return err
+	}
+	if err := b.SHA256Check(); err != nil {
+		_ = os.Remove(b.Path())
+		return err
+	}
+	return nil
+}
+
+// extractFile extracts the file of the name from the gzipped tarball to the path.
+func extractFile(archive, name, path string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return errors.Wrapf(err, "read %s failed", archive)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return errors.Errorf("%s is not found in %s", name, archive)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "read %s failed", archive)
+		}
+		if hdr.Name != name && hdr.Name != "./"+name {
+			continue
+		}
+
+		out, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, tr); err != nil {
+			out.Close()
+			return errors.Wrapf(err, "extract %s from %s failed", name, archive)
+		}
+		return out.Close()
+	}
+}
+
+func (b *KubeBinary) downloadByCmd() error {
 	for i := 5; i > 0; i-- {
 		cmd := exec.Command("/bin/sh", "-c", b.GetCmd())
 		stdout, err := cmd.StdoutPipe()
@@ -18,7 +18,7 @@ package images
 
 import (
 	"context"
-	"os"
+	"io"
 
 	"github.com/containers/image/v5/copy"
 	"github.com/containers/image/v5/signature"
@@ -29,6 +29,8 @@ type CopyImageOptions struct {
 	srcImage           *srcImageOptions
 	destImage          *destImageOptions
 	imageListSelection copy.ImageListSelection
+	// reportWriter is where the progress of the copy is written, it is discarded if nil.
+	reportWriter io.Writer
 }
 
 func (c *CopyImageOptions) Copy() error {
@@ -50,8 +52,12 @@ func (c *CopyImageOptions) Copy() error {
 	srcContext := c.srcImage.systemContext()
 	destContext := c.destImage.systemContext()
 
+	reportWriter := c.reportWriter
+	if reportWriter == nil {
+		reportWriter = io.Discard
+	}
 	_, err = copy.Image(context.Background(), policyContext, destRef, srcRef, &copy.Options{
-		ReportWriter:       os.Stdout,
+		ReportWriter:       reportWriter,
 		SourceCtx:          srcContext,
 		DestinationCtx:     destContext,
 		ImageListSelection: c.imageListSelection,
@@ -72,6 +78,7 @@ type Index struct {
 }
 
 type Manifest struct {
+	Digest      string
 	Annotations annotations
 }
 
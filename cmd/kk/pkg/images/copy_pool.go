@@ -0,0 +1,314 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package images
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+)
+
+const (
+	// DefaultCopyConcurrency is the number of the images copied at the same time by default.
+	DefaultCopyConcurrency = 4
+
+	copyRetries = 5
+
+	// SaveProgressFile and PushProgressFile in the work dir record the images saved into the artifact and pushed to
+	// the registry, they are removed once all the images are copied.
+	SaveProgressFile = "images-save-progress.json"
+	PushProgressFile = "images-push-progress.json"
+)
+
+var (
+	copyBackoff    = 2 * time.Second
+	maxCopyBackoff = time.Minute
+)
+
+// copyJob is an image to copy, its key identifies it in the progress file.
+type copyJob struct {
+	key  string
+	copy func() error
+}
+
+// CopyProgress is the persisted progress of copying images, an interrupted run resumes from the images not copied.
+type CopyProgress struct {
+	path string
+	mu   sync.Mutex
+	done map[string]bool
+}
+
+type copyProgressFile struct {
+	Done []string `json:"done"`
+}
+
+// LoadCopyProgress loads the progress in the file, it is empty if the file does not exist.
+func LoadCopyProgress(path string) (*CopyProgress, error) {
+	p := &CopyProgress{path: path, done: make(map[string]bool)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "read progress file %s failed", path)
+	}
+
+	f := copyProgressFile{}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, errors.Wrapf(err, "parse progress file %s failed", path)
+	}
+	for _, key := range f.Done {
+		p.done[key] = true
+	}
+	return p, nil
+}
+
+// IsDone reports whether the image has been copied.
+func (p *CopyProgress) IsDone(key string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.done[key]
+}
+
+// MarkDone records the image as copied and persists the progress.
+func (p *CopyProgress) MarkDone(key string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done[key] = true
+
+	f := copyProgressFile{Done: make([]string, 0, len(p.done))}
+	for k := range p.done {
+		f.Done = append(f.Done, k)
+	}
+	sort.Strings(f.Done)
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// the file is replaced by renaming, so it is never left half written
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return errors.Wrapf(err, "write progress file %s failed", tmp)
+	}
+	return errors.Wrapf(os.Rename(tmp, p.path), "write progress file %s failed", p.path)
+}
+
+// Remove removes the progress file.
+func (p *CopyProgress) Remove() error {
+	if err := os.Remove(p.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// copyImages copies the images by a pool of workers. Every image is retried with an exponential backoff, and recorded
+// in the progress once it is copied, so the images copied are skipped by the next run. No more images are started
+// after one of them fails or the ctx is done, and the progress file is removed after all of them are copied.
+func copyImages(ctx context.Context, jobs []copyJob, concurrency int, progress *CopyProgress) error {
+	if concurrency <= 0 {
+		concurrency = DefaultCopyConcurrency
+	}
+
+	pending := make([]copyJob, 0, len(jobs))
+	for _, job := range jobs {
+		if progress.IsDone(job.key) {
+			logger.Log.Infof("Skip the copied image %s", job.key)
+			continue
+		}
+		pending = append(pending, job)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		errs   []error
+		copied int
+	)
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(errs) != 0
+	}
+
+	ch := make(chan copyJob)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range ch {
+				if failed() || ctx.Err() != nil {
+					continue
+				}
+				err := copyWithRetry(ctx, job)
+				if err == nil {
+					err = progress.MarkDone(job.key)
+				}
+
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, err)
+				} else {
+					copied++
+					logger.Log.Infof("[%d/%d] Copied %s", copied, len(pending), job.key)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, job := range pending {
+		if failed() || ctx.Err() != nil {
+			break
+		}
+		ch <- job
+	}
+	close(ch)
+	wg.Wait()
+
+	if len(errs) == 0 && ctx.Err() != nil {
+		errs = append(errs, errors.Wrap(ctx.Err(), "copy images is cancelled"))
+	}
+	if len(errs) != 0 {
+		for _, err := range errs[1:] {
+			logger.Log.Errorf("%v", err)
+		}
+		return errors.Wrapf(errs[0], "%d images are not copied, they are resumed by running it again", len(pending)-copied)
+	}
+	return progress.Remove()
+}
+
+// copyWithRetry copies the image, and retries it after the backoff unless the ctx is done.
+func copyWithRetry(ctx context.Context, job copyJob) error {
+	backoff := copyBackoff
+	var err error
+	for i := 0; i < copyRetries; i++ {
+		if i > 0 {
+			logger.Log.Warnf("copy image %s failed: %v, retry in %s", job.key, err, backoff)
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return errors.Wrapf(ctx.Err(), "copy image %s is cancelled, the last error is %v", job.key, err)
+			case <-timer.C:
+			}
+			if backoff *= 2; backoff > maxCopyBackoff {
+				backoff = maxCopyBackoff
+			}
+		}
+		if err = job.copy(); err == nil {
+			return nil
+		}
+	}
+	return errors.Wrapf(err, "copy image %s failed after %d retries", job.key, copyRetries)
+}
+
+// ociLayout is an OCI image layout the images are saved into concurrently. Every image is copied into a staging
+// layout sharing the blobs directory of the layout, so the blobs are only saved once for all the images, and then
+// merged into the index of the layout.
+type ociLayout struct {
+	dir        string
+	stagingDir string
+	mu         sync.Mutex
+}
+
+// blobsDir is the blobs directory shared by the staging layouts.
+func (l *ociLayout) blobsDir() string {
+	return filepath.Join(l.dir, "blobs")
+}
+
+// staging returns the staging layout of an image.
+func (l *ociLayout) staging(index int) string {
+	return filepath.Join(l.stagingDir, strconv.Itoa(index))
+}
+
+// merge merges the index of the staging layout into the index of the layout, and removes the staging layout.
+func (l *ociLayout) merge(stagingDir string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	staging, err := readOCIIndex(filepath.Join(stagingDir, "index.json"))
+	if err != nil {
+		return err
+	}
+	index, err := readOCIIndex(filepath.Join(l.dir, "index.json"))
+	if os.IsNotExist(errors.Cause(err)) {
+		index = &ocispec.Index{Annotations: map[string]string{}}
+		index.SchemaVersion = 2
+	} else if err != nil {
+		return err
+	}
+	mergeOCIIndex(index, staging)
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	layout, err := json.Marshal(ocispec.ImageLayout{Version: ocispec.ImageLayoutVersion})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(l.dir, ocispec.ImageLayoutFile), layout, 0644); err != nil {
+		return err
+	}
+	tmp := filepath.Join(l.dir, "index.json.tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(l.dir, "index.json")); err != nil {
+		return err
+	}
+	return os.RemoveAll(stagingDir)
+}
+
+func readOCIIndex(path string) (*ocispec.Index, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	index := &ocispec.Index{}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, errors.Wrapf(err, "parse %s failed", path)
+	}
+	return index, nil
+}
+
+// mergeOCIIndex adds the manifests of the staging index into the index, a manifest replaces the one with the same
+// ref name as the OCI layout of containers/image does.
+func mergeOCIIndex(index, staging *ocispec.Index) {
+	for _, desc := range staging.Manifests {
+		ref := desc.Annotations[ocispec.AnnotationRefName]
+		manifests := index.Manifests[:0]
+		for _, m := range index.Manifests {
+			if (ref != "" && m.Annotations[ocispec.AnnotationRefName] == ref) ||
+				(m.Digest == desc.Digest && m.Annotations[ocispec.AnnotationRefName] == "") {
+				continue
+			}
+			manifests = append(manifests, m)
+		}
+		index.Manifests = append(manifests, desc)
+	}
+}
@@ -0,0 +1,165 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package images
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+)
+
+func TestCopyImages(t *testing.T) {
+	logger.Log = logger.NewLogger(t.TempDir(), false)
+	copyBackoff, maxCopyBackoff = time.Millisecond, time.Millisecond
+
+	path := filepath.Join(t.TempDir(), SaveProgressFile)
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	broken := map[string]bool{"image-3": true}
+	newJobs := func() []copyJob {
+		var jobs []copyJob
+		for i := 0; i < 6; i++ {
+			key := fmt.Sprintf("image-%d", i)
+			jobs = append(jobs, copyJob{key: key, copy: func() error {
+				mu.Lock()
+				defer mu.Unlock()
+				attempts[key]++
+				// image-1 is copied at the second attempt
+				if broken[key] || (key == "image-1" && attempts[key] == 1) {
+					return errors.New("connection reset")
+				}
+				return nil
+			}})
+		}
+		return jobs
+	}
+
+	progress, err := LoadCopyProgress(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := copyImages(context.Background(), newJobs(), 1, progress); err == nil {
+		t.Fatal("copyImages() succeeded with a broken image")
+	}
+	if attempts["image-3"] != copyRetries || attempts["image-1"] != 2 || attempts["image-4"] != 0 {
+		t.Errorf("unexpected attempts %v", attempts)
+	}
+
+	// the next run resumes from the broken image
+	broken = map[string]bool{}
+	attempts = make(map[string]int)
+	progress, err = LoadCopyProgress(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := copyImages(context.Background(), newJobs(), 3, progress); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"image-3": 1, "image-4": 1, "image-5": 1}
+	if !reflect.DeepEqual(attempts, want) {
+		t.Errorf("attempts = %v, want %v", attempts, want)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("progress file %s is not removed: %v", path, err)
+	}
+}
+
+func TestCopyImagesCancelled(t *testing.T) {
+	logger.Log = logger.NewLogger(t.TempDir(), false)
+	copyBackoff, maxCopyBackoff = time.Hour, time.Hour
+
+	path := filepath.Join(t.TempDir(), PushProgressFile)
+	progress, err := LoadCopyProgress(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var attempts int
+	jobs := []copyJob{
+		// the job is cancelled while waiting to retry, instead of after the backoff
+		{key: "image-0", copy: func() error {
+			attempts++
+			cancel()
+			return errors.New("connection reset")
+		}},
+		{key: "image-1", copy: func() error {
+			attempts++
+			return nil
+		}},
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- copyImages(ctx, jobs, 1, progress)
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("copyImages() error = %v, want it cancelled", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("copyImages() does not return once the ctx is cancelled")
+	}
+	if attempts != 1 || progress.IsDone("image-1") {
+		t.Errorf("copyImages() copied the images after the ctx is cancelled, attempts = %d", attempts)
+	}
+
+	// no image is copied with a cancelled ctx, and the progress is kept
+	if err := copyImages(ctx, jobs[1:], 1, progress); !errors.Is(err, context.Canceled) {
+		t.Errorf("copyImages() error = %v, want it cancelled", err)
+	}
+	if attempts != 1 {
+		t.Errorf("copyImages() copied the images with a cancelled ctx, attempts = %d", attempts)
+	}
+}
+
+func TestMergeOCIIndex(t *testing.T) {
+	ref := func(name string) map[string]string {
+		return map[string]string{ocispec.AnnotationRefName: name}
+	}
+
+	index := &ocispec.Index{Manifests: []ocispec.Descriptor{
+		{Digest: "sha256:a", Annotations: ref("docker.io/library/pause:3.9-amd64")},
+		{Digest: "sha256:b", Annotations: ref("docker.io/library/pause:3.9-arm64")},
+		{Digest: "sha256:c"},
+	}}
+	mergeOCIIndex(index, &ocispec.Index{Manifests: []ocispec.Descriptor{
+		{Digest: "sha256:d", Annotations: ref("docker.io/library/pause:3.9-arm64")},
+		{Digest: "sha256:c", Annotations: ref("docker.io/calico/cni:v3.26.1-amd64")},
+		{Digest: "sha256:e", Annotations: ref("docker.io/calico/node:v3.26.1-amd64")},
+	}})
+
+	want := []ocispec.Descriptor{
+		{Digest: "sha256:a", Annotations: ref("docker.io/library/pause:3.9-amd64")},
+		{Digest: "sha256:d", Annotations: ref("docker.io/library/pause:3.9-arm64")},
+		{Digest: "sha256:c", Annotations: ref("docker.io/calico/cni:v3.26.1-amd64")},
+		{Digest: "sha256:e", Annotations: ref("docker.io/calico/node:v3.26.1-amd64")},
+	}
+	if !reflect.DeepEqual(index.Manifests, want) {
+		t.Errorf("mergeOCIIndex() = %v, want %v", index.Manifests, want)
+	}
+}
@@ -50,7 +50,8 @@ func (p *PullModule) Init() {
 type CopyImagesToLocalModule struct {
 	common.ArtifactModule
 	ImageStartIndex int
-	ImageTransport string
+	ImageTransport  string
+	Concurrency     int
 }
 
 func (c *CopyImagesToLocalModule) Init() {
@@ -60,7 +61,7 @@ func (c *CopyImagesToLocalModule) Init() {
 	copyImage := &task.LocalTask{
 		Name:   "SaveImages",
 		Desc:   "Copy images to a local OCI path from registries",
-		Action: &SaveImages{ImageStartIndex: c.ImageStartIndex, ImageTransport: c.ImageTransport},
+		Action: &SaveImages{ImageStartIndex: c.ImageStartIndex, ImageTransport: c.ImageTransport, Concurrency: c.Concurrency},
 	}
 
 	c.Tasks = []task.Interface{
@@ -73,6 +74,7 @@ type CopyImagesToRegistryModule struct {
 	Skip           bool
 	ImagePath      string
 	ImageTransport string
+	Concurrency    int
 }
 
 func (c *CopyImagesToRegistryModule) IsSkip() bool {
@@ -86,7 +88,7 @@ func (c *CopyImagesToRegistryModule) Init() {
 	copyImage := &task.LocalTask{
 		Name:   "CopyImagesToRegistry",
 		Desc:   "Copy images to a private registry from an artifact OCI Path",
-		Action: &CopyImagesToRegistry{ImagesPath: c.ImagePath, ImageTransport: c.ImageTransport},
+		Action: &CopyImagesToRegistry{ImagesPath: c.ImagePath, ImageTransport: c.ImageTransport, Concurrency: c.Concurrency},
 	}
 
 	pushManifest := &task.LocalTask{
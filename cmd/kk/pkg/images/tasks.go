@@ -23,7 +23,6 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
-	"time"
 
 	manifestregistry "github.com/estesp/manifest-tool/v2/pkg/registry"
 	manifesttypes "github.com/estesp/manifest-tool/v2/pkg/types"
@@ -156,7 +155,8 @@ func GetImage(runtime connector.ModuleRuntime, kubeConf *common.KubeConf, name s
 type SaveImages struct {
 	common.ArtifactAction
 	ImageStartIndex int
-	ImageTransport string
+	ImageTransport  string
+	Concurrency     int
 }
 
 func (s *SaveImages) Execute(runtime connector.Runtime) error {
@@ -166,6 +166,17 @@ func (s *SaveImages) Execute(runtime connector.Runtime) error {
 	if err := coreutil.Mkdir(dirName); err != nil {
 		return errors.Wrapf(errors.WithStack(err), "mkdir %s failed", dirName)
 	}
+	layout := &ociLayout{dir: dirName, stagingDir: filepath.Join(runtime.GetWorkDir(), "images-staging")}
+	// the images in the staging layouts left by an interrupted run are copied again, their blobs are reused
+	if err := os.RemoveAll(layout.stagingDir); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "remove %s failed", layout.stagingDir)
+	}
+	progress, err := LoadCopyProgress(filepath.Join(runtime.GetWorkDir(), SaveProgressFile))
+	if err != nil {
+		return err
+	}
+
+	var jobs []copyJob
 	for index, image := range s.Manifest.Spec.Images {
 		if s.ImageStartIndex > index {
 			continue
@@ -190,9 +201,9 @@ func (s *SaveImages) Execute(runtime connector.Runtime) error {
 			// Ex:
 			// oci:./kubekey/artifact/images:docker.io/kubesphere/kube-apiserver:v1.21.5-amd64
 			// oci:./kubekey/artifact/images:docker.io/kubesphere/kube-apiserver:v1.21.5-arm-v7
-			destName := fmt.Sprintf("oci:%s:%s-%s%s", dirName, image, arch, variant)
-			logger.Log.Infof("[%d]Source: %s", index, srcName)
-			logger.Log.Infof("[%d]Destination: %s", index, destName)
+			ref := ArchImageRef(image, platform)
+			destName := fmt.Sprintf("oci:%s:%s", dirName, ref)
+			staging := layout.staging(len(jobs))
 
 			o := &CopyImageOptions{
 				srcImage: &srcImageOptions{
@@ -208,7 +219,8 @@ func (s *SaveImages) Execute(runtime connector.Runtime) error {
 					},
 				},
 				destImage: &destImageOptions{
-					imageName: destName,
+					imageName:     fmt.Sprintf("oci:%s:%s", staging, ref),
+					sharedBlobDir: layout.blobsDir(),
 					dockerImage: dockerImageOptions{
 						arch:    arch,
 						variant: variant,
@@ -216,29 +228,36 @@ func (s *SaveImages) Execute(runtime connector.Runtime) error {
 					},
 				},
 			}
+			if s.Concurrency == 1 {
+				o.reportWriter = os.Stdout
+			}
 
-			// Copy image
-			// retry 3 times
-			for i := 0; i < 3; i++ {
-				if err := o.Copy(); err != nil {
-					if i == 2 {
-						return errors.Wrapf(err, "copy image %s failed", srcName)
+			index := index
+			jobs = append(jobs, copyJob{
+				key: ref,
+				copy: func() error {
+					logger.Log.Infof("[%d]Source: %s", index, srcName)
+					logger.Log.Infof("[%d]Destination: %s", index, destName)
+					if err := o.Copy(); err != nil {
+						return err
 					}
-					logger.Log.Warnf("copy image %s failed, retrying", srcName)
-					time.Sleep(5 * time.Second)
-					continue
-				}
-				break
-			}
+					return layout.merge(staging)
+				},
+			})
 		}
 	}
-	return nil
+
+	if err := copyImages(runtime.GetRunner().Context(), jobs, s.Concurrency, progress); err != nil {
+		return err
+	}
+	return os.RemoveAll(layout.stagingDir)
 }
 
 type CopyImagesToRegistry struct {
 	common.KubeAction
 	ImagesPath     string
 	ImageTransport string
+	Concurrency    int
 }
 
 func (c *CopyImagesToRegistry) Execute(runtime connector.Runtime) error {
@@ -260,10 +279,18 @@ func (c *CopyImagesToRegistry) Execute(runtime connector.Runtime) error {
 	}
 
 	auths := registry.DockerRegistryAuthEntries(c.KubeConf.Cluster.Registry.Auths)
+	progress, err := LoadCopyProgress(filepath.Join(runtime.GetWorkDir(), PushProgressFile))
+	if err != nil {
+		return err
+	}
 
+	var jobs []copyJob
 	manifestList := make(map[string][]manifesttypes.ManifestEntry)
 	for _, m := range index.Manifests {
 		ref := m.Annotations.RefName
+		if ref == "" {
+			continue
+		}
 
 		// Ex:
 		// docker.io/calico/cni:v3.20.0-amd64
@@ -315,8 +342,6 @@ func (c *CopyImagesToRegistry) Execute(runtime connector.Runtime) error {
 
 		srcName := fmt.Sprintf("oci:%s:%s", imagesPath, ref)
 		destName := formatImageName(c.ImageTransport, uniqueImage)
-		logger.Log.Infof("Source: %s", srcName)
-		logger.Log.Infof("Destination: %s", destName)
 
 		o := &CopyImageOptions{
 			srcImage: &srcImageOptions{
@@ -340,18 +365,23 @@ func (c *CopyImagesToRegistry) Execute(runtime connector.Runtime) error {
 				},
 			},
 		}
-
-		retry, maxRetry := 0, 5
-		for ; retry < maxRetry; retry++ {
-			if err := o.Copy(); err == nil {
-				break
-			} else {
-				fmt.Println(errors.WithStack(err))
-			}
-		}
-		if retry >= maxRetry {
-			return errors.Wrap(errors.WithStack(err), fmt.Sprintf("copy image %s to %s failed, retry %d", srcName, destName, maxRetry))
+		if c.Concurrency == 1 {
+			o.reportWriter = os.Stdout
 		}
+
+		// the digest is in the key, so the image is pushed again if it is changed in the artifact
+		jobs = append(jobs, copyJob{
+			key: fmt.Sprintf("%s@%s => %s", ref, m.Digest, destName),
+			copy: func() error {
+				logger.Log.Infof("Source: %s", srcName)
+				logger.Log.Infof("Destination: %s", destName)
+				return o.Copy()
+			},
+		})
+	}
+
+	if err := copyImages(runtime.GetRunner().Context(), jobs, c.Concurrency, progress); err != nil {
+		return err
 	}
 
 	c.ModuleCache.Set("manifestList", manifestList)
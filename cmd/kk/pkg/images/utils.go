@@ -72,13 +72,15 @@ func (s *srcImageOptions) systemContext() *types.SystemContext {
 }
 
 type destImageOptions struct {
-	dockerImage dockerImageOptions
-	imageName   string
+	dockerImage   dockerImageOptions
+	imageName     string
+	sharedBlobDir string
 }
 
 func (d *destImageOptions) systemContext() *types.SystemContext {
 	ctx := d.dockerImage.systemContext()
 	ctx.DockerCertPath = d.dockerImage.dockerCertPath
+	ctx.OCISharedBlobDirPath = d.sharedBlobDir
 	ctx.DockerAuthConfig = &types.DockerAuthConfig{
 		Username: d.dockerImage.username,
 		Password: d.dockerImage.password,
@@ -102,6 +104,18 @@ func ParseArchVariant(platform string) (string, string) {
 	return arch, variant
 }
 
+// ArchImageRef returns the ref of the image for the platform in the OCI layout of an artifact.
+// Ex:
+// docker.io/kubesphere/kube-apiserver:v1.21.5-amd64
+// docker.io/kubesphere/kube-apiserver:v1.21.5-arm-v7
+func ArchImageRef(image, platform string) string {
+	arch, variant := ParseArchVariant(platform)
+	if variant != "" {
+		return fmt.Sprintf("%s-%s-%s", image, arch, variant)
+	}
+	return fmt.Sprintf("%s-%s", image, arch)
+}
+
 func ParseImageWithArchTag(ref string) (string, ocispec.Platform) {
 	n := strings.LastIndex(ref, "-")
 	if n < 0 {
@@ -170,3 +170,32 @@ func TestParseImageWithArchTag(t *testing.T) {
 		})
 	}
 }
+
+func TestArchImageRef(t *testing.T) {
+	tests := []struct {
+		name     string
+		image    string
+		platform string
+		want     string
+	}{
+		{
+			name:     "arch",
+			image:    "docker.io/kubesphere/kube-apiserver:v1.21.5",
+			platform: "amd64",
+			want:     "docker.io/kubesphere/kube-apiserver:v1.21.5-amd64",
+		},
+		{
+			name:     "arch with variant",
+			image:    "docker.io/kubesphere/kube-apiserver:v1.21.5",
+			platform: "arm/v7",
+			want:     "docker.io/kubesphere/kube-apiserver:v1.21.5-arm-v7",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ArchImageRef(tt.image, tt.platform); got != tt.want {
+				t.Errorf("ArchImageRef() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
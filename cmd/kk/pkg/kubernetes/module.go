@@ -386,6 +386,11 @@ func (r *ResetClusterModule) Init() {
 
 type CompareConfigAndClusterInfoModule struct {
 	common.KubeModule
+	Skip bool
+}
+
+func (c *CompareConfigAndClusterInfoModule) IsSkip() bool {
+	return c.Skip
 }
 
 func (c *CompareConfigAndClusterInfoModule) Init() {
@@ -428,6 +433,11 @@ func (r *RestartKubeletModule) init() {
 
 type DeleteKubeNodeModule struct {
 	common.KubeModule
+	Skip bool
+}
+
+func (d *DeleteKubeNodeModule) IsSkip() bool {
+	return d.Skip
 }
 
 func (d *DeleteKubeNodeModule) Init() {
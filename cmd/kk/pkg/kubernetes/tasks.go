@@ -183,14 +183,20 @@ type GenerateKubeletEnv struct {
 
 func (g *GenerateKubeletEnv) Execute(runtime connector.Runtime) error {
 	host := runtime.RemoteHost()
+	// the socket of iSulad is not one kubelet knows, so it is pinned here rather than left to kubeadm-flags.env only
+	var containerRuntimeEndpoint string
+	if g.KubeConf.Cluster.Kubernetes.ContainerManager == common.Isula {
+		containerRuntimeEndpoint = g.KubeConf.Cluster.Kubernetes.ContainerRuntimeEndpoint
+	}
 	templateAction := action.Template{
 		Template: templates.KubeletEnv,
 		Dst:      filepath.Join("/etc/systemd/system/kubelet.service.d", templates.KubeletEnv.Name()),
 		Data: util.Data{
-			"NodeIP":           host.GetInternalAddress(),
-			"Hostname":         host.GetName(),
-			"ContainerRuntime": "",
-			"KubeletArgs":      g.KubeConf.Cluster.Kubernetes.KubeletArgs,
+			"NodeIP":                   host.GetInternalAddress(),
+			"Hostname":                 host.GetName(),
+			"ContainerRuntime":         "",
+			"ContainerRuntimeEndpoint": containerRuntimeEndpoint,
+			"KubeletArgs":              g.KubeConf.Cluster.Kubernetes.KubeletArgs,
 		},
 	}
 
@@ -329,6 +335,11 @@ func (k *KubeadmInit) Execute(runtime connector.Runtime) error {
 		initCmd = initCmd + " --skip-phases=addon/kube-proxy"
 	}
 
+	// the CA is copied on every try, because it is removed by kubeadm reset
+	if err := copyExternalCA(runtime, k.KubeConf.Cluster.Certificates.Kubernetes); err != nil {
+		return err
+	}
+
 	if _, err := runtime.GetRunner().SudoCmd(initCmd, true); err != nil {
 		// kubeadm reset and then retry
 		resetCmd := "/usr/local/bin/kubeadm reset -f"
@@ -341,6 +352,31 @@ func (k *KubeadmInit) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+// copyExternalCA puts the CA given by the user where kubeadm init signs the kubernetes certs with it instead of
+// generating a self-signed one.
+func copyExternalCA(runtime connector.Runtime, cfg kubekeyv1alpha2.ComponentCerts) error {
+	if !cfg.IsExternal() {
+		return nil
+	}
+	// rejected by the validation of the config, kubeadm can not sign the certs without the key
+	if cfg.CAKeyFile == "" {
+		return errors.New("the caKeyFile of the kubernetes certificates is required, the kubernetes certs can not be pre-issued")
+	}
+
+	if _, err := runtime.GetRunner().SudoCmd("mkdir -p /etc/kubernetes/pki", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "create /etc/kubernetes/pki failed")
+	}
+	for src, dst := range map[string]string{cfg.CAFile: "/etc/kubernetes/pki/ca.crt", cfg.CAKeyFile: "/etc/kubernetes/pki/ca.key"} {
+		if err := runtime.GetRunner().SudoScp(src, dst); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "copy %s to %s failed", src, dst)
+		}
+	}
+	if _, err := runtime.GetRunner().SudoCmd("chmod 600 /etc/kubernetes/pki/ca.key", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "chmod /etc/kubernetes/pki/ca.key failed")
+	}
+	return nil
+}
+
 type CopyKubeConfigForControlPlane struct {
 	common.KubeAction
 }
@@ -793,7 +829,7 @@ func KubeadmUpgradeTasks(runtime connector.Runtime, u *UpgradeKubeMaster) error
 	for i := range tasks {
 		t := tasks[i]
 		t.Init(runtime, u.ModuleCache, u.PipelineCache)
-		if res := t.Execute(); res.IsFailed() {
+		if res := t.Execute(runtime.GetRunner().Context()); res.IsFailed() {
 			return res.CombineErr()
 		}
 	}
@@ -850,7 +886,7 @@ func SetKubeletTasks(runtime connector.Runtime, kubeAction common.KubeAction) er
 	for i := range tasks {
 		t := tasks[i]
 		t.Init(runtime, kubeAction.ModuleCache, kubeAction.PipelineCache)
-		if res := t.Execute(); res.IsFailed() {
+		if res := t.Execute(runtime.GetRunner().Context()); res.IsFailed() {
 			return res.CombineErr()
 		}
 	}
@@ -378,3 +378,137 @@ func (k *DeleteVIPModule) Init() {
 		DeleteVIP,
 	}
 }
+
+// KeepalivedModule installs haproxy and keepalived on the loadbalancer nodes, which are not part of the cluster.
+// haproxy balances the kube-apiservers, and keepalived holds the LB address as the VIP on one of the nodes.
+// It is run again to refresh the configs when the masters or the loadbalancer nodes change.
+type KeepalivedModule struct {
+	common.KubeModule
+	Skip bool
+	// Exclude is the name of the node being deleted, which is left out of the configs.
+	Exclude string
+}
+
+func (k *KeepalivedModule) IsSkip() bool {
+	return k.Skip
+}
+
+func (k *KeepalivedModule) Init() {
+	k.Name = "KeepalivedModule"
+	k.Desc = "Install keepalived and haproxy on the loadbalancer nodes"
+
+	lbHosts := loadbalancerHosts(k.Runtime, k.Exclude)
+
+	checkLoadbalancerHosts := &task.LocalTask{
+		Name:   "CheckLoadbalancerHosts",
+		Desc:   "Check loadbalancer nodes",
+		Action: new(CheckLoadbalancerHosts),
+	}
+
+	checkVIPAddress := &task.LocalTask{
+		Name:   "CheckVIPAddress",
+		Desc:   "Check VIP Address",
+		Action: new(CheckVIPAddress),
+	}
+
+	installKeepalived := &task.RemoteTask{
+		Name:     "InstallKeepalived",
+		Desc:     "Install haproxy and keepalived",
+		Hosts:    lbHosts,
+		Action:   new(InstallKeepalived),
+		Parallel: true,
+		Retry:    2,
+	}
+
+	getInterface := &task.RemoteTask{
+		Name:     "GetNodeInterface",
+		Desc:     "Get Node Interface",
+		Hosts:    lbHosts,
+		Action:   new(GetInterfaceName),
+		Parallel: true,
+	}
+
+	haproxyCfg := &task.RemoteTask{
+		Name:  "GenerateHaproxyConfig",
+		Desc:  "Generate haproxy.cfg",
+		Hosts: lbHosts,
+		Action: &action.Template{
+			Template: templates.LoadbalancerHaproxyConfig,
+			Dst:      filepath.Join(systemHaproxyDir, templates.LoadbalancerHaproxyConfig.Name()),
+			Data: util.Data{
+				"MasterNodes":                          loadbalancerBackends(k.Runtime, k.Exclude),
+				"LoadbalancerApiserverPort":            k.KubeConf.Cluster.ControlPlaneEndpoint.Port,
+				"LoadbalancerApiserverHealthcheckPort": 8081,
+				"KubernetesType":                       k.KubeConf.Cluster.Kubernetes.Type,
+			},
+		},
+		Parallel: true,
+	}
+
+	keepalivedCfg := &task.RemoteTask{
+		Name:     "GenerateKeepalivedConfig",
+		Desc:     "Generate keepalived.conf",
+		Hosts:    lbHosts,
+		Action:   &GenerateKeepalivedConfig{LoadbalancerHosts: lbHosts},
+		Parallel: true,
+	}
+
+	// one by one, so that the VIP is always held by a node while the configs are refreshed
+	enableKeepalived := &task.RemoteTask{
+		Name:     "EnableKeepalived",
+		Desc:     "Enable haproxy and keepalived",
+		Hosts:    lbHosts,
+		Action:   new(EnableKeepalived),
+		Parallel: false,
+	}
+
+	k.Tasks = []task.Interface{
+		checkLoadbalancerHosts,
+		checkVIPAddress,
+		installKeepalived,
+	}
+	if k.KubeConf.Cluster.ControlPlaneEndpoint.Keepalived.Interface == "" {
+		k.Tasks = append(k.Tasks, getInterface)
+	}
+	k.Tasks = append(k.Tasks,
+		haproxyCfg,
+		keepalivedCfg,
+		enableKeepalived,
+	)
+}
+
+// DeleteKeepalivedModule stops haproxy and keepalived on the loadbalancer nodes and removes their configs.
+type DeleteKeepalivedModule struct {
+	common.KubeModule
+	Skip bool
+	// Node limits the cleanup to the named node, all the loadbalancer nodes are cleaned if it is empty.
+	Node string
+}
+
+func (d *DeleteKeepalivedModule) IsSkip() bool {
+	return d.Skip
+}
+
+func (d *DeleteKeepalivedModule) Init() {
+	d.Name = "DeleteKeepalivedModule"
+	d.Desc = "Delete keepalived and haproxy on the loadbalancer nodes"
+
+	var hosts []connector.Host
+	for _, host := range d.Runtime.GetHostsByRole(common.Loadbalancer) {
+		if d.Node == "" || host.GetName() == d.Node {
+			hosts = append(hosts, host)
+		}
+	}
+
+	deleteKeepalived := &task.RemoteTask{
+		Name:     "DeleteKeepalived",
+		Desc:     "Stop keepalived and haproxy and remove their configs",
+		Hosts:    hosts,
+		Action:   new(DeleteKeepalived),
+		Parallel: true,
+	}
+
+	d.Tasks = []task.Interface{
+		deleteKeepalived,
+	}
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/action"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
@@ -322,3 +323,174 @@ func (g *DeleteVIP) Execute(runtime connector.Runtime) error {
 	runtime.GetRunner().SudoCmd(cmd, false)
 	return nil
 }
+
+const (
+	keepalivedDir      = "/etc/keepalived"
+	systemHaproxyDir   = "/etc/haproxy"
+	checkHaproxyScript = "/etc/keepalived/check_haproxy.sh"
+)
+
+// loadbalancerHosts returns the loadbalancer nodes except the excluded one, which is being deleted.
+func loadbalancerHosts(runtime connector.ModuleRuntime, exclude string) []connector.Host {
+	var hosts []connector.Host
+	for _, host := range runtime.GetHostsByRole(common.Loadbalancer) {
+		if host.GetName() != exclude {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// loadbalancerBackends returns the kube-apiservers behind the haproxy on the loadbalancer nodes.
+func loadbalancerBackends(runtime connector.ModuleRuntime, exclude string) []string {
+	var backends []string
+	for _, node := range runtime.GetHostsByRole(common.Master) {
+		if node.GetName() != exclude {
+			backends = append(backends, fmt.Sprintf("%s %s:%d", node.GetName(), node.GetAddress(), kubekeyapiv1alpha2.DefaultApiserverPort))
+		}
+	}
+	return backends
+}
+
+type CheckLoadbalancerHosts struct {
+	common.KubeAction
+}
+
+func (c *CheckLoadbalancerHosts) Execute(runtime connector.Runtime) error {
+	hosts := runtime.GetHostsByRole(common.Loadbalancer)
+	if len(hosts) == 0 {
+		return errors.Errorf("the keepalived load balancer requires at least one node with the %s role", common.Loadbalancer)
+	}
+	vip := c.KubeConf.Cluster.ControlPlaneEndpoint.Address
+	for _, host := range hosts {
+		if host.IsRole(common.Master) && c.KubeConf.Cluster.ControlPlaneEndpoint.Port == kubekeyapiv1alpha2.DefaultApiserverPort {
+			return errors.Errorf("%s can not be both a %s node and a master node, haproxy would conflict with kube-apiserver on port %d",
+				host.GetName(), common.Loadbalancer, kubekeyapiv1alpha2.DefaultApiserverPort)
+		}
+		if host.GetAddress() == vip || host.GetInternalIPv4Address() == vip {
+			return errors.Errorf("the VIP %s is the address of %s, it must be an unused address", vip, host.GetName())
+		}
+	}
+	return nil
+}
+
+type InstallKeepalived struct {
+	common.KubeAction
+}
+
+func (i *InstallKeepalived) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd("command -v haproxy && command -v keepalived", false); err == nil {
+		return nil
+	}
+
+	cmd := "if command -v apt-get > /dev/null; then apt-get update && DEBIAN_FRONTEND=noninteractive apt-get install -y haproxy keepalived curl; " +
+		"elif command -v dnf > /dev/null; then dnf install -y haproxy keepalived curl; " +
+		"elif command -v yum > /dev/null; then yum install -y haproxy keepalived curl; " +
+		"else echo 'neither apt-get, dnf nor yum is found' && exit 1; fi"
+	if _, err := runtime.GetRunner().SudoCmd(cmd, true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "install haproxy and keepalived failed, please install them manually")
+	}
+	return nil
+}
+
+type GenerateKeepalivedConfig struct {
+	common.KubeAction
+	LoadbalancerHosts []connector.Host
+}
+
+func (g *GenerateKeepalivedConfig) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	keepalived := g.KubeConf.Cluster.ControlPlaneEndpoint.Keepalived
+	interfaceName := keepalived.Interface
+	if interfaceName == "" {
+		name, ok := host.GetCache().GetMustString("interface")
+		if !ok {
+			return errors.New("get interface failed")
+		}
+		interfaceName = name
+	}
+
+	// the first loadbalancer node has the highest priority and holds the VIP as long as it is healthy
+	priority := 100
+	var peers []string
+	for i, h := range g.LoadbalancerHosts {
+		if h.GetName() == host.GetName() {
+			priority = 100 - i
+			continue
+		}
+		peers = append(peers, h.GetInternalIPv4Address())
+	}
+
+	authPass := keepalived.AuthPass
+	if len(authPass) > 8 {
+		authPass = authPass[:8]
+	}
+
+	templateAction := action.Template{
+		Template: templates.KeepalivedConfig,
+		Dst:      filepath.Join(keepalivedDir, templates.KeepalivedConfig.Name()),
+		Data: util.Data{
+			"RouterID":        host.GetName(),
+			"CheckScript":     checkHaproxyScript,
+			"Interface":       interfaceName,
+			"VirtualRouterID": keepalived.VirtualRouterID,
+			"Priority":        priority,
+			"AuthPass":        authPass,
+			"SourceIP":        host.GetInternalIPv4Address(),
+			"Peers":           peers,
+			"VIP":             g.KubeConf.Cluster.ControlPlaneEndpoint.Address,
+		},
+	}
+	templateAction.Init(nil, nil)
+	if err := templateAction.Execute(runtime); err != nil {
+		return err
+	}
+
+	scriptAction := action.Template{
+		Template: templates.CheckHaproxyScript,
+		Dst:      checkHaproxyScript,
+		Data: util.Data{
+			"LoadbalancerApiserverHealthcheckPort": 8081,
+		},
+	}
+	scriptAction.Init(nil, nil)
+	if err := scriptAction.Execute(runtime); err != nil {
+		return err
+	}
+	// keepalived refuses to run a script which is writable by a non-root user
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chown root:root %s && chmod 755 %s", checkHaproxyScript, checkHaproxyScript), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "chmod the haproxy check script failed")
+	}
+	return nil
+}
+
+type EnableKeepalived struct {
+	common.KubeAction
+}
+
+func (e *EnableKeepalived) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("haproxy -c -f %s", filepath.Join(systemHaproxyDir, "haproxy.cfg")), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "invalid haproxy.cfg")
+	}
+	// reload them if they are running, so that the VIP is kept when the configs are refreshed
+	cmd := "systemctl daemon-reload && systemctl enable haproxy keepalived && " +
+		"systemctl reload-or-restart haproxy && systemctl reload-or-restart keepalived"
+	if _, err := runtime.GetRunner().SudoCmd(cmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "start haproxy and keepalived failed")
+	}
+	return nil
+}
+
+type DeleteKeepalived struct {
+	common.KubeAction
+}
+
+func (d *DeleteKeepalived) Execute(runtime connector.Runtime) error {
+	_, _ = runtime.GetRunner().SudoCmd("systemctl disable --now keepalived haproxy", false)
+	cmd := fmt.Sprintf("rm -f %s %s %s", filepath.Join(keepalivedDir, templates.KeepalivedConfig.Name()), checkHaproxyScript,
+		filepath.Join(systemHaproxyDir, templates.LoadbalancerHaproxyConfig.Name()))
+	if _, err := runtime.GetRunner().SudoCmd(cmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "remove the configs of haproxy and keepalived failed")
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package templates
+
+import (
+	"text/template"
+
+	"github.com/lithammer/dedent"
+)
+
+// LoadbalancerHaproxyConfig is the haproxy.cfg of the haproxy service on the loadbalancer nodes. Unlike the
+// static pod on the workers, it listens on all the addresses so that the VIP held by keepalived is served.
+var LoadbalancerHaproxyConfig = template.Must(template.New("haproxy.cfg").Parse(
+	dedent.Dedent(`# Generated by KubeKey, do not edit it manually.
+global
+    maxconn                 4000
+    log                     127.0.0.1 local0
+
+defaults
+    mode                    http
+    log                     global
+    option                  httplog
+    option                  dontlognull
+    option                  http-server-close
+    option                  redispatch
+    retries                 5
+    timeout http-request    5m
+    timeout queue           5m
+    timeout connect         30s
+    timeout client          30s
+    timeout server          15m
+    timeout http-keep-alive 30s
+    timeout check           30s
+    maxconn                 4000
+
+frontend healthz
+  bind *:{{ .LoadbalancerApiserverHealthcheckPort }}
+  mode http
+  monitor-uri /healthz
+
+frontend kube_api_frontend
+  bind *:{{ .LoadbalancerApiserverPort }}
+  mode tcp
+  option tcplog
+  default_backend kube_api_backend
+
+backend kube_api_backend
+  mode tcp
+  balance leastconn
+  default-server inter 15s downinter 15s rise 2 fall 2 slowstart 60s maxconn 1000 maxqueue 256 weight 100
+  {{- if ne .KubernetesType "k3s"}}
+  option httpchk GET /healthz
+  {{- end }}
+  http-check expect status 200
+  {{- range .MasterNodes }}
+  server {{ . }} check check-ssl verify none
+  {{- end }}
+`)))
+
+// KeepalivedConfig runs all the loadbalancer nodes as BACKUP and lets the priority elect the one holding the VIP.
+// The VRRP advertisements are unicast to the peers, because multicast is often dropped in the cloud.
+var KeepalivedConfig = template.Must(template.New("keepalived.conf").Parse(
+	dedent.Dedent(`# Generated by KubeKey, do not edit it manually.
+global_defs {
+    router_id {{ .RouterID }}
+    script_user root
+    enable_script_security
+}
+
+vrrp_script check_haproxy {
+    script "{{ .CheckScript }}"
+    interval 3
+    timeout 5
+    fall 2
+    rise 2
+}
+
+vrrp_instance kube_apiserver {
+    state BACKUP
+    interface {{ .Interface }}
+    virtual_router_id {{ .VirtualRouterID }}
+    priority {{ .Priority }}
+    advert_int 1
+    authentication {
+        auth_type PASS
+        auth_pass {{ .AuthPass }}
+    }
+    unicast_src_ip {{ .SourceIP }}
+    unicast_peer {
+    {{- range .Peers }}
+        {{ . }}
+    {{- end }}
+    }
+    virtual_ipaddress {
+        {{ .VIP }}
+    }
+    track_script {
+        check_haproxy
+    }
+}
+`)))
+
+// CheckHaproxyScript fails when haproxy is down or does not answer, and keepalived moves the VIP to another
+// loadbalancer node then. The kube-apiservers are not checked, or no node would hold the VIP while the first
+// control plane is being initialized.
+var CheckHaproxyScript = template.Must(template.New("check_haproxy.sh").Parse(
+	dedent.Dedent(`#!/bin/bash
+# Generated by KubeKey, do not edit it manually.
+
+if ! pidof haproxy > /dev/null; then
+  echo "haproxy is not running"
+  exit 1
+fi
+
+if ! curl -sf --max-time 3 -o /dev/null http://127.0.0.1:{{ .LoadbalancerApiserverHealthcheckPort }}/healthz; then
+  echo "haproxy does not answer the health check"
+  exit 1
+fi
+`)))
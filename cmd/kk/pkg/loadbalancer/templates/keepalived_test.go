@@ -0,0 +1,69 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package templates
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestKeepalivedConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		peers []string
+		want  []string
+	}{
+		{
+			name:  "peers",
+			peers: []string{"172.16.0.11", "172.16.0.12"},
+			want: []string{
+				"    unicast_src_ip 172.16.0.10\n    unicast_peer {\n        172.16.0.11\n        172.16.0.12\n    }\n",
+				"    virtual_ipaddress {\n        172.16.0.100\n    }\n",
+				"    priority 100\n",
+			},
+		},
+		{
+			name: "single node",
+			want: []string{"    unicast_peer {\n    }\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := KeepalivedConfig.Execute(&buf, map[string]interface{}{
+				"RouterID":        "lb1",
+				"CheckScript":     "/etc/keepalived/check_haproxy.sh",
+				"Interface":       "eth0",
+				"VirtualRouterID": 51,
+				"Priority":        100,
+				"AuthPass":        "kubekey",
+				"SourceIP":        "172.16.0.10",
+				"Peers":           tt.peers,
+				"VIP":             "172.16.0.100",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("keepalived.conf does not contain %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
@@ -51,6 +51,8 @@ func setBinaryPath(kubeConf *common.KubeConf, path, arch string, binaries []stri
 			kubeBinary = files.NewKubeBinary(binary, arch, kubekeyapiv1alpha2.DefaultDockerVersion, path, kubeConf.Arg.DownloadCommand)
 		case "containerd":
 			kubeBinary = files.NewKubeBinary(binary, arch, kubekeyapiv1alpha2.DefaultContainerdVersion, path, kubeConf.Arg.DownloadCommand)
+		case "crio":
+			kubeBinary = files.NewKubeBinary(binary, arch, kubekeyapiv1alpha2.DefaultCrioVersion, path, kubeConf.Arg.DownloadCommand)
 		case "helm":
 			kubeBinary = files.NewKubeBinary(binary, arch, kubekeyapiv1alpha2.DefaultHelmVersion, path, kubeConf.Arg.DownloadCommand)
 		case "crictl":
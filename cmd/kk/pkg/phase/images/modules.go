@@ -52,12 +52,12 @@ type setBinaryCacheModule struct {
 
 func (p *setBinaryCacheModule) Init() {
 	p.Name = "setBinaryCacheModule"
-	p.Desc = "set the docker and containerd binary paths in cache"
+	p.Desc = "set the container runtime binary paths in cache"
 
 	setBinaryCache := &task.LocalTask{
 		Name:   "SetBinaryCache",
 		Desc:   "Set Binary Path in PipelineCache",
-		Action: &binary.GetBinaryPath{Binaries: []string{"docker", "containerd", "runc", "crictl"}},
+		Action: &binary.GetBinaryPath{Binaries: []string{"docker", "containerd", "crio", "runc", "crictl"}},
 	}
 
 	p.Tasks = []task.Interface{
@@ -17,6 +17,7 @@
 package pipelines
 
 import (
+	"context"
 	"fmt"
 
 	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
@@ -41,7 +42,7 @@ import (
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/loadbalancer"
 )
 
-func NewAddNodesPipeline(runtime *common.KubeRuntime) error {
+func NewAddNodesPipeline(ctx context.Context, runtime *common.KubeRuntime) error {
 	noArtifact := runtime.Arg.Artifact == ""
 
 	m := []module.Module{
@@ -69,6 +70,7 @@ func NewAddNodesPipeline(runtime *common.KubeRuntime) error {
 		&kubernetes.InstallKubeBinariesModule{},
 		&kubernetes.JoinNodesModule{},
 		&loadbalancer.HaproxyModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabled()},
+		&loadbalancer.KeepalivedModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledKeepalived()},
 		&kubernetes.ConfigureKubernetesModule{},
 		&filesystem.ChownModule{},
 		&certs.AutoRenewCertsModule{Skip: !runtime.Cluster.Kubernetes.EnableAutoRenewCerts()},
@@ -76,18 +78,20 @@ func NewAddNodesPipeline(runtime *common.KubeRuntime) error {
 	}
 
 	p := pipeline.Pipeline{
-		Name:    "AddNodesPipeline",
-		Modules: m,
-		Runtime: runtime,
+		Name:       "AddNodesPipeline",
+		Modules:    m,
+		Runtime:    runtime,
+		ReportPath: runtime.Arg.ReportPath,
+		NoRollback: runtime.Arg.NoRollback,
 	}
-	if err := p.Start(); err != nil {
+	if err := p.StartContext(ctx); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func NewK3sAddNodesPipeline(runtime *common.KubeRuntime) error {
+func NewK3sAddNodesPipeline(ctx context.Context, runtime *common.KubeRuntime) error {
 	noArtifact := runtime.Arg.Artifact == ""
 
 	m := []module.Module{
@@ -106,6 +110,7 @@ func NewK3sAddNodesPipeline(runtime *common.KubeRuntime) error {
 		&k3s.InstallKubeBinariesModule{},
 		&k3s.JoinNodesModule{},
 		&loadbalancer.K3sHaproxyModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabled()},
+		&loadbalancer.KeepalivedModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledKeepalived()},
 		&kubernetes.ConfigureKubernetesModule{},
 		&filesystem.ChownModule{},
 		&certs.AutoRenewCertsModule{Skip: !runtime.Cluster.Kubernetes.EnableAutoRenewCerts()},
@@ -113,18 +118,20 @@ func NewK3sAddNodesPipeline(runtime *common.KubeRuntime) error {
 	}
 
 	p := pipeline.Pipeline{
-		Name:    "AddNodesPipeline",
-		Modules: m,
-		Runtime: runtime,
+		Name:       "AddNodesPipeline",
+		Modules:    m,
+		Runtime:    runtime,
+		ReportPath: runtime.Arg.ReportPath,
+		NoRollback: runtime.Arg.NoRollback,
 	}
-	if err := p.Start(); err != nil {
+	if err := p.StartContext(ctx); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func NewK8eAddNodesPipeline(runtime *common.KubeRuntime) error {
+func NewK8eAddNodesPipeline(ctx context.Context, runtime *common.KubeRuntime) error {
 	noArtifact := runtime.Arg.Artifact == ""
 
 	m := []module.Module{
@@ -143,29 +150,34 @@ func NewK8eAddNodesPipeline(runtime *common.KubeRuntime) error {
 		&k8e.InstallKubeBinariesModule{},
 		&k8e.JoinNodesModule{},
 		&loadbalancer.K3sHaproxyModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabled()},
+		&loadbalancer.KeepalivedModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledKeepalived()},
 		&kubernetes.ConfigureKubernetesModule{},
 		&filesystem.ChownModule{},
 		&certs.AutoRenewCertsModule{Skip: !runtime.Cluster.Kubernetes.EnableAutoRenewCerts()},
 	}
 
 	p := pipeline.Pipeline{
-		Name:    "AddNodesPipeline",
-		Modules: m,
-		Runtime: runtime,
+		Name:       "AddNodesPipeline",
+		Modules:    m,
+		Runtime:    runtime,
+		ReportPath: runtime.Arg.ReportPath,
+		NoRollback: runtime.Arg.NoRollback,
 	}
-	if err := p.Start(); err != nil {
+	if err := p.StartContext(ctx); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func AddNodes(args common.Argument, downloadCmd string) error {
-	args.DownloadCommand = func(path, url string) string {
-		// this is an extension point for downloading tools, for example users can set the timeout, proxy or retry under
-		// some poor network environment. Or users even can choose another cli, it might be wget.
-		// perhaps we should have a build-in download function instead of totally rely on the external one
-		return fmt.Sprintf(downloadCmd, path, url)
+func AddNodes(ctx context.Context, args common.Argument, downloadCmd string) error {
+	// the built-in downloader is used if there is no download command
+	if downloadCmd != "" {
+		args.DownloadCommand = func(path, url string) string {
+			// this is an extension point for downloading tools, for example users can set the timeout, proxy or retry under
+			// some poor network environment. Or users even can choose another cli, it might be wget.
+			return fmt.Sprintf(downloadCmd, path, url)
+		}
 	}
 
 	var loaderType string
@@ -182,17 +194,17 @@ func AddNodes(args common.Argument, downloadCmd string) error {
 
 	switch runtime.Cluster.Kubernetes.Type {
 	case common.K3s:
-		if err := NewK3sAddNodesPipeline(runtime); err != nil {
+		if err := NewK3sAddNodesPipeline(ctx, runtime); err != nil {
 			return err
 		}
 	case common.K8e:
-		if err := NewK8eAddNodesPipeline(runtime); err != nil {
+		if err := NewK8eAddNodesPipeline(ctx, runtime); err != nil {
 			return err
 		}
 	case common.Kubernetes:
 		fallthrough
 	default:
-		if err := NewAddNodesPipeline(runtime); err != nil {
+		if err := NewAddNodesPipeline(ctx, runtime); err != nil {
 			return err
 		}
 	}
@@ -34,7 +34,8 @@ import (
 func NewArtifactExportPipeline(runtime *common.ArtifactRuntime) error {
 	m := []module.Module{
 		&confirm.CheckFileExistModule{FileName: runtime.Arg.Output},
-		&images.CopyImagesToLocalModule{ImageStartIndex: runtime.Arg.ImageStartIndex, ImageTransport: runtime.Arg.ImageTransport},
+		&images.CopyImagesToLocalModule{ImageStartIndex: runtime.Arg.ImageStartIndex, ImageTransport: runtime.Arg.ImageTransport,
+			Concurrency: runtime.Arg.ImageConcurrency},
 		&binaries.ArtifactBinariesModule{},
 		&artifact.RepositoryModule{},
 		&artifact.ArchiveModule{},
@@ -58,7 +59,7 @@ func NewArtifactExportPipeline(runtime *common.ArtifactRuntime) error {
 func NewK3sArtifactExportPipeline(runtime *common.ArtifactRuntime) error {
 	m := []module.Module{
 		&confirm.CheckFileExistModule{FileName: runtime.Arg.Output},
-		&images.CopyImagesToLocalModule{ImageStartIndex: runtime.Arg.ImageStartIndex},
+		&images.CopyImagesToLocalModule{ImageStartIndex: runtime.Arg.ImageStartIndex, Concurrency: runtime.Arg.ImageConcurrency},
 		&binaries.K3sArtifactBinariesModule{},
 		&artifact.RepositoryModule{},
 		&artifact.ArchiveModule{},
@@ -82,7 +83,7 @@ func NewK3sArtifactExportPipeline(runtime *common.ArtifactRuntime) error {
 func NewK8eArtifactExportPipeline(runtime *common.ArtifactRuntime) error {
 	m := []module.Module{
 		&confirm.CheckFileExistModule{FileName: runtime.Arg.Output},
-		&images.CopyImagesToLocalModule{ImageStartIndex: runtime.Arg.ImageStartIndex},
+		&images.CopyImagesToLocalModule{ImageStartIndex: runtime.Arg.ImageStartIndex, Concurrency: runtime.Arg.ImageConcurrency},
 		&binaries.K8eArtifactBinariesModule{},
 		&artifact.RepositoryModule{},
 		&artifact.ArchiveModule{},
@@ -104,11 +105,13 @@ func NewK8eArtifactExportPipeline(runtime *common.ArtifactRuntime) error {
 }
 
 func ArtifactExport(args common.ArtifactArgument, downloadCmd string) error {
-	args.DownloadCommand = func(path, url string) string {
-		// this is an extension point for downloading tools, for example users can set the timeout, proxy or retry under
-		// some poor network environment. Or users even can choose another cli, it might be wget.
-		// perhaps we should have a build-in download function instead of totally rely on the external one
-		return fmt.Sprintf(downloadCmd, path, url)
+	// the built-in downloader is used if there is no download command
+	if downloadCmd != "" {
+		args.DownloadCommand = func(path, url string) string {
+			// this is an extension point for downloading tools, for example users can set the timeout, proxy or retry under
+			// some poor network environment. Or users even can choose another cli, it might be wget.
+			return fmt.Sprintf(downloadCmd, path, url)
+		}
 	}
 
 	runtime, err := common.NewArtifactRuntime(args)
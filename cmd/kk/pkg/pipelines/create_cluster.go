@@ -17,6 +17,7 @@
 package pipelines
 
 import (
+	"context"
 	"fmt"
 
 	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
@@ -46,7 +47,7 @@ import (
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/plugins/storage"
 )
 
-func NewCreateClusterPipeline(runtime *common.KubeRuntime) error {
+func NewCreateClusterPipeline(ctx context.Context, runtime *common.KubeRuntime) error {
 	noArtifact := runtime.Arg.Artifact == ""
 	skipPushImages := runtime.Arg.SkipPushImages || noArtifact || (!noArtifact && runtime.Cluster.Registry.PrivateRegistry == "")
 	skipLocalStorage := true
@@ -60,7 +61,7 @@ func NewCreateClusterPipeline(runtime *common.KubeRuntime) error {
 		&precheck.GreetingsModule{},
 		&customscripts.CustomScriptsModule{Phase: "PreInstall", Scripts: runtime.Cluster.System.PreInstall},
 		&precheck.NodePreCheckModule{},
-		&confirm.InstallConfirmModule{},
+		&confirm.InstallConfirmModule{Skip: runtime.Arg.DryRun},
 		&artifact.UnArchiveModule{Skip: noArtifact},
 		&os.RepositoryModule{Skip: noArtifact || !runtime.Arg.InstallPackages},
 		&binaries.NodeBinariesModule{},
@@ -76,6 +77,7 @@ func NewCreateClusterPipeline(runtime *common.KubeRuntime) error {
 		&etcd.ConfigureModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
 		&etcd.BackupModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
 		&kubernetes.InstallKubeBinariesModule{},
+		&loadbalancer.KeepalivedModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledKeepalived()},
 		// init kubeVip on first master
 		&loadbalancer.KubevipModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledVip()},
 		&kubernetes.InitKubernetesModule{},
@@ -101,14 +103,21 @@ func NewCreateClusterPipeline(runtime *common.KubeRuntime) error {
 	}
 
 	p := pipeline.Pipeline{
-		Name:    "CreateClusterPipeline",
-		Modules: m,
-		Runtime: runtime,
+		Name:       "CreateClusterPipeline",
+		Modules:    m,
+		Runtime:    runtime,
+		Resume:     runtime.Arg.Resume,
+		ReportPath: runtime.Arg.ReportPath,
+		NoRollback: runtime.Arg.NoRollback,
 	}
-	if err := p.Start(); err != nil {
+	if err := p.StartContext(ctx); err != nil {
 		return err
 	}
 
+	if runtime.Arg.DryRun {
+		return nil
+	}
+
 	if runtime.Cluster.KubeSphere.Enabled {
 
 		fmt.Print(`Installation is complete.
@@ -132,7 +141,7 @@ Please check the result using the command:
 	return nil
 }
 
-func NewK3sCreateClusterPipeline(runtime *common.KubeRuntime) error {
+func NewK3sCreateClusterPipeline(ctx context.Context, runtime *common.KubeRuntime) error {
 	noArtifact := runtime.Arg.Artifact == ""
 	skipPushImages := runtime.Arg.SkipPushImages || noArtifact || (!noArtifact && runtime.Cluster.Registry.PrivateRegistry == "")
 	skipLocalStorage := true
@@ -155,6 +164,7 @@ func NewK3sCreateClusterPipeline(runtime *common.KubeRuntime) error {
 		&etcd.InstallETCDBinaryModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
 		&etcd.ConfigureModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
 		&etcd.BackupModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
+		&loadbalancer.KeepalivedModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledKeepalived()},
 		&loadbalancer.K3sKubevipModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledVip()},
 		&k3s.InstallKubeBinariesModule{},
 		&k3s.InitClusterModule{},
@@ -176,14 +186,21 @@ func NewK3sCreateClusterPipeline(runtime *common.KubeRuntime) error {
 	}
 
 	p := pipeline.Pipeline{
-		Name:    "K3sCreateClusterPipeline",
-		Modules: m,
-		Runtime: runtime,
+		Name:       "K3sCreateClusterPipeline",
+		Modules:    m,
+		Runtime:    runtime,
+		Resume:     runtime.Arg.Resume,
+		ReportPath: runtime.Arg.ReportPath,
+		NoRollback: runtime.Arg.NoRollback,
 	}
-	if err := p.Start(); err != nil {
+	if err := p.StartContext(ctx); err != nil {
 		return err
 	}
 
+	if runtime.Arg.DryRun {
+		return nil
+	}
+
 	if runtime.Cluster.KubeSphere.Enabled {
 
 		fmt.Print(`Installation is complete.
@@ -207,7 +224,7 @@ Please check the result using the command:
 	return nil
 }
 
-func NewK8eCreateClusterPipeline(runtime *common.KubeRuntime) error {
+func NewK8eCreateClusterPipeline(ctx context.Context, runtime *common.KubeRuntime) error {
 	noArtifact := runtime.Arg.Artifact == ""
 	skipPushImages := runtime.Arg.SkipPushImages || noArtifact || (!noArtifact && runtime.Cluster.Registry.PrivateRegistry == "")
 	skipLocalStorage := true
@@ -230,6 +247,7 @@ func NewK8eCreateClusterPipeline(runtime *common.KubeRuntime) error {
 		&etcd.InstallETCDBinaryModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
 		&etcd.ConfigureModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
 		&etcd.BackupModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
+		&loadbalancer.KeepalivedModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledKeepalived()},
 		&loadbalancer.K3sKubevipModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledVip()},
 		&k8e.InstallKubeBinariesModule{},
 		&k8e.InitClusterModule{},
@@ -251,14 +269,21 @@ func NewK8eCreateClusterPipeline(runtime *common.KubeRuntime) error {
 	}
 
 	p := pipeline.Pipeline{
-		Name:    "K8eCreateClusterPipeline",
-		Modules: m,
-		Runtime: runtime,
+		Name:       "K8eCreateClusterPipeline",
+		Modules:    m,
+		Runtime:    runtime,
+		Resume:     runtime.Arg.Resume,
+		ReportPath: runtime.Arg.ReportPath,
+		NoRollback: runtime.Arg.NoRollback,
 	}
-	if err := p.Start(); err != nil {
+	if err := p.StartContext(ctx); err != nil {
 		return err
 	}
 
+	if runtime.Arg.DryRun {
+		return nil
+	}
+
 	if runtime.Cluster.KubeSphere.Enabled {
 
 		fmt.Print(`Installation is complete.
@@ -282,12 +307,14 @@ Please check the result using the command:
 	return nil
 }
 
-func CreateCluster(args common.Argument, downloadCmd string) error {
-	args.DownloadCommand = func(path, url string) string {
-		// this is an extension point for downloading tools, for example users can set the timeout, proxy or retry under
-		// some poor network environment. Or users even can choose another cli, it might be wget.
-		// perhaps we should have a build-in download function instead of totally rely on the external one
-		return fmt.Sprintf(downloadCmd, path, url)
+func CreateCluster(ctx context.Context, args common.Argument, downloadCmd string) error {
+	// the built-in downloader is used if there is no download command
+	if downloadCmd != "" {
+		args.DownloadCommand = func(path, url string) string {
+			// this is an extension point for downloading tools, for example users can set the timeout, proxy or retry under
+			// some poor network environment. Or users even can choose another cli, it might be wget.
+			return fmt.Sprintf(downloadCmd, path, url)
+		}
 	}
 
 	var loaderType string
@@ -304,17 +331,17 @@ func CreateCluster(args common.Argument, downloadCmd string) error {
 
 	switch runtime.Cluster.Kubernetes.Type {
 	case common.K3s:
-		if err := NewK3sCreateClusterPipeline(runtime); err != nil {
+		if err := NewK3sCreateClusterPipeline(ctx, runtime); err != nil {
 			return err
 		}
 	case common.K8e:
-		if err := NewK8eCreateClusterPipeline(runtime); err != nil {
+		if err := NewK8eCreateClusterPipeline(ctx, runtime); err != nil {
 			return err
 		}
 	case common.Kubernetes:
 		fallthrough
 	default:
-		if err := NewCreateClusterPipeline(runtime); err != nil {
+		if err := NewCreateClusterPipeline(ctx, runtime); err != nil {
 			return err
 		}
 	}
@@ -40,6 +40,7 @@ func NewDeleteClusterPipeline(runtime *common.KubeRuntime) error {
 		&os.ClearOSEnvironmentModule{},
 		&certs.UninstallAutoRenewCertsModule{},
 		&loadbalancer.DeleteVIPModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledVip()},
+		&loadbalancer.DeleteKeepalivedModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledKeepalived()},
 	}
 
 	p := pipeline.Pipeline{
@@ -61,6 +62,7 @@ func NewK3sDeleteClusterPipeline(runtime *common.KubeRuntime) error {
 		&os.ClearOSEnvironmentModule{},
 		&certs.UninstallAutoRenewCertsModule{},
 		&loadbalancer.DeleteVIPModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledVip()},
+		&loadbalancer.DeleteKeepalivedModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledKeepalived()},
 	}
 
 	p := pipeline.Pipeline{
@@ -81,6 +83,7 @@ func NewK8eDeleteClusterPipeline(runtime *common.KubeRuntime) error {
 		&k8e.DeleteClusterModule{},
 		&os.ClearOSEnvironmentModule{},
 		&certs.UninstallAutoRenewCertsModule{},
+		&loadbalancer.DeleteKeepalivedModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledKeepalived()},
 	}
 
 	p := pipeline.Pipeline{
@@ -17,24 +17,54 @@
 package pipelines
 
 import (
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/confirm"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/os"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/precheck"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/module"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/etcd"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/kubernetes"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/loadbalancer"
 )
 
 func DeleteNodePipeline(runtime *common.KubeRuntime) error {
+	var node connector.Host
+	for _, host := range runtime.GetAllHosts() {
+		if host.GetName() == runtime.Arg.NodeName {
+			node = host
+		}
+	}
+	// a node which only has the etcd or loadbalancer role is not a kubernetes node
+	deleteKubeNode := node == nil || node.IsRole(common.Worker) || node.IsRole(common.Master) ||
+		(!node.IsRole(common.ETCD) && !node.IsRole(common.Loadbalancer))
+	keepalived := runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledKeepalived()
+	deleteETCD := node != nil && node.IsRole(common.ETCD) && runtime.Cluster.Etcd.Type == kubekeyapiv1alpha2.KubeKey
+	if deleteETCD {
+		if len(runtime.GetHostsByRole(common.ETCD)) == 1 {
+			return errors.Errorf("%s is the only etcd member, it can not be deleted", node.GetName())
+		}
+		// the other etcd members and kube-apiservers are refreshed without the node
+		runtime.RoleMapDeleteRole(node, common.ETCD)
+	}
+
 	m := []module.Module{
 		&precheck.GreetingsModule{},
 		&confirm.DeleteNodeConfirmModule{Skip: runtime.Arg.SkipConfirmCheck},
-		&kubernetes.CompareConfigAndClusterInfoModule{},
-		&kubernetes.DeleteKubeNodeModule{},
-		&os.ClearNodeOSModule{},
+		&kubernetes.CompareConfigAndClusterInfoModule{Skip: !deleteKubeNode},
+		&kubernetes.DeleteKubeNodeModule{Skip: !deleteKubeNode},
+		&etcd.PreCheckModule{Skip: !deleteETCD},
+		&etcd.RemoveMemberModule{Skip: !deleteETCD, Member: runtime.Arg.NodeName, Clean: true},
+		&etcd.RefreshMembersModule{Skip: !deleteETCD},
+		&os.ClearNodeOSModule{Skip: !deleteKubeNode},
 		&loadbalancer.DeleteVIPModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledVip()},
+		&loadbalancer.DeleteKeepalivedModule{Skip: !keepalived, Node: runtime.Arg.NodeName},
+		// the haproxy and keepalived on the other loadbalancer nodes are refreshed without the node
+		&loadbalancer.KeepalivedModule{Skip: !keepalived, Exclude: runtime.Arg.NodeName},
 	}
 
 	p := pipeline.Pipeline{
@@ -0,0 +1,102 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/precheck"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/module"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/etcd"
+)
+
+func ListETCDBackupsPipeline(ctx context.Context, runtime *common.KubeRuntime) error {
+	m := []module.Module{
+		&etcd.ListBackupsModule{Node: runtime.Arg.NodeName},
+	}
+
+	p := pipeline.Pipeline{
+		Name:    "ListETCDBackupsPipeline",
+		Modules: m,
+		Runtime: runtime,
+	}
+	if err := p.StartContext(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func DownloadETCDBackupPipeline(ctx context.Context, runtime *common.KubeRuntime) error {
+	m := []module.Module{
+		&precheck.GreetingsModule{},
+		&etcd.DownloadBackupModule{Snapshot: runtime.Arg.EtcdSnapshot},
+	}
+
+	p := pipeline.Pipeline{
+		Name:       "DownloadETCDBackupPipeline",
+		Modules:    m,
+		Runtime:    runtime,
+		ReportPath: runtime.Arg.ReportPath,
+	}
+	if err := p.StartContext(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func newETCDBackupRuntime(args common.Argument) (*common.KubeRuntime, error) {
+	var loaderType string
+	if args.FilePath != "" {
+		loaderType = common.File
+	} else {
+		loaderType = common.AllInOne
+	}
+
+	runtime, err := common.NewKubeRuntime(loaderType, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey {
+		return nil, errors.Errorf("the etcd backups are only supported for the etcd installed by KubeKey, but the etcd type is %s", runtime.Cluster.Etcd.Type)
+	}
+	if runtime.Cluster.Etcd.BackupTarget == nil {
+		return nil, errors.New("the backup target of etcd is not configured, set it by etcd.backupTarget in the configuration file")
+	}
+	return runtime, nil
+}
+
+func ListETCDBackups(ctx context.Context, args common.Argument) error {
+	runtime, err := newETCDBackupRuntime(args)
+	if err != nil {
+		return err
+	}
+	return ListETCDBackupsPipeline(ctx, runtime)
+}
+
+func DownloadETCDBackup(ctx context.Context, args common.Argument) error {
+	runtime, err := newETCDBackupRuntime(args)
+	if err != nil {
+		return err
+	}
+	return DownloadETCDBackupPipeline(ctx, runtime)
+}
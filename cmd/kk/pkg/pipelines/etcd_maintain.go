@@ -0,0 +1,76 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/precheck"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/module"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/etcd"
+)
+
+func MaintainETCDPipeline(ctx context.Context, runtime *common.KubeRuntime) error {
+	m := []module.Module{
+		&precheck.GreetingsModule{},
+		&etcd.PreCheckModule{},
+	}
+	if runtime.Arg.EtcdSchedule != "" {
+		m = append(m, &etcd.MaintainTimerModule{Schedule: runtime.Arg.EtcdSchedule})
+	} else {
+		m = append(m, &etcd.MaintainModule{StatusOnly: runtime.Arg.EtcdStatusOnly, Output: runtime.Arg.EtcdOutput})
+	}
+
+	p := pipeline.Pipeline{
+		Name:       "MaintainETCDPipeline",
+		Modules:    m,
+		Runtime:    runtime,
+		ReportPath: runtime.Arg.ReportPath,
+	}
+	if err := p.StartContext(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func MaintainETCD(ctx context.Context, args common.Argument) error {
+	var loaderType string
+	if args.FilePath != "" {
+		loaderType = common.File
+	} else {
+		loaderType = common.AllInOne
+	}
+
+	runtime, err := common.NewKubeRuntime(loaderType, args)
+	if err != nil {
+		return err
+	}
+
+	if runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey {
+		return errors.Errorf("maintaining etcd is only supported for the etcd installed by KubeKey, but the etcd type is %s", runtime.Cluster.Etcd.Type)
+	}
+
+	if err := MaintainETCDPipeline(ctx, runtime); err != nil {
+		return err
+	}
+	return nil
+}
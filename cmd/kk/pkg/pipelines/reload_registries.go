@@ -0,0 +1,71 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/precheck"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/container"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/module"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/pipeline"
+)
+
+func ReloadRegistriesPipeline(ctx context.Context, runtime *common.KubeRuntime) error {
+	m := []module.Module{
+		&precheck.GreetingsModule{},
+		&container.ReloadRegistriesModule{},
+	}
+
+	p := pipeline.Pipeline{
+		Name:       "ReloadRegistriesPipeline",
+		Modules:    m,
+		Runtime:    runtime,
+		ReportPath: runtime.Arg.ReportPath,
+	}
+	if err := p.StartContext(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ReloadRegistries(ctx context.Context, args common.Argument) error {
+	var loaderType string
+	if args.FilePath != "" {
+		loaderType = common.File
+	} else {
+		loaderType = common.AllInOne
+	}
+
+	runtime, err := common.NewKubeRuntime(loaderType, args)
+	if err != nil {
+		return err
+	}
+
+	if runtime.Cluster.Kubernetes.ContainerManager != common.Containerd {
+		return errors.Errorf("reloading the registries is only supported for containerd, but the container manager is %s",
+			runtime.Cluster.Kubernetes.ContainerManager)
+	}
+
+	if err := ReloadRegistriesPipeline(ctx, runtime); err != nil {
+		return err
+	}
+	return nil
+}
@@ -17,17 +17,30 @@
 package pipelines
 
 import (
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/precheck"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/certs"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/module"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/etcd"
 )
 
 func RenewCertsPipeline(runtime *common.KubeRuntime) error {
+	components, err := certs.ParseComponents(runtime.Arg.CertsComponents)
+	if err != nil {
+		return err
+	}
+	// only the etcd deployed by kubekey has its certs signed by kubekey
+	renewETCD := components.Has(certs.ComponentEtcd) && runtime.Cluster.Etcd.Type == kubekeyapiv1alpha2.KubeKey
+
 	m := []module.Module{
 		&precheck.GreetingsModule{},
-		&certs.RenewCertsModule{},
+		&etcd.PreCheckModule{Skip: !renewETCD},
+		&etcd.CertsModule{Skip: !renewETCD, Renew: true},
+		&etcd.RollingRestartModule{Skip: !renewETCD},
+		&certs.RenewCertsModule{Skip: len(components.KubeadmCerts()) == 0 && !renewETCD, Components: components},
+		&certs.KubeletCertsModule{Skip: !components.Has(certs.ComponentKubelet)},
 		&certs.CheckCertsModule{},
 		&certs.PrintClusterCertsModule{},
 	}
@@ -0,0 +1,117 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/artifact"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/binaries"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/confirm"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/os"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/precheck"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/module"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/etcd"
+)
+
+func ReplaceETCDMemberPipeline(ctx context.Context, runtime *common.KubeRuntime) error {
+	noArtifact := runtime.Arg.Artifact == ""
+
+	var nodeBinaries module.Module
+	switch runtime.Cluster.Kubernetes.Type {
+	case common.K3s:
+		nodeBinaries = &binaries.K3sNodeBinariesModule{}
+	case common.K8e:
+		nodeBinaries = &binaries.K8eNodeBinariesModule{}
+	default:
+		nodeBinaries = &binaries.NodeBinariesModule{}
+	}
+
+	m := []module.Module{
+		&precheck.GreetingsModule{},
+		&confirm.ReplaceETCDMemberConfirmModule{Skip: runtime.Arg.SkipConfirmCheck},
+		&artifact.UnArchiveModule{Skip: noArtifact},
+		nodeBinaries,
+		&os.ConfigureOSModule{Skip: runtime.Cluster.System.SkipConfigureOS},
+		&etcd.PreCheckModule{},
+		&etcd.RemoveMemberModule{Member: runtime.Arg.NodeName},
+		&etcd.CertsModule{},
+		&etcd.InstallETCDBinaryModule{},
+		&etcd.ConfigureModule{},
+		&etcd.RefreshMembersModule{},
+		&etcd.BackupModule{},
+	}
+
+	p := pipeline.Pipeline{
+		Name:       "ReplaceETCDMemberPipeline",
+		Modules:    m,
+		Runtime:    runtime,
+		ReportPath: runtime.Arg.ReportPath,
+		NoRollback: runtime.Arg.NoRollback,
+	}
+	if err := p.StartContext(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ReplaceETCDMember(ctx context.Context, args common.Argument, downloadCmd string) error {
+	// the built-in downloader is used if there is no download command
+	if downloadCmd != "" {
+		args.DownloadCommand = func(path, url string) string {
+			return fmt.Sprintf(downloadCmd, path, url)
+		}
+	}
+
+	var loaderType string
+	if args.FilePath != "" {
+		loaderType = common.File
+	} else {
+		loaderType = common.AllInOne
+	}
+
+	runtime, err := common.NewKubeRuntime(loaderType, args)
+	if err != nil {
+		return err
+	}
+
+	if runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey {
+		return errors.Errorf("replacing an etcd member is only supported for the etcd installed by KubeKey, but the etcd type is %s", runtime.Cluster.Etcd.Type)
+	}
+	for _, host := range runtime.GetHostsByRole(common.ETCD) {
+		if host.GetName() == args.NodeName {
+			return errors.Errorf("%s is still in the etcd role group, replace it with the new etcd host in the config first", args.NodeName)
+		}
+	}
+	// the old member is usually down, so its host is never connected
+	for _, host := range runtime.GetAllHosts() {
+		if host.GetName() == args.NodeName {
+			runtime.DeleteHost(host)
+		}
+	}
+
+	if err := ReplaceETCDMemberPipeline(ctx, runtime); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/precheck"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/certs"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/module"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/pipeline"
+)
+
+func RotateCAPipeline(runtime *common.KubeRuntime) error {
+	cas, err := certs.ParseCAs(runtime.Arg.CertsCAs)
+	if err != nil {
+		return err
+	}
+	// only the etcd deployed by kubekey has its CA managed by kubekey
+	if runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey {
+		cas = certs.WithoutCA(cas, certs.CAEtcd)
+	}
+	// the external CAs are rotated by their owner and given again in the config
+	external := map[string]bool{
+		certs.CACluster: runtime.Cluster.Certificates.Kubernetes.IsExternal(),
+		certs.CAEtcd:    runtime.Cluster.Certificates.Etcd.IsExternal(),
+	}
+	for _, ca := range cas {
+		if external[ca] {
+			logger.Log.Warnf("the %s CA is external, it is not rotated", ca)
+			cas = certs.WithoutCA(cas, ca)
+		}
+	}
+	if len(cas) == 0 {
+		return errors.New("no CA to rotate")
+	}
+
+	until := len(certs.RotateCAPhases) - 1
+	if runtime.Arg.RotateCAUntil != "" {
+		until = -1
+		for i, phase := range certs.RotateCAPhases {
+			if phase == runtime.Arg.RotateCAUntil {
+				until = i
+			}
+		}
+		if until < 0 {
+			return errors.Errorf("unknown phase %q, it should be one of %s", runtime.Arg.RotateCAUntil,
+				strings.Join(certs.RotateCAPhases, ", "))
+		}
+	}
+
+	state, err := certs.LoadRotateCAState(certs.RotateCADir(runtime.GetWorkDir()))
+	if err != nil {
+		return err
+	}
+	if err := state.Resume(cas, len(runtime.Arg.CertsCAs) > 0); err != nil {
+		return err
+	}
+	if state.InProgress() {
+		logger.Log.Infof("resume the rotation of the CAs %s, the completed phases: %s",
+			strings.Join(state.CAs, ","), strings.Join(state.Phases, ","))
+	}
+
+	m := []module.Module{
+		&precheck.GreetingsModule{},
+	}
+	for i, phase := range certs.RotateCAPhases {
+		m = append(m, &certs.RotateCAModule{Skip: i > until, Phase: phase, State: state})
+	}
+	m = append(m,
+		&certs.CheckCertsModule{},
+		&certs.PrintClusterCertsModule{},
+	)
+
+	p := pipeline.Pipeline{
+		Name:    "RotateCAPipeline",
+		Modules: m,
+		Runtime: runtime,
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func RotateCA(args common.Argument) error {
+	var loaderType string
+	if args.FilePath != "" {
+		loaderType = common.File
+	} else {
+		loaderType = common.AllInOne
+	}
+
+	runtime, err := common.NewKubeRuntime(loaderType, args)
+	if err != nil {
+		return err
+	}
+
+	if err := RotateCAPipeline(runtime); err != nil {
+		return err
+	}
+	return nil
+}
@@ -17,6 +17,7 @@
 package pipelines
 
 import (
+	"context"
 	"fmt"
 
 	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
@@ -41,14 +42,14 @@ import (
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/loadbalancer"
 )
 
-func NewUpgradeClusterPipeline(runtime *common.KubeRuntime) error {
+func NewUpgradeClusterPipeline(ctx context.Context, runtime *common.KubeRuntime) error {
 	noArtifact := runtime.Arg.Artifact == ""
 	skipUpgradeETCD := (runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey) || (runtime.Arg.EtcdUpgrade == false)
 	m := []module.Module{
 		&precheck.GreetingsModule{},
 		&precheck.NodePreCheckModule{},
 		&precheck.ClusterPreCheckModule{SkipDependencyCheck: runtime.Arg.SkipDependencyCheck},
-		&confirm.UpgradeConfirmModule{Skip: runtime.Arg.SkipConfirmCheck},
+		&confirm.UpgradeConfirmModule{Skip: runtime.Arg.SkipConfirmCheck || runtime.Arg.DryRun},
 		&artifact.UnArchiveModule{Skip: noArtifact},
 		&binaries.NodeBinariesModule{},
 		&container.InstallCriDockerdModule{Skip: runtime.Cluster.Kubernetes.ContainerManager != "docker"},
@@ -71,22 +72,26 @@ func NewUpgradeClusterPipeline(runtime *common.KubeRuntime) error {
 	}
 
 	p := pipeline.Pipeline{
-		Name:    "UpgradeClusterPipeline",
-		Modules: m,
-		Runtime: runtime,
+		Name:       "UpgradeClusterPipeline",
+		Modules:    m,
+		Runtime:    runtime,
+		ReportPath: runtime.Arg.ReportPath,
+		NoRollback: runtime.Arg.NoRollback,
 	}
-	if err := p.Start(); err != nil {
+	if err := p.StartContext(ctx); err != nil {
 		return err
 	}
 	return nil
 }
 
-func UpgradeCluster(args common.Argument, downloadCmd string) error {
-	args.DownloadCommand = func(path, url string) string {
-		// this is an extension point for downloading tools, for example users can set the timeout, proxy or retry under
-		// some poor network environment. Or users even can choose another cli, it might be wget.
-		// perhaps we should have a build-in download function instead of totally rely on the external one
-		return fmt.Sprintf(downloadCmd, path, url)
+func UpgradeCluster(ctx context.Context, args common.Argument, downloadCmd string) error {
+	// the built-in downloader is used if there is no download command
+	if downloadCmd != "" {
+		args.DownloadCommand = func(path, url string) string {
+			// this is an extension point for downloading tools, for example users can set the timeout, proxy or retry under
+			// some poor network environment. Or users even can choose another cli, it might be wget.
+			return fmt.Sprintf(downloadCmd, path, url)
+		}
 	}
 
 	var loaderType string
@@ -103,7 +108,7 @@ func UpgradeCluster(args common.Argument, downloadCmd string) error {
 
 	switch runtime.Cluster.Kubernetes.Type {
 	case common.Kubernetes:
-		if err := NewUpgradeClusterPipeline(runtime); err != nil {
+		if err := NewUpgradeClusterPipeline(ctx, runtime); err != nil {
 			return err
 		}
 	default:
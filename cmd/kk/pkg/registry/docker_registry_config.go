@@ -40,6 +40,9 @@ type DockerRegistryEntry struct {
 	CertFile string `yaml:"certFile" json:"certFile,omitempty"`
 	// KeyFile is an SSL key file used to secure etcd communication.
 	KeyFile string `yaml:"keyFile" json:"keyFile,omitempty"`
+	// Capabilities are what containerd does with the registry when it is a mirror or a server in hosts.toml,
+	// e.g. pull, resolve and push.
+	Capabilities []string `yaml:"capabilities" json:"capabilities,omitempty"`
 }
 
 func DockerRegistryAuthEntries(auths runtime.RawExtension) (entries map[string]*DockerRegistryEntry) {
@@ -0,0 +1,85 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package registry
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// RobotAuthsFile keeps the auths entry of the robot account generated by "kk init registry" in the work dir, it is
+// merged into the auths of the registry by the later commands run from the same work dir.
+const RobotAuthsFile = "harbor-robot-auths.json"
+
+// SaveRobotAuths writes the auths entry of the robot account of the registry into the work dir.
+func SaveRobotAuths(workDir, registry, username, password string) error {
+	data, err := json.MarshalIndent(map[string]DockerRegistryEntry{
+		registry: {Username: username, Password: password},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(workDir, RobotAuthsFile)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return errors.Wrapf(err, "write the robot account auths %s failed", path)
+	}
+	return nil
+}
+
+// MergeRobotAuths adds the auths entries saved in the work dir into the auths, an entry of the same registry in the
+// auths is kept.
+func MergeRobotAuths(auths runtime.RawExtension, workDir string) (runtime.RawExtension, error) {
+	path := filepath.Join(workDir, RobotAuthsFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return auths, nil
+	}
+	if err != nil {
+		return auths, errors.Wrapf(err, "read the robot account auths %s failed", path)
+	}
+
+	saved := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return auths, errors.Wrapf(err, "parse the robot account auths %s failed", path)
+	}
+	entries := make(map[string]json.RawMessage)
+	if len(auths.Raw) != 0 {
+		if err := json.Unmarshal(auths.Raw, &entries); err != nil {
+			return auths, errors.Wrap(err, "parse the registry auths failed")
+		}
+	}
+
+	changed := false
+	for repo, entry := range saved {
+		if _, ok := entries[repo]; !ok {
+			entries[repo] = entry
+			changed = true
+		}
+	}
+	if !changed {
+		return auths, nil
+	}
+	raw, err := json.Marshal(entries)
+	if err != nil {
+		return auths, err
+	}
+	return runtime.RawExtension{Raw: raw}, nil
+}
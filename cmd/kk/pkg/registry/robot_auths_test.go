@@ -0,0 +1,78 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package registry
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestMergeRobotAuths(t *testing.T) {
+	workDir := t.TempDir()
+	if err := SaveRobotAuths(workDir, "dockerhub.kubekey.local", "robot$kubekey", "Kubekey123"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		auths string
+		want  map[string]DockerRegistryEntry
+	}{
+		{
+			name: "no auths",
+			want: map[string]DockerRegistryEntry{"dockerhub.kubekey.local": {Username: "robot$kubekey", Password: "Kubekey123"}},
+		},
+		{
+			name:  "auths of another registry",
+			auths: `{"docker.io": {"username": "kubekey", "password": "secret"}}`,
+			want: map[string]DockerRegistryEntry{
+				"docker.io":               {Username: "kubekey", Password: "secret"},
+				"dockerhub.kubekey.local": {Username: "robot$kubekey", Password: "Kubekey123"},
+			},
+		},
+		{
+			name:  "auths of the registry are kept",
+			auths: `{"dockerhub.kubekey.local": {"username": "admin", "password": "Harbor12345", "skipTLSVerify": true}}`,
+			want: map[string]DockerRegistryEntry{
+				"dockerhub.kubekey.local": {Username: "admin", Password: "Harbor12345", SkipTLSVerify: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auths, err := MergeRobotAuths(runtime.RawExtension{Raw: []byte(tt.auths)}, workDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]DockerRegistryEntry)
+			if err := json.Unmarshal(auths.Raw, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeRobotAuths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	auths, err := MergeRobotAuths(runtime.RawExtension{}, t.TempDir())
+	if err != nil || len(auths.Raw) != 0 {
+		t.Errorf("MergeRobotAuths() without the saved auths = %s, %v", auths.Raw, err)
+	}
+}
@@ -0,0 +1,239 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package s3
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultRegion = "us-east-1"
+	// DefaultPartSize is the size of the parts of the multipart uploads. The uploader makes the parts larger for the
+	// files of more than 10000 parts, the limit of S3.
+	DefaultPartSize = 64 * 1024 * 1024
+)
+
+// Config is the config of an S3-compatible object storage, such as AWS S3 or MinIO.
+type Config struct {
+	// Endpoint is the URL of the object storage, e.g. https://s3.us-west-2.amazonaws.com or http://10.0.0.2:9000.
+	Endpoint string `json:"endpoint"`
+	// Region is the region of the bucket, us-east-1 is used if it is empty.
+	Region string `json:"region,omitempty"`
+	Bucket string `json:"bucket"`
+	// VirtualHostedStyle addresses the bucket by the host name instead of the path, which MinIO does not support by default.
+	VirtualHostedStyle bool   `json:"virtualHostedStyle,omitempty"`
+	AccessKeyID        string `json:"accessKeyID"`
+	SecretAccessKey    string `json:"secretAccessKey"`
+	SessionToken       string `json:"sessionToken,omitempty"`
+	// CAFile is the path of a PEM file of the CAs to trust besides the system ones.
+	CAFile             string `json:"caFile,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+// Object is an object in the bucket.
+type Object struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// Client puts, gets, lists and deletes the objects of a bucket, and sets the lifecycle rules of the bucket. The files are uploaded in parts, so the ones larger
+// than 5 GiB, the limit of a single upload, are supported.
+type Client struct {
+	bucket     string
+	svc        *s3.S3
+	uploader   *s3manager.Uploader
+	downloader *s3manager.Downloader
+}
+
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("the endpoint and the bucket of the object storage can not be empty")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("the credentials of the object storage can not be empty")
+	}
+	if cfg.Region == "" {
+		cfg.Region = DefaultRegion
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, errors.Errorf("invalid object storage endpoint %s", cfg.Endpoint)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = time.Minute
+	if cfg.CAFile != "" || cfg.InsecureSkipVerify {
+		tlsConfig := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify, MinVersion: tls.VersionTLS12} // #nosec G402
+		if cfg.CAFile != "" {
+			pem, err := os.ReadFile(cfg.CAFile)
+			if err != nil {
+				return nil, errors.Wrapf(err, "read CA file %s failed", cfg.CAFile)
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil || pool == nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, errors.Errorf("no certificate found in CA file %s", cfg.CAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:         aws.String(cfg.Endpoint),
+		Region:           aws.String(cfg.Region),
+		Credentials:      credentials.NewStaticCredentials(cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken),
+		S3ForcePathStyle: aws.Bool(!cfg.VirtualHostedStyle),
+		HTTPClient:       &http.Client{Transport: transport},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create the session of the object storage failed")
+	}
+
+	svc := s3.New(sess)
+	return &Client{
+		bucket: cfg.Bucket,
+		svc:    svc,
+		uploader: s3manager.NewUploaderWithClient(svc, func(u *s3manager.Uploader) {
+			u.PartSize = DefaultPartSize
+		}),
+		downloader: s3manager.NewDownloaderWithClient(svc),
+	}, nil
+}
+
+// PutObject uploads the file as the object, the parts of a large file are uploaded concurrently.
+func (c *Client) PutObject(ctx context.Context, key, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "open %s failed", path)
+	}
+	defer f.Close()
+
+	if _, err := c.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+		Body:   f,
+	}); err != nil {
+		return errors.Wrapf(err, "upload %s to %s failed", path, key)
+	}
+	return nil
+}
+
+// GetObject downloads the object to the path, the file is moved to the path once it is complete.
+func (c *Client) GetObject(ctx context.Context, key, path string) error {
+	part := path + ".part"
+	f, err := os.Create(part)
+	if err != nil {
+		return errors.Wrapf(err, "create %s failed", part)
+	}
+	if _, err := c.downloader.DownloadWithContext(ctx, f, &s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		_ = f.Close()
+		_ = os.Remove(part)
+		return errors.Wrapf(err, "download %s failed", key)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(part, path)
+}
+
+// DeleteObject deletes the object, deleting an object which does not exist is not an error.
+func (c *Client) DeleteObject(ctx context.Context, key string) error {
+	if _, err := c.svc.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		return errors.Wrapf(err, "delete %s failed", key)
+	}
+	return nil
+}
+
+// ListObjects lists all objects whose keys begin with the prefix.
+func (c *Client) ListObjects(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	err := c.svc.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(c.bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, o := range page.Contents {
+			objects = append(objects, Object{
+				Key:          aws.StringValue(o.Key),
+				Size:         aws.Int64Value(o.Size),
+				LastModified: aws.TimeValue(o.LastModified),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "list objects with prefix %s failed", prefix)
+	}
+	return objects, nil
+}
+
+// PutExpirationRule sets the lifecycle rule of the id, which expires the objects whose keys begin with the prefix
+// after the days. The other rules of the bucket are kept, the rule is replaced if it exists.
+func (c *Client) PutExpirationRule(ctx context.Context, id, prefix string, days int) error {
+	var rules []*s3.LifecycleRule
+	out, err := c.svc.GetBucketLifecycleConfigurationWithContext(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(c.bucket),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != "NoSuchLifecycleConfiguration" {
+			return errors.Wrapf(err, "get the lifecycle rules of bucket %s failed", c.bucket)
+		}
+	} else {
+		for _, r := range out.Rules {
+			if aws.StringValue(r.ID) != id {
+				rules = append(rules, r)
+			}
+		}
+	}
+
+	rules = append(rules, &s3.LifecycleRule{
+		ID:         aws.String(id),
+		Status:     aws.String(s3.ExpirationStatusEnabled),
+		Filter:     &s3.LifecycleRuleFilter{Prefix: aws.String(prefix)},
+		Expiration: &s3.LifecycleExpiration{Days: aws.Int64(int64(days))},
+	})
+	if _, err := c.svc.PutBucketLifecycleConfigurationWithContext(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(c.bucket),
+		LifecycleConfiguration: &s3.BucketLifecycleConfiguration{Rules: rules},
+	}); err != nil {
+		return errors.Wrapf(err, "put the lifecycle rule %s of bucket %s failed", id, c.bucket)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/s3/s3test"
+)
+
+func TestClient(t *testing.T) {
+	server := s3test.NewServer()
+	defer server.Close()
+	server.MaxKeys = 2
+
+	c, err := NewClient(Config{
+		Endpoint:        server.URL,
+		Bucket:          s3test.Bucket,
+		AccessKeyID:     s3test.AccessKeyID,
+		SecretAccessKey: s3test.SecretAccessKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	file := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := os.WriteFile(file, []byte("snapshot"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"etcd/node1/1.db", "etcd/node1/2.db", "etcd/node1/3.db", "etcd/node2/1.db"} {
+		if err := c.PutObject(ctx, key, file); err != nil {
+			t.Fatalf("PutObject() error = %v", err)
+		}
+	}
+
+	objects, err := c.ListObjects(ctx, "etcd/node1/")
+	if err != nil {
+		t.Fatalf("ListObjects() error = %v", err)
+	}
+	var keys []string
+	for _, o := range objects {
+		keys = append(keys, o.Key)
+	}
+	if want := []string{"etcd/node1/1.db", "etcd/node1/2.db", "etcd/node1/3.db"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("ListObjects() = %v, want %v", keys, want)
+	}
+
+	download := filepath.Join(t.TempDir(), "download.db")
+	if err := c.GetObject(ctx, "etcd/node2/1.db", download); err != nil {
+		t.Fatalf("GetObject() error = %v", err)
+	}
+	if data, _ := os.ReadFile(download); string(data) != "snapshot" {
+		t.Errorf("GetObject() = %q, want %q", data, "snapshot")
+	}
+	if err := c.GetObject(ctx, "etcd/node3/1.db", download); err == nil || !strings.Contains(err.Error(), "NoSuchKey") {
+		t.Errorf("GetObject() of a missing object error = %v", err)
+	}
+
+	if err := c.DeleteObject(ctx, "etcd/node1/1.db"); err != nil {
+		t.Fatalf("DeleteObject() error = %v", err)
+	}
+	if want := []string{"etcd/node1/2.db", "etcd/node1/3.db", "etcd/node2/1.db"}; !reflect.DeepEqual(server.Keys(), want) {
+		t.Errorf("objects after DeleteObject() = %v, want %v", server.Keys(), want)
+	}
+
+	unknown, err := NewClient(Config{Endpoint: server.URL, Bucket: s3test.Bucket, AccessKeyID: "unknown", SecretAccessKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unknown.ListObjects(ctx, ""); err == nil || !strings.Contains(err.Error(), "InvalidAccessKeyId") {
+		t.Errorf("ListObjects() with an unknown key error = %v", err)
+	}
+}
+
+func TestClientMultipart(t *testing.T) {
+	server := s3test.NewServer()
+	defer server.Close()
+
+	c, err := NewClient(Config{
+		Endpoint:        server.URL,
+		Bucket:          s3test.Bucket,
+		AccessKeyID:     s3test.AccessKeyID,
+		SecretAccessKey: s3test.SecretAccessKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.uploader.PartSize = s3manager.MinUploadPartSize
+	c.downloader.PartSize = s3manager.MinUploadPartSize
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), int(s3manager.MinUploadPartSize*5/2/16))
+	file := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PutObject(ctx, "etcd/node1/large.db", file); err != nil {
+		t.Fatalf("PutObject() error = %v", err)
+	}
+	if parts := server.Parts("etcd/node1/large.db"); parts != 3 {
+		t.Errorf("PutObject() uploaded %d parts, want 3", parts)
+	}
+
+	download := filepath.Join(t.TempDir(), "download.db")
+	if err := c.GetObject(ctx, "etcd/node1/large.db", download); err != nil {
+		t.Fatalf("GetObject() error = %v", err)
+	}
+	if got, _ := os.ReadFile(download); !bytes.Equal(got, data) {
+		t.Errorf("GetObject() returned %d bytes, want the %d bytes uploaded", len(got), len(data))
+	}
+}
+
+func TestClientPutExpirationRule(t *testing.T) {
+	server := s3test.NewServer()
+	defer server.Close()
+
+	c, err := NewClient(Config{
+		Endpoint:        server.URL,
+		Bucket:          s3test.Bucket,
+		AccessKeyID:     s3test.AccessKeyID,
+		SecretAccessKey: s3test.SecretAccessKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err := c.PutExpirationRule(ctx, "logs", "logs/", 7); err != nil {
+		t.Fatalf("PutExpirationRule() error = %v", err)
+	}
+	if err := c.PutExpirationRule(ctx, "etcd", "etcd/", 5); err != nil {
+		t.Fatalf("PutExpirationRule() error = %v", err)
+	}
+	// the rule of the same id is replaced, the others are kept
+	if err := c.PutExpirationRule(ctx, "etcd", "cluster/", 10); err != nil {
+		t.Fatalf("PutExpirationRule() error = %v", err)
+	}
+	want := []s3test.LifecycleRule{
+		{ID: "logs", Status: "Enabled", Prefix: "logs/", Days: 7},
+		{ID: "etcd", Status: "Enabled", Prefix: "cluster/", Days: 10},
+	}
+	if got := server.LifecycleRules(); !reflect.DeepEqual(got, want) {
+		t.Errorf("lifecycle rules = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,333 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package s3test provides an in-memory stand-in of an S3-compatible object storage, like MinIO, for the tests.
+package s3test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	AccessKeyID     = "minioadmin"
+	SecretAccessKey = "minioadmin-secret"
+	Bucket          = "backup"
+)
+
+// Server serves the objects of the bucket Bucket in the path style, and refuses the requests which are not signed
+// by AccessKeyID or whose payload hash does not match the body. The objects can be uploaded in parts. The lifecycle
+// rules of the bucket are stored but not applied.
+type Server struct {
+	*httptest.Server
+	// MaxKeys is the number of the objects in a page of the list, it is 1000 if it is 0.
+	MaxKeys int
+
+	mu      sync.Mutex
+	objects map[string][]byte
+	// uploads are the parts of the multipart uploads in progress by the upload id
+	uploads map[string]map[int][]byte
+	// parts are the numbers of the parts the objects were uploaded in
+	parts     map[string]int
+	uploadID  int
+	lifecycle []LifecycleRule
+}
+
+// LifecycleRule is an expiration rule of the lifecycle configuration of the bucket.
+type LifecycleRule struct {
+	ID     string `xml:"ID"`
+	Status string `xml:"Status"`
+	Prefix string `xml:"Filter>Prefix"`
+	Days   int    `xml:"Expiration>Days"`
+}
+
+type lifecycleConfiguration struct {
+	XMLName xml.Name        `xml:"LifecycleConfiguration"`
+	Rules   []LifecycleRule `xml:"Rule"`
+}
+
+func NewServer() *Server {
+	s := &Server{objects: make(map[string][]byte), uploads: make(map[string]map[int][]byte), parts: make(map[string]int)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Put puts an object into the bucket directly.
+func (s *Server) Put(key string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+}
+
+// Keys returns the sorted keys of the objects in the bucket.
+func (s *Server) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.objects))
+	for k := range s.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Parts returns the number of the parts an object was uploaded in, it is 0 if the object was uploaded at once.
+func (s *Server) Parts(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.parts[key]
+}
+
+// LifecycleRules returns the lifecycle rules of the bucket.
+func (s *Server) LifecycleRules() []LifecycleRule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]LifecycleRule(nil), s.lifecycle...)
+}
+
+// Get returns the content of an object.
+func (s *Server) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	return data, ok
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential="+AccessKeyID+"/") {
+		writeError(w, http.StatusForbidden, "InvalidAccessKeyId", "The access key ID you provided does not exist.")
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != Bucket {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	switch {
+	case key == "" && query.Has("lifecycle"):
+		s.handleLifecycle(w, r)
+	case r.Method == http.MethodGet && key == "":
+		s.list(w, r)
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.uploadID++
+		id := strconv.Itoa(s.uploadID)
+		s.uploads[id] = make(map[int][]byte)
+		writeXML(w, initiateMultipartUploadResult{Bucket: bucket, Key: key, UploadID: id})
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		s.completeUpload(w, r, key)
+	case r.Method == http.MethodPut:
+		body, ok := readBody(w, r)
+		if !ok {
+			return
+		}
+		if !query.Has("uploadId") {
+			s.objects[key] = body
+			delete(s.parts, key)
+			return
+		}
+		parts, ok := s.uploads[query.Get("uploadId")]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+			return
+		}
+		n, _ := strconv.Atoi(query.Get("partNumber"))
+		parts[n] = body
+		sum := md5.Sum(body)
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+	case r.Method == http.MethodGet:
+		data, ok := s.objects[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		http.ServeContent(w, r, key, time.Time{}, bytes.NewReader(data))
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(s.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed.")
+	}
+}
+
+// readBody reads the body of the request, and checks it against the payload hash of the signature.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return nil, false
+	}
+	sum := sha256.Sum256(body)
+	if hash := r.Header.Get("X-Amz-Content-Sha256"); hash != "UNSIGNED-PAYLOAD" && hash != hex.EncodeToString(sum[:]) {
+		writeError(w, http.StatusBadRequest, "XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed.")
+		return nil, false
+	}
+	return body, true
+}
+
+func (s *Server) handleLifecycle(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if len(s.lifecycle) == 0 {
+			writeError(w, http.StatusNotFound, "NoSuchLifecycleConfiguration", "The lifecycle configuration does not exist.")
+			return
+		}
+		writeXML(w, lifecycleConfiguration{Rules: s.lifecycle})
+	case http.MethodPut:
+		body, ok := readBody(w, r)
+		if !ok {
+			return
+		}
+		var config lifecycleConfiguration
+		if err := xml.Unmarshal(body, &config); err != nil || len(config.Rules) == 0 {
+			writeError(w, http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed.")
+			return
+		}
+		s.lifecycle = config.Rules
+	case http.MethodDelete:
+		s.lifecycle = nil
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed.")
+	}
+}
+
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+type completeMultipartUpload struct {
+	Parts []struct {
+		PartNumber int `xml:"PartNumber"`
+	} `xml:"Part"`
+}
+
+type completeMultipartUploadResult struct {
+	XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+	Bucket  string   `xml:"Bucket"`
+	Key     string   `xml:"Key"`
+}
+
+// completeUpload joins the parts listed in the request into the object.
+func (s *Server) completeUpload(w http.ResponseWriter, r *http.Request, key string) {
+	id := r.URL.Query().Get("uploadId")
+	parts, ok := s.uploads[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+		return
+	}
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	var complete completeMultipartUpload
+	if err := xml.Unmarshal(body, &complete); err != nil || len(complete.Parts) == 0 {
+		writeError(w, http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed.")
+		return
+	}
+
+	var data []byte
+	for i, p := range complete.Parts {
+		part, ok := parts[p.PartNumber]
+		if !ok || p.PartNumber != i+1 {
+			writeError(w, http.StatusBadRequest, "InvalidPart", "One or more of the specified parts could not be found.")
+			return
+		}
+		data = append(data, part...)
+	}
+	s.objects[key] = data
+	s.parts[key] = len(complete.Parts)
+	delete(s.uploads, id)
+	writeXML(w, completeMultipartUploadResult{Bucket: Bucket, Key: key})
+}
+
+type object struct {
+	Key          string `xml:"Key"`
+	Size         int    `xml:"Size"`
+	LastModified string `xml:"LastModified"`
+}
+
+type listBucketResult struct {
+	XMLName               xml.Name `xml:"ListBucketResult"`
+	Contents              []object `xml:"Contents"`
+	IsTruncated           bool     `xml:"IsTruncated"`
+	NextContinuationToken string   `xml:"NextContinuationToken,omitempty"`
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	var keys []string
+	for k := range s.objects {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	start, _ := strconv.Atoi(r.URL.Query().Get("continuation-token"))
+	maxKeys := s.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = 1000
+	}
+	result := listBucketResult{}
+	for i := start; i < len(keys) && i < start+maxKeys; i++ {
+		result.Contents = append(result.Contents, object{
+			Key:          keys[i],
+			Size:         len(s.objects[keys[i]]),
+			LastModified: time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+	if start+maxKeys < len(keys) {
+		result.IsTruncated = true
+		result.NextContinuationToken = strconv.Itoa(start + maxKeys)
+	}
+
+	writeXML(w, result)
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, message)
+}
@@ -49,10 +49,38 @@ type KubekeyCert struct {
 	Config   CertConfig
 }
 
-// GetConfig returns the definition for the given cert given the provided InitConfiguration
-func (k *KubekeyCert) GetConfig(_ *common.KubeConf) (*CertConfig, error) {
+// GetConfig returns the definition for the given cert. The key algorithm, the key size and the validity of the
+// cluster are applied unless they are set in the definition.
+func (k *KubekeyCert) GetConfig(kubeConf *common.KubeConf) (*CertConfig, error) {
+	cfg := k.Config
+	if kubeConf == nil || kubeConf.Cluster == nil {
+		return &cfg, nil
+	}
 
-	return &k.Config, nil
+	certificates := kubeConf.Cluster.Certificates
+	if cfg.PublicKeyAlgorithm == x509.UnknownPublicKeyAlgorithm {
+		algorithm, err := certificates.GetKeyAlgorithm()
+		if err != nil {
+			return nil, err
+		}
+		cfg.PublicKeyAlgorithm = algorithm
+		// the key size is meaningless for the algorithm set in the definition
+		if cfg.KeySize == 0 {
+			cfg.KeySize = certificates.KeySize
+		}
+	}
+	// the validity of the CAs is not configurable
+	if k.CAName != "" && cfg.NotAfter == nil {
+		validity, err := certificates.GetValidity()
+		if err != nil {
+			return nil, err
+		}
+		if validity > 0 {
+			notAfter := time.Now().Add(validity).UTC()
+			cfg.NotAfter = &notAfter
+		}
+	}
+	return &cfg, nil
 }
 
 // CreateFromCA makes and writes a certificate using the given CA cert and key.
@@ -193,7 +221,7 @@ func NewCertAndKey(caCert *x509.Certificate, caKey crypto.Signer, config *CertCo
 		return nil, nil, errors.New("must specify at least one ExtKeyUsage")
 	}
 
-	key, err := NewPrivateKey(config.PublicKeyAlgorithm)
+	key, err := newPrivateKey(config)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "unable to create private key")
 	}
@@ -227,6 +255,10 @@ func NewSignedCert(cfg *CertConfig, key crypto.Signer, caCert *x509.Certificate,
 	if cfg.NotAfter != nil {
 		notAfter = *cfg.NotAfter
 	}
+	// a cert outliving its CA is rejected by some clients, the external CAs are often short-lived
+	if notAfter.After(caCert.NotAfter) {
+		notAfter = caCert.NotAfter
+	}
 
 	certTmpl := x509.Certificate{
 		Subject: pkix.Name{
@@ -0,0 +1,310 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	certutil "k8s.io/client-go/util/cert"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+)
+
+func TestGetConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		cert         KubekeyCert
+		certificates kubekeyapiv1alpha2.Certificates
+		wantAlg      x509.PublicKeyAlgorithm
+		wantSize     int
+		wantValidity time.Duration
+		wantErr      bool
+	}{
+		{
+			name:    "default",
+			cert:    KubekeyCert{CAName: "ca"},
+			wantAlg: x509.RSA,
+		},
+		{
+			name:         "cluster config",
+			cert:         KubekeyCert{CAName: "ca"},
+			certificates: kubekeyapiv1alpha2.Certificates{KeyAlgorithm: "ecdsa", KeySize: 384, Validity: "8760h"},
+			wantAlg:      x509.ECDSA,
+			wantSize:     384,
+			wantValidity: 8760 * time.Hour,
+		},
+		{
+			name:         "the validity of a CA is not changed",
+			certificates: kubekeyapiv1alpha2.Certificates{Validity: "8760h"},
+			wantAlg:      x509.RSA,
+		},
+		{
+			name:         "the algorithm of the definition is kept",
+			cert:         KubekeyCert{CAName: "ca", Config: CertConfig{PublicKeyAlgorithm: x509.ECDSA}},
+			certificates: kubekeyapiv1alpha2.Certificates{KeySize: 4096},
+			wantAlg:      x509.ECDSA,
+		},
+		{
+			name:         "unknown algorithm",
+			certificates: kubekeyapiv1alpha2.Certificates{KeyAlgorithm: "dsa"},
+			wantErr:      true,
+		},
+		{
+			name:         "invalid validity",
+			cert:         KubekeyCert{CAName: "ca"},
+			certificates: kubekeyapiv1alpha2.Certificates{Validity: "1y"},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeConf := &common.KubeConf{Cluster: &kubekeyapiv1alpha2.ClusterSpec{Certificates: tt.certificates}}
+			cfg, err := tt.cert.GetConfig(kubeConf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if cfg.PublicKeyAlgorithm != tt.wantAlg || cfg.KeySize != tt.wantSize {
+				t.Errorf("GetConfig() = %v %d, want %v %d", cfg.PublicKeyAlgorithm, cfg.KeySize, tt.wantAlg, tt.wantSize)
+			}
+			if tt.wantValidity == 0 {
+				if cfg.NotAfter != nil {
+					t.Errorf("GetConfig() NotAfter = %v, want nil", cfg.NotAfter)
+				}
+			} else if cfg.NotAfter == nil || time.Until(*cfg.NotAfter) > tt.wantValidity ||
+				time.Until(*cfg.NotAfter) < tt.wantValidity-time.Minute {
+				t.Errorf("GetConfig() NotAfter = %v, want %v later", cfg.NotAfter, tt.wantValidity)
+			}
+			if tt.cert.Config.NotAfter != nil {
+				t.Error("GetConfig() changed the definition")
+			}
+		})
+	}
+}
+
+func TestValidateCertificates(t *testing.T) {
+	tests := []struct {
+		name         string
+		certificates kubekeyapiv1alpha2.Certificates
+		wantErr      bool
+	}{
+		{name: "default"},
+		{
+			name: "external CAs",
+			certificates: kubekeyapiv1alpha2.Certificates{
+				Kubernetes: kubekeyapiv1alpha2.ComponentCerts{CAFile: "ca.pem", CAKeyFile: "ca-key.pem"},
+				Etcd:       kubekeyapiv1alpha2.ComponentCerts{CAFile: "ca.pem", CertsDir: "/etc/ssl/etcd"},
+			},
+		},
+		{
+			name:         "pre-issued kubernetes certs",
+			certificates: kubekeyapiv1alpha2.Certificates{Kubernetes: kubekeyapiv1alpha2.ComponentCerts{CAFile: "ca.pem"}},
+			wantErr:      true,
+		},
+		{
+			name: "kubernetes certs dir",
+			certificates: kubekeyapiv1alpha2.Certificates{
+				Kubernetes: kubekeyapiv1alpha2.ComponentCerts{CAFile: "ca.pem", CAKeyFile: "ca-key.pem", CertsDir: "/etc/ssl/kubernetes"},
+			},
+			wantErr: true,
+		},
+		{
+			name:         "registry CA key without the CA",
+			certificates: kubekeyapiv1alpha2.Certificates{Registry: kubekeyapiv1alpha2.ComponentCerts{CAKeyFile: "ca-key.pem"}},
+			wantErr:      true,
+		},
+		{
+			name:         "invalid validity",
+			certificates: kubekeyapiv1alpha2.Certificates{Validity: "1y"},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.certificates.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGeneratePrivateKeyWithSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		alg     x509.PublicKeyAlgorithm
+		size    int
+		want    int
+		wantErr bool
+	}{
+		{name: "rsa", alg: x509.RSA, size: 3072, want: 3072},
+		{name: "rsa too small", alg: x509.RSA, size: 1024, wantErr: true},
+		{name: "ecdsa", alg: x509.ECDSA, size: 384, want: 384},
+		{name: "ecdsa unknown curve", alg: x509.ECDSA, size: 512, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := GeneratePrivateKeyWithSize(tt.alg, tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GeneratePrivateKeyWithSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var got int
+			switch k := key.(type) {
+			case *rsa.PrivateKey:
+				got = k.N.BitLen()
+			case *ecdsa.PrivateKey:
+				got = k.Curve.Params().BitSize
+			}
+			if got != tt.want {
+				t.Errorf("GeneratePrivateKeyWithSize() size = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSignedCertNotAfterCA(t *testing.T) {
+	caNotAfter := time.Now().Add(24 * time.Hour).UTC()
+	caCfg := &CertConfig{Config: certutil.Config{CommonName: "ca"}, NotAfter: &caNotAfter}
+	caKey, err := NewPrivateKey(x509.RSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the CA is self-signed by a temporary CA to get the validity of one day
+	tmpCA, tmpKey, err := NewCertificateAuthority(&CertConfig{Config: certutil.Config{CommonName: "tmp"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := NewSignedCert(caCfg, caKey, tmpCA, tmpKey, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, _, err := NewCertAndKey(caCert, caKey, &CertConfig{Config: certutil.Config{
+		CommonName: "server",
+		Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cert.NotAfter.Equal(caCert.NotAfter) {
+		t.Errorf("NotAfter = %v, want the one of the CA %v", cert.NotAfter, caCert.NotAfter)
+	}
+}
+
+func TestAppendSANs(t *testing.T) {
+	altNames := &certutil.AltNames{DNSNames: []string{"localhost"}}
+	AppendSANs(altNames, []string{"lb.example.com", "10.0.0.1", "localhost", "", "fd00::1"})
+
+	// the alt names are sorted when the duplicated ones are removed
+	if len(altNames.DNSNames) != 2 || altNames.DNSNames[0] != "lb.example.com" || altNames.DNSNames[1] != "localhost" {
+		t.Errorf("DNSNames = %v", altNames.DNSNames)
+	}
+	if len(altNames.IPs) != 2 {
+		t.Errorf("IPs = %v", altNames.IPs)
+	}
+}
+
+func TestUseExternalCerts(t *testing.T) {
+	src := t.TempDir()
+	certsDir := filepath.Join(src, "certs")
+	if err := os.MkdirAll(certsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, dir := range map[string]string{"ca.crt": src, "ca.key": src, "server.pem": certsDir, "server-key.pem": certsDir} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := []string{"ca.pem", "ca-key.pem", "server.pem", "server-key.pem", "client.pem", "client-key.pem"}
+
+	tests := []struct {
+		name    string
+		cfg     kubekeyapiv1alpha2.ComponentCerts
+		files   []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "not external",
+			want: map[string]string{},
+		},
+		{
+			name:  "ca and key",
+			cfg:   kubekeyapiv1alpha2.ComponentCerts{CAFile: filepath.Join(src, "ca.crt"), CAKeyFile: filepath.Join(src, "ca.key")},
+			files: files,
+			want:  map[string]string{"ca.pem": "ca.crt", "ca-key.pem": "ca.key"},
+		},
+		{
+			name: "pre-issued certs",
+			cfg: kubekeyapiv1alpha2.ComponentCerts{CAFile: filepath.Join(src, "ca.crt"), CAKeyFile: filepath.Join(src, "ca.key"),
+				CertsDir: certsDir},
+			files: files,
+			want: map[string]string{"ca.pem": "ca.crt", "ca-key.pem": "ca.key", "server.pem": "server.pem",
+				"server-key.pem": "server-key.pem"},
+		},
+		{
+			name:  "keyless CA with all the certs",
+			cfg:   kubekeyapiv1alpha2.ComponentCerts{CAFile: filepath.Join(src, "ca.crt"), CertsDir: certsDir},
+			files: files[:4],
+			want:  map[string]string{"ca.pem": "ca.crt", "server.pem": "server.pem", "server-key.pem": "server-key.pem"},
+		},
+		{
+			name:    "keyless CA without a cert",
+			cfg:     kubekeyapiv1alpha2.ComponentCerts{CAFile: filepath.Join(src, "ca.crt"), CertsDir: certsDir},
+			files:   files,
+			wantErr: true,
+		},
+		{
+			name:    "key without the CA",
+			cfg:     kubekeyapiv1alpha2.ComponentCerts{CAKeyFile: filepath.Join(src, "ca.key")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkiPath := filepath.Join(t.TempDir(), "pki")
+			err := UseExternalCerts(&tt.cfg, pkiPath, "ca", tt.files)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UseExternalCerts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			entries, _ := os.ReadDir(pkiPath)
+			if len(entries) != len(tt.want) {
+				t.Errorf("UseExternalCerts() wrote %d files, want %d", len(entries), len(tt.want))
+			}
+			for name, content := range tt.want {
+				data, err := os.ReadFile(filepath.Join(pkiPath, name))
+				if err != nil || string(data) != content {
+					t.Errorf("%s = %q, %v, want %q", name, data, err, content)
+				}
+			}
+		})
+	}
+}
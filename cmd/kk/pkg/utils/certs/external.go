@@ -0,0 +1,103 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	certutil "k8s.io/client-go/util/cert"
+	netutils "k8s.io/utils/net"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+)
+
+// AppendSANs appends the extra subject alternative names to the alt names, the IP addresses are told apart from
+// the DNS names by parsing them.
+func AppendSANs(altNames *certutil.AltNames, sans []string) {
+	for _, san := range sans {
+		if san == "" {
+			continue
+		}
+		if ip := netutils.ParseIPSloppy(san); ip != nil {
+			altNames.IPs = append(altNames.IPs, ip)
+		} else {
+			altNames.DNSNames = append(altNames.DNSNames, san)
+		}
+	}
+	RemoveDuplicateAltNames(altNames)
+}
+
+// UseExternalCerts copies the CA given by the user, and the certs in its certs dir, into the pki path, so that they
+// are used instead of being generated. The CA is named after caBaseName and the certs must be named as the files.
+// Without the CA key, all the files must be given in the certs dir because no cert can be signed.
+func UseExternalCerts(cfg *kubekeyapiv1alpha2.ComponentCerts, pkiPath, caBaseName string, files []string) error {
+	if !cfg.IsExternal() {
+		if cfg.CAKeyFile != "" || cfg.CertsDir != "" {
+			return errors.New("the caKeyFile and the certsDir require the caFile")
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(pkiPath, os.ModePerm); err != nil {
+		return errors.Wrapf(err, "create dir %s failed", pkiPath)
+	}
+
+	caCertPath, caKeyPath := PathsForCertAndKey(pkiPath, caBaseName)
+	if err := copyFile(cfg.CAFile, caCertPath); err != nil {
+		return err
+	}
+	if cfg.CAKeyFile != "" {
+		if err := copyFile(cfg.CAKeyFile, caKeyPath); err != nil {
+			return err
+		}
+	} else if err := os.RemoveAll(caKeyPath); err != nil {
+		// a key left by an earlier run does not match the external CA
+		return errors.Wrapf(err, "remove %s failed", caKeyPath)
+	}
+
+	for _, name := range files {
+		if name == filepath.Base(caCertPath) || name == filepath.Base(caKeyPath) {
+			continue
+		}
+		src := filepath.Join(cfg.CertsDir, name)
+		if cfg.CertsDir != "" {
+			if _, err := os.Stat(src); err == nil {
+				if err := copyFile(src, filepath.Join(pkiPath, name)); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if cfg.CAKeyFile == "" {
+			return errors.Errorf("%s is not found in the certs dir %q, it can not be signed without the caKeyFile", name, cfg.CertsDir)
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return errors.Wrapf(err, "read %s failed", src)
+	}
+	if err := os.WriteFile(dst, data, 0600); err != nil {
+		return errors.Wrapf(err, "write %s failed", dst)
+	}
+	return nil
+}
@@ -159,6 +159,8 @@ type CertConfig struct {
 	certutil.Config
 	NotAfter           *time.Time
 	PublicKeyAlgorithm x509.PublicKeyAlgorithm
+	// KeySize is the size of the private key in bits, the default size of the algorithm is used if it is 0.
+	KeySize int
 }
 
 var (
@@ -178,9 +180,38 @@ func GeneratePrivateKey(keyType x509.PublicKeyAlgorithm) (crypto.Signer, error)
 	return rsa.GenerateKey(cryptorand.Reader, rsaKeySize)
 }
 
+// GeneratePrivateKeyWithSize generates a private key of the size in bits, which is the size of an RSA key or the
+// curve size of an ECDSA key.
+func GeneratePrivateKeyWithSize(keyType x509.PublicKeyAlgorithm, size int) (crypto.Signer, error) {
+	if keyType == x509.ECDSA {
+		switch size {
+		case 256:
+			return ecdsa.GenerateKey(elliptic.P256(), cryptorand.Reader)
+		case 384:
+			return ecdsa.GenerateKey(elliptic.P384(), cryptorand.Reader)
+		case 521:
+			return ecdsa.GenerateKey(elliptic.P521(), cryptorand.Reader)
+		default:
+			return nil, errors.Errorf("unsupported ECDSA key size %d, it should be 256, 384 or 521", size)
+		}
+	}
+
+	if size < rsaKeySize {
+		return nil, errors.Errorf("unsupported RSA key size %d, it should be at least %d", size, rsaKeySize)
+	}
+	return rsa.GenerateKey(cryptorand.Reader, size)
+}
+
+func newPrivateKey(config *CertConfig) (crypto.Signer, error) {
+	if config.KeySize == 0 {
+		return NewPrivateKey(config.PublicKeyAlgorithm)
+	}
+	return GeneratePrivateKeyWithSize(config.PublicKeyAlgorithm, config.KeySize)
+}
+
 // NewCertificateAuthority creates new certificate and private key for the certificate authority
 func NewCertificateAuthority(config *CertConfig) (*x509.Certificate, crypto.Signer, error) {
-	key, err := NewPrivateKey(config.PublicKeyAlgorithm)
+	key, err := newPrivateKey(config)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "unable to create private key while generating CA certificate")
 	}
@@ -57,6 +57,8 @@ import (
 	"github.com/kubesphere/kubekey/v3/pkg/service/provisioning"
 	"github.com/kubesphere/kubekey/v3/pkg/service/repository"
 	"github.com/kubesphere/kubekey/v3/util"
+	"github.com/kubesphere/kubekey/v3/util/hostkey"
+	"github.com/kubesphere/kubekey/v3/util/secret"
 )
 
 const (
@@ -110,7 +112,11 @@ func (r *Reconciler) getSSHClient(scope *scope.InstanceScope) ssh.Interface {
 			}
 		}
 	}
-	return ssh.NewClient(scope.KKInstance.Spec.Address, scope.KKInstance.Spec.Auth, &scope.Logger)
+	// the host keys recorded on the first connections are kept in a secret of the cluster
+	knownHosts := hostkey.New(&secret.KnownHostsStore{Client: r.Client, Cluster: scope.Cluster}, func(format string, args ...interface{}) {
+		scope.Logger.V(4).Info(fmt.Sprintf(format, args...))
+	})
+	return ssh.NewClient(scope.KKInstance.Spec.Address, scope.KKInstance.Spec.Auth, knownHosts, &scope.Logger)
 }
 
 func (r *Reconciler) getBootstrapService(sshClient ssh.Interface, scope scope.LBScope, instanceScope *scope.InstanceScope) service.Bootstrap {
@@ -0,0 +1,54 @@
+#!/bin/bash
+
+# Copyright 2022 The KubeSphere Authors.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+#####################################################################
+#
+#  Usage:
+#    Add the checksums of the CRI-O static bundles to version/components.json.
+#
+#    For example:
+#
+#      bash hack/fetch-crio-hash.sh 1.29.1 1.30.0
+#
+####################################################################
+
+set -e
+
+versions=("$@")
+if [ ${#versions[@]} -eq 0 ]; then
+  echo "usage: $0 VERSION..."
+  exit 1
+fi
+
+arches=("amd64" "arm64")
+file="version/components.json"
+json=$(cat "${file}")
+for arch in "${arches[@]}"
+do
+  for ver in "${versions[@]}"
+  do
+    url="https://github.com/cri-o/cri-o/releases/download/v${ver}/cri-o.${arch}.v${ver}.tar.gz.sha256sum"
+    hash=$(wget --quiet -O - "$url" | awk '{print $1}')
+    if [ -z "${hash}" ]; then
+      echo "failed to fetch the checksum of cri-o ${ver} ${arch}"
+      exit 1
+    fi
+    echo "crio@${arch} \"${ver}\": \"${hash}\""
+    json=$(echo "$json" | jq ".crio.${arch} += {\"${ver}\":\"${hash}\"}")
+  done
+done
+
+echo "$json" | jq --indent 4 . > "${file}"
@@ -42,6 +42,7 @@ ETCD_VERSION=${ETCD_VERSION}
 CRICTL_VERSION=${CRICTL_VERSION}
 K3S_VERSION=${K3S_VERSION}
 CONTAINERD_VERSION=${CONTAINERD_VERSION}
+CRIO_VERSION=${CRIO_VERSION}
 RUNC_VERSION=${RUNC_VERSION}
 COMPOSE_VERSION=${COMPOSE_VERSION}
 CALICO_VERSION=${CALICO_VERSION}
@@ -254,6 +255,26 @@ if [ $CONTAINERD_VERSION ]; then
    rm -rf binaries
 fi
 
+# Sync CRI-O Binary
+if [ $CRIO_VERSION ]; then
+   for arch in ${ARCHS[@]}
+   do
+     mkdir -p binaries/crio/$CRIO_VERSION/$arch
+     echo "Synchronizing cri-o-$arch"
+
+     curl -L -o binaries/crio/$CRIO_VERSION/$arch/cri-o.$arch.v$CRIO_VERSION.tar.gz \
+                https://github.com/cri-o/cri-o/releases/download/v$CRIO_VERSION/cri-o.$arch.v$CRIO_VERSION.tar.gz
+
+     sha256sum binaries/crio/$CRIO_VERSION/$arch/cri-o.$arch.v$CRIO_VERSION.tar.gz
+
+     qsctl cp binaries/crio/$CRIO_VERSION/$arch/cri-o.$arch.v$CRIO_VERSION.tar.gz \
+           qs://kubernetes-release/cri-o/cri-o/releases/download/v$CRIO_VERSION/cri-o.$arch.v$CRIO_VERSION.tar.gz \
+           -c qsctl-config.yaml
+   done
+
+   rm -rf binaries
+fi
+
 # Sync runc Binary
 if [ $RUNC_VERSION ]; then
    for arch in ${ARCHS[@]}
@@ -37,6 +37,7 @@ import (
 
 	infrav1 "github.com/kubesphere/kubekey/v3/api/v1beta1"
 	"github.com/kubesphere/kubekey/v3/pkg/util/filesystem"
+	"github.com/kubesphere/kubekey/v3/util/hostkey"
 )
 
 // Default values.
@@ -56,14 +57,18 @@ type Client struct {
 	privateKey     string
 	privateKeyPath string
 	timeout        *time.Duration
+	hostKeyCheck   string
+	fingerprint    string
+	knownHosts     *hostkey.KnownHosts
 	host           string
 	sshClient      *ssh.Client
 	sftpClient     *sftp.Client
 	fs             filesystem.Interface
 }
 
-// NewClient returns a new client given ssh information.
-func NewClient(host string, auth infrav1.Auth, log *logr.Logger) Interface {
+// NewClient returns a new client given ssh information. The host keys are verified against the known hosts, only a
+// pinned fingerprint is verified if it is nil.
+func NewClient(host string, auth infrav1.Auth, knownHosts *hostkey.KnownHosts, log *logr.Logger) Interface {
 	if log == nil {
 		l := klogr.New()
 		log = &l
@@ -89,6 +94,9 @@ func NewClient(host string, auth infrav1.Auth, log *logr.Logger) Interface {
 		privateKey:     auth.PrivateKey,
 		privateKeyPath: auth.PrivateKeyPath,
 		timeout:        auth.Timeout,
+		hostKeyCheck:   auth.HostKeyChecking,
+		fingerprint:    auth.HostKeyFingerprint,
+		knownHosts:     knownHosts,
 		host:           host,
 		fs:             filesystem.NewFileSystem(),
 		Logger:         *log,
@@ -102,13 +110,16 @@ func (c *Client) Connect() error {
 		return errors.Wrap(err, "The given SSH key could not be parsed")
 	}
 
+	hostKeyCallback, err := c.knownHosts.HostKeyCallback(c.hostKeyCheck, c.fingerprint)
+	if err != nil {
+		return err
+	}
+
 	sshConfig := &ssh.ClientConfig{
-		User:    c.user,
-		Timeout: *c.timeout,
-		Auth:    authMethods,
-		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
-			return nil
-		},
+		User:            c.user,
+		Timeout:         *c.timeout,
+		Auth:            authMethods,
+		HostKeyCallback: hostKeyCallback,
 	}
 
 	endpoint := net.JoinHostPort(c.host, strconv.Itoa(*c.port))
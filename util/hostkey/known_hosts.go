@@ -0,0 +1,195 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package hostkey
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	// CheckingStrict only connects to the hosts whose keys are in the known hosts.
+	CheckingStrict = "strict"
+	// CheckingAcceptNew records the keys of the new hosts, and refuses the keys which have changed.
+	CheckingAcceptNew = "accept-new"
+	// CheckingOff does not verify the host keys.
+	CheckingOff = "off"
+)
+
+// Store keeps the known hosts in the format of the OpenSSH known_hosts file.
+type Store interface {
+	// Load returns the known hosts, it is empty if nothing is recorded yet.
+	Load() ([]byte, error)
+	// Add records the known_hosts line of a new host key.
+	Add(line string) error
+	// String describes where the known hosts are kept in the messages.
+	String() string
+}
+
+// Logger logs the host keys recorded in the store.
+type Logger func(format string, args ...interface{})
+
+// KnownHosts verifies the host keys against the known hosts in the store.
+type KnownHosts struct {
+	mu    sync.Mutex
+	store Store
+	log   Logger
+}
+
+// New returns the KnownHosts of the store, the log may be nil.
+func New(store Store, log Logger) *KnownHosts {
+	return &KnownHosts{store: store, log: log}
+}
+
+// HostKeyCallback verifies the host keys by the checking mode. A pinned fingerprint is always verified, a key
+// matching it is trusted and recorded like a new key in the accept-new mode. A nil KnownHosts only verifies the
+// pinned fingerprint.
+func (k *KnownHosts) HostKeyCallback(mode, fingerprint string) (ssh.HostKeyCallback, error) {
+	switch mode {
+	case "":
+		mode = CheckingAcceptNew
+	case CheckingStrict, CheckingAcceptNew, CheckingOff:
+	default:
+		return nil, errors.Errorf("unknown host key checking mode %q, it should be one of %s, %s and %s",
+			mode, CheckingStrict, CheckingAcceptNew, CheckingOff)
+	}
+	if fingerprint != "" && !strings.HasPrefix(fingerprint, "SHA256:") {
+		fingerprint = "SHA256:" + fingerprint
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		accept := mode == CheckingAcceptNew
+		if fingerprint != "" {
+			if actual := ssh.FingerprintSHA256(key); actual != fingerprint {
+				return errors.Errorf("host key verification failed for %s: the fingerprint of its %s key is %s, "+
+					"but %s is pinned by hostKeyFingerprint in the cluster config", hostname, key.Type(), actual, fingerprint)
+			}
+			accept = true
+		}
+		if mode == CheckingOff || k == nil {
+			return nil
+		}
+		return k.verify(hostname, remote, key, accept)
+	}, nil
+}
+
+func (k *KnownHosts) verify(hostname string, remote net.Addr, key ssh.PublicKey, accept bool) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	callback, err := k.load()
+	if err != nil {
+		return err
+	}
+
+	err = callback(hostname, remote, key)
+	if err == nil {
+		return nil
+	}
+	var keyErr *knownhosts.KeyError
+	if !errors.As(err, &keyErr) {
+		return errors.Wrapf(err, "host key verification failed for %s", hostname)
+	}
+	if len(keyErr.Want) > 0 {
+		return errors.Errorf("host key verification failed for %s: its %s key %s does not match the key recorded at line %d "+
+			"of %s, the key has changed since KubeKey last connected to it. If the host has been reinstalled, remove the line "+
+			"and try again, otherwise someone may be intercepting the connection",
+			hostname, key.Type(), ssh.FingerprintSHA256(key), keyErr.Want[0].Line, k.store)
+	}
+	if !accept {
+		return errors.Errorf("host key verification failed for %s: its %s key %s is not in %s while hostKeyChecking is %s. "+
+			"Add the key to it, pin it by hostKeyFingerprint, or use the %s mode to record it on the first connection",
+			hostname, key.Type(), ssh.FingerprintSHA256(key), k.store, CheckingStrict, CheckingAcceptNew)
+	}
+
+	if err := k.store.Add(knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)); err != nil {
+		return err
+	}
+	if k.log != nil {
+		k.log("add the %s key %s of %s to %s", key.Type(), ssh.FingerprintSHA256(key), hostname, k.store)
+	}
+	return nil
+}
+
+// load parses the known hosts in the store, knownhosts only reads them from a file.
+func (k *KnownHosts) load() (ssh.HostKeyCallback, error) {
+	data, err := k.store.Load()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp("", "known_hosts")
+	if err != nil {
+		return nil, errors.Wrap(err, "create the temporary known hosts file failed")
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return nil, errors.Wrapf(err, "write %s failed", f.Name())
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	callback, err := knownhosts.New(f.Name())
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse the known hosts in %s failed", k.store)
+	}
+	return callback, nil
+}
+
+// FileStore keeps the known hosts in a known_hosts file.
+type FileStore struct {
+	Path string
+}
+
+func (s *FileStore) Load() ([]byte, error) {
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "read known hosts %s failed", s.Path)
+	}
+	return data, nil
+}
+
+func (s *FileStore) Add(line string) error {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return errors.Wrap(err, "create known hosts dir failed")
+	}
+	f, err := os.OpenFile(s.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "open known hosts %s failed", s.Path)
+	}
+	defer f.Close()
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		return errors.Wrapf(err, "write known hosts %s failed", s.Path)
+	}
+	return nil
+}
+
+func (s *FileStore) String() string {
+	return s.Path
+}
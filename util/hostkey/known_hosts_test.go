@@ -0,0 +1,113 @@
+/*
+ Copyright 2021 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package hostkey
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func newHostKey(t *testing.T) ssh.PublicKey {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestKnownHosts_HostKeyCallback(t *testing.T) {
+	key := newHostKey(t)
+	changed := newHostKey(t)
+	remote := &net.TCPAddr{IP: net.ParseIP("172.16.0.2"), Port: 22}
+	hostname := "172.16.0.2:22"
+
+	tests := []struct {
+		name        string
+		mode        string
+		fingerprint string
+		known       ssh.PublicKey
+		key         ssh.PublicKey
+		wantErr     string
+	}{
+		{name: "accept new host", mode: CheckingAcceptNew, key: key},
+		{name: "default mode accepts new host", key: key},
+		{name: "accept known host", mode: CheckingStrict, known: key, key: key},
+		{name: "refuse unknown host in strict mode", mode: CheckingStrict, key: key, wantErr: "is not in"},
+		{name: "refuse changed key", mode: CheckingAcceptNew, known: key, key: changed, wantErr: "has changed"},
+		{name: "changed key with checking off", mode: CheckingOff, known: key, key: changed},
+		{name: "pinned fingerprint in strict mode", mode: CheckingStrict, fingerprint: ssh.FingerprintSHA256(key), key: key},
+		{name: "pinned fingerprint without prefix", mode: CheckingStrict,
+			fingerprint: strings.TrimPrefix(ssh.FingerprintSHA256(key), "SHA256:"), key: key},
+		{name: "pinned fingerprint mismatch", mode: CheckingOff, fingerprint: ssh.FingerprintSHA256(key), key: changed,
+			wantErr: "is pinned"},
+		{name: "unknown mode", mode: "yes", key: key, wantErr: "unknown host key checking mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := New(&FileStore{Path: filepath.Join(t.TempDir(), "kubekey", "known_hosts")}, t.Logf)
+			if tt.known != nil {
+				if err := k.verify(hostname, remote, tt.known, true); err != nil {
+					t.Fatalf("record the known key failed: %v", err)
+				}
+			}
+
+			callback, err := k.HostKeyCallback(tt.mode, tt.fingerprint)
+			if err == nil {
+				err = callback(hostname, remote, tt.key)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestKnownHosts_RecordNewHost(t *testing.T) {
+	key := newHostKey(t)
+	remote := &net.TCPAddr{IP: net.ParseIP("172.16.0.3"), Port: 22}
+	k := New(&FileStore{Path: filepath.Join(t.TempDir(), "known_hosts")}, t.Logf)
+
+	accept, _ := k.HostKeyCallback(CheckingAcceptNew, "")
+	if err := accept("172.16.0.3:22", remote, key); err != nil {
+		t.Fatalf("accept the new host failed: %v", err)
+	}
+	// the key recorded on the first connection is trusted in the strict mode later
+	strict, _ := k.HostKeyCallback(CheckingStrict, "")
+	if err := strict("172.16.0.3:22", remote, key); err != nil {
+		t.Fatalf("the recorded key should be trusted: %v", err)
+	}
+	if err := strict("172.16.0.3:22", remote, newHostKey(t)); err == nil {
+		t.Fatalf("a changed key should be refused")
+	}
+}
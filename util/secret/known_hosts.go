@@ -0,0 +1,112 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package secret
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// KnownHostsDataName is the key used to store the known hosts in the secret's data field.
+	KnownHostsDataName = "known_hosts"
+
+	// KnownHosts is the secret name suffix for the known hosts of the machines of the cluster.
+	KnownHosts Purpose = "knownhosts"
+
+	knownHostsTimeout = 15 * time.Second
+)
+
+// KnownHostsStore keeps the SSH known hosts of the machines of a cluster in a secret owned by the cluster, so that
+// the host keys recorded on the first connections are still trusted after the controller restarts.
+type KnownHostsStore struct {
+	Client  client.Client
+	Cluster *clusterv1.Cluster
+}
+
+// Load returns the known hosts in the secret, it is empty if the secret does not exist yet.
+func (s *KnownHostsStore) Load() ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), knownHostsTimeout)
+	defer cancel()
+
+	secret, err := Get(ctx, s.Client, client.ObjectKeyFromObject(s.Cluster), KnownHosts)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "get %s failed", s)
+	}
+	return secret.Data[KnownHostsDataName], nil
+}
+
+// Add appends the line to the known hosts in the secret, the secret is created if it does not exist.
+func (s *KnownHostsStore) Add(line string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), knownHostsTimeout)
+	defer cancel()
+
+	// the secret may be updated or created by the reconciling of another machine at the same time
+	retriable := func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}
+	err := retry.OnError(retry.DefaultBackoff, retriable, func() error {
+		secret, err := Get(ctx, s.Client, client.ObjectKeyFromObject(s.Cluster), KnownHosts)
+		if apierrors.IsNotFound(err) {
+			return s.Client.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      Name(s.Cluster.Name, KnownHosts),
+					Namespace: s.Cluster.Namespace,
+					Labels:    map[string]string{clusterv1.ClusterLabelName: s.Cluster.Name},
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: clusterv1.GroupVersion.String(),
+						Kind:       "Cluster",
+						Name:       s.Cluster.Name,
+						UID:        s.Cluster.UID,
+					}},
+				},
+				Data: map[string][]byte{KnownHostsDataName: []byte(line + "\n")},
+				Type: clusterv1.ClusterSecretType,
+			})
+		}
+		if err != nil {
+			return err
+		}
+
+		patch := client.MergeFromWithOptions(secret.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		secret.Data[KnownHostsDataName] = append(secret.Data[KnownHostsDataName], []byte(line+"\n")...)
+		return s.Client.Patch(ctx, secret, patch)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "add the host key to %s failed", s)
+	}
+	return nil
+}
+
+func (s *KnownHostsStore) String() string {
+	return fmt.Sprintf("secret %s/%s", s.Cluster.Namespace, Name(s.Cluster.Name, KnownHosts))
+}
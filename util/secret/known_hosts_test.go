@@ -0,0 +1,58 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package secret
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestKnownHostsStore(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "uid"}}
+	store := &KnownHostsStore{Client: fake.NewClientBuilder().WithScheme(scheme).Build(), Cluster: cluster}
+
+	data, err := store.Load()
+	if err != nil || len(data) != 0 {
+		t.Fatalf("Load() = %q, %v, want empty known hosts", data, err)
+	}
+
+	lines := []string{"172.16.0.2 ssh-ed25519 AAAA1", "172.16.0.3 ssh-ed25519 AAAA2"}
+	for _, line := range lines {
+		if err := store.Add(line); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	// a store created after a restart of the controller loads the recorded keys
+	restarted := &KnownHostsStore{Client: store.Client, Cluster: cluster}
+	data, err = restarted.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if want := lines[0] + "\n" + lines[1] + "\n"; string(data) != want {
+		t.Errorf("Load() = %q, want %q", data, want)
+	}
+}